@@ -8,12 +8,14 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/stlpine/will-it-compile/internal/api"
 	"github.com/stlpine/will-it-compile/internal/config"
 	"github.com/stlpine/will-it-compile/internal/storage"
+	"github.com/stlpine/will-it-compile/pkg/models"
 )
 
 func main() {
@@ -43,8 +45,18 @@ func main() {
 
 	// Create server configuration
 	serverConfig := api.ServerConfig{
-		MaxWorkers: cfg.Workers.MaxWorkers,
-		QueueSize:  cfg.Workers.QueueSize,
+		MaxWorkers:          cfg.Workers.MaxWorkers,
+		QueueSize:           cfg.Workers.QueueSize,
+		StaleJobGracePeriod: cfg.Workers.StaleJobGracePeriod,
+		MaxBodyBytes:        api.MaxBodyBytesFor(cfg.Compilation.MaxSourceSize),
+		MaxSourceSize:       cfg.Compilation.MaxSourceSize,
+		MaxBatchSize:        cfg.Server.MaxBatchSize,
+		AdminToken:          cfg.Server.AdminToken,
+		APIKeys:             cfg.Server.APIKeys,
+		APIKeyRateLimit:     cfg.Server.APIKeyRateLimit,
+		TrustedAPIKeys:      cfg.Server.TrustedAPIKeys,
+		DailyQuota:          cfg.Server.DailyQuota,
+		WorkerReservations:  cfg.Workers.Reservations,
 	}
 
 	// Create API server with storage
@@ -58,6 +70,33 @@ func main() {
 		}
 	}()
 
+	// A floating tag may have moved to another patch release since the versions were pinned
+	if err := server.VerifyToolchainVersions(context.Background()); err != nil {
+		log.Fatalf("Toolchain version check failed, refusing to start: %v", err)
+	}
+
+	// Report what each image's compiler actually is, which may differ from the configured version
+	if err := server.ProbeVersions(context.Background()); err != nil {
+		log.Printf("Warning: some compiler versions could not be probed: %v", err)
+	}
+
+	// Catch images that exist but cannot compile before serving any request
+	if cfg.Server.SelfTest {
+		log.Println("Running self-test: compiling hello world in every environment...")
+		if err := server.SelfTest(context.Background()); err != nil {
+			log.Fatalf("Self-test failed, refusing to start: %v", err)
+		}
+		log.Println("Self-test passed")
+	} else if cfg.Server.Warmup {
+		// The self-test already compiled in every environment, which warmed them as well
+		log.Println("Warming up: compiling hello world in every environment...")
+		if err := server.Warmup(context.Background()); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			log.Println("Warm-up done")
+		}
+	}
+
 	// Create Echo instance with rate limiting enabled
 	e := api.NewEchoServer(server, true)
 
@@ -70,6 +109,23 @@ func main() {
 		}
 	}()
 
+	// Reload environments.yaml on SIGHUP without restarting
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			log.Println("Received SIGHUP, reloading environments configuration...")
+			if err := server.ReloadEnvironments(context.Background()); err != nil {
+				log.Printf("Config reload failed, keeping previous configuration: %v", err)
+				continue
+			}
+			log.Println("Environments configuration reloaded")
+			if err := server.ProbeVersions(context.Background()); err != nil {
+				log.Printf("Warning: some compiler versions could not be probed: %v", err)
+			}
+		}
+	}()
+
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -103,6 +159,44 @@ func loadConfig() *config.Config {
 		cfg.Server.Environment = env
 	}
 
+	if maxBatch := os.Getenv("MAX_BATCH_SIZE"); maxBatch != "" {
+		if b, err := strconv.Atoi(maxBatch); err == nil {
+			cfg.Server.MaxBatchSize = b
+		}
+	}
+
+	if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+		cfg.Server.AdminToken = token
+	}
+
+	if selfTest := os.Getenv("SELF_TEST"); selfTest == "true" {
+		cfg.Server.SelfTest = true
+	}
+
+	if warmup := os.Getenv("WARMUP"); warmup == "true" {
+		cfg.Server.Warmup = true
+	}
+
+	if keys := os.Getenv("API_KEYS"); keys != "" {
+		cfg.Server.APIKeys = splitList(keys)
+	}
+
+	if keys := os.Getenv("TRUSTED_API_KEYS"); keys != "" {
+		cfg.Server.TrustedAPIKeys = splitList(keys)
+	}
+
+	if limit := os.Getenv("API_KEY_RATE_LIMIT"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			cfg.Server.APIKeyRateLimit = l
+		}
+	}
+
+	if quota := os.Getenv("DAILY_COMPILE_QUOTA"); quota != "" {
+		if q, err := strconv.Atoi(quota); err == nil {
+			cfg.Server.DailyQuota = q
+		}
+	}
+
 	// Redis configuration
 	if enabled := os.Getenv("REDIS_ENABLED"); enabled == "true" {
 		cfg.Redis.Enabled = true
@@ -134,6 +228,45 @@ func loadConfig() *config.Config {
 		}
 	}
 
+	if prefix := os.Getenv("REDIS_KEY_PREFIX"); prefix != "" {
+		cfg.Redis.KeyPrefix = prefix
+	}
+
+	if sliding := os.Getenv("REDIS_SLIDING_TTL"); sliding == "true" {
+		cfg.Redis.SlidingTTL = true
+	}
+
+	if maxAge := os.Getenv("REDIS_MAX_JOB_AGE_HOURS"); maxAge != "" {
+		if hours, err := strconv.Atoi(maxAge); err == nil {
+			cfg.Redis.MaxJobAge = time.Duration(hours) * time.Hour
+		}
+	}
+
+	// Storage configuration
+	if maxStored := os.Getenv("MAX_STORED_OUTPUT_BYTES"); maxStored != "" {
+		if m, err := strconv.Atoi(maxStored); err == nil {
+			cfg.Storage.MaxStoredOutputBytes = m
+		}
+	}
+
+	if maxJobs := os.Getenv("MAX_MEMORY_JOBS"); maxJobs != "" {
+		if m, err := strconv.Atoi(maxJobs); err == nil {
+			cfg.Storage.MaxMemoryJobs = m
+		}
+	}
+
+	// Result retention by final status, e.g. RESULT_TTL_FAILED_HOURS=168
+	for _, status := range []models.JobStatus{models.StatusCompleted, models.StatusFailed, models.StatusTimeout, models.StatusError} {
+		if ttl := os.Getenv("RESULT_TTL_" + strings.ToUpper(string(status)) + "_HOURS"); ttl != "" {
+			if hours, err := strconv.Atoi(ttl); err == nil {
+				if cfg.Storage.ResultTTLByStatus == nil {
+					cfg.Storage.ResultTTLByStatus = make(map[models.JobStatus]time.Duration)
+				}
+				cfg.Storage.ResultTTLByStatus[status] = time.Duration(hours) * time.Hour
+			}
+		}
+	}
+
 	// Worker configuration
 	if maxWorkers := os.Getenv("MAX_WORKERS"); maxWorkers != "" {
 		if w, err := strconv.Atoi(maxWorkers); err == nil {
@@ -147,5 +280,42 @@ func loadConfig() *config.Config {
 		}
 	}
 
+	if grace := os.Getenv("STALE_JOB_GRACE_SECONDS"); grace != "" {
+		if seconds, err := strconv.Atoi(grace); err == nil {
+			cfg.Workers.StaleJobGracePeriod = time.Duration(seconds) * time.Second
+		}
+	}
+
+	// Workers reserved per language, e.g. WORKER_RESERVATIONS=go=2,c=1
+	if reservations := os.Getenv("WORKER_RESERVATIONS"); reservations != "" {
+		for _, entry := range splitList(reservations) {
+			language, slots, _ := strings.Cut(entry, "=")
+			if n, err := strconv.Atoi(slots); err == nil {
+				if cfg.Workers.Reservations == nil {
+					cfg.Workers.Reservations = make(map[models.Language]int)
+				}
+				cfg.Workers.Reservations[models.Language(strings.TrimSpace(language))] = n
+			}
+		}
+	}
+
+	// Compilation configuration
+	if maxSource := os.Getenv("MAX_SOURCE_SIZE"); maxSource != "" {
+		if m, err := strconv.Atoi(maxSource); err == nil {
+			cfg.Compilation.MaxSourceSize = m
+		}
+	}
+
 	return cfg
 }
+
+// splitList splits a comma-separated environment variable, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
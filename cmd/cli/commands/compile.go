@@ -39,6 +39,13 @@ resource limits and no network access.`,
   # Compile with specific compiler
   will-it-compile compile mycode.cpp --compiler=gcc-13
 
+  # Compile Go or Rust (go-1.23 and rustc-1.80 unless --compiler is set)
+  will-it-compile compile main.go
+  will-it-compile compile main.rs
+
+  # Compile on a server and print a link to the result
+  will-it-compile compile mycode.cpp --remote=http://localhost:8080 --share
+
   # Verbose output
   will-it-compile compile mycode.cpp --verbose`,
 	Args: cobra.ExactArgs(1),
@@ -51,6 +58,8 @@ var (
 	compileTimeout    int
 	compileShowStdout bool
 	compileShowStderr bool
+	compileRemote     string
+	compileShare      bool
 )
 
 func init() {
@@ -62,6 +71,8 @@ func init() {
 	compileCmd.Flags().IntVar(&compileTimeout, "timeout", 30, "compilation timeout in seconds")
 	compileCmd.Flags().BoolVar(&compileShowStdout, "stdout", true, "show compilation stdout")
 	compileCmd.Flags().BoolVar(&compileShowStderr, "stderr", true, "show compilation stderr")
+	compileCmd.Flags().StringVar(&compileRemote, "remote", "", "compile on a will-it-compile server (e.g., http://localhost:8080) instead of local Docker")
+	compileCmd.Flags().BoolVar(&compileShare, "share", false, "with --remote, print only the result's shareable URL and exit")
 }
 
 func runCompile(cmd *cobra.Command, args []string) error {
@@ -106,38 +117,40 @@ func runCompile(cmd *cobra.Command, args []string) error {
 
 	if compileCompiler != "" {
 		request.Compiler = models.Compiler(compileCompiler)
+	} else {
+		request.Compiler = defaultCompiler(language)
 	}
 
-	printInfo(cmd, "Compiling %s...", filepath.Base(filePath))
-	printVerbose(cmd, "Language: %s, Compiler: %s, Standard: %s", request.Language, request.Compiler, request.Standard)
-
-	// Create Docker runtime (CLI always uses Docker)
-	dockerRuntime, err := docker.NewDockerRuntime()
-	if err != nil {
-		printError("failed to create Docker runtime: %v", err)
-		return err
+	if compileShare && compileRemote == "" {
+		printError("%v", ErrShareRequiresRemote)
+		return ErrShareRequiresRemote
 	}
-	defer func() {
-		if err := dockerRuntime.Close(); err != nil {
-			printError("failed to close Docker runtime: %v", err)
-		}
-	}()
-
-	// Create compiler
-	comp := compiler.NewCompilerWithRuntime(dockerRuntime)
 
-	// Create compilation job
-	job := models.CompilationJob{
-		ID:      uuid.New().String(),
-		Request: request,
+	// Only the permalink is printed with --share, so it can be piped
+	if compileShare {
+		url, err := shareRemote(request)
+		if err != nil {
+			printError("%v", err)
+			return err
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), url) //nolint:errcheck // stdout write
+		return nil
 	}
 
-	// Run compilation with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(compileTimeout)*time.Second)
-	defer cancel()
+	printInfo(cmd, "Compiling %s...", filepath.Base(filePath))
+	printVerbose(cmd, "Language: %s, Compiler: %s, Standard: %s", request.Language, request.Compiler, request.Standard)
 
 	startTime := time.Now()
-	result := comp.Compile(ctx, job)
+	var result models.CompilationResult
+	if compileRemote != "" {
+		result, err = compileRemotely(cmd, request)
+	} else {
+		result, err = compileLocally(request)
+	}
+	if err != nil {
+		printError("%v", err)
+		return err
+	}
 	duration := time.Since(startTime)
 
 	// Print results
@@ -178,6 +191,64 @@ func runCompile(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// compileLocally compiles the request in a local Docker container.
+func compileLocally(request models.CompilationRequest) (models.CompilationResult, error) {
+	// Create Docker runtime (local compiles always use Docker)
+	dockerRuntime, err := docker.NewDockerRuntime()
+	if err != nil {
+		return models.CompilationResult{}, fmt.Errorf("failed to create Docker runtime: %w", err)
+	}
+	defer func() {
+		if err := dockerRuntime.Close(); err != nil {
+			printError("failed to close Docker runtime: %v", err)
+		}
+	}()
+
+	// Create compiler
+	comp := compiler.NewCompilerWithRuntime(dockerRuntime)
+
+	// Create compilation job
+	job := models.CompilationJob{
+		ID:      uuid.New().String(),
+		Request: request,
+	}
+
+	// Run compilation with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(compileTimeout)*time.Second)
+	defer cancel()
+
+	return comp.Compile(ctx, job), nil
+}
+
+// compileRemotely submits the request to the --remote server, prints the result's
+// permalink, and waits for the result.
+func compileRemotely(cmd *cobra.Command, request models.CompilationRequest) (models.CompilationResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(compileTimeout)*time.Second)
+	defer cancel()
+
+	job, err := submitRemote(ctx, compileRemote, request)
+	if err != nil {
+		return models.CompilationResult{}, err
+	}
+
+	url := shareURL(compileRemote, job.JobID)
+	printInfo(cmd, "Submitted job %s: %s", job.JobID, url)
+
+	return waitRemoteResult(ctx, url)
+}
+
+// shareRemote submits the request to the --remote server and returns the result's permalink.
+func shareRemote(request models.CompilationRequest) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(compileTimeout)*time.Second)
+	defer cancel()
+
+	job, err := submitRemote(ctx, compileRemote, request)
+	if err != nil {
+		return "", err
+	}
+	return shareURL(compileRemote, job.JobID), nil
+}
+
 // detectLanguage detects the programming language from file extension.
 func detectLanguage(filePath string) (models.Language, error) {
 	ext := filepath.Ext(filePath)
@@ -187,7 +258,38 @@ func detectLanguage(filePath string) (models.Language, error) {
 		return models.LanguageCpp, nil
 	case ".c":
 		return models.LanguageC, nil
+	case ".go":
+		return models.LanguageGo, nil
+	case ".rs":
+		return models.LanguageRust, nil
+	case ".m":
+		return models.LanguageObjC, nil
+	case ".mm":
+		return models.LanguageObjCpp, nil
+	case ".swift":
+		return models.LanguageSwift, nil
+	case ".kt":
+		return models.LanguageKotlin, nil
+	default:
+		return "", fmt.Errorf("%w: %s (supported: .cpp, .cc, .cxx, .c++, .c, .go, .rs, .m, .mm, .swift, .kt)", ErrUnsupportedFileExt, ext)
+	}
+}
+
+// defaultCompiler returns the compiler for a language when --compiler is unset. C and C++ are left
+// to the server's default (GCC, or a proxy's X-Default-Compiler); it cannot compile the others.
+func defaultCompiler(language models.Language) models.Compiler {
+	switch language {
+	case models.LanguageGo:
+		return models.CompilerGo123
+	case models.LanguageRust:
+		return models.CompilerRustc180
+	case models.LanguageObjC, models.LanguageObjCpp:
+		return models.CompilerClang18
+	case models.LanguageSwift:
+		return models.CompilerSwiftc510
+	case models.LanguageKotlin:
+		return models.CompilerKotlinc20
 	default:
-		return "", fmt.Errorf("%w: %s (supported: .cpp, .cc, .cxx, .c++, .c)", ErrUnsupportedFileExt, ext)
+		return ""
 	}
 }
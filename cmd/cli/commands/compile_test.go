@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDetectLanguage tests that each supported extension maps to its language, with a compiler that
+// can build it by default.
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		file     string
+		language models.Language
+		compiler models.Compiler
+	}{
+		{"main.cpp", models.LanguageCpp, ""},
+		{"main.cc", models.LanguageCpp, ""},
+		{"main.cxx", models.LanguageCpp, ""},
+		{"main.c++", models.LanguageCpp, ""},
+		{"main.c", models.LanguageC, ""},
+		{"main.go", models.LanguageGo, models.CompilerGo123},
+		{"src/main.rs", models.LanguageRust, models.CompilerRustc180},
+		{"main.m", models.LanguageObjC, models.CompilerClang18},
+		{"main.mm", models.LanguageObjCpp, models.CompilerClang18},
+		{"main.swift", models.LanguageSwift, models.CompilerSwiftc510},
+		{"Main.kt", models.LanguageKotlin, models.CompilerKotlinc20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			language, err := detectLanguage(tt.file)
+			require.NoError(t, err)
+			assert.Equal(t, tt.language, language)
+			assert.Equal(t, tt.compiler, defaultCompiler(language))
+		})
+	}
+}
+
+// TestDetectLanguage_Unsupported tests that unknown extensions are rejected with the supported list.
+func TestDetectLanguage_Unsupported(t *testing.T) {
+	for _, file := range []string{"main.py", "Makefile"} {
+		_, err := detectLanguage(file)
+		require.ErrorIs(t, err, ErrUnsupportedFileExt, file)
+		assert.Contains(t, err.Error(), ".go, .rs", file)
+	}
+}
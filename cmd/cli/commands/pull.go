@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stlpine/will-it-compile/internal/docker"
+)
+
+var pullCmd = &cobra.Command{
+	Use:   "pull <image>",
+	Short: "Pull a compilation image",
+	Long: `Pull a Docker image used by a compilation environment, showing progress.
+
+The pull is aborted if it does not finish within the timeout.`,
+	Example: `  # Pull the default C/C++ image
+  will-it-compile pull gcc:13
+
+  # Allow a slow connection more time
+  will-it-compile pull rust:1.80 --timeout=30m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPull,
+}
+
+var pullTimeout time.Duration
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+
+	pullCmd.Flags().DurationVar(&pullTimeout, "timeout", docker.DefaultPullTimeout, "maximum time to wait for the pull")
+}
+
+func runPull(cmd *cobra.Command, args []string) error {
+	imageTag := args[0]
+
+	client, err := docker.NewClient()
+	if err != nil {
+		printError("failed to create Docker client: %v", err)
+		return err
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			printError("failed to close Docker client: %v", err)
+		}
+	}()
+	client.SetPullTimeout(pullTimeout)
+
+	printInfo(cmd, "Pulling %s...", imageTag)
+
+	// Only print status changes per layer; byte-level updates are verbose-only
+	lastStatus := make(map[string]string)
+	err = client.PullImage(context.Background(), imageTag, func(p docker.PullProgress) {
+		if p.Total > 0 {
+			printVerbose(cmd, "%s: %s %d/%d bytes", p.ID, p.Status, p.Current, p.Total)
+		}
+		if lastStatus[p.ID] == p.Status {
+			return
+		}
+		lastStatus[p.ID] = p.Status
+		if p.ID == "" {
+			printInfo(cmd, "  %s", p.Status)
+		} else {
+			printInfo(cmd, "  %s: %s", p.ID, p.Status)
+		}
+	})
+	if err != nil {
+		if errors.Is(err, docker.ErrPullTimeout) {
+			printError("pull of %s did not finish within %s", imageTag, pullTimeout)
+		} else {
+			printError("%v", err)
+		}
+		return err
+	}
+
+	printInfo(cmd, "✓ Pulled %s", imageTag)
+	return nil
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// Sentinel errors for remote compilation.
+var (
+	ErrRemoteAPI           = errors.New("remote API error")
+	ErrShareRequiresRemote = errors.New("--share requires --remote")
+)
+
+// remotePollInterval is how often a remote job is polled for its result.
+const remotePollInterval = 500 * time.Millisecond
+
+// shareURL returns the permalink of a job's result on a will-it-compile server.
+func shareURL(baseURL, jobID string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/api/v1/compile/" + jobID
+}
+
+// submitRemote submits a compilation request to a will-it-compile server.
+func submitRemote(ctx context.Context, baseURL string, request models.CompilationRequest) (models.JobResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return models.JobResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/api/v1/compile", bytes.NewReader(body))
+	if err != nil {
+		return models.JobResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return models.JobResponse{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // standard practice for HTTP client
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body) //nolint:errcheck // best effort error message
+		return models.JobResponse{}, fmt.Errorf("%w (status %d): %s", ErrRemoteAPI, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var job models.JobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return models.JobResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return job, nil
+}
+
+// waitRemoteResult polls a remote job until its result is available.
+// The API returns a JobResponse while the job is pending and the CompilationResult once it is done.
+func waitRemoteResult(ctx context.Context, url string) (models.CompilationResult, error) {
+	for {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return models.CompilationResult{}, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			return models.CompilationResult{}, fmt.Errorf("request failed: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close() //nolint:errcheck,gosec // body fully read
+		if err != nil {
+			return models.CompilationResult{}, fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return models.CompilationResult{}, fmt.Errorf("%w (status %d): %s", ErrRemoteAPI, resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		var pending struct {
+			models.CompilationResult
+			Status models.JobStatus `json:"status"`
+		}
+		if err := json.Unmarshal(body, &pending); err != nil {
+			return models.CompilationResult{}, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if pending.Status == "" {
+			return pending.CompilationResult, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return models.CompilationResult{}, ctx.Err()
+		case <-time.After(remotePollInterval):
+		}
+	}
+}
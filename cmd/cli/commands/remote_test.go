@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunCompile_Share tests that --share prints only the permalink of the submitted job.
+func TestRunCompile_Share(t *testing.T) {
+	var submitted models.CompilationRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/compile", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&submitted))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(models.JobResponse{JobID: "job-123", Status: models.StatusQueued}) //nolint:errcheck // test server
+	}))
+	defer server.Close()
+
+	source := filepath.Join(t.TempDir(), "main.cpp")
+	require.NoError(t, os.WriteFile(source, []byte("int main() { return 0; }"), 0o600))
+
+	compileRemote, compileShare = server.URL+"/", true
+	t.Cleanup(func() { compileRemote, compileShare = "", false })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	require.NoError(t, runCompile(cmd, []string{source}))
+
+	assert.Equal(t, server.URL+"/api/v1/compile/job-123\n", out.String())
+	assert.Equal(t, models.LanguageCpp, submitted.Language)
+}
+
+// TestRunCompile_ShareRequiresRemote tests that --share is rejected for local compiles.
+func TestRunCompile_ShareRequiresRemote(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "main.c")
+	require.NoError(t, os.WriteFile(source, []byte("int main(void) { return 0; }"), 0o600))
+
+	compileShare = true
+	t.Cleanup(func() { compileShare = false })
+
+	err := runCompile(&cobra.Command{}, []string{source})
+	assert.ErrorIs(t, err, ErrShareRequiresRemote)
+}
@@ -68,6 +68,36 @@ func (c *Client) SubmitCompilation(ctx context.Context, req models.CompilationRe
 	return &job, nil
 }
 
+// RerunJob submits the request of an earlier job again and returns the new job.
+func (c *Client) RerunJob(ctx context.Context, jobID string) (*models.JobResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v1/compile/"+jobID+"/rerun", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // standard practice for HTTP client
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrJobNotFound
+	}
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body) //nolint:errcheck // best effort error message
+		return nil, fmt.Errorf("%w (status %d): %s", ErrAPIError, resp.StatusCode, string(body))
+	}
+
+	var job models.JobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &job, nil
+}
+
 // JobStatus represents the status of a job.
 type JobStatus struct {
 	JobID  string                    `json:"job_id,omitempty"`
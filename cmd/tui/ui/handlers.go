@@ -75,6 +75,7 @@ func (m Model) handleHistoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.historyIndex--
 			}
 			m.statusMsg = "Job removed from history"
+			m.persistHistory()
 		}
 
 	case "c":
@@ -82,6 +83,7 @@ func (m Model) handleHistoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.jobHistory = []JobInfo{}
 		m.historyIndex = 0
 		m.statusMsg = "History cleared"
+		m.persistHistory()
 	}
 
 	return m, nil
@@ -96,6 +98,15 @@ func (m Model) handleJobDetailKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, m.pollJob(m.currentJob.ID)
 		}
 
+	case "R":
+		// Re-run the job's request as a new job
+		if m.currentJob != nil && !m.isCompiling {
+			return m, tea.Batch(
+				m.compileCode(),
+				m.rerunJob(*m.currentJob),
+			)
+		}
+
 	case "backspace":
 		// Go back to history
 		m.state = ViewHistory
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleJobDetailKeys_Rerun tests that R resubmits the current job and opens the new job.
+func TestHandleJobDetailKeys_Rerun(t *testing.T) {
+	var rerunPath string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rerunPath = r.Method + " " + r.URL.Path
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(models.JobResponse{JobID: "job-2", Status: models.StatusQueued}) //nolint:errcheck // test server
+	}))
+	defer api.Close()
+
+	m := NewModel(api.URL)
+	m.historyPath = filepath.Join(t.TempDir(), "history.json")
+	m.state = ViewJobDetail
+	m.currentJob = &JobInfo{ID: "job-1", Language: models.LanguageGo, Status: models.StatusFailed}
+	m.jobHistory = []JobInfo{*m.currentJob}
+
+	updated, cmd := m.handleJobDetailKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	require.NotNil(t, cmd, "R should issue a submit command")
+
+	// The batch starts the compile spinner and submits the rerun
+	batch, ok := cmd().(tea.BatchMsg)
+	require.True(t, ok)
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, c())
+	}
+	require.Len(t, msgs, 2)
+	assert.IsType(t, compileStartMsg{}, msgs[0])
+	assert.Equal(t, "POST /api/v1/compile/job-1/rerun", rerunPath)
+
+	result, ok := msgs[1].(compileResultMsg)
+	require.True(t, ok)
+	require.NoError(t, result.err)
+
+	next, pollCmd := updated.Update(result)
+	model := next.(Model)
+	assert.Equal(t, ViewJobDetail, model.state)
+	require.NotNil(t, model.currentJob)
+	assert.Equal(t, "job-2", model.currentJob.ID)
+	assert.Equal(t, models.LanguageGo, model.currentJob.Language)
+	assert.Len(t, model.jobHistory, 2)
+	assert.NotNil(t, pollCmd, "The queued job should be polled")
+}
+
+// TestHandleJobDetailKeys_RerunWhileCompiling tests that R does nothing while a compile is in flight.
+func TestHandleJobDetailKeys_RerunWhileCompiling(t *testing.T) {
+	m := NewModel("http://localhost:8080")
+	m.state = ViewJobDetail
+	m.currentJob = &JobInfo{ID: "job-1", Language: models.LanguageC}
+	m.isCompiling = true
+
+	_, cmd := m.handleJobDetailKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	assert.Nil(t, cmd)
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// maxHistoryEntries caps the jobs kept in the history file, newest first.
+const maxHistoryEntries = 100
+
+// defaultHistoryPath returns the history file under the user's config directory,
+// or "" if there is none, which disables persistence.
+func defaultHistoryPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "will-it-compile", "history.json")
+}
+
+// loadHistory reads the job history from path. A missing or corrupt file yields an empty history.
+func loadHistory(path string) []JobInfo {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: the TUI's own history file
+	if err != nil {
+		return nil
+	}
+	var jobs []JobInfo
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil
+	}
+	return capHistory(jobs)
+}
+
+// saveHistory writes the newest maxHistoryEntries jobs to path. The file is replaced atomically,
+// so a crash mid-write never leaves a truncated history behind.
+func saveHistory(path string, jobs []JobInfo) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(capHistory(jobs), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // already renamed on success
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // the write error is reported
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// capHistory returns the newest maxHistoryEntries jobs.
+func capHistory(jobs []JobInfo) []JobInfo {
+	if len(jobs) > maxHistoryEntries {
+		return jobs[:maxHistoryEntries]
+	}
+	return jobs
+}
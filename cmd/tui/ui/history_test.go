@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stlpine/will-it-compile/cmd/tui/client"
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHistory_PersistsAcrossSessions tests that submitted jobs and their results are saved and
+// loaded back by the next session.
+func TestHistory_PersistsAcrossSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "will-it-compile", "history.json")
+
+	m := NewModel("http://localhost:8080")
+	m.historyPath = path
+	next, _ := m.Update(compileResultMsg{job: &models.CompilationJob{
+		ID:        "job-1",
+		Request:   models.CompilationRequest{Language: models.LanguageC},
+		Status:    models.StatusCompleted,
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}})
+	result := &models.CompilationResult{JobID: "job-1", Success: true, Compiled: true}
+	next, _ = next.Update(jobUpdateMsg{status: &client.JobStatus{JobID: "job-1", Status: models.StatusCompleted, Result: result}})
+	require.Empty(t, next.(Model).errorMsg)
+
+	restarted := NewModel("http://localhost:8080")
+	restarted.historyPath = path
+	loaded, _ := restarted.Update(restarted.loadHistory()())
+
+	history := loaded.(Model).jobHistory
+	require.Len(t, history, 1)
+	assert.Equal(t, "job-1", history[0].ID)
+	assert.Equal(t, models.LanguageC, history[0].Language)
+	assert.Equal(t, models.StatusCompleted, history[0].Status)
+	assert.Equal(t, result, history[0].Result)
+}
+
+// TestLoadHistory_MissingOrCorrupt tests that an unreadable history file starts an empty history.
+func TestLoadHistory_MissingOrCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	assert.Empty(t, loadHistory(filepath.Join(dir, "missing.json")))
+
+	corrupt := filepath.Join(dir, "history.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte(`[{"id": "job-1"`), 0o600))
+	assert.Empty(t, loadHistory(corrupt))
+}
+
+// TestSaveHistory_Capped tests that only the newest maxHistoryEntries jobs are kept.
+func TestSaveHistory_Capped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	jobs := make([]JobInfo, maxHistoryEntries+20)
+	for i := range jobs {
+		jobs[i] = JobInfo{ID: fmt.Sprintf("job-%d", i)}
+	}
+
+	require.NoError(t, saveHistory(path, jobs))
+
+	loaded := loadHistory(path)
+	require.Len(t, loaded, maxHistoryEntries)
+	assert.Equal(t, "job-0", loaded[0].ID)
+	assert.Equal(t, fmt.Sprintf("job-%d", maxHistoryEntries-1), loaded[maxHistoryEntries-1].ID)
+}
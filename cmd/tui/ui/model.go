@@ -29,11 +29,11 @@ const (
 
 // JobInfo combines job metadata with its result.
 type JobInfo struct {
-	ID        string
-	Language  models.Language
-	Status    models.JobStatus
-	Result    *models.CompilationResult
-	CreatedAt time.Time
+	ID        string                    `json:"id"`
+	Language  models.Language           `json:"language"`
+	Status    models.JobStatus          `json:"status"`
+	Result    *models.CompilationResult `json:"result,omitempty"`
+	CreatedAt time.Time                 `json:"created_at"`
 }
 
 // Model is the main TUI model.
@@ -59,6 +59,7 @@ type Model struct {
 	currentJob   *JobInfo
 	jobHistory   []JobInfo
 	historyIndex int
+	historyPath  string // File the history persists to; "" keeps it in memory only
 	isCompiling  bool
 
 	// Status
@@ -96,6 +97,7 @@ func NewModel(apiURL string) Model {
 		language:     models.LanguageCpp, // Default
 		jobHistory:   []JobInfo{},
 		historyIndex: 0,
+		historyPath:  defaultHistoryPath(),
 		autoRefresh:  true,
 	}
 }
@@ -107,6 +109,7 @@ func (m Model) Init() tea.Cmd {
 		m.spinner.Tick,
 		m.checkHealth(),
 		m.fetchEnvironments(),
+		m.loadHistory(),
 	)
 }
 
@@ -123,6 +126,10 @@ type (
 
 	compileStartMsg struct{}
 
+	historyLoadedMsg struct {
+		jobs []JobInfo
+	}
+
 	compileResultMsg struct {
 		job *models.CompilationJob
 		err error
@@ -213,6 +220,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.environments = msg.envs
 		}
 
+	case historyLoadedMsg:
+		// Jobs submitted while the file was being read are newer than any in it
+		m.jobHistory = capHistory(append(m.jobHistory, msg.jobs...))
+
 	case compileStartMsg:
 		m.isCompiling = true
 		m.statusMsg = "Compiling..."
@@ -232,7 +243,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				CreatedAt: msg.job.CreatedAt,
 			}
 			m.currentJob = jobInfo
-			m.jobHistory = append([]JobInfo{*jobInfo}, m.jobHistory...)
+			m.jobHistory = capHistory(append([]JobInfo{*jobInfo}, m.jobHistory...))
+			m.persistHistory()
 			m.state = ViewJobDetail
 
 			// Start polling if job is still processing
@@ -257,6 +269,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 			}
+			m.persistHistory()
 
 			// Continue polling if still processing
 			if msg.status.Status == models.StatusQueued || msg.status.Status == models.StatusProcessing {
@@ -362,6 +375,40 @@ func (m Model) submitCompilation() tea.Cmd {
 	}
 }
 
+// rerunJob resubmits the request of an earlier job on the server; the new job is reported
+// like a fresh submission, so it opens in the detail view.
+func (m Model) rerunJob(job JobInfo) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		resp, err := m.client.RerunJob(ctx, job.ID)
+		if err != nil {
+			return compileResultMsg{err: err}
+		}
+
+		return compileResultMsg{job: &models.CompilationJob{
+			ID:        resp.JobID,
+			Request:   models.CompilationRequest{Language: job.Language},
+			Status:    resp.Status,
+			CreatedAt: time.Now(),
+		}}
+	}
+}
+
+func (m Model) loadHistory() tea.Cmd {
+	return func() tea.Msg {
+		return historyLoadedMsg{jobs: loadHistory(m.historyPath)}
+	}
+}
+
+// persistHistory saves the job history, reporting a failure in the status line.
+func (m *Model) persistHistory() {
+	if err := saveHistory(m.historyPath, m.jobHistory); err != nil {
+		m.errorMsg = fmt.Sprintf("Failed to save history: %v", err)
+	}
+}
+
 func (m Model) pollJob(jobID string) tea.Cmd {
 	return func() tea.Msg {
 		time.Sleep(500 * time.Millisecond) // Poll every 500ms
@@ -145,6 +145,10 @@ func (m Model) viewJobDetail() string {
 			resultSummary = errorStyle.Render("✗ Error: " + result.Error)
 		}
 
+		if result.Cached {
+			resultSummary += " " + mutedStyle.Render(fmt.Sprintf("(cached %s ago)", formatDuration(result.CacheAge)))
+		}
+
 		b.WriteString(resultSummary + "\n\n")
 
 		// Details
@@ -177,7 +181,7 @@ func (m Model) viewJobDetail() string {
 	}
 
 	// Help
-	b.WriteString(helpStyle.Render("Esc: back to editor  q: quit\n"))
+	b.WriteString(helpStyle.Render("r: refresh  R: re-run  Esc: back to editor  q: quit\n"))
 
 	return b.String()
 }
@@ -218,6 +222,7 @@ func (m Model) viewHelp() string {
 		{"Tab", "Toggle between editor and history"},
 		{"↑/↓", "Navigate in history or file picker"},
 		{"Enter", "View job details (in history)"},
+		{"R", "Re-run the job as a new job (in job details)"},
 		{"?", "Show this help screen"},
 		{"Esc", "Go back to editor"},
 		{"q / Ctrl+C", "Quit the application"},
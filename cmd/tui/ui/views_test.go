@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestViewJobDetail_Cached tests that cached results are marked in the job detail view.
+func TestViewJobDetail_Cached(t *testing.T) {
+	m := NewModel("http://localhost:8080")
+	m.width = 120
+	m.currentJob = &JobInfo{
+		ID:       "job-1",
+		Language: models.LanguageCpp,
+		Status:   models.StatusCompleted,
+		Result:   &models.CompilationResult{Success: true, Compiled: true},
+	}
+
+	assert.NotContains(t, m.viewJobDetail(), "(cached")
+
+	m.currentJob.Result.Cached = true
+	m.currentJob.Result.CacheAge = 2 * time.Second
+	assert.Contains(t, m.viewJobDetail(), "(cached 2.00s ago)")
+}
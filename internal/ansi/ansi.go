@@ -0,0 +1,251 @@
+// Package ansi parses ANSI escape sequences in program and compiler output, to strip them
+// or to render the colors they select as HTML.
+package ansi
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// colorNames are the names of the 8 standard colors, in SGR order (30-37, 40-47).
+var colorNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// Style is the text style selected by SGR ("Select Graphic Rendition") sequences.
+// Colors are class name suffixes (e.g. "red", "bright-red", "196") or "#rrggbb" for 24-bit colors.
+type Style struct {
+	Foreground string
+	Background string
+	Bold       bool
+	Dim        bool
+	Italic     bool
+	Underline  bool
+}
+
+// Segment is a run of text in a single style.
+type Segment struct {
+	Text  string
+	Style Style
+}
+
+// Parse splits s into styled segments. SGR sequences change the style; every other escape
+// sequence (cursor movement, window titles, ...) is dropped.
+func Parse(s string) []Segment {
+	var segments []Segment
+	var text strings.Builder
+	var style Style
+
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		segments = append(segments, Segment{Text: text.String(), Style: style})
+		text.Reset()
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] != '\x1b' {
+			text.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		params, final, next := parseEscape(s, i)
+		if final == 'm' {
+			flush()
+			style = applySGR(style, params)
+		}
+		i = next
+	}
+	flush()
+
+	return segments
+}
+
+// parseEscape reads the escape sequence starting at s[start] (an ESC) and returns the
+// parameters and final byte of a CSI sequence (0 for other sequences) and the index after it.
+func parseEscape(s string, start int) (string, byte, int) {
+	i := start + 1
+	if i >= len(s) {
+		return "", 0, i
+	}
+
+	switch s[i] {
+	case '[': // CSI: parameter and intermediate bytes, then a final byte in 0x40-0x7e
+		i++
+		paramsStart := i
+		for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+			i++
+		}
+		if i >= len(s) {
+			return "", 0, i
+		}
+		return s[paramsStart:i], s[i], i + 1
+	case ']': // OSC: terminated by BEL or ST (ESC \)
+		for i++; i < len(s); i++ {
+			if s[i] == '\a' {
+				return "", 0, i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return "", 0, i + 2
+			}
+		}
+		return "", 0, i
+	default: // Two-byte sequence, e.g. ESC c
+		return "", 0, i + 1
+	}
+}
+
+// applySGR returns the style after the SGR parameters params (e.g. "1;31").
+func applySGR(style Style, params string) Style {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			if codes[i] != "" {
+				continue
+			}
+			code = 0 // An empty parameter is a reset, as in "ESC[m"
+		}
+
+		switch {
+		case code == 0:
+			style = Style{}
+		case code == 1:
+			style.Bold = true
+		case code == 2:
+			style.Dim = true
+		case code == 3:
+			style.Italic = true
+		case code == 4:
+			style.Underline = true
+		case code == 22:
+			style.Bold, style.Dim = false, false
+		case code == 23:
+			style.Italic = false
+		case code == 24:
+			style.Underline = false
+		case code >= 30 && code <= 37:
+			style.Foreground = colorNames[code-30]
+		case code >= 90 && code <= 97:
+			style.Foreground = "bright-" + colorNames[code-90]
+		case code == 39:
+			style.Foreground = ""
+		case code >= 40 && code <= 47:
+			style.Background = colorNames[code-40]
+		case code >= 100 && code <= 107:
+			style.Background = "bright-" + colorNames[code-100]
+		case code == 49:
+			style.Background = ""
+		case code == 38 || code == 48:
+			color, consumed := extendedColor(codes[i+1:])
+			i += consumed
+			if code == 38 {
+				style.Foreground = color
+			} else {
+				style.Background = color
+			}
+		}
+	}
+	return style
+}
+
+// extendedColor parses the parameters after 38 or 48: "5;n" for the 256-color palette or
+// "2;r;g;b" for 24-bit color. It returns the color ("" if malformed) and the parameters used.
+func extendedColor(codes []string) (string, int) {
+	if len(codes) == 0 {
+		return "", 0
+	}
+	values := make([]int, 0, 4)
+	for _, code := range codes {
+		value, err := strconv.Atoi(code)
+		if err != nil || value < 0 || value > 255 {
+			break
+		}
+		values = append(values, value)
+	}
+
+	switch {
+	case len(values) >= 2 && values[0] == 5:
+		return strconv.Itoa(values[1]), 2
+	case len(values) >= 4 && values[0] == 2:
+		return fmt.Sprintf("#%02x%02x%02x", values[1], values[2], values[3]), 4
+	default:
+		return "", len(values)
+	}
+}
+
+// Strip returns s without its escape sequences.
+func Strip(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var b strings.Builder
+	for _, segment := range Parse(s) {
+		b.WriteString(segment.Text)
+	}
+	return b.String()
+}
+
+// ToHTML returns s as escaped HTML, with each styled segment in a span. Styles are classes:
+// "ansi-bold", "ansi-dim", "ansi-italic", "ansi-underline", "ansi-fg-<color>" and
+// "ansi-bg-<color>" (e.g. "ansi-fg-red", "ansi-fg-bright-red", "ansi-bg-196"); 24-bit colors
+// are inline styles. Unstyled text is only escaped.
+func ToHTML(s string) string {
+	var b strings.Builder
+	for _, segment := range Parse(s) {
+		text := html.EscapeString(segment.Text)
+		open := spanTag(segment.Style)
+		if open == "" {
+			b.WriteString(text)
+			continue
+		}
+		b.WriteString(open + text + "</span>")
+	}
+	return b.String()
+}
+
+// spanTag returns the opening span of a style, or "" for the default style.
+func spanTag(style Style) string {
+	var classes, css []string
+	for _, flag := range []struct {
+		set   bool
+		class string
+	}{
+		{style.Bold, "ansi-bold"},
+		{style.Dim, "ansi-dim"},
+		{style.Italic, "ansi-italic"},
+		{style.Underline, "ansi-underline"},
+	} {
+		if flag.set {
+			classes = append(classes, flag.class)
+		}
+	}
+	for _, color := range []struct {
+		value, class, property string
+	}{
+		{style.Foreground, "ansi-fg-", "color"},
+		{style.Background, "ansi-bg-", "background-color"},
+	} {
+		switch {
+		case color.value == "":
+		case strings.HasPrefix(color.value, "#"):
+			css = append(css, color.property+":"+color.value)
+		default:
+			classes = append(classes, color.class+color.value)
+		}
+	}
+
+	if len(classes) == 0 && len(css) == 0 {
+		return ""
+	}
+	tag := "<span"
+	if len(classes) > 0 {
+		tag += ` class="` + strings.Join(classes, " ") + `"`
+	}
+	if len(css) > 0 {
+		tag += ` style="` + strings.Join(css, ";") + `"`
+	}
+	return tag + ">"
+}
@@ -0,0 +1,66 @@
+package ansi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestToHTML tests that SGR sequences become styled spans and all text is escaped.
+func TestToHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "plain_text_is_escaped",
+			input:    `if (a < b && c > d) return "<b>";`,
+			expected: `if (a &lt; b &amp;&amp; c &gt; d) return &#34;&lt;b&gt;&#34;;`,
+		},
+		{
+			name:     "foreground_color",
+			input:    "\x1b[31merror\x1b[0m: <bad>",
+			expected: `<span class="ansi-fg-red">error</span>: &lt;bad&gt;`,
+		},
+		{
+			name:     "combined_attributes",
+			input:    "\x1b[1;92;44mPASS\x1b[m",
+			expected: `<span class="ansi-bold ansi-fg-bright-green ansi-bg-blue">PASS</span>`,
+		},
+		{
+			name:     "attributes_accumulate_until_reset",
+			input:    "\x1b[4mlink\x1b[36m here\x1b[24m done\x1b[39m.",
+			expected: `<span class="ansi-underline">link</span><span class="ansi-underline ansi-fg-cyan"> here</span><span class="ansi-fg-cyan"> done</span>.`,
+		},
+		{
+			name:     "palette_color",
+			input:    "\x1b[38;5;196mhot\x1b[0m",
+			expected: `<span class="ansi-fg-196">hot</span>`,
+		},
+		{
+			name:     "truecolor",
+			input:    "\x1b[48;2;255;128;0;1mwarm\x1b[0m",
+			expected: `<span class="ansi-bold" style="background-color:#ff8000">warm</span>`,
+		},
+		{
+			name:     "non_sgr_sequences_are_dropped",
+			input:    "\x1b]0;title\x07\x1b[2K\rprogress\x1b[?25h",
+			expected: "\rprogress",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ToHTML(tc.input))
+		})
+	}
+}
+
+// TestStrip tests that escape sequences are removed and text is left untouched.
+func TestStrip(t *testing.T) {
+	assert.Equal(t, "error: 1 < 2\n", Strip("\x1b[1;31merror\x1b[0m: 1 < 2\n"))
+	assert.Equal(t, "progress", Strip("\x1b]0;title\x1b\\\x1b[2Kprogress\x1b[?25h"))
+	assert.Equal(t, "no escapes", Strip("no escapes"))
+	assert.Equal(t, "cut", Strip("cut\x1b[3"), "An unterminated sequence at the end is dropped")
+}
@@ -41,7 +41,7 @@ func BenchmarkAsyncJobProcessing_NoVirtualization(b *testing.B) {
 
 		done := make(chan struct{})
 		go func() {
-			server.processJob(job)
+			server.processJob(context.Background(), job)
 			close(done)
 		}()
 		<-done
@@ -72,7 +72,7 @@ func BenchmarkAsyncJobProcessing_WithVirtualization(b *testing.B) {
 
 			done := make(chan struct{})
 			go func() {
-				server.processJob(job)
+				server.processJob(context.Background(), job)
 				close(done)
 			}()
 			<-done
@@ -106,7 +106,7 @@ func BenchmarkConcurrentJobs(b *testing.B) {
 				}
 
 				go func(j models.CompilationJob) {
-					server.processJob(j)
+					server.processJob(context.Background(), j)
 					done <- struct{}{}
 				}(job)
 			}
@@ -234,7 +234,7 @@ func BenchmarkRealisticWorkload(b *testing.B) {
 				}
 
 				go func(j models.CompilationJob) {
-					server.processJob(j)
+					server.processJob(context.Background(), j)
 					done <- struct{}{}
 				}(job)
 			}
@@ -4,13 +4,22 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"testing/synctest"
 	"time"
 
+	"github.com/labstack/echo/v4"
 	"github.com/stlpine/will-it-compile/internal/compiler"
+	"github.com/stlpine/will-it-compile/internal/storage"
+	"github.com/stlpine/will-it-compile/internal/storage/memory"
 	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -121,7 +130,7 @@ func TestAsyncJobProcessing(t *testing.T) {
 				// Process job asynchronously
 				done := make(chan struct{})
 				go func() {
-					server.processJob(job)
+					server.processJob(context.Background(), job)
 					close(done)
 				}()
 
@@ -179,7 +188,7 @@ func TestConcurrentJobs(t *testing.T) {
 			}
 
 			go func(j models.CompilationJob) {
-				server.processJob(j)
+				server.processJob(context.Background(), j)
 				done <- j.ID
 			}(job)
 		}
@@ -237,7 +246,7 @@ func TestJobLifecycle(t *testing.T) {
 		// Process job
 		done := make(chan struct{})
 		go func() {
-			server.processJob(job)
+			server.processJob(context.Background(), job)
 			close(done)
 		}()
 		<-done
@@ -252,6 +261,61 @@ func TestJobLifecycle(t *testing.T) {
 	})
 }
 
+// TestJobEvents verifies the job timeline carries increasing timestamps and phase durations.
+func TestJobEvents(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &mockCompiler{compileDelay: 3 * time.Second, shouldFail: false},
+			jobs:     newJobStore(),
+		}
+		e := NewEchoServer(server, false)
+
+		job := models.CompilationJob{
+			ID: "events-job",
+			Request: models.CompilationRequest{
+				Code:     "I2luY2x1ZGUgPGlvc3RyZWFtPg==",
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerGCC9,
+			},
+			Status:    models.StatusQueued,
+			CreatedAt: time.Now(),
+		}
+		server.jobs.Store(job)
+
+		// Wait in the queue, then compile
+		time.Sleep(2 * time.Second)
+		server.processJob(context.Background(), job)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/compile/events-job/events", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp models.JobEventsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Events, 3)
+
+		assert.Equal(t, models.StatusQueued, resp.Events[0].Status)
+		assert.Equal(t, models.StatusProcessing, resp.Events[1].Status)
+		assert.Equal(t, models.StatusCompleted, resp.Events[2].Status)
+		assert.True(t, resp.Events[1].Timestamp.After(resp.Events[0].Timestamp))
+		assert.True(t, resp.Events[2].Timestamp.After(resp.Events[1].Timestamp))
+
+		assert.Equal(t, 2*time.Second, resp.Events[0].Duration, "Time spent queued")
+		assert.Equal(t, 3*time.Second, resp.Events[1].Duration, "Time spent compiling")
+		assert.Zero(t, resp.Events[2].Duration, "Final status has no duration")
+
+		// A start recorded before creation (clock skew between instances) is clamped
+		skewed := models.CompilationJob{ID: "skewed-job", Status: models.StatusProcessing, CreatedAt: time.Now()}
+		startedAt := skewed.CreatedAt.Add(-time.Second)
+		skewed.StartedAt = &startedAt
+
+		events := skewed.Events()
+		require.Len(t, events, 2)
+		assert.Equal(t, events[0].Timestamp, events[1].Timestamp)
+		assert.Zero(t, events[0].Duration)
+	})
+}
+
 // TestRapidJobSubmission tests handling of rapid consecutive job submissions.
 func TestRapidJobSubmission(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
@@ -277,7 +341,7 @@ func TestRapidJobSubmission(t *testing.T) {
 			}
 
 			go func(j models.CompilationJob) {
-				server.processJob(j)
+				server.processJob(context.Background(), j)
 				done <- j.ID
 			}(job)
 		}
@@ -327,7 +391,7 @@ func TestMixedSuccessFailure(t *testing.T) {
 			}
 
 			go func(s *Server, j models.CompilationJob, fail bool) {
-				s.processJob(j)
+				s.processJob(context.Background(), j)
 				done <- j.ID
 			}(server, job, shouldFail)
 		}
@@ -362,7 +426,7 @@ func TestJobTimestamps(t *testing.T) {
 
 		done := make(chan struct{})
 		go func() {
-			server.processJob(job)
+			server.processJob(context.Background(), job)
 			close(done)
 		}()
 		<-done
@@ -380,3 +444,345 @@ func TestJobTimestamps(t *testing.T) {
 		assert.True(t, duration >= 200*time.Millisecond, "Duration should be at least 200ms, got %v", duration)
 	})
 }
+
+// timeoutReportingCompiler is a mockCompiler that reports a fixed effective timeout, as a compiler
+// clamping the request's timeout_seconds or shortening it in quick mode would.
+type timeoutReportingCompiler struct {
+	mockCompiler
+	timeout time.Duration
+}
+
+func (m *timeoutReportingCompiler) EffectiveTimeout(models.CompilationRequest) time.Duration {
+	return m.timeout
+}
+
+// TestHandleCompileSync verifies that the sync endpoint returns the result in one response, and the
+// job ID with 504 once the wait budget is spent.
+func TestHandleCompileSync(t *testing.T) {
+	tests := []struct {
+		name             string
+		delay            time.Duration
+		timeoutSeconds   int
+		effectiveTimeout time.Duration // Timeout reported by the compiler (0 = not reported)
+		expectCode       int
+		expectWaited     time.Duration
+	}{
+		{"result", 4 * time.Second, 0, 0, http.StatusOK, 4 * time.Second},
+		{"deadline", 2 * time.Minute, 5, 0, http.StatusGatewayTimeout, 5*time.Second + syncWaitBuffer},
+		{"clamped_deadline", 2 * time.Minute, 600, 60 * time.Second, http.StatusGatewayTimeout, 60*time.Second + syncWaitBuffer},
+		{"quick_deadline", 2 * time.Minute, 0, 5 * time.Second, http.StatusGatewayTimeout, 5*time.Second + syncWaitBuffer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				var mock compiler.CompilerInterface = &mockCompiler{compileDelay: tt.delay}
+				if tt.effectiveTimeout > 0 {
+					mock = &timeoutReportingCompiler{mockCompiler: mockCompiler{compileDelay: tt.delay}, timeout: tt.effectiveTimeout}
+				}
+				server := &Server{
+					compiler: mock,
+					jobs:     newJobStore(),
+				}
+				server.workerPool = NewWorkerPool(1, 10, server)
+				server.workerPool.Start()
+				defer server.workerPool.Stop()
+				e := NewEchoServer(server, false)
+
+				body := fmt.Sprintf(`{"code":"aW50IG1haW4oKSB7fQ==","language":"cpp","timeout_seconds":%d}`, tt.timeoutSeconds)
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/compile/sync", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				rec := httptest.NewRecorder()
+
+				start := time.Now()
+				e.ServeHTTP(rec, req)
+				assert.Equal(t, tt.expectWaited, time.Since(start), "The handler returns as soon as the job is done")
+				require.Equal(t, tt.expectCode, rec.Code)
+
+				if tt.expectCode == http.StatusOK {
+					var result models.CompilationResult
+					require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+					assert.True(t, result.Compiled)
+					assert.Equal(t, "compilation successful", result.Stdout)
+					return
+				}
+
+				var response models.JobResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.NotEmpty(t, response.JobID)
+				assert.Equal(t, models.StatusProcessing, response.Status)
+				_, hasResult := server.jobs.GetResult(response.JobID)
+				assert.False(t, hasResult, "The job is still compiling and can be polled")
+			})
+		})
+	}
+}
+
+// TestHandleCompileBench verifies that a bench compiles the source once per standard and reports each
+// compile's own duration, in the order of the requested standards.
+func TestHandleCompileBench(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		delays := map[string]time.Duration{"-std=c++17": 3 * time.Second, "-std=c++20": 5 * time.Second, "-std=c++14": 2 * time.Second}
+		server := &Server{
+			compiler: compiler.NewCompilerWithRuntime(&runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					var duration time.Duration
+					for flag, delay := range delays {
+						if strings.Contains(config.CompileCommand, flag) {
+							duration = delay
+						}
+					}
+					time.Sleep(duration)
+					return &runtime.CompilationOutput{ExitCode: 0, Duration: duration}, nil
+				},
+			}),
+			jobs: newJobStore(),
+		}
+		server.workerPool = NewWorkerPool(2, 10, server)
+		server.workerPool.Start()
+		defer server.workerPool.Stop()
+		e := NewEchoServer(server, false)
+
+		body := `{"request":{"code":"aW50IG1haW4oKSB7fQ==","language":"cpp","compiler":"gcc-13"},"standards":["c++20","c++14","c++17"]}`
+		rec := serve(e, http.MethodPost, "/api/v1/compile/bench", echo.MIMEApplicationJSON, []byte(body))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response models.BenchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Len(t, response.Results, 3)
+		for i, expected := range []struct {
+			standard models.Standard
+			duration time.Duration
+		}{{models.StandardCpp20, 5 * time.Second}, {models.StandardCpp14, 2 * time.Second}, {models.StandardCpp17, 3 * time.Second}} {
+			result := response.Results[i]
+			assert.Equal(t, expected.standard, result.Standard)
+			assert.True(t, result.Compiled, "%s should compile", expected.standard)
+			assert.Equal(t, expected.duration, result.Duration, "%s should report its own compile time", expected.standard)
+			assert.NotEmpty(t, result.JobID)
+		}
+	})
+}
+
+// TestHandleCompileBench_InvalidStandards verifies that a bench without standards, with too many or with
+// duplicates is rejected before anything is queued.
+func TestHandleCompileBench_InvalidStandards(t *testing.T) {
+	tests := []struct {
+		name      string
+		standards string
+		errorMsg  string
+	}{
+		{"none", `[]`, "at least one standard"},
+		{"too_many", `["c++11","c++14","c++17","c++20","c++23","c++26"]`, "the maximum is 5"},
+		{"duplicate", `["c++17","c++17"]`, `standard \"c++17\" is named twice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, jobs := newUploadTestServer(DefaultMaxSourceSize)
+			e := NewEchoServer(server, false)
+
+			body := `{"request":{"code":"aW50IG1haW4oKSB7fQ==","language":"cpp"},"standards":` + tt.standards + `}`
+			rec := serve(e, http.MethodPost, "/api/v1/compile/bench", echo.MIMEApplicationJSON, []byte(body))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.errorMsg)
+			assert.Empty(t, jobs.jobs, "Nothing should be queued")
+		})
+	}
+}
+
+// contextCompiler compiles for compileDelay unless its context is cancelled first.
+type contextCompiler struct {
+	mockCompiler
+}
+
+func (m *contextCompiler) Compile(ctx context.Context, job models.CompilationJob) models.CompilationResult {
+	select {
+	case <-ctx.Done():
+		return models.CompilationResult{JobID: job.ID, Error: "compilation failed: " + ctx.Err().Error()}
+	case <-time.After(m.compileDelay):
+		return models.CompilationResult{JobID: job.ID, Success: true, Compiled: true}
+	}
+}
+
+// TestHandleCancelJobs verifies that cancelling a label cancels its processing and queued jobs, and only those.
+func TestHandleCancelJobs(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler:   &contextCompiler{mockCompiler{compileDelay: time.Minute}},
+			jobs:       memory.NewStore(),
+			adminToken: "admin-secret",
+		}
+		server.workerPool = NewWorkerPool(1, 10, server)
+		server.workerPool.Start()
+		defer server.workerPool.Stop()
+		e := NewEchoServer(server, false)
+
+		var labelled []string
+		for _, label := range []string{"PR-1", "PR-1", "PR-2", "PR-1"} {
+			job, err := server.enqueueJob(models.CompilationRequest{Code: "aW50IG1haW4oKSB7fQ==", Language: models.LanguageCpp, Label: label})
+			require.NoError(t, err)
+			if label == "PR-1" {
+				labelled = append(labelled, job.JobID)
+			}
+		}
+		synctest.Wait()
+
+		rec := cancelLabel(e, "PR-1", "Bearer admin-secret")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var response models.CancelJobsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.Cancelled, "One processing and two queued jobs")
+		assert.ElementsMatch(t, labelled, response.JobIDs)
+
+		// The worker moves on to the other label's job straight away
+		time.Sleep(time.Minute)
+		synctest.Wait()
+
+		for _, jobID := range labelled {
+			job, _ := server.jobs.Get(jobID)
+			assert.Equal(t, models.StatusCancelled, job.Status)
+			result, found := server.jobs.GetResult(jobID)
+			require.True(t, found)
+			assert.True(t, result.Cancelled)
+			assert.Equal(t, ErrJobCancelled.Error(), result.Error)
+		}
+		lister := server.jobs.(storage.JobLister)
+		completed, err := lister.ListByStatus(models.StatusCompleted)
+		require.NoError(t, err)
+		require.Len(t, completed, 1)
+		assert.Equal(t, "PR-2", completed[0].Request.Label)
+
+		// Nothing is left to cancel
+		rec = cancelLabel(e, "PR-1", "Bearer admin-secret")
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Zero(t, response.Cancelled)
+	})
+}
+
+// TestHandleCancelJob verifies that a processing job and a queued job are cancelled one by one, and
+// that finished and unknown jobs are refused.
+func TestHandleCancelJob(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &contextCompiler{mockCompiler{compileDelay: time.Minute}},
+			jobs:     memory.NewStore(),
+		}
+		server.workerPool = NewWorkerPool(1, 10, server)
+		server.workerPool.Start()
+		defer server.workerPool.Stop()
+		e := NewEchoServer(server, false)
+
+		var jobIDs []string
+		for range 3 {
+			job, err := server.enqueueJob(models.CompilationRequest{Code: "aW50IG1haW4oKSB7fQ==", Language: models.LanguageCpp})
+			require.NoError(t, err)
+			jobIDs = append(jobIDs, job.JobID)
+		}
+		synctest.Wait()
+		processing, queued, next := jobIDs[0], jobIDs[1], jobIDs[2]
+
+		for _, jobID := range []string{processing, queued} {
+			rec := serve(e, http.MethodDelete, "/api/v1/compile/"+jobID, "", nil)
+			require.Equal(t, http.StatusAccepted, rec.Code)
+			var response models.JobResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, models.JobResponse{JobID: jobID, Status: models.StatusCancelled}, response)
+		}
+		synctest.Wait()
+
+		// The worker was freed for the last job without waiting out the cancelled compile
+		job, _ := server.jobs.Get(next)
+		assert.Equal(t, models.StatusProcessing, job.Status)
+		for _, jobID := range []string{processing, queued} {
+			job, _ := server.jobs.Get(jobID)
+			assert.Equal(t, models.StatusCancelled, job.Status)
+			result, found := server.jobs.GetResult(jobID)
+			require.True(t, found)
+			assert.True(t, result.Cancelled)
+		}
+
+		rec := serve(e, http.MethodDelete, "/api/v1/compile/"+processing, "", nil)
+		assert.Equal(t, http.StatusConflict, rec.Code, "Already cancelled")
+
+		time.Sleep(time.Minute)
+		synctest.Wait()
+		rec = serve(e, http.MethodDelete, "/api/v1/compile/"+next, "", nil)
+		assert.Equal(t, http.StatusConflict, rec.Code, "Completed")
+
+		rec = serve(e, http.MethodDelete, "/api/v1/compile/unknown", "", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// cancelLabel cancels the jobs with a label, with the Authorization header if not empty.
+func cancelLabel(e *echo.Echo, label, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/compile/cancel?label="+url.QueryEscape(label), nil)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestHandleCancelJobs_InvalidLabel verifies that a missing or malformed label is rejected.
+func TestHandleCancelJobs_InvalidLabel(t *testing.T) {
+	server, _ := newUploadTestServer(DefaultMaxSourceSize)
+	server.adminToken = "admin-secret"
+	e := NewEchoServer(server, false)
+
+	for _, label := range []string{"", "-bad label"} {
+		rec := cancelLabel(e, label, "Bearer admin-secret")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, label)
+	}
+}
+
+// TestHandleCancelJobs_RequiresAdmin verifies that cancelling by label needs the admin token, and
+// is not served at all without one, so that no client cancels the jobs of another by their label.
+func TestHandleCancelJobs_RequiresAdmin(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &contextCompiler{mockCompiler{compileDelay: time.Minute}},
+			jobs:     memory.NewStore(),
+			apiKeys:  map[string]bool{"tenant-a": false, "tenant-b": false},
+		}
+		server.workerPool = NewWorkerPool(1, 10, server)
+		server.workerPool.Start()
+		defer server.workerPool.Stop()
+
+		var jobIDs []string
+		for range 2 {
+			job, err := server.enqueueJob(models.CompilationRequest{Code: "aW50IG1haW4oKSB7fQ==", Language: models.LanguageCpp, Label: "PR-1"})
+			require.NoError(t, err)
+			jobIDs = append(jobIDs, job.JobID)
+		}
+		synctest.Wait()
+
+		// Without an admin token the route does not exist
+		e := NewEchoServer(server, false)
+		rec := cancelLabel(e, "PR-1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		server.adminToken = "admin-secret"
+		e = NewEchoServer(server, false)
+		rec = cancelLabel(e, "PR-1", "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = cancelLabel(e, "PR-1", "Bearer wrong")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		// Another tenant's API key is not the admin token either
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/compile/cancel?label=PR-1", nil)
+		req.Header.Set(APIKeyHeader, "tenant-b")
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		synctest.Wait()
+		for _, jobID := range jobIDs {
+			job, _ := server.jobs.Get(jobID)
+			assert.NotEqual(t, models.StatusCancelled, job.Status)
+		}
+	})
+}
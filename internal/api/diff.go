@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// HandleGetJobDiff compares the result of a job against the result of another job
+//
+// @HTTP   GET /api/v1/compile/:job_id/diff?against=:other_id
+// @Param  job_id  path  string true "Job ID"
+// @Param  against query string true "Baseline job ID"
+// @Return 200 {object} models.ResultDiff "Differences in compiled status, exit code and diagnostics"
+// @Return 400 {object} models.ErrorResponse "Missing job ID or baseline job ID"
+// @Return 404 {object} models.ErrorResponse "Job not found"
+// @Return 409 {object} models.ErrorResponse "Job has no result yet".
+func (s *Server) HandleGetJobDiff(c echo.Context) error {
+	jobID := c.Param("job_id")
+	if jobID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "job ID required")
+	}
+	against := c.QueryParam("against")
+	if against == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "against job ID required")
+	}
+
+	result, err := s.completedResult(jobID)
+	if err != nil {
+		return err
+	}
+	baseline, err := s.completedResult(against)
+	if err != nil {
+		return err
+	}
+
+	diff := diffResults(baseline, result)
+	diff.JobID = jobID
+	diff.Against = against
+	return c.JSON(http.StatusOK, diff)
+}
+
+// completedResult returns the result of a job, or an HTTP error if the job is unknown or still running.
+func (s *Server) completedResult(jobID string) (models.CompilationResult, error) {
+	if _, exists := s.jobs.Get(jobID); !exists {
+		return models.CompilationResult{}, echo.NewHTTPError(http.StatusNotFound, "job not found: "+jobID)
+	}
+	result, hasResult := s.jobs.GetResult(jobID)
+	if !hasResult {
+		return models.CompilationResult{}, echo.NewHTTPError(http.StatusConflict,
+			fmt.Sprintf("job %s has no result yet", jobID))
+	}
+	return result, nil
+}
+
+// diffResults compares a result against a baseline result.
+func diffResults(baseline, result models.CompilationResult) models.ResultDiff {
+	diff := models.ResultDiff{
+		Regressed: baseline.Compiled && !result.Compiled,
+		Compiled:  models.BoolChange{Before: baseline.Compiled, After: result.Compiled},
+		ExitCode:  models.IntChange{Before: baseline.ExitCode, After: result.ExitCode},
+	}
+	diff.AddedDiagnostics = diagnosticsNotIn(result.Diagnostics, baseline.Diagnostics)
+	diff.RemovedDiagnostics = diagnosticsNotIn(baseline.Diagnostics, result.Diagnostics)
+	diff.Changed = diff.Compiled.Before != diff.Compiled.After ||
+		diff.ExitCode.Before != diff.ExitCode.After ||
+		len(diff.AddedDiagnostics) > 0 || len(diff.RemovedDiagnostics) > 0
+	return diff
+}
+
+// diagnosticKey identifies a diagnostic regardless of where it moved in the file.
+type diagnosticKey struct {
+	file, severity, message string
+}
+
+// diagnosticsNotIn returns the diagnostics of a that have no counterpart in b, in order.
+// Repeated diagnostics are matched one for one.
+func diagnosticsNotIn(a, b []models.Diagnostic) []models.Diagnostic {
+	remaining := make(map[diagnosticKey]int, len(b))
+	for _, d := range b {
+		remaining[diagnosticKey{d.File, d.Severity, d.Message}]++
+	}
+
+	var missing []models.Diagnostic
+	for _, d := range a {
+		key := diagnosticKey{d.File, d.Severity, d.Message}
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		missing = append(missing, d)
+	}
+	return missing
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleGetJobDiff tests that the diff of two stored results highlights what changed.
+func TestHandleGetJobDiff(t *testing.T) {
+	server, jobs := newUploadTestServer(DefaultMaxSourceSize)
+	e := NewEchoServer(server, false)
+
+	missingSemicolon := models.Diagnostic{File: "source.cpp", Line: 3, Column: 5, Severity: "error", Message: "expected ';' before 'return'"}
+	unusedBefore := models.Diagnostic{File: "source.cpp", Line: 2, Column: 9, Severity: "warning", Message: "unused variable 'x'"}
+	unusedAfter := unusedBefore
+	unusedAfter.Line = 4 // Moved by the change, not a new warning
+	shadow := models.Diagnostic{File: "source.cpp", Line: 7, Column: 13, Severity: "warning", Message: "declaration of 'y' shadows a previous local"}
+
+	jobs.jobs["before"] = models.CompilationJob{ID: "before", Status: models.StatusCompleted}
+	jobs.results["before"] = models.CompilationResult{
+		JobID:       "before",
+		Compiled:    true,
+		Diagnostics: []models.Diagnostic{unusedBefore, shadow},
+	}
+	jobs.jobs["after"] = models.CompilationJob{ID: "after", Status: models.StatusFailed}
+	jobs.results["after"] = models.CompilationResult{
+		JobID:       "after",
+		Compiled:    false,
+		ExitCode:    1,
+		Diagnostics: []models.Diagnostic{unusedAfter, missingSemicolon},
+	}
+
+	rec := serve(e, http.MethodGet, "/api/v1/compile/after/diff?against=before", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var diff models.ResultDiff
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &diff))
+	assert.Equal(t, "after", diff.JobID)
+	assert.Equal(t, "before", diff.Against)
+	assert.True(t, diff.Changed)
+	assert.True(t, diff.Regressed)
+	assert.Equal(t, models.BoolChange{Before: true, After: false}, diff.Compiled)
+	assert.Equal(t, models.IntChange{Before: 0, After: 1}, diff.ExitCode)
+	assert.Equal(t, []models.Diagnostic{missingSemicolon}, diff.AddedDiagnostics)
+	assert.Equal(t, []models.Diagnostic{shadow}, diff.RemovedDiagnostics)
+
+	// Comparing a job with itself shows no change
+	rec = serve(e, http.MethodGet, "/api/v1/compile/before/diff?against=before", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var same models.ResultDiff
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &same))
+	assert.False(t, same.Changed)
+	assert.False(t, same.Regressed)
+	assert.Empty(t, same.AddedDiagnostics)
+	assert.Empty(t, same.RemovedDiagnostics)
+}
+
+// TestHandleGetJobDiff_Errors tests that both results are required.
+func TestHandleGetJobDiff_Errors(t *testing.T) {
+	server, jobs := newUploadTestServer(DefaultMaxSourceSize)
+	e := NewEchoServer(server, false)
+
+	jobs.jobs["done"] = models.CompilationJob{ID: "done", Status: models.StatusCompleted}
+	jobs.results["done"] = models.CompilationResult{JobID: "done", Compiled: true}
+	jobs.jobs["queued"] = models.CompilationJob{ID: "queued", Status: models.StatusQueued}
+
+	testCases := []struct {
+		name     string
+		target   string
+		expected int
+	}{
+		{"missing_against", "/api/v1/compile/done/diff", http.StatusBadRequest},
+		{"unknown_job", "/api/v1/compile/missing/diff?against=done", http.StatusNotFound},
+		{"unknown_baseline", "/api/v1/compile/done/diff?against=missing", http.StatusNotFound},
+		{"baseline_without_result", "/api/v1/compile/done/diff?against=queued", http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(e, http.MethodGet, tc.target, "", nil)
+			assert.Equal(t, tc.expected, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,24 +19,102 @@ import (
 	"github.com/stlpine/will-it-compile/pkg/models"
 )
 
+// Headers a proxy can set to supply per-tenant defaults for omitted request fields.
+const (
+	HeaderDefaultCompiler = "X-Default-Compiler"
+	HeaderDefaultStandard = "X-Default-Standard"
+)
+
+// Sentinel errors for API server.
+var (
+	ErrReloadNotSupported         = errors.New("compiler does not support config reload")
+	ErrSelfTestNotSupported       = errors.New("compiler does not support self-test")
+	ErrWarmupNotSupported         = errors.New("compiler does not support warm-up")
+	ErrToolchainCheckNotSupported = errors.New("compiler does not support toolchain version checks")
+	ErrVersionProbeNotSupported   = errors.New("compiler does not support version probes")
+)
+
 // Server represents the API server.
 type Server struct {
 	compiler   compiler.CompilerInterface
 	jobs       storage.JobStore
 	workerPool *WorkerPool
+
+	staleJobGracePeriod time.Duration
+	maxBodyBytes        int64
+	maxBatchSize        int
+	adminToken          string
+	apiKeys             map[string]bool // API key -> trusted
+	apiKeyRateLimit     int
+	uploads             *uploadStore
+	quota               *DailyQuota // nil without a daily quota
 }
 
 // ServerConfig holds configuration for the server.
 type ServerConfig struct {
 	MaxWorkers int // Maximum number of concurrent workers (default: 5)
 	QueueSize  int // Size of the job queue (default: 100)
+
+	// StaleJobGracePeriod is how old a processing job's heartbeat must be before
+	// the job is re-enqueued on startup (default: 2m)
+	StaleJobGracePeriod time.Duration
+
+	// MaxBodyBytes caps request bodies at the HTTP layer (default: DefaultMaxBodyBytes)
+	MaxBodyBytes int64
+
+	// MaxBatchSize caps the number of requests in one batch submission (default: DefaultMaxBatchSize)
+	MaxBatchSize int
+
+	// AdminToken is the bearer token for operator endpoints such as the queue snapshot (empty disables them)
+	AdminToken string
+
+	// APIKeys are accepted in the X-API-Key header; their compile requests are rate limited
+	// per key at APIKeyRateLimit requests per minute instead of per IP (default: DefaultAPIKeyRateLimit)
+	APIKeys         []string
+	APIKeyRateLimit int
+
+	// TrustedAPIKeys are accepted like APIKeys but are not rate limited at all
+	TrustedAPIKeys []string
+
+	// MaxSourceSize caps the total size of a chunked source upload (default: DefaultMaxSourceSize)
+	MaxSourceSize int
+
+	// DailyQuota caps compiles per IP or API key per UTC day (0 disables the quota; trusted keys are exempt)
+	DailyQuota int
+
+	// WorkerReservations reserves workers per language, so a flood of slow builds in one language
+	// cannot take every worker (none by default; must leave at least one worker unreserved)
+	WorkerReservations map[models.Language]int
 }
 
+// DefaultMaxBatchSize is the batch size cap used when none is configured.
+const DefaultMaxBatchSize = 10
+
+// MaxBenchStandards caps the standards a bench compiles under.
+const MaxBenchStandards = 5
+
+// Wait budget of synchronous compiles (see syncWait).
+const (
+	// defaultSyncCompileTimeout matches the compiler's timeout for requests without timeout_seconds
+	defaultSyncCompileTimeout = 30 * time.Second
+
+	// syncWaitBuffer covers the time in the queue, container startup and extra passes
+	syncWaitBuffer = 15 * time.Second
+)
+
+// DefaultAPIKeyRateLimit is the per-key compile request limit per minute used when none is configured.
+const DefaultAPIKeyRateLimit = 60
+
 // DefaultServerConfig returns the default server configuration.
 func DefaultServerConfig() ServerConfig {
 	return ServerConfig{
-		MaxWorkers: 5,
-		QueueSize:  100,
+		MaxWorkers:          5,
+		QueueSize:           100,
+		StaleJobGracePeriod: DefaultStaleJobGracePeriod,
+		MaxBodyBytes:        DefaultMaxBodyBytes,
+		MaxBatchSize:        DefaultMaxBatchSize,
+		APIKeyRateLimit:     DefaultAPIKeyRateLimit,
+		MaxSourceSize:       DefaultMaxSourceSize,
 	}
 }
 
@@ -48,12 +131,24 @@ func NewServerWithConfig(config ServerConfig) (*Server, error) {
 	}
 
 	server := &Server{
-		compiler: comp,
-		jobs:     memory.NewStore(),
+		compiler:            comp,
+		jobs:                memory.NewStore(),
+		staleJobGracePeriod: config.StaleJobGracePeriod,
+		maxBodyBytes:        config.MaxBodyBytes,
+		maxBatchSize:        config.MaxBatchSize,
+		adminToken:          config.AdminToken,
+		apiKeys:             apiKeySet(config),
+		apiKeyRateLimit:     apiKeyRateLimit(config),
+		uploads:             newUploadStore(config.MaxSourceSize),
+		quota:               dailyQuota(config),
 	}
 
 	// Create and start worker pool
 	server.workerPool = NewWorkerPool(config.MaxWorkers, config.QueueSize, server)
+	if err := server.workerPool.SetReservations(config.WorkerReservations); err != nil {
+		_ = comp.Close() //nolint:errcheck // already failing
+		return nil, err
+	}
 	server.workerPool.Start()
 
 	return server, nil
@@ -67,17 +162,67 @@ func NewServerWithStorage(config ServerConfig, jobStore storage.JobStore) (*Serv
 	}
 
 	server := &Server{
-		compiler: comp,
-		jobs:     jobStore,
+		compiler:            comp,
+		jobs:                jobStore,
+		staleJobGracePeriod: config.StaleJobGracePeriod,
+		maxBodyBytes:        config.MaxBodyBytes,
+		maxBatchSize:        config.MaxBatchSize,
+		adminToken:          config.AdminToken,
+		apiKeys:             apiKeySet(config),
+		apiKeyRateLimit:     apiKeyRateLimit(config),
+		uploads:             newUploadStore(config.MaxSourceSize),
+		quota:               dailyQuota(config),
 	}
 
 	// Create and start worker pool
 	server.workerPool = NewWorkerPool(config.MaxWorkers, config.QueueSize, server)
+	if err := server.workerPool.SetReservations(config.WorkerReservations); err != nil {
+		_ = comp.Close() //nolint:errcheck // already failing
+		return nil, err
+	}
 	server.workerPool.Start()
 
+	// Pick up jobs a previous instance left behind in persistent storage
+	if requeued := server.RequeuePersistedJobs(time.Now()); requeued > 0 {
+		log.Printf("Re-enqueued %d persisted jobs", requeued)
+	}
+
 	return server, nil
 }
 
+// apiKeySet maps each configured API key to whether it is trusted. A key listed
+// in both APIKeys and TrustedAPIKeys is trusted.
+func apiKeySet(config ServerConfig) map[string]bool {
+	keys := make(map[string]bool, len(config.APIKeys)+len(config.TrustedAPIKeys))
+	for _, key := range config.APIKeys {
+		if key != "" {
+			keys[key] = false
+		}
+	}
+	for _, key := range config.TrustedAPIKeys {
+		if key != "" {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// apiKeyRateLimit returns the configured per-key rate limit, or DefaultAPIKeyRateLimit when unset.
+func apiKeyRateLimit(config ServerConfig) int {
+	if config.APIKeyRateLimit <= 0 {
+		return DefaultAPIKeyRateLimit
+	}
+	return config.APIKeyRateLimit
+}
+
+// dailyQuota returns the configured daily quota, or nil when none is configured.
+func dailyQuota(config ServerConfig) *DailyQuota {
+	if config.DailyQuota <= 0 {
+		return nil
+	}
+	return NewDailyQuota(config.DailyQuota)
+}
+
 // Close cleans up server resources.
 func (s *Server) Close() error {
 	if s.workerPool != nil {
@@ -92,6 +237,52 @@ func (s *Server) Close() error {
 	return s.compiler.Close()
 }
 
+// SelfTest compiles a hello-world program in every environment of the compiler.
+func (s *Server) SelfTest(ctx context.Context) error {
+	tester, ok := s.compiler.(compiler.SelfTester)
+	if !ok {
+		return ErrSelfTestNotSupported
+	}
+	return tester.SelfTest(ctx)
+}
+
+// Warmup runs a trivial compile in every environment of the compiler to prime its caches.
+func (s *Server) Warmup(ctx context.Context) error {
+	warmer, ok := s.compiler.(compiler.Warmer)
+	if !ok {
+		return ErrWarmupNotSupported
+	}
+	return warmer.Warmup(ctx)
+}
+
+// VerifyToolchainVersions checks that every environment pinning a toolchain version still runs it.
+func (s *Server) VerifyToolchainVersions(ctx context.Context) error {
+	verifier, ok := s.compiler.(compiler.ToolchainVerifier)
+	if !ok {
+		return ErrToolchainCheckNotSupported
+	}
+	return verifier.VerifyToolchainVersions(ctx)
+}
+
+// ProbeVersions records the version each environment's compiler actually reports, for the environments listing.
+func (s *Server) ProbeVersions(ctx context.Context) error {
+	prober, ok := s.compiler.(compiler.VersionProber)
+	if !ok {
+		return ErrVersionProbeNotSupported
+	}
+	return prober.ProbeVersions(ctx)
+}
+
+// ReloadEnvironments reloads the environments configuration into the compiler.
+// On failure the compiler keeps serving with its previous configuration.
+func (s *Server) ReloadEnvironments(ctx context.Context) error {
+	reloader, ok := s.compiler.(compiler.ConfigReloader)
+	if !ok {
+		return ErrReloadNotSupported
+	}
+	return reloader.ReloadConfig(ctx, compiler.GetDefaultConfigPath())
+}
+
 // =============================================================================
 // HTTP Handlers
 // =============================================================================
@@ -101,22 +292,468 @@ func (s *Server) Close() error {
 // @HTTP   POST /api/v1/compile
 // @Accept application/json
 // @Param  request body models.CompilationRequest true "Compilation request"
+// @Param  echo query bool false "Include the request as the server understood it in the response"
 // @Return 202 {object} models.JobResponse "Job created and queued"
 // @Return 400 {object} models.ErrorResponse "Invalid request body"
-// @Return 429 {object} models.ErrorResponse "No workers available (all busy)".
+// @Return 429 {object} models.ErrorResponse "No workers available (all busy), job queue full or daily quota exceeded"
+// @Return 503 {object} models.ErrorResponse "Server shutting down, or the image is being pulled (see Retry-After)".
 func (s *Server) HandleCompile(c echo.Context) error {
+	req, response, err := s.submitCompile(c)
+	if err != nil {
+		return err
+	}
+
+	if c.QueryParam("echo") == "true" {
+		echoed := s.resolvedRequest(req)
+		response.Request = &echoed
+	}
+
+	return c.JSON(http.StatusAccepted, response)
+}
+
+// resolvedRequest returns a queued request as the server understood it, for ?echo=true: without its sources,
+// with its language normalized and the compiler and standard of the environment it resolves to.
+func (s *Server) resolvedRequest(req models.CompilationRequest) models.CompilationRequest {
+	req = req.WithoutSource()
+	req.Language = req.Language.Normalize()
+	if resolver, ok := s.compiler.(compiler.EnvironmentResolver); ok {
+		if env, err := resolver.ResolveEnvironment(req); err == nil {
+			req.Compiler = env.Compiler
+			req.Standard = env.Standard
+		}
+	}
+	return req
+}
+
+// HandleCompileSync submits a compilation request and waits for its result
+//
+// @HTTP   POST /api/v1/compile/sync
+// @Accept application/json
+// @Param  request body models.CompilationRequest true "Compilation request"
+// @Return 200 {object} models.CompilationResult "Compilation result"
+// @Return 400 {object} models.ErrorResponse "Invalid request body"
+// @Return 429 {object} models.ErrorResponse "No workers available (all busy), job queue full or daily quota exceeded"
+// @Return 503 {object} models.ErrorResponse "Server shutting down, or the image is being pulled (see Retry-After)"
+// @Return 504 {object} models.JobResponse "Still queued or compiling at the deadline; poll the job instead".
+func (s *Server) HandleCompileSync(c echo.Context) error {
+	req, response, err := s.submitCompile(c)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.NewTimer(s.syncWait(req))
+	defer deadline.Stop()
+
+	result, finished, err := s.awaitResult(c, response.JobID, deadline.C)
+	if err != nil {
+		return err
+	}
+	if !finished {
+		return c.JSON(http.StatusGatewayTimeout, models.JobResponse{JobID: response.JobID, Status: s.jobStatus(response.JobID)})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// awaitResult waits for the result of a submitted job until the deadline. It returns false if the job is still
+// queued or compiling by then, and an error if the client goes away; the job keeps running either way.
+func (s *Server) awaitResult(c echo.Context, jobID string, deadline <-chan time.Time) (models.CompilationResult, bool, error) {
+	// Awaited after submitting, so a job that is already done is found in the store instead
+	done, stopWaiting := s.workerPool.Await(jobID)
+	defer stopWaiting()
+
+	if _, finished := s.jobs.GetResult(jobID); !finished {
+		select {
+		case <-done:
+		case <-deadline:
+			return models.CompilationResult{}, false, nil
+		case <-c.Request().Context().Done():
+			return models.CompilationResult{}, false, c.Request().Context().Err()
+		}
+	}
+
+	result, ok := s.jobs.GetResult(jobID)
+	if !ok {
+		return models.CompilationResult{}, false, echo.NewHTTPError(http.StatusInternalServerError, "failed to load result")
+	}
+	return result, true, nil
+}
+
+// jobStatus returns the current status of a job, or queued if it is not in the store (yet).
+func (s *Server) jobStatus(jobID string) models.JobStatus {
+	if job, exists := s.jobs.Get(jobID); exists {
+		return job.Status
+	}
+	return models.StatusQueued
+}
+
+// submitCompile is the submit path shared by HandleCompile and HandleCompileSync: it checks for a free
+// worker, binds the request, charges the daily quota and queues the job, refunding the quota if it could not
+// be queued. It returns the request as queued, with header defaults and any upload resolved.
+func (s *Server) submitCompile(c echo.Context) (models.CompilationRequest, models.JobResponse, error) {
+	var req models.CompilationRequest
+
 	// Check if workers are available
 	stats := s.workerPool.GetStats()
 	if stats.AvailableSlots == 0 {
-		return echo.NewHTTPError(http.StatusTooManyRequests, "no workers available, all workers are busy processing requests")
+		s.workerPool.RecordRejection()
+		return req, models.JobResponse{}, echo.NewHTTPError(http.StatusTooManyRequests, "no workers available, all workers are busy processing requests")
 	}
 
 	// Parse request body
-	var req models.CompilationRequest
 	if err := c.Bind(&req); err != nil {
+		return req, models.JobResponse{}, echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	applyHeaderDefaults(&req, c.Request().Header)
+	if err := s.resolveUpload(&req); err != nil {
+		return req, models.JobResponse{}, err
+	}
+	if err := s.checkImagePulling(c, req); err != nil {
+		return req, models.JobResponse{}, err
+	}
+	if err := s.consumeQuota(c, 1); err != nil {
+		return req, models.JobResponse{}, err
+	}
+
+	response, err := s.enqueueJob(req)
+	if err != nil {
+		s.refundQuota(c, 1)
+		return req, models.JobResponse{}, err
+	}
+	if req.UploadID != "" {
+		s.uploads.delete(req.UploadID)
+	}
+
+	return req, response, nil
+}
+
+// checkImagePulling turns a request away with 503 and Retry-After while the image it compiles in is
+// being pulled, rather than queuing a job that would hold a worker until the pull is done.
+func (s *Server) checkImagePulling(c echo.Context, req models.CompilationRequest) error {
+	reporter, ok := s.compiler.(compiler.ImagePullReporter)
+	if !ok || !reporter.ImagePulling(req) {
+		return nil
+	}
+	c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(compiler.PullRetryAfter.Seconds())))
+	return echo.NewHTTPError(http.StatusServiceUnavailable, "compiler image is being pulled, please try again later")
+}
+
+// syncWait is how long HandleCompileSync waits for a result: the compile timeout the request will actually
+// run with, as reported by the compiler, plus syncWaitBuffer.
+func (s *Server) syncWait(req models.CompilationRequest) time.Duration {
+	if resolver, ok := s.compiler.(compiler.TimeoutResolver); ok {
+		return resolver.EffectiveTimeout(req) + syncWaitBuffer
+	}
+
+	timeout := defaultSyncCompileTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+	return timeout + syncWaitBuffer
+}
+
+// HandleCompileBatch submits several compilation requests at once
+//
+// @HTTP   POST /api/v1/compile/batch
+// @Accept application/json
+// @Param  request body models.BatchCompilationRequest true "Compilation requests"
+// @Return 202 {object} models.BatchJobResponse "Jobs created and queued, in request order"
+// @Return 400 {object} models.ErrorResponse "Invalid request body, empty batch or batch too large"
+// @Return 429 {object} models.ErrorResponse "No workers available, not enough queue space or daily quota exceeded"
+// @Return 503 {object} models.ErrorResponse "Server shutting down, or an image is being pulled (see Retry-After)".
+func (s *Server) HandleCompileBatch(c echo.Context) error {
+	// Check if workers are available
+	stats := s.workerPool.GetStats()
+	if stats.AvailableSlots == 0 {
+		s.workerPool.RecordRejection()
+		return echo.NewHTTPError(http.StatusTooManyRequests, "no workers available, all workers are busy processing requests")
+	}
+
+	// Parse request body
+	var batch models.BatchCompilationRequest
+	if err := c.Bind(&batch); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
+	maxBatchSize := s.maxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultMaxBatchSize
+	}
+	if len(batch.Requests) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "batch must contain at least one request")
+	}
+	if len(batch.Requests) > maxBatchSize {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("batch contains %d requests, the maximum is %d", len(batch.Requests), maxBatchSize))
+	}
+
+	// Queue the whole batch or none of it
+	if free := s.workerPool.QueueCapacity() - stats.QueuedJobs; len(batch.Requests) > free {
+		s.workerPool.RecordRejection()
+		return echo.NewHTTPError(http.StatusTooManyRequests, "job queue is full, please try again later")
+	}
+
+	// Resolve uploads and check images up front, so a bad reference or an image being pulled rejects the
+	// batch before anything is queued
+	for i := range batch.Requests {
+		applyHeaderDefaults(&batch.Requests[i], c.Request().Header)
+		if err := s.resolveUpload(&batch.Requests[i]); err != nil {
+			return err
+		}
+		if err := s.checkImagePulling(c, batch.Requests[i]); err != nil {
+			return err
+		}
+	}
+
+	// Each request of the batch counts against the daily quota
+	if err := s.consumeQuota(c, len(batch.Requests)); err != nil {
+		return err
+	}
+
+	response := models.BatchJobResponse{Jobs: make([]models.JobResponse, 0, len(batch.Requests))}
+	for i, req := range batch.Requests {
+		job, err := s.enqueueJob(req)
+		if err != nil {
+			// The queue filled up since it was checked: the client never learns the IDs of the jobs
+			// already queued, so they are cancelled and refunded too, unless one has finished already
+			refund := len(batch.Requests) - i
+			for _, queued := range response.Jobs {
+				if s.workerPool.Cancel(queued.JobID) {
+					refund++
+				}
+			}
+			s.refundQuota(c, refund)
+			return err
+		}
+		response.Jobs = append(response.Jobs, job)
+	}
+	for _, req := range batch.Requests {
+		if req.UploadID != "" {
+			s.uploads.delete(req.UploadID)
+		}
+	}
+
+	return c.JSON(http.StatusAccepted, response)
+}
+
+// HandleCompileBench compiles the same source under several standards and reports each compile time
+//
+// @HTTP   POST /api/v1/compile/bench
+// @Accept application/json
+// @Param  request body models.BenchCompilationRequest true "Request and the standards to compile it under"
+// @Return 200 {object} models.BenchResponse "Compile time per standard, in request order"
+// @Return 400 {object} models.ErrorResponse "Invalid request body, no standards, too many or duplicate standards"
+// @Return 429 {object} models.ErrorResponse "No workers available, not enough queue space or daily quota exceeded"
+// @Return 503 {object} models.ErrorResponse "Server shutting down"
+// @Return 504 {object} models.BatchJobResponse "Not all compiles finished by the deadline; poll the jobs instead".
+func (s *Server) HandleCompileBench(c echo.Context) error {
+	// Check if workers are available
+	stats := s.workerPool.GetStats()
+	if stats.AvailableSlots == 0 {
+		s.workerPool.RecordRejection()
+		return echo.NewHTTPError(http.StatusTooManyRequests, "no workers available, all workers are busy processing requests")
+	}
+
+	// Parse request body
+	var bench models.BenchCompilationRequest
+	if err := c.Bind(&bench); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if len(bench.Standards) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "bench must name at least one standard")
+	}
+	if len(bench.Standards) > MaxBenchStandards {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("bench names %d standards, the maximum is %d", len(bench.Standards), MaxBenchStandards))
+	}
+	for i, standard := range bench.Standards {
+		if slices.Contains(bench.Standards[:i], standard) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("standard %q is named twice", standard))
+		}
+	}
+
+	// Queue every standard or none of them, like a batch
+	if free := s.workerPool.QueueCapacity() - stats.QueuedJobs; len(bench.Standards) > free {
+		s.workerPool.RecordRejection()
+		return echo.NewHTTPError(http.StatusTooManyRequests, "job queue is full, please try again later")
+	}
+
+	applyHeaderDefaults(&bench.Request, c.Request().Header)
+	if err := s.resolveUpload(&bench.Request); err != nil {
+		return err
+	}
+
+	// Each standard's compile counts against the daily quota
+	if err := s.consumeQuota(c, len(bench.Standards)); err != nil {
+		return err
+	}
+
+	jobs := make([]models.JobResponse, 0, len(bench.Standards))
+	for i, standard := range bench.Standards {
+		req := bench.Request
+		req.Standard = standard
+		job, err := s.enqueueJob(req)
+		if err != nil {
+			s.refundQuota(c, len(bench.Standards)-i)
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+	if bench.Request.UploadID != "" {
+		s.uploads.delete(bench.Request.UploadID)
+	}
+
+	// The compiles may run one after another, so the wait covers each of them
+	deadline := time.NewTimer(time.Duration(len(jobs)) * s.syncWait(bench.Request))
+	defer deadline.Stop()
+
+	response := models.BenchResponse{Results: make([]models.BenchResult, 0, len(jobs))}
+	for i, job := range jobs {
+		result, finished, err := s.awaitResult(c, job.JobID, deadline.C)
+		if err != nil {
+			return err
+		}
+		if !finished {
+			for j := range jobs {
+				jobs[j].Status = s.jobStatus(jobs[j].JobID)
+			}
+			return c.JSON(http.StatusGatewayTimeout, models.BatchJobResponse{Jobs: jobs})
+		}
+
+		response.Results = append(response.Results, models.BenchResult{
+			Standard: bench.Standards[i],
+			JobID:    job.JobID,
+			Compiled: result.Compiled,
+			Duration: result.Duration,
+			Cached:   result.Cached,
+			Error:    result.Error,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// HandleCancelJobs cancels every queued or processing job with a label, e.g. all jobs of a superseded pull request.
+// Labels are not scoped to a client, so this is an operator endpoint.
+//
+// @HTTP   POST /api/v1/compile/cancel
+// @Param  Authorization header string true "Bearer <ADMIN_TOKEN>"
+// @Param  label query string true "Label of the jobs to cancel"
+// @Return 200 {object} models.CancelJobsResponse "Jobs cancelled"
+// @Return 400 {object} models.ErrorResponse "Missing or invalid label"
+// @Return 401 {object} models.ErrorResponse "Invalid or missing admin token"
+// @Return 501 {object} models.ErrorResponse "Job storage cannot list jobs".
+func (s *Server) HandleCancelJobs(c echo.Context) error {
+	label := c.QueryParam("label")
+	if !models.LabelPattern.MatchString(label) {
+		return echo.NewHTTPError(http.StatusBadRequest, "a valid label is required")
+	}
+
+	lister, ok := s.jobs.(storage.JobLister)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotImplemented, "job storage cannot list jobs")
+	}
+
+	response := models.CancelJobsResponse{Label: label, JobIDs: []string{}}
+	for _, status := range []models.JobStatus{models.StatusQueued, models.StatusProcessing} {
+		jobs, err := lister.ListByStatus(status)
+		if err != nil {
+			log.Printf("Failed to list %s jobs: %v", status, err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to list jobs")
+		}
+		for _, job := range jobs {
+			if job.Request.Label == label && s.workerPool.Cancel(job.ID) {
+				response.JobIDs = append(response.JobIDs, job.ID)
+			}
+		}
+	}
+	slices.Sort(response.JobIDs)
+	response.Cancelled = len(response.JobIDs)
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// HandleCancelJob cancels a queued or processing job, freeing its worker. A processing job's
+// container is stopped and the job is stored as cancelled once it is; a queued job is dropped at once.
+//
+// @HTTP   DELETE /api/v1/compile/:job_id
+// @Param  job_id path string true "Job ID"
+// @Return 202 {object} models.JobResponse "Job cancelled"
+// @Return 400 {object} models.ErrorResponse "Missing job ID"
+// @Return 404 {object} models.ErrorResponse "Job not found"
+// @Return 409 {object} models.ErrorResponse "Job already finished, or processed by another instance".
+func (s *Server) HandleCancelJob(c echo.Context) error {
+	jobID := c.Param("job_id")
+	if jobID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "job ID required")
+	}
+
+	job, exists := s.jobs.Get(jobID)
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+	if job.Status != models.StatusQueued && job.Status != models.StatusProcessing {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("job already finished (%s)", job.Status))
+	}
+
+	if !s.workerPool.Cancel(jobID) {
+		// Finished since it was read, or queued on another instance, whose pool only it can cancel
+		if job, exists := s.jobs.Get(jobID); exists && job.Status != models.StatusQueued && job.Status != models.StatusProcessing {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("job already finished (%s)", job.Status))
+		}
+		return echo.NewHTTPError(http.StatusConflict, "job is processed by another instance")
+	}
+
+	return c.JSON(http.StatusAccepted, models.JobResponse{JobID: jobID, Status: models.StatusCancelled})
+}
+
+// HandleRerunJob submits the request of an earlier job again as a new job
+//
+// @HTTP   POST /api/v1/compile/:job_id/rerun
+// @Param  job_id path string true "Job ID of the request to rerun"
+// @Return 202 {object} models.JobResponse "New job created and queued"
+// @Return 400 {object} models.ErrorResponse "Missing job ID"
+// @Return 404 {object} models.ErrorResponse "Job not found"
+// @Return 429 {object} models.ErrorResponse "No workers available (all busy), job queue full or daily quota exceeded"
+// @Return 503 {object} models.ErrorResponse "Server shutting down".
+func (s *Server) HandleRerunJob(c echo.Context) error {
+	// Check if workers are available
+	stats := s.workerPool.GetStats()
+	if stats.AvailableSlots == 0 {
+		s.workerPool.RecordRejection()
+		return echo.NewHTTPError(http.StatusTooManyRequests, "no workers available, all workers are busy processing requests")
+	}
+
+	jobID := c.Param("job_id")
+	if jobID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "job ID required")
+	}
+
+	job, exists := s.jobs.Get(jobID)
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+
+	// The upload was resolved into the code when the job was submitted, and is gone by now
+	req := job.Request
+	req.UploadID = ""
+
+	if err := s.consumeQuota(c, 1); err != nil {
+		return err
+	}
+
+	response, err := s.enqueueJob(req)
+	if err != nil {
+		s.refundQuota(c, 1)
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, response)
+}
+
+// enqueueJob stores a new job for the request and submits it to the worker pool.
+// Errors are HTTP errors ready to be returned by a handler.
+func (s *Server) enqueueJob(req models.CompilationRequest) (models.JobResponse, error) {
 	// Create compilation job
 	job := models.CompilationJob{
 		ID:        uuid.New().String(),
@@ -128,22 +765,36 @@ func (s *Server) HandleCompile(c echo.Context) error {
 	// Store job
 	if err := s.jobs.Store(job); err != nil {
 		log.Printf("Failed to store job %s: %v", job.ID, err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to store job")
+		return models.JobResponse{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to store job")
 	}
 
-	// Submit to worker pool
-	if !s.workerPool.Submit(job) {
-		// Queue is full, return error
-		return echo.NewHTTPError(http.StatusTooManyRequests, "job queue is full, please try again later")
-	}
+	// Estimated before submitting, so a worker picking the job up straight away doesn't skew it
+	estimatedWait := s.workerPool.EstimateWait()
 
-	// Return job response
-	response := models.JobResponse{
-		JobID:  job.ID,
-		Status: models.StatusQueued,
+	// Submit to worker pool; a full queue is transient, a stopped pool means the server is going away
+	if err := s.workerPool.Submit(job); err != nil {
+		if errors.Is(err, ErrPoolStopped) {
+			return models.JobResponse{}, echo.NewHTTPError(http.StatusServiceUnavailable, "server is shutting down, please try again later")
+		}
+		return models.JobResponse{}, echo.NewHTTPError(http.StatusTooManyRequests, "job queue is full, please try again later")
 	}
 
-	return c.JSON(http.StatusAccepted, response)
+	return models.JobResponse{
+		JobID:           job.ID,
+		Status:          models.StatusQueued,
+		EstimatedWaitMs: estimatedWait.Milliseconds(),
+	}, nil
+}
+
+// applyHeaderDefaults fills the compiler and standard from proxy-supplied default headers
+// when the body omits them. The values are then validated like any body field.
+func applyHeaderDefaults(req *models.CompilationRequest, header http.Header) {
+	if req.Compiler == "" {
+		req.Compiler = models.Compiler(header.Get(HeaderDefaultCompiler))
+	}
+	if req.Standard == "" {
+		req.Standard = models.Standard(header.Get(HeaderDefaultStandard))
+	}
 }
 
 // HandleGetJob retrieves the status and result of a compilation job
@@ -180,6 +831,69 @@ func (s *Server) HandleGetJob(c echo.Context) error {
 	})
 }
 
+// HandleGetJobEvents returns the timeline of a compilation job
+//
+// @HTTP   GET /api/v1/compile/:job_id/events
+// @Param  job_id path string true "Job ID"
+// @Return 200 {object} models.JobEventsResponse "Status changes with timestamps and phase durations"
+// @Return 400 {object} models.ErrorResponse "Missing job ID"
+// @Return 404 {object} models.ErrorResponse "Job not found".
+func (s *Server) HandleGetJobEvents(c echo.Context) error {
+	jobID := c.Param("job_id")
+	if jobID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "job ID required")
+	}
+
+	job, exists := s.jobs.Get(jobID)
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+
+	return c.JSON(http.StatusOK, models.JobEventsResponse{
+		JobID:  job.ID,
+		Events: job.Events(),
+	})
+}
+
+// HandleGetJobFull returns a compilation job's metadata, timeline, resolved environment and result
+// in one response. The request's sources are only included for the admin token.
+//
+// @HTTP   GET /api/v1/compile/:job_id/full
+// @Param  job_id path string true "Job ID"
+// @Return 200 {object} models.JobDetails "Job, events, environment and result (once finished)"
+// @Return 400 {object} models.ErrorResponse "Missing job ID"
+// @Return 404 {object} models.ErrorResponse "Job not found".
+func (s *Server) HandleGetJobFull(c echo.Context) error {
+	jobID := c.Param("job_id")
+	if jobID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "job ID required")
+	}
+
+	job, exists := s.jobs.Get(jobID)
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+
+	details := models.JobDetails{
+		Job:            job,
+		Events:         job.Events(),
+		SourceIncluded: isAdmin(c, s.adminToken),
+	}
+	if !details.SourceIncluded {
+		details.Job.Request = job.Request.WithoutSource()
+	}
+	if resolver, ok := s.compiler.(compiler.EnvironmentResolver); ok {
+		if env, err := resolver.ResolveEnvironment(job.Request); err == nil {
+			details.Environment = &env
+		}
+	}
+	if result, hasResult := s.jobs.GetResult(jobID); hasResult {
+		details.Result = &result
+	}
+
+	return c.JSON(http.StatusOK, details)
+}
+
 // HandleGetEnvironments returns a list of supported compilation environments
 //
 // @HTTP   GET /api/v1/environments
@@ -200,6 +914,66 @@ func (s *Server) HandleHealth(c echo.Context) error {
 	})
 }
 
+// DetailedHealth is the response of /health/detailed.
+type DetailedHealth struct {
+	Status       string      `json:"status"`
+	Time         string      `json:"time"`
+	DockerPingMs *float64    `json:"docker_ping_ms,omitempty"` // Latest Docker daemon ping (omitted when unknown or unreachable)
+	Workers      WorkerStats `json:"workers"`
+}
+
+// HandleHealthDetailed returns the health status with runtime latency and worker statistics
+//
+// @HTTP   GET /health/detailed
+// @Return 200 {object} DetailedHealth "Detailed health status".
+func (s *Server) HandleHealthDetailed(c echo.Context) error {
+	health := DetailedHealth{
+		Status:  "healthy",
+		Time:    time.Now().Format(time.RFC3339),
+		Workers: s.workerPool.GetStats(),
+	}
+	if pingMs, ok := s.dockerPingMs(); ok {
+		health.DockerPingMs = &pingMs
+	}
+	return c.JSON(http.StatusOK, health)
+}
+
+// HandleMetrics returns gauges and the queue wait histogram in the Prometheus text exposition format
+//
+// @HTTP   GET /metrics
+// @Return 200 {string} string "Prometheus metrics".
+func (s *Server) HandleMetrics(c echo.Context) error {
+	stats := s.workerPool.GetStats()
+
+	var b strings.Builder
+	writeGauge(&b, "will_it_compile_active_workers", "Workers currently compiling.", float64(stats.ActiveWorkers))
+	writeGauge(&b, "will_it_compile_queued_jobs", "Jobs waiting in the queue.", float64(stats.QueuedJobs))
+	if pingMs, ok := s.dockerPingMs(); ok {
+		writeGauge(&b, "will_it_compile_docker_ping_ms", "Latency of the latest Docker daemon ping in milliseconds.", pingMs)
+	}
+	s.workerPool.queueWait.write(&b, "will_it_compile_queue_wait_seconds", "Time jobs waited in the queue before a worker started them.")
+
+	return c.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+// writeGauge writes a single gauge with its HELP and TYPE lines.
+func writeGauge(b *strings.Builder, name, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+}
+
+// dockerPingMs returns the runtime's latest Docker ping in milliseconds, if the compiler reports one.
+func (s *Server) dockerPingMs() (float64, bool) {
+	reporter, ok := s.compiler.(compiler.RuntimeLatencyReporter)
+	if !ok {
+		return 0, false
+	}
+	latency, ok := reporter.RuntimeLatency()
+	if !ok {
+		return 0, false
+	}
+	return float64(latency.Microseconds()) / 1000, true
+}
+
 // HandleGetWorkerStats returns the current worker pool statistics
 //
 // @HTTP   GET /api/v1/workers/stats
@@ -208,3 +982,89 @@ func (s *Server) HandleGetWorkerStats(c echo.Context) error {
 	stats := s.workerPool.GetStats()
 	return c.JSON(http.StatusOK, stats)
 }
+
+// ServerInfo describes the server to clients, such as queue-aware UIs.
+type ServerInfo struct {
+	Capacity PoolCapacity `json:"capacity"`
+}
+
+// HandleGetInfo returns the server's capacity
+//
+// @HTTP   GET /api/v1/info
+// @Return 200 {object} ServerInfo "Workers, queue size and worker reservations of the pool".
+func (s *Server) HandleGetInfo(c echo.Context) error {
+	return c.JSON(http.StatusOK, ServerInfo{Capacity: s.workerPool.Capacity()})
+}
+
+// HandleGetQueue returns the queued jobs in order and the jobs being processed
+//
+// @HTTP   GET /api/v1/queue
+// @Param  Authorization header string true "Bearer <ADMIN_TOKEN>"
+// @Return 200 {object} QueueSnapshot "Queued and processing jobs"
+// @Return 401 {object} models.ErrorResponse "Invalid or missing admin token".
+func (s *Server) HandleGetQueue(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.workerPool.Snapshot())
+}
+
+// Page sizes of the job listing.
+const (
+	defaultJobListLimit = 50
+	maxJobListLimit     = 500
+)
+
+// HandleListJobs returns a page of the stored jobs, newest first
+//
+// @HTTP   GET /api/v1/jobs
+// @Param  Authorization header string true "Bearer <ADMIN_TOKEN>"
+// @Param  status query string false "Only jobs in this status"
+// @Param  limit query int false "Jobs per page (default 50, at most 500)"
+// @Param  offset query int false "Jobs to skip (default 0)"
+// @Return 200 {object} models.JobListResponse "Job summaries, newest first"
+// @Return 400 {object} models.ErrorResponse "Invalid status, limit or offset"
+// @Return 401 {object} models.ErrorResponse "Invalid or missing admin token".
+func (s *Server) HandleListJobs(c echo.Context) error {
+	filter := models.JobFilter{Status: models.JobStatus(c.QueryParam("status")), Limit: defaultJobListLimit}
+	if filter.Status != "" && !filter.Status.Valid() {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unknown status %q", filter.Status))
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxJobListLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxJobListLimit))
+		}
+		filter.Limit = n
+	}
+	if offset := c.QueryParam("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "offset must be a non-negative integer")
+		}
+		filter.Offset = n
+	}
+
+	// One job past the page tells whether another page follows
+	page := filter
+	page.Limit++
+	jobs, err := s.jobs.List(page)
+	if err != nil {
+		log.Printf("Failed to list jobs: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list jobs")
+	}
+
+	response := models.JobListResponse{
+		Jobs:    make([]models.JobSummary, 0, min(len(jobs), filter.Limit)),
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+		HasMore: len(jobs) > filter.Limit,
+	}
+	for _, job := range jobs[:min(len(jobs), filter.Limit)] {
+		response.Jobs = append(response.Jobs, models.JobSummary{
+			JobID:     job.ID,
+			Status:    job.Status,
+			Language:  job.Request.Language,
+			CreatedAt: job.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -3,14 +3,19 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stlpine/will-it-compile/internal/compiler"
 	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -57,6 +62,7 @@ func TestHandleCompile_NoWorkersAvailable(t *testing.T) {
 	require.True(t, ok, "Expected echo.HTTPError")
 	assert.Equal(t, http.StatusTooManyRequests, httpErr.Code)
 	assert.Contains(t, httpErr.Message, "no workers available")
+	assert.Equal(t, int64(1), server.workerPool.GetStats().TotalRejected, "Rejection should be counted")
 }
 
 // TestHandleCompile_WorkersAvailable tests that compile requests are accepted
@@ -106,6 +112,571 @@ func TestHandleCompile_WorkersAvailable(t *testing.T) {
 	assert.Equal(t, models.StatusQueued, resp.Status)
 }
 
+// TestHandleCompile_SubmitErrors tests that a full queue and a stopped pool map to 429 and 503.
+func TestHandleCompile_SubmitErrors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		setup      func(pool *WorkerPool)
+		expected   int
+		messageHas string
+	}{
+		{
+			name: "queue_full",
+			setup: func(pool *WorkerPool) {
+				// Workers are not started, so the queued job stays in the only queue slot
+				require.NoError(t, pool.Submit(models.CompilationJob{ID: "queued"}))
+			},
+			expected:   http.StatusTooManyRequests,
+			messageHas: "job queue is full",
+		},
+		{
+			name:       "pool_stopped",
+			setup:      func(pool *WorkerPool) { pool.Stop() },
+			expected:   http.StatusServiceUnavailable,
+			messageHas: "shutting down",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &Server{
+				compiler: &httpMockCompiler{},
+				jobs:     newHTTPMockJobStore(),
+			}
+			server.workerPool = NewWorkerPool(1, 1, server)
+			defer server.workerPool.Stop()
+			tc.setup(server.workerPool)
+
+			bodyBytes, err := json.Marshal(models.CompilationRequest{
+				Code:     "aW50IG1haW4oKSB7IHJldHVybiAwOyB9", // base64 encoded "int main() { return 0; }"
+				Language: models.LanguageCpp,
+			})
+			require.NoError(t, err)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/compile", bytes.NewReader(bodyBytes))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			err = server.HandleCompile(c)
+
+			require.Error(t, err)
+			httpErr, ok := err.(*echo.HTTPError)
+			require.True(t, ok, "Expected echo.HTTPError")
+			assert.Equal(t, tc.expected, httpErr.Code)
+			assert.Contains(t, httpErr.Message, tc.messageHas)
+		})
+	}
+}
+
+// TestHandleCompile_BodyTooLarge tests that oversized bodies are rejected with 413
+// before the handler reads them.
+func TestHandleCompile_BodyTooLarge(t *testing.T) {
+	jobs := newHTTPMockJobStore()
+	server := &Server{
+		compiler:     &httpMockCompiler{},
+		jobs:         jobs,
+		maxBodyBytes: 1024,
+	}
+	server.workerPool = NewWorkerPool(1, 10, server)
+
+	reqBody := models.CompilationRequest{
+		Code:     base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("x"), 2048)),
+		Language: models.LanguageCpp,
+	}
+	bodyBytes, err := json.Marshal(reqBody)
+	require.NoError(t, err)
+
+	e := NewEchoServer(server, false)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/compile", bytes.NewReader(bodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Empty(t, jobs.jobs, "No job should be created")
+	assert.Zero(t, server.workerPool.GetStats().QueuedJobs)
+}
+
+// TestHandleCompile_HeaderDefaults tests that default headers fill only omitted body fields.
+func TestHandleCompile_HeaderDefaults(t *testing.T) {
+	testCases := []struct {
+		name             string
+		compiler         models.Compiler
+		standard         models.Standard
+		expectedCompiler models.Compiler
+		expectedStandard models.Standard
+	}{
+		{
+			name:             "body_fields_empty",
+			expectedCompiler: models.CompilerZig,
+			expectedStandard: models.StandardCpp17,
+		},
+		{
+			name:             "body_fields_set",
+			compiler:         models.CompilerGCC13,
+			standard:         models.StandardCpp20,
+			expectedCompiler: models.CompilerGCC13,
+			expectedStandard: models.StandardCpp20,
+		},
+		{
+			name:             "only_standard_set",
+			standard:         models.StandardCpp20,
+			expectedCompiler: models.CompilerZig,
+			expectedStandard: models.StandardCpp20,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jobs := newHTTPMockJobStore()
+			server := &Server{
+				compiler: &httpMockCompiler{},
+				jobs:     jobs,
+			}
+			// Not started, so the stored job is only written by the handler
+			server.workerPool = NewWorkerPool(1, 10, server)
+
+			bodyBytes, err := json.Marshal(models.CompilationRequest{
+				Code:     "aW50IG1haW4oKSB7IHJldHVybiAwOyB9",
+				Language: models.LanguageCpp,
+				Compiler: tc.compiler,
+				Standard: tc.standard,
+			})
+			require.NoError(t, err)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/compile", bytes.NewReader(bodyBytes))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(HeaderDefaultCompiler, string(models.CompilerZig))
+			req.Header.Set(HeaderDefaultStandard, string(models.StandardCpp17))
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, server.HandleCompile(e.NewContext(req, rec)))
+			require.Len(t, jobs.jobs, 1)
+
+			for _, job := range jobs.jobs {
+				assert.Equal(t, tc.expectedCompiler, job.Request.Compiler)
+				assert.Equal(t, tc.expectedStandard, job.Request.Standard)
+			}
+		})
+	}
+}
+
+// TestHandleCompile_Echo tests that ?echo=true returns the request with the compiler and standard it
+// resolved to, and without its source, and that responses leave it out otherwise.
+func TestHandleCompile_Echo(t *testing.T) {
+	server := &Server{
+		compiler: compiler.NewCompilerWithRuntime(&runtime.MockRuntime{}),
+		jobs:     newHTTPMockJobStore(),
+	}
+	// Not started, so jobs stay queued
+	server.workerPool = NewWorkerPool(1, 10, server)
+	e := NewEchoServer(server, false)
+
+	submit := func(query string) models.JobResponse {
+		t.Helper()
+		body := `{"code":"aW50IG1haW4oKSB7IHJldHVybiAwOyB9","language":"c++"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/compile"+query, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+		var response models.JobResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+
+	response := submit("?echo=true")
+	require.NotNil(t, response.Request)
+	assert.Equal(t, models.LanguageCpp, response.Request.Language)
+	assert.Equal(t, models.CompilerGCC13, response.Request.Compiler)
+	assert.Equal(t, models.StandardCpp20, response.Request.Standard)
+	assert.Empty(t, response.Request.Code)
+
+	assert.Nil(t, submit("").Request)
+}
+
+// TestHandleCompile_ImagePulling tests that requests for an image being pulled get 503 with Retry-After
+// and queue nothing, while requests for other images and for the image once pulled are queued.
+func TestHandleCompile_ImagePulling(t *testing.T) {
+	comp := compiler.NewCompilerWithRuntime(&runtime.MockRuntime{})
+	jobs := newHTTPMockJobStore()
+	server := &Server{compiler: comp, jobs: jobs}
+	// Not started, so jobs stay queued
+	server.workerPool = NewWorkerPool(1, 10, server)
+	e := NewEchoServer(server, false)
+
+	submit := func(target, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	const cpp = `{"code":"aW50IG1haW4oKSB7IHJldHVybiAwOyB9","language":"cpp"}`
+	const golang = `{"code":"cGFja2FnZSBtYWluCgpmdW5jIG1haW4oKSB7fQ==","language":"go"}`
+
+	comp.StartPull("gcc:13")
+
+	for _, target := range []string{"/api/v1/compile", "/api/v1/compile/sync"} {
+		rec := submit(target, cpp)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, target)
+		assert.Equal(t, "10", rec.Header().Get(echo.HeaderRetryAfter), target)
+		assert.Contains(t, rec.Body.String(), "being pulled", target)
+	}
+	rec := submit("/api/v1/compile/batch", `{"requests":[`+golang+`,`+cpp+`]}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "One image being pulled rejects the batch")
+	assert.Empty(t, jobs.jobs, "Nothing was queued")
+
+	rec = submit("/api/v1/compile", golang)
+	assert.Equal(t, http.StatusAccepted, rec.Code, "Another image is not held up")
+
+	comp.FinishPull("gcc:13")
+	rec = submit("/api/v1/compile", cpp)
+	assert.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.Empty(t, rec.Header().Get(echo.HeaderRetryAfter))
+}
+
+// TestHandleCompileBatch_MaxBatchSize tests that batches over the configured size are rejected
+// and one at the limit is accepted.
+func TestHandleCompileBatch_MaxBatchSize(t *testing.T) {
+	const maxBatchSize = 3
+
+	newBatch := func(size int) []byte {
+		batch := models.BatchCompilationRequest{}
+		for range size {
+			batch.Requests = append(batch.Requests, models.CompilationRequest{
+				Code:     "aW50IG1haW4oKSB7IHJldHVybiAwOyB9",
+				Language: models.LanguageCpp,
+			})
+		}
+		bodyBytes, err := json.Marshal(batch)
+		require.NoError(t, err)
+		return bodyBytes
+	}
+
+	jobs := newHTTPMockJobStore()
+	server := &Server{
+		compiler:     &httpMockCompiler{},
+		jobs:         jobs,
+		maxBatchSize: maxBatchSize,
+	}
+	// Not started, so queued jobs stay in the queue
+	server.workerPool = NewWorkerPool(1, 10, server)
+	e := echo.New()
+
+	// Over the limit
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/compile/batch", bytes.NewReader(newBatch(maxBatchSize+1)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	err := server.HandleCompileBatch(e.NewContext(req, rec))
+	require.Error(t, err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok, "Expected echo.HTTPError")
+	assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	assert.Contains(t, httpErr.Message, "the maximum is 3")
+	assert.Empty(t, jobs.jobs, "No job should be created for a rejected batch")
+
+	// At the limit
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/compile/batch", bytes.NewReader(newBatch(maxBatchSize)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+
+	require.NoError(t, server.HandleCompileBatch(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	var resp models.BatchJobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Jobs, maxBatchSize)
+	assert.Len(t, jobs.jobs, maxBatchSize)
+	assert.Equal(t, maxBatchSize, server.workerPool.GetStats().QueuedJobs)
+}
+
+// hookJobStore is an httpMockJobStore that calls onStore before storing a new job.
+type hookJobStore struct {
+	*httpMockJobStore
+	onStore func(job models.CompilationJob)
+}
+
+func (s *hookJobStore) Store(job models.CompilationJob) error {
+	if _, exists := s.jobs[job.ID]; !exists && s.onStore != nil {
+		s.onStore(job)
+	}
+	return s.httpMockJobStore.Store(job)
+}
+
+// TestHandleCompileBatch_QueueFillsUp tests that a batch whose queue fills up while it is being queued
+// cancels the jobs it already queued and refunds their quota, so none of it runs unseen by the client.
+func TestHandleCompileBatch_QueueFillsUp(t *testing.T) {
+	jobs := &hookJobStore{httpMockJobStore: newHTTPMockJobStore()}
+	server := &Server{
+		compiler: &httpMockCompiler{},
+		jobs:     jobs,
+		quota:    NewDailyQuota(3),
+	}
+	// Not started, so queued jobs stay in the queue
+	server.workerPool = NewWorkerPool(1, 3, server)
+	e := NewEchoServer(server, false)
+
+	// Another client's job takes the last slot between the batch's second and third job
+	stored := 0
+	jobs.onStore = func(models.CompilationJob) {
+		stored++
+		if stored == 2 {
+			require.NoError(t, server.workerPool.Submit(models.CompilationJob{ID: "other-client"}))
+		}
+	}
+
+	request := `{"code":"aW50IG1haW4oKSB7IHJldHVybiAwOyB9","language":"cpp"}`
+	rec := serve(e, http.MethodPost, "/api/v1/compile/batch", echo.MIMEApplicationJSON,
+		[]byte(`{"requests":[`+request+`,`+request+`,`+request+`]}`))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code, rec.Body.String())
+
+	assert.Equal(t, []QueuedJob{{JobID: "other-client", Position: 1}}, server.workerPool.Snapshot().Queued,
+		"Only the other client's job is left")
+	cancelled := 0
+	for _, job := range jobs.jobs {
+		if job.Status == models.StatusCancelled {
+			cancelled++
+		}
+	}
+	assert.Equal(t, 2, cancelled, "The two queued jobs were cancelled")
+
+	allowed, _ := server.quota.Allow("ip:192.0.2.1", 3)
+	assert.True(t, allowed, "The whole batch was refunded")
+}
+
+// TestHandleRerunJob tests that rerunning a job queues a new, distinct job with the same request.
+func TestHandleRerunJob(t *testing.T) {
+	jobs := newHTTPMockJobStore()
+	server := &Server{
+		compiler: &httpMockCompiler{},
+		jobs:     jobs,
+	}
+	// Not started, so queued jobs stay in the queue
+	server.workerPool = NewWorkerPool(1, 10, server)
+	e := NewEchoServer(server, false)
+
+	original := models.CompilationRequest{
+		Code:              "aW50IG1haW4oKSB7IHJldHVybiAwOyB9",
+		Language:          models.LanguageCpp,
+		Standard:          models.StandardCpp17,
+		DiagnosticsFormat: models.DiagnosticsFormatJSON,
+	}
+	bodyBytes, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/compile", bytes.NewReader(bodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var submitted models.JobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &submitted))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/compile/"+submitted.JobID+"/rerun", nil))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var rerun models.JobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rerun))
+	assert.NotEmpty(t, rerun.JobID)
+	assert.NotEqual(t, submitted.JobID, rerun.JobID)
+	assert.Equal(t, models.StatusQueued, rerun.Status)
+
+	require.Len(t, jobs.jobs, 2)
+	assert.Equal(t, jobs.jobs[submitted.JobID].Request, jobs.jobs[rerun.JobID].Request)
+	assert.Equal(t, original, jobs.jobs[rerun.JobID].Request)
+	assert.Equal(t, 2, server.workerPool.GetStats().QueuedJobs)
+
+	// Unknown jobs cannot be rerun
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/compile/missing/rerun", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestHandleListJobs tests that the job listing pages through the jobs newest first, filters them
+// by status and requires the admin token.
+func TestHandleListJobs(t *testing.T) {
+	jobs := newHTTPMockJobStore()
+	server := &Server{jobs: jobs, adminToken: "admin-secret"}
+	e := NewEchoServer(server, false)
+
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i, status := range []models.JobStatus{models.StatusCompleted, models.StatusFailed, models.StatusCompleted} {
+		id := fmt.Sprintf("job-%d", i)
+		jobs.jobs[id] = models.CompilationJob{
+			ID:        id,
+			Request:   models.CompilationRequest{Code: "aW50IG1haW4oKSB7fQ==", Language: models.LanguageC},
+			Status:    status,
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		}
+	}
+
+	list := func(query, authorization string) (*httptest.ResponseRecorder, models.JobListResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs"+query, nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var response models.JobListResponse
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		}
+		return rec, response
+	}
+
+	rec, _ := list("", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec, response := list("", "Bearer admin-secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, models.JobListResponse{
+		Jobs: []models.JobSummary{
+			{JobID: "job-2", Status: models.StatusCompleted, Language: models.LanguageC, CreatedAt: created.Add(2 * time.Minute)},
+			{JobID: "job-1", Status: models.StatusFailed, Language: models.LanguageC, CreatedAt: created.Add(time.Minute)},
+			{JobID: "job-0", Status: models.StatusCompleted, Language: models.LanguageC, CreatedAt: created},
+		},
+		Limit: defaultJobListLimit,
+	}, response)
+	assert.NotContains(t, rec.Body.String(), "aW50IG1haW4oKSB7fQ==", "Summaries never include the source")
+
+	_, response = list("?limit=1&offset=1", "Bearer admin-secret")
+	require.Len(t, response.Jobs, 1)
+	assert.Equal(t, "job-1", response.Jobs[0].JobID)
+	assert.True(t, response.HasMore)
+
+	_, response = list("?status=completed&offset=1", "Bearer admin-secret")
+	require.Len(t, response.Jobs, 1)
+	assert.Equal(t, "job-0", response.Jobs[0].JobID)
+	assert.False(t, response.HasMore)
+
+	for _, query := range []string{"?status=done", "?limit=0", "?limit=501", "?limit=x", "?offset=-1"} {
+		rec, _ = list(query, "Bearer admin-secret")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+// TestHandleGetJobFull tests that the combined payload of a completed job has every section, and
+// that the sources are only included for the admin token.
+func TestHandleGetJobFull(t *testing.T) {
+	jobs := newHTTPMockJobStore()
+	server := &Server{
+		compiler:   compiler.NewCompilerWithRuntime(&runtime.MockRuntime{}),
+		jobs:       jobs,
+		adminToken: "admin-secret",
+	}
+	e := NewEchoServer(server, false)
+
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	started := created.Add(time.Second)
+	completed := started.Add(2 * time.Second)
+	jobs.jobs["done"] = models.CompilationJob{
+		ID: "done",
+		Request: models.CompilationRequest{
+			Code:     "aW50IG1haW4oKSB7IHJldHVybiAwOyB9",
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+			Standard: models.StandardCpp17,
+			Label:    "PR-1",
+		},
+		Status:      models.StatusCompleted,
+		CreatedAt:   created,
+		StartedAt:   &started,
+		CompletedAt: &completed,
+	}
+	jobs.results["done"] = models.CompilationResult{JobID: "done", Success: true, Compiled: true}
+
+	get := func(authorization string) models.JobDetails {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/compile/done/full", nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var details models.JobDetails
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &details))
+		return details
+	}
+
+	details := get("")
+	assert.Equal(t, "done", details.Job.ID)
+	assert.Equal(t, models.StatusCompleted, details.Job.Status)
+	assert.Equal(t, "PR-1", details.Job.Request.Label)
+	require.Len(t, details.Events, 3)
+	assert.Equal(t, models.StatusCompleted, details.Events[2].Status)
+	require.NotNil(t, details.Environment)
+	assert.Equal(t, "gcc:13", details.Environment.ImageTag)
+	assert.Equal(t, models.StandardCpp17, details.Environment.Standard)
+	require.NotNil(t, details.Result)
+	assert.True(t, details.Result.Compiled)
+
+	// Sources need the admin token
+	assert.False(t, details.SourceIncluded)
+	assert.Empty(t, details.Job.Request.Code)
+	assert.Empty(t, get("Bearer wrong").Job.Request.Code)
+
+	details = get("Bearer admin-secret")
+	assert.True(t, details.SourceIncluded)
+	assert.Equal(t, "aW50IG1haW4oKSB7IHJldHVybiAwOyB9", details.Job.Request.Code)
+
+	rec := serve(e, http.MethodGet, "/api/v1/compile/missing/full", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// TestHandleHealthDetailed_DockerPing tests that the runtime's ping latency is surfaced
+// in /health/detailed and /metrics, and omitted when the compiler cannot report it.
+func TestHandleHealthDetailed_DockerPing(t *testing.T) {
+	server := &Server{
+		compiler: &latencyMockCompiler{latency: 2500 * time.Microsecond},
+		jobs:     newHTTPMockJobStore(),
+	}
+	server.workerPool = NewWorkerPool(1, 10, server)
+	e := NewEchoServer(server, false)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/detailed", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var health DetailedHealth
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+	require.NotNil(t, health.DockerPingMs)
+	assert.InDelta(t, 2.5, *health.DockerPingMs, 0.001)
+	assert.Equal(t, 1, health.Workers.MaxWorkers)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "# TYPE will_it_compile_docker_ping_ms gauge\nwill_it_compile_docker_ping_ms 2.5\n")
+
+	// A compiler without a probing runtime reports no latency
+	server.compiler = &httpMockCompiler{}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/detailed", nil))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+	assert.NotContains(t, rec.Body.String(), "docker_ping_ms")
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NotContains(t, rec.Body.String(), "docker_ping_ms")
+	assert.Contains(t, rec.Body.String(), "will_it_compile_queued_jobs 0\n")
+	assert.Contains(t, rec.Body.String(), "# TYPE will_it_compile_queue_wait_seconds histogram\n")
+	assert.Contains(t, rec.Body.String(), "will_it_compile_queue_wait_seconds_count 0\n")
+}
+
 // Mock implementations for testing (named differently to avoid conflicts with async_job_test.go)
 
 type httpMockCompiler struct{}
@@ -138,6 +709,18 @@ func (m *httpMockCompiler) Close() error {
 // Ensure httpMockCompiler implements the compiler interface
 var _ compiler.CompilerInterface = (*httpMockCompiler)(nil)
 
+// latencyMockCompiler is an httpMockCompiler whose runtime reports a fixed ping latency.
+type latencyMockCompiler struct {
+	httpMockCompiler
+	latency time.Duration
+}
+
+func (m *latencyMockCompiler) RuntimeLatency() (time.Duration, bool) {
+	return m.latency, true
+}
+
+var _ compiler.RuntimeLatencyReporter = (*latencyMockCompiler)(nil)
+
 type httpMockJobStore struct {
 	jobs    map[string]models.CompilationJob
 	results map[string]models.CompilationResult
@@ -170,6 +753,16 @@ func (s *httpMockJobStore) GetResult(jobID string) (models.CompilationResult, bo
 	return result, exists
 }
 
+func (s *httpMockJobStore) List(filter models.JobFilter) ([]models.CompilationJob, error) {
+	var jobs []models.CompilationJob
+	for _, job := range s.jobs {
+		if filter.Status == "" || job.Status == filter.Status {
+			jobs = append(jobs, job)
+		}
+	}
+	return filter.Page(jobs), nil
+}
+
 func (s *httpMockJobStore) Close() error {
 	return nil
 }
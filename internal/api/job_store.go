@@ -41,6 +41,19 @@ func (s *jobStore) Get(jobID string) (models.CompilationJob, bool) {
 	return job, exists
 }
 
+// List returns the jobs matching the filter, newest first.
+func (s *jobStore) List(filter models.JobFilter) ([]models.CompilationJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var jobs []models.CompilationJob
+	for _, job := range s.jobs {
+		if filter.Status == "" || job.Status == filter.Status {
+			jobs = append(jobs, job)
+		}
+	}
+	return filter.Page(jobs), nil
+}
+
 // StoreResult saves a compilation result.
 func (s *jobStore) StoreResult(jobID string, result models.CompilationResult) error {
 	s.mu.Lock()
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// queueWaitBuckets are the upper bounds in seconds of the queue wait histogram, from an idle
+// pool (well under a second) to a saturated one where jobs wait for several compiles ahead.
+var queueWaitBuckets = []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 120, 300}
+
+// histogram is a Prometheus-style cumulative histogram with fixed bucket bounds.
+type histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // Observations per bucket (not cumulative); the last entry is +Inf
+	sum    float64
+	count  uint64
+}
+
+// newHistogram creates a histogram with the given ascending upper bounds.
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// observe records a single value.
+func (h *histogram) observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := 0
+	for i < len(h.bounds) && value > h.bounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.sum += value
+	h.count++
+}
+
+// write writes the histogram with its HELP and TYPE lines in the Prometheus text exposition format.
+func (h *histogram) write(b *strings.Builder, name, help string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	var cumulative uint64
+	for i, count := range h.counts {
+		cumulative += count
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = strconv.FormatFloat(h.bounds[i], 'g', -1, 64)
+		}
+		fmt.Fprintf(b, "%s_bucket{le=%q} %d\n", name, le, cumulative)
+	}
+	fmt.Fprintf(b, "%s_sum %g\n%s_count %d\n", name, h.sum, name, h.count)
+}
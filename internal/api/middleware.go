@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"sync"
 	"time"
@@ -82,14 +83,69 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// APIKeyHeader is the request header carrying a client's API key.
+const APIKeyHeader = "X-API-Key"
+
+// apiClientContextKey is the echo context key under which APIKeyAuthMiddleware stores the *apiClient.
+const apiClientContextKey = "api_client"
+
+// apiClient is a client identified by a valid API key.
+type apiClient struct {
+	key     string
+	trusted bool // Trusted clients are not rate limited
+}
+
+// APIKeyAuthMiddleware returns an Echo middleware that identifies clients by the X-API-Key header.
+// keys maps each accepted key to whether it is trusted. Requests without the header stay anonymous;
+// requests with an unknown key are rejected rather than silently treated as anonymous.
+func APIKeyAuthMiddleware(keys map[string]bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			provided := c.Request().Header.Get(APIKeyHeader)
+			if provided == "" {
+				return next(c)
+			}
+
+			client := lookupAPIKey(keys, provided)
+			if client == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid API key")
+			}
+			c.Set(apiClientContextKey, client)
+			return next(c)
+		}
+	}
+}
+
+// lookupAPIKey compares the provided key against every accepted key in constant time,
+// so response timing does not reveal how much of a key matched.
+func lookupAPIKey(keys map[string]bool, provided string) *apiClient {
+	var found *apiClient
+	for key, trusted := range keys {
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key)) == 1 {
+			found = &apiClient{key: key, trusted: trusted}
+		}
+	}
+	return found
+}
+
 // RateLimitMiddleware returns an Echo middleware that enforces rate limiting.
-func RateLimitMiddleware(limiter *RateLimiter) echo.MiddlewareFunc {
+// Anonymous requests are limited per IP by anonymous; requests authenticated by
+// APIKeyAuthMiddleware (which must run first) are limited per key by authenticated,
+// and trusted keys are not limited at all.
+func RateLimitMiddleware(anonymous, authenticated *RateLimiter) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			if client, ok := c.Get(apiClientContextKey).(*apiClient); ok {
+				if !client.trusted && !authenticated.Allow(client.key) {
+					return echo.NewHTTPError(http.StatusTooManyRequests, "Rate limit exceeded")
+				}
+				return next(c)
+			}
+
 			// Extract IP (Echo handles X-Forwarded-For via RealIP())
 			ip := c.RealIP()
 
-			if !limiter.Allow(ip) {
+			if !anonymous.Allow(ip) {
 				return echo.NewHTTPError(http.StatusTooManyRequests, "Rate limit exceeded")
 			}
 
@@ -97,3 +153,26 @@ func RateLimitMiddleware(limiter *RateLimiter) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// AdminAuthMiddleware returns an Echo middleware that requires "Authorization: Bearer <token>"
+// for operator endpoints.
+func AdminAuthMiddleware(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !isAdmin(c, token) {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or missing admin token")
+			}
+			return next(c)
+		}
+	}
+}
+
+// isAdmin reports whether the request carries "Authorization: Bearer <token>", compared in constant
+// time. No request is an admin while token is empty.
+func isAdmin(c echo.Context, token string) bool {
+	if token == "" {
+		return false
+	}
+	provided := []byte(c.Request().Header.Get(echo.HeaderAuthorization))
+	return subtle.ConstantTimeCompare(provided, []byte("Bearer "+token)) == 1
+}
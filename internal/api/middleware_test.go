@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRateLimitedEcho returns an Echo instance with the API key and rate limit middleware in front of a no-op
+// handler, allowing anonymousLimit requests per IP and keyLimit requests per API key.
+func newRateLimitedEcho(keys map[string]bool, anonymousLimit, keyLimit int) *echo.Echo {
+	e := echo.New()
+	e.Use(APIKeyAuthMiddleware(keys), RateLimitMiddleware(
+		NewRateLimiter(anonymousLimit, time.Minute),
+		NewRateLimiter(keyLimit, time.Minute),
+	))
+	e.POST("/compile", func(c echo.Context) error { return c.NoContent(http.StatusAccepted) })
+	return e
+}
+
+// sendRequests sends n requests with the given API key (none when empty) and returns the response codes.
+func sendRequests(e *echo.Echo, apiKey string, n int) []int {
+	codes := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/compile", nil)
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	return codes
+}
+
+// TestRateLimitMiddleware_APIKeys tests that API keys get their own limit and trusted keys exceed the anonymous limit.
+func TestRateLimitMiddleware_APIKeys(t *testing.T) {
+	const anonymousLimit, keyLimit = 3, 5
+	keys := map[string]bool{"partner-key": false, "trusted-key": true}
+
+	t.Run("anonymous", func(t *testing.T) {
+		codes := sendRequests(newRateLimitedEcho(keys, anonymousLimit, keyLimit), "", anonymousLimit+1)
+		assert.Equal(t, []int{http.StatusAccepted, http.StatusAccepted, http.StatusAccepted, http.StatusTooManyRequests}, codes)
+	})
+
+	t.Run("api_key_uses_key_limit", func(t *testing.T) {
+		e := newRateLimitedEcho(keys, anonymousLimit, keyLimit)
+		codes := sendRequests(e, "partner-key", keyLimit+1)
+		for i, code := range codes[:keyLimit] {
+			assert.Equal(t, http.StatusAccepted, code, "request %d", i)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, codes[keyLimit])
+
+		// The key's bucket is separate from the IP's
+		assert.Equal(t, http.StatusAccepted, sendRequests(e, "", 1)[0])
+	})
+
+	t.Run("trusted_key_exceeds_anonymous_limit", func(t *testing.T) {
+		e := newRateLimitedEcho(keys, anonymousLimit, keyLimit)
+		for i, code := range sendRequests(e, "trusted-key", 10*keyLimit) {
+			assert.Equal(t, http.StatusAccepted, code, "request %d", i)
+		}
+	})
+
+	t.Run("unknown_key_rejected", func(t *testing.T) {
+		codes := sendRequests(newRateLimitedEcho(keys, anonymousLimit, keyLimit), "guessed-key", 1)
+		assert.Equal(t, []int{http.StatusUnauthorized}, codes)
+	})
+}
+
+// TestAPIKeySet tests that keys listed as both regular and trusted are trusted.
+func TestAPIKeySet(t *testing.T) {
+	keys := apiKeySet(ServerConfig{
+		APIKeys:        []string{"a", "b", ""},
+		TrustedAPIKeys: []string{"b", "c"},
+	})
+	assert.Equal(t, map[string]bool{"a": false, "b": true, "c": true}, keys)
+}
@@ -3,27 +3,48 @@ package api
 import (
 	"context"
 	"log"
-	"strings"
 	"time"
 
+	"github.com/stlpine/will-it-compile/internal/compiler"
 	"github.com/stlpine/will-it-compile/pkg/models"
 )
 
 // processJob processes a compilation job asynchronously
 // This runs in a goroutine and updates the job status throughout the process.
-func (s *Server) processJob(job models.CompilationJob) {
+// Cancelling ctx stops the compile, and the job is stored as cancelled.
+func (s *Server) processJob(ctx context.Context, job models.CompilationJob) {
 	// Update status to processing
 	job.Status = models.StatusProcessing
 	now := time.Now()
 	job.StartedAt = &now
+	job.LastHeartbeat = &now
+
+	// Queue wait sizes the pool: it grows when jobs arrive faster than the workers finish them
+	if s.workerPool != nil && !job.CreatedAt.IsZero() {
+		s.workerPool.queueWait.observe(now.Sub(job.CreatedAt).Seconds())
+	}
 
 	if err := s.jobs.Store(job); err != nil {
 		log.Printf("Failed to update job %s to processing status: %v", job.ID, err)
 		// Continue processing despite storage error
 	}
 
-	// Compile the code
-	result := s.compiler.Compile(context.Background(), job)
+	// Compile the code, keeping the heartbeat fresh so the job isn't considered abandoned
+	stopHeartbeat := s.startHeartbeat(job)
+	var result models.CompilationResult
+	if streamer, ok := s.compiler.(compiler.OutputStreamer); ok && s.workerPool != nil {
+		result = streamer.CompileStreaming(ctx, job, func(stream, chunk string) {
+			s.workerPool.publishOutput(job.ID, stream, chunk)
+		})
+	} else {
+		result = s.compiler.Compile(ctx, job)
+	}
+	stopHeartbeat()
+
+	if ctx.Err() != nil {
+		s.storeCancelled(job, result.Duration)
+		return
+	}
 
 	// Update job status based on result
 	// StatusCompleted = code compiled successfully (exit code 0)
@@ -33,7 +54,7 @@ func (s *Server) processJob(job models.CompilationJob) {
 	completed := time.Now()
 	job.CompletedAt = &completed
 
-	job.Status = determineJobStatus(result)
+	job.Status = result.Status()
 
 	if err := s.jobs.Store(job); err != nil {
 		log.Printf("Failed to update job %s to final status: %v", job.ID, err)
@@ -45,37 +66,48 @@ func (s *Server) processJob(job models.CompilationJob) {
 	}
 }
 
-// determineJobStatus determines the appropriate job status based on compilation result.
-// Status meanings:
-//   - StatusCompleted: code compiled successfully (exit code 0)
-//   - StatusFailed: code failed to compile (syntax/linker errors) - user's fault
-//   - StatusTimeout: compilation timed out - could be user's code (infinite template) or system
-//   - StatusError: infrastructure/system error - our fault
-func determineJobStatus(result models.CompilationResult) models.JobStatus {
-	// Check for timeout first (specific error message from compiler)
-	if result.Error == "compilation timeout" {
-		return models.StatusTimeout
+// storeCancelled stores a job as cancelled, with a result saying so.
+func (s *Server) storeCancelled(job models.CompilationJob, duration time.Duration) {
+	completed := time.Now()
+	job.CompletedAt = &completed
+	job.Status = models.StatusCancelled
+	if err := s.jobs.Store(job); err != nil {
+		log.Printf("Failed to update job %s to cancelled status: %v", job.ID, err)
 	}
 
-	// Check for infrastructure errors (runtime failures, validation errors)
-	if result.Error != "" {
-		// These are system/infrastructure errors, not user code errors
-		// Examples: "compilation failed: ...", "invalid base64 encoding"
-		if strings.HasPrefix(result.Error, "compilation failed:") ||
-			strings.Contains(result.Error, "base64") ||
-			strings.Contains(result.Error, "docker") ||
-			strings.Contains(result.Error, "container") {
-			return models.StatusError
-		}
-		// Any other error is treated as infrastructure error
-		return models.StatusError
+	result := models.CompilationResult{JobID: job.ID, Error: ErrJobCancelled.Error(), Cancelled: true, Duration: duration}
+	if err := s.jobs.StoreResult(job.ID, result); err != nil {
+		log.Printf("Failed to store result for job %s: %v", job.ID, err)
 	}
+}
 
-	// No error - check if code actually compiled
-	if result.Compiled {
-		return models.StatusCompleted
-	}
+// startHeartbeat periodically refreshes the job's heartbeat while it is processing.
+// The returned function stops the heartbeat and waits for any in-flight update,
+// so it cannot overwrite the job's final status.
+func (s *Server) startHeartbeat(job models.CompilationJob) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
 
-	// Code didn't compile (syntax errors, linker errors, etc.) - user's code issue
-	return models.StatusFailed
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case tick := <-ticker.C:
+				job.LastHeartbeat = &tick
+				if err := s.jobs.Store(job); err != nil {
+					log.Printf("Failed to update heartbeat for job %s: %v", job.ID, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
 }
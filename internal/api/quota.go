@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// QuotaResetHeader tells a client over its daily quota when the quota resets (RFC 3339, next UTC midnight).
+const QuotaResetHeader = "X-Quota-Reset"
+
+// DailyQuota counts compiles per client per UTC day.
+// ⚠️ Counts are local to the instance, like the rate limiters: each instance allows the full quota.
+type DailyQuota struct {
+	mu     sync.Mutex
+	limit  int
+	day    time.Time      // Start of the UTC day the counts belong to
+	counts map[string]int // Compiles so far today, by client
+	now    func() time.Time
+}
+
+// NewDailyQuota creates a quota of limit compiles per client per UTC day.
+func NewDailyQuota(limit int) *DailyQuota {
+	return &DailyQuota{
+		limit:  limit,
+		counts: make(map[string]int),
+		now:    time.Now,
+	}
+}
+
+// Allow records n compiles for a client if they fit in today's quota, and returns when the quota resets.
+// Compiles that don't fit are not recorded, so a rejected batch does not use up the quota.
+func (q *DailyQuota) Allow(client string, n int) (bool, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !day.Equal(q.day) {
+		// A new day starts everyone over; dropping yesterday's counts also keeps the map small
+		q.day = day
+		q.counts = make(map[string]int)
+	}
+	reset := day.AddDate(0, 0, 1)
+
+	if q.counts[client]+n > q.limit {
+		return false, reset
+	}
+	q.counts[client] += n
+	return true, reset
+}
+
+// Refund takes back n compiles recorded today for a client, e.g. for requests that were never queued.
+// Compiles recorded on an earlier day are already forgotten.
+func (q *DailyQuota) Refund(client string, n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now().UTC()
+	if !time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Equal(q.day) {
+		return
+	}
+	q.counts[client] = max(q.counts[client]-n, 0)
+	if q.counts[client] == 0 {
+		delete(q.counts, client)
+	}
+}
+
+// quotaClient identifies the client of a request for the daily quota: by API key, or by IP when
+// anonymous. It returns false when the client has no quota (no quota configured or a trusted key).
+func (s *Server) quotaClient(c echo.Context) (string, bool) {
+	if s.quota == nil {
+		return "", false
+	}
+	if key, ok := c.Get(apiClientContextKey).(*apiClient); ok {
+		if key.trusted {
+			return "", false
+		}
+		return "key:" + key.key, true
+	}
+	return "ip:" + c.RealIP(), true
+}
+
+// consumeQuota records n compiles against the client's daily quota, if one is configured.
+// Handlers refund compiles they end up not queueing with refundQuota.
+// Errors are HTTP errors ready to be returned by a handler.
+func (s *Server) consumeQuota(c echo.Context, n int) error {
+	client, ok := s.quotaClient(c)
+	if !ok {
+		return nil
+	}
+
+	allowed, reset := s.quota.Allow(client, n)
+	if allowed {
+		return nil
+	}
+
+	retryAfter := int(math.Ceil(reset.Sub(s.quota.now()).Seconds()))
+	c.Response().Header().Set(QuotaResetHeader, reset.Format(time.RFC3339))
+	c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return echo.NewHTTPError(http.StatusTooManyRequests,
+		fmt.Sprintf("daily compile quota of %d exceeded, resets at %s", s.quota.limit, reset.Format(time.RFC3339)))
+}
+
+// refundQuota takes back n compiles recorded by consumeQuota that were not queued.
+func (s *Server) refundQuota(c echo.Context, n int) {
+	if client, ok := s.quotaClient(c); ok {
+		s.quota.Refund(client, n)
+	}
+}
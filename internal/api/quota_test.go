@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDailyQuota tests that the quota blocks after the limit and resets at the next UTC midnight.
+func TestDailyQuota(t *testing.T) {
+	now := time.Date(2024, 1, 15, 22, 30, 0, 0, time.UTC)
+	quota := NewDailyQuota(3)
+	quota.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := quota.Allow("ip:192.0.2.1", 1)
+		require.True(t, allowed, "Compile %d is within the quota", i+1)
+	}
+	allowed, reset := quota.Allow("ip:192.0.2.1", 1)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), reset)
+
+	// Other clients have their own quota
+	allowed, _ = quota.Allow("ip:192.0.2.2", 1)
+	assert.True(t, allowed)
+
+	// A batch that doesn't fit is not recorded
+	allowed, _ = quota.Allow("ip:192.0.2.2", 3)
+	assert.False(t, allowed)
+	allowed, _ = quota.Allow("ip:192.0.2.2", 2)
+	assert.True(t, allowed)
+
+	// Refunded compiles can be used again
+	quota.Refund("ip:192.0.2.2", 2)
+	allowed, _ = quota.Allow("ip:192.0.2.2", 2)
+	assert.True(t, allowed)
+
+	// The next UTC day starts over, and refunds of yesterday's compiles don't carry over
+	now = time.Date(2024, 1, 16, 0, 0, 1, 0, time.UTC)
+	quota.Refund("ip:192.0.2.1", 3)
+	allowed, _ = quota.Allow("ip:192.0.2.1", 3)
+	assert.True(t, allowed)
+	allowed, _ = quota.Allow("ip:192.0.2.1", 1)
+	assert.False(t, allowed)
+}
+
+// TestHandleCompile_DailyQuota tests that compile requests over the quota get 429 with the reset time.
+func TestHandleCompile_DailyQuota(t *testing.T) {
+	server, _ := newUploadTestServer(DefaultMaxSourceSize)
+	server.quota = NewDailyQuota(2)
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	server.quota.now = func() time.Time { return now }
+	server.apiKeys = map[string]bool{"trusted-key": true}
+	e := NewEchoServer(server, false)
+
+	body, err := json.Marshal(models.CompilationRequest{
+		Code:     "aW50IG1haW4oKSB7IHJldHVybiAwOyB9",
+		Language: models.LanguageCpp,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		rec := serve(e, http.MethodPost, "/api/v1/compile", echo.MIMEApplicationJSON, body)
+		require.Equal(t, http.StatusAccepted, rec.Code)
+	}
+
+	rec := serve(e, http.MethodPost, "/api/v1/compile", echo.MIMEApplicationJSON, body)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), "daily compile quota of 2 exceeded")
+	assert.Equal(t, "2024-01-16T00:00:00Z", rec.Header().Get(QuotaResetHeader))
+	assert.Equal(t, "43200", rec.Header().Get(echo.HeaderRetryAfter))
+
+	// Trusted keys are exempt
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/compile", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(APIKeyHeader, "trusted-key")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	// The quota resets the next UTC day
+	now = now.Add(12 * time.Hour)
+	rec = serve(e, http.MethodPost, "/api/v1/compile", echo.MIMEApplicationJSON, body)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+// TestHandleCompile_DailyQuotaQueueFull tests that requests rejected by a full queue don't use up the quota.
+func TestHandleCompile_DailyQuotaQueueFull(t *testing.T) {
+	server, _ := newUploadTestServer(DefaultMaxSourceSize)
+	server.workerPool = NewWorkerPool(1, 1, server)
+	server.quota = NewDailyQuota(1)
+	e := NewEchoServer(server, false)
+
+	body, err := json.Marshal(models.CompilationRequest{
+		Code:     "aW50IG1haW4oKSB7IHJldHVybiAwOyB9",
+		Language: models.LanguageCpp,
+	})
+	require.NoError(t, err)
+
+	// Workers are not started, so the queued job stays in the only queue slot
+	require.NoError(t, server.workerPool.Submit(models.CompilationJob{ID: "queued"}))
+	for i := 0; i < 3; i++ {
+		rec := serve(e, http.MethodPost, "/api/v1/compile", echo.MIMEApplicationJSON, body)
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Contains(t, rec.Body.String(), "job queue is full", "Rejected by the queue, not the quota")
+	}
+
+	// With room in the queue again, the quota is still unused
+	server.workerPool = NewWorkerPool(1, 10, server)
+	rec := serve(e, http.MethodPost, "/api/v1/compile", echo.MIMEApplicationJSON, body)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"log"
+	"time"
+
+	"github.com/stlpine/will-it-compile/internal/storage"
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+const (
+	// heartbeatInterval is how often a processing job's heartbeat is refreshed.
+	heartbeatInterval = 15 * time.Second
+
+	// DefaultStaleJobGracePeriod is the default heartbeat age after which a
+	// processing job is considered abandoned. It must comfortably exceed heartbeatInterval.
+	DefaultStaleJobGracePeriod = 2 * time.Minute
+)
+
+// RequeuePersistedJobs re-enqueues jobs left unfinished by a previous instance and
+// returns how many were submitted. Queued jobs are always re-enqueued; processing jobs
+// only once their heartbeat is older than the grace period, so jobs another instance
+// is still compiling are left alone. Stores that cannot list jobs are skipped.
+func (s *Server) RequeuePersistedJobs(now time.Time) int {
+	lister, ok := s.jobs.(storage.JobLister)
+	if !ok {
+		return 0
+	}
+
+	gracePeriod := s.staleJobGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultStaleJobGracePeriod
+	}
+
+	var pending []models.CompilationJob
+	for _, status := range []models.JobStatus{models.StatusQueued, models.StatusProcessing} {
+		jobs, err := lister.ListByStatus(status)
+		if err != nil {
+			log.Printf("Failed to list %s jobs for recovery: %v", status, err)
+			continue
+		}
+		for _, job := range jobs {
+			if job.Status == models.StatusProcessing && !isStale(job, now, gracePeriod) {
+				continue
+			}
+			pending = append(pending, job)
+		}
+	}
+
+	requeued := 0
+	for _, job := range pending {
+		job.Status = models.StatusQueued
+		job.StartedAt = nil
+		job.LastHeartbeat = nil
+
+		if err := s.jobs.Store(job); err != nil {
+			log.Printf("Failed to reset job %s to queued status: %v", job.ID, err)
+			continue
+		}
+		if err := s.workerPool.Submit(job); err != nil {
+			log.Printf("Could not re-enqueue job %s: %v", job.ID, err)
+			continue
+		}
+		requeued++
+	}
+
+	return requeued
+}
+
+// isStale reports whether a processing job's heartbeat is older than the grace period.
+// Jobs stored before heartbeats existed fall back to their start time.
+func isStale(job models.CompilationJob, now time.Time, gracePeriod time.Duration) bool {
+	lastSeen := job.LastHeartbeat
+	if lastSeen == nil {
+		lastSeen = job.StartedAt
+	}
+	if lastSeen == nil {
+		return true
+	}
+	return now.Sub(*lastSeen) > gracePeriod
+}
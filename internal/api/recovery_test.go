@@ -0,0 +1,69 @@
+//go:build go1.25
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stlpine/will-it-compile/internal/storage/memory"
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequeuePersistedJobs tests that only queued jobs and processing jobs with a
+// stale heartbeat are re-enqueued on startup.
+func TestRequeuePersistedJobs(t *testing.T) {
+	now := time.Now()
+	fresh := now.Add(-10 * time.Second)
+	stale := now.Add(-5 * time.Minute)
+
+	store := memory.NewStore()
+	server := &Server{
+		compiler:            &mockCompiler{},
+		jobs:                store,
+		staleJobGracePeriod: time.Minute,
+	}
+	// Not started, so submitted jobs stay in the queue for inspection
+	server.workerPool = NewWorkerPool(1, 10, server)
+
+	jobs := []models.CompilationJob{
+		{ID: "queued", Status: models.StatusQueued, CreatedAt: stale},
+		{ID: "processing-fresh", Status: models.StatusProcessing, CreatedAt: stale, StartedAt: &stale, LastHeartbeat: &fresh},
+		{ID: "processing-stale", Status: models.StatusProcessing, CreatedAt: stale, StartedAt: &stale, LastHeartbeat: &stale},
+		{ID: "completed", Status: models.StatusCompleted, CreatedAt: stale},
+	}
+	for _, job := range jobs {
+		require.NoError(t, store.Store(job))
+	}
+
+	requeued := server.RequeuePersistedJobs(now)
+	assert.Equal(t, 2, requeued)
+
+	var queuedIDs []string
+	for len(server.workerPool.jobQueue) > 0 {
+		queuedIDs = append(queuedIDs, (<-server.workerPool.jobQueue).ID)
+	}
+	assert.ElementsMatch(t, []string{"queued", "processing-stale"}, queuedIDs)
+
+	// The stale job is reset; the fresh one is left to the instance still compiling it
+	job, _ := store.Get("processing-stale")
+	assert.Equal(t, models.StatusQueued, job.Status)
+	assert.Nil(t, job.LastHeartbeat)
+
+	job, _ = store.Get("processing-fresh")
+	assert.Equal(t, models.StatusProcessing, job.Status)
+}
+
+// TestIsStale tests the heartbeat age check, including jobs stored before heartbeats existed.
+func TestIsStale(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-30 * time.Second)
+	old := now.Add(-3 * time.Minute)
+
+	assert.False(t, isStale(models.CompilationJob{LastHeartbeat: &recent, StartedAt: &old}, now, time.Minute))
+	assert.True(t, isStale(models.CompilationJob{LastHeartbeat: &old}, now, time.Minute))
+	assert.False(t, isStale(models.CompilationJob{StartedAt: &recent}, now, time.Minute))
+	assert.True(t, isStale(models.CompilationJob{}, now, time.Minute))
+}
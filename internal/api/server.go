@@ -2,12 +2,31 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
+const (
+	// bodyLimitOverhead leaves room for the JSON fields around the encoded source.
+	bodyLimitOverhead = 256 * 1024
+
+	// DefaultMaxBodyBytes is the request body limit for the default 1MB source size.
+	DefaultMaxBodyBytes = 2*1024*1024 + bodyLimitOverhead
+
+	// anonymousRateLimit is the number of compile requests per minute allowed per IP without an API key.
+	anonymousRateLimit = 10
+)
+
+// MaxBodyBytesFor returns the request body limit for a maximum decoded source size.
+// Base64 grows the source by a third; twice the size matches the compiler's own
+// check on the encoded source, so that check stays reachable with its clearer error.
+func MaxBodyBytesFor(maxSourceSize int) int64 {
+	return 2*int64(maxSourceSize) + bodyLimitOverhead
+}
+
 // NewEchoServer creates a new Echo instance configured with the Server handlers.
 func NewEchoServer(server *Server, withRateLimit bool) *echo.Echo {
 	e := echo.New()
@@ -17,15 +36,24 @@ func NewEchoServer(server *Server, withRateLimit bool) *echo.Echo {
 	e.Use(middleware.Logger())  // Echo's built-in request logger
 	e.Use(middleware.Recover()) // Panic recovery
 
+	// Reject oversized bodies (413) before they are read into memory by c.Bind
+	maxBodyBytes := server.maxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	e.Use(middleware.BodyLimit(strconv.FormatInt(maxBodyBytes, 10)))
+
 	// CORS middleware
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
-		AllowHeaders: []string{"Content-Type"},
+		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
+		AllowHeaders: []string{"Content-Type", "Authorization", APIKeyHeader},
 	}))
 
-	// Health endpoint (no rate limit)
+	// Health and metrics endpoints (no rate limit)
 	e.GET("/health", server.HandleHealth)
+	e.GET("/health/detailed", server.HandleHealthDetailed)
+	e.GET("/metrics", server.HandleMetrics)
 
 	// API routes
 	apiGroup := e.Group("/api/v1")
@@ -33,18 +61,44 @@ func NewEchoServer(server *Server, withRateLimit bool) *echo.Echo {
 	// Read-only endpoints (no rate limit - lightweight, frequently polled)
 	apiGroup.GET("/environments", server.HandleGetEnvironments)
 	apiGroup.GET("/workers/stats", server.HandleGetWorkerStats)
+	apiGroup.GET("/info", server.HandleGetInfo)
 	apiGroup.GET("/compile/:job_id", server.HandleGetJob)
+	apiGroup.GET("/compile/:job_id/events", server.HandleGetJobEvents)
+	apiGroup.GET("/compile/:job_id/stream", server.HandleStreamJob)
+	apiGroup.GET("/compile/:job_id/full", server.HandleGetJobFull)
+	apiGroup.GET("/compile/:job_id/diff", server.HandleGetJobDiff)
+
+	// Operator endpoints, only registered when an admin token is configured
+	if server.adminToken != "" {
+		adminGroup := apiGroup.Group("")
+		adminGroup.Use(AdminAuthMiddleware(server.adminToken))
+		adminGroup.GET("/queue", server.HandleGetQueue)
+		adminGroup.GET("/jobs", server.HandleListJobs)
+		// Labels are shared by all clients, so only operators cancel by label
+		adminGroup.POST("/compile/cancel", server.HandleCancelJobs)
+	}
 
 	// Compilation endpoint (with optional rate limiting)
-	// This is resource-intensive and should be rate-limited
+	// This is resource-intensive and should be rate-limited. API keys are resolved
+	// first so that the rate limiter can apply the per-key limit.
+	compileGroup := apiGroup.Group("")
+	compileGroup.Use(APIKeyAuthMiddleware(server.apiKeys))
 	if withRateLimit {
-		rateLimiter := NewRateLimiter(10, time.Minute)
-		compileGroup := apiGroup.Group("")
-		compileGroup.Use(RateLimitMiddleware(rateLimiter))
-		compileGroup.POST("/compile", server.HandleCompile)
-	} else {
-		apiGroup.POST("/compile", server.HandleCompile)
+		compileGroup.Use(RateLimitMiddleware(
+			NewRateLimiter(anonymousRateLimit, time.Minute),
+			NewRateLimiter(server.apiKeyRateLimit, time.Minute),
+		))
 	}
+	compileGroup.POST("/compile", server.HandleCompile)
+	compileGroup.POST("/compile/sync", server.HandleCompileSync)
+	compileGroup.POST("/compile/batch", server.HandleCompileBatch)
+	compileGroup.POST("/compile/bench", server.HandleCompileBench)
+	compileGroup.DELETE("/compile/:job_id", server.HandleCancelJob)
+	compileGroup.POST("/compile/:job_id/rerun", server.HandleRerunJob)
+	compileGroup.POST("/sources", server.HandleCreateUpload)
+
+	// Chunks of an upload are not rate limited individually; the upload ID is only known to its creator
+	apiGroup.PUT("/sources/:upload_id", server.HandleUploadChunk)
 
 	return e
 }
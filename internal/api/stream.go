@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// streamPollInterval is how often a stream re-reads the job store, for jobs processed by another
+// instance, whose progress this instance's workers never publish.
+const streamPollInterval = time.Second
+
+// HandleStreamJob streams the progress of a compilation job as Server-Sent Events
+//
+// @HTTP   GET /api/v1/compile/:job_id/stream
+// @Param  job_id path string true "Job ID"
+// @Return 200 {string} string "Event stream of status, output and result events, ending with the result"
+// @Return 400 {object} models.ErrorResponse "Missing job ID"
+// @Return 404 {object} models.ErrorResponse "Job not found".
+func (s *Server) HandleStreamJob(c echo.Context) error {
+	jobID := c.Param("job_id")
+	if jobID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "job ID required")
+	}
+
+	// Subscribed before the store is read, so a job finishing in between still closes the channel;
+	// a nil channel never fires
+	var progress <-chan JobProgress
+	if s.workerPool != nil {
+		events, unsubscribe := s.workerPool.Subscribe(jobID)
+		defer unsubscribe()
+		progress = events
+	}
+
+	if _, exists := s.jobs.Get(jobID); !exists {
+		return echo.NewHTTPError(http.StatusNotFound, "job not found")
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &jobStream{server: s, w: w, jobID: jobID, progress: progress}
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if done := stream.poll(); done {
+			return nil
+		}
+
+	events:
+		for {
+			select {
+			case <-c.Request().Context().Done():
+				return nil
+			case <-ticker.C:
+				break events
+			case event, ok := <-stream.progress:
+				if !ok {
+					stream.progress = nil // Finished: the store has the result
+					break events
+				}
+				if err := stream.send(event); err != nil {
+					return nil // The response has started; a failed write means the client is gone
+				}
+			}
+		}
+	}
+}
+
+// jobStream is the state of an event stream of a job.
+type jobStream struct {
+	server   *Server
+	w        *echo.Response
+	jobID    string
+	progress <-chan JobProgress // Nil once closed, or if no worker pool publishes progress
+	status   models.JobStatus   // Last status sent
+}
+
+// poll reads the job from the store and sends its status if it changed, and its result once it has one.
+// It reports whether the stream is done: the result was sent, the job expired, or the client is gone.
+func (js *jobStream) poll() bool {
+	job, exists := js.server.jobs.Get(js.jobID)
+	result, hasResult := js.server.jobs.GetResult(js.jobID)
+
+	// Output published before the job was stored as finished still waits in the channel, and comes first
+	for drained := false; !drained; {
+		select {
+		case event, ok := <-js.progress:
+			if !ok {
+				js.progress = nil
+				drained = true
+			} else if err := js.send(event); err != nil {
+				return true
+			}
+		default:
+			drained = true
+		}
+	}
+
+	if !exists {
+		return true
+	}
+	if err := js.send(JobProgress{Status: job.Status}); err != nil {
+		return true
+	}
+	if !hasResult {
+		return false
+	}
+	_ = writeEvent(js.w, "result", result) //nolint:errcheck // The stream ends either way
+	return true
+}
+
+// send writes a progress event: a status event if the status changed, or an output event.
+func (js *jobStream) send(event JobProgress) error {
+	if event.Status == "" {
+		return writeEvent(js.w, "output", models.JobOutputChunk{Stream: event.Stream, Data: event.Output})
+	}
+	// The store may still say queued after the pool reported the job processing
+	if event.Status == js.status || (event.Status == models.StatusQueued && js.status != "") {
+		return nil
+	}
+	js.status = event.Status
+	return writeEvent(js.w, "status", models.JobResponse{JobID: js.jobID, Status: event.Status})
+}
+
+// writeEvent writes a Server-Sent Event with JSON data and flushes it to the client.
+func writeEvent(w *echo.Response, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,112 @@
+//go:build go1.25
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stlpine/will-it-compile/internal/storage/memory"
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingCompiler writes a line to stdout and one to stderr a second apart while it compiles.
+type streamingCompiler struct {
+	mockCompiler
+}
+
+func (m *streamingCompiler) CompileStreaming(ctx context.Context, job models.CompilationJob, onOutput runtime.OutputFunc) models.CompilationResult {
+	time.Sleep(time.Second)
+	onOutput(runtime.StreamStdout, "Compiling source.cpp\n")
+	time.Sleep(time.Second)
+	onOutput(runtime.StreamStderr, "warning: unused variable 'x'\n")
+	return models.CompilationResult{JobID: job.ID, Success: true, Compiled: true, Stderr: "warning: unused variable 'x'\n"}
+}
+
+// sseEvent is an event parsed from a text/event-stream body.
+type sseEvent struct {
+	event string
+	data  string
+}
+
+// parseEvents splits an event stream into its events.
+func parseEvents(t *testing.T, body string) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		event, data, found := strings.Cut(block, "\n")
+		require.True(t, found, block)
+		events = append(events, sseEvent{event: strings.TrimPrefix(event, "event: "), data: strings.TrimPrefix(data, "data: ")})
+	}
+	return events
+}
+
+// TestHandleStreamJob verifies that a stream follows a job's status and output as they happen and
+// ends with its result.
+func TestHandleStreamJob(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &streamingCompiler{},
+			jobs:     memory.NewStore(),
+		}
+		server.workerPool = NewWorkerPool(1, 10, server)
+		server.workerPool.Start()
+		defer server.workerPool.Stop()
+		e := NewEchoServer(server, false)
+
+		job, err := server.enqueueJob(models.CompilationRequest{Code: "aW50IG1haW4oKSB7fQ==", Language: models.LanguageCpp})
+		require.NoError(t, err)
+		synctest.Wait()
+
+		rec := serve(e, http.MethodGet, "/api/v1/compile/"+job.JobID+"/stream", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+		events := parseEvents(t, rec.Body.String())
+		require.Len(t, events, 5, rec.Body.String())
+
+		status := func(status models.JobStatus) string {
+			data, err := json.Marshal(models.JobResponse{JobID: job.JobID, Status: status})
+			require.NoError(t, err)
+			return string(data)
+		}
+		assert.Equal(t, sseEvent{"status", status(models.StatusProcessing)}, events[0])
+		assert.Equal(t, sseEvent{"output", `{"stream":"stdout","data":"Compiling source.cpp\n"}`}, events[1])
+		assert.Equal(t, sseEvent{"output", `{"stream":"stderr","data":"warning: unused variable 'x'\n"}`}, events[2])
+		assert.Equal(t, sseEvent{"status", status(models.StatusCompleted)}, events[3])
+
+		assert.Equal(t, "result", events[4].event)
+		var result models.CompilationResult
+		require.NoError(t, json.Unmarshal([]byte(events[4].data), &result))
+		assert.True(t, result.Compiled)
+		assert.Equal(t, job.JobID, result.JobID)
+	})
+}
+
+// TestHandleStreamJob_Finished verifies that a finished job's stream sends its final status and result at once.
+func TestHandleStreamJob_Finished(t *testing.T) {
+	server, store := newUploadTestServer(DefaultMaxSourceSize)
+	e := NewEchoServer(server, false)
+
+	store.jobs["job-1"] = models.CompilationJob{ID: "job-1", Status: models.StatusFailed}
+	store.results["job-1"] = models.CompilationResult{JobID: "job-1", Success: true, ExitCode: 1}
+
+	rec := serve(e, http.MethodGet, "/api/v1/compile/job-1/stream", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	events := parseEvents(t, rec.Body.String())
+	require.Len(t, events, 2)
+	assert.Equal(t, sseEvent{"status", `{"job_id":"job-1","status":"failed"}`}, events[0])
+	assert.Equal(t, "result", events[1].event)
+
+	rec = serve(e, http.MethodGet, "/api/v1/compile/unknown/stream", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
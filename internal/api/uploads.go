@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+const (
+	// DefaultMaxSourceSize is the total size cap of a chunked upload used when none is configured.
+	DefaultMaxSourceSize = 1 * 1024 * 1024
+
+	// uploadTTL is how long an upload may go unused before it is discarded.
+	uploadTTL = 15 * time.Minute
+
+	// maxPendingUploads caps the uploads held in memory at once.
+	maxPendingUploads = 100
+)
+
+// Sentinel errors for chunked uploads.
+var (
+	ErrUploadNotFound = errors.New("upload not found")
+	ErrUploadTooLarge = errors.New("upload too large")
+	ErrTooManyUploads = errors.New("too many pending uploads")
+	ErrUploadWithCode = errors.New("upload_id cannot be combined with code, archive or gist")
+)
+
+// upload is a source being assembled from chunks.
+type upload struct {
+	data      []byte
+	updatedAt time.Time
+}
+
+// uploadStore holds chunked source uploads in memory until a compile request references them.
+// ⚠️ Uploads are local to the instance: the chunks and the compile request must reach the same one.
+type uploadStore struct {
+	mu      sync.Mutex
+	uploads map[string]*upload
+	maxSize int
+	now     func() time.Time
+}
+
+// newUploadStore creates an upload store capping each upload at maxSize bytes.
+func newUploadStore(maxSize int) *uploadStore {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSourceSize
+	}
+	return &uploadStore{
+		uploads: make(map[string]*upload),
+		maxSize: maxSize,
+		now:     time.Now,
+	}
+}
+
+// create starts an empty upload and returns its ID. Expired uploads are discarded first.
+func (s *uploadStore) create() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, u := range s.uploads {
+		if now.Sub(u.updatedAt) > uploadTTL {
+			delete(s.uploads, id)
+		}
+	}
+	if len(s.uploads) >= maxPendingUploads {
+		return "", ErrTooManyUploads
+	}
+
+	id := uuid.New().String()
+	s.uploads[id] = &upload{updatedAt: now}
+	return id, nil
+}
+
+// appendChunk reads a chunk from r and appends it to the upload, returning the new total size.
+// A chunk that would take the upload past maxSize is rejected without changing the upload.
+func (s *uploadStore) appendChunk(id string, r io.Reader) (int, error) {
+	s.mu.Lock()
+	u, exists := s.uploads[id]
+	if !exists {
+		s.mu.Unlock()
+		return 0, ErrUploadNotFound
+	}
+	remaining := s.maxSize - len(u.data)
+	s.mu.Unlock()
+
+	// Read at most one byte past the remaining space, so oversized chunks are detected without buffering them
+	chunk, err := io.ReadAll(io.LimitReader(r, int64(remaining)+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read chunk: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The upload may have been consumed, or grown by a concurrent chunk, while reading
+	u, exists = s.uploads[id]
+	if !exists {
+		return 0, ErrUploadNotFound
+	}
+	if len(u.data)+len(chunk) > s.maxSize {
+		return 0, fmt.Errorf("%w: exceeds %d bytes", ErrUploadTooLarge, s.maxSize)
+	}
+	u.data = append(u.data, chunk...)
+	u.updatedAt = s.now()
+	return len(u.data), nil
+}
+
+// get returns the assembled source of an upload.
+func (s *uploadStore) get(id string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, exists := s.uploads[id]
+	if !exists {
+		return nil, false
+	}
+	return u.data, true
+}
+
+// delete discards an upload.
+func (s *uploadStore) delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.uploads, id)
+}
+
+// HandleCreateUpload starts a chunked source upload
+//
+// @HTTP   POST /api/v1/sources
+// @Return 201 {object} models.SourceUploadResponse "Upload created"
+// @Return 503 {object} models.ErrorResponse "Too many pending uploads".
+func (s *Server) HandleCreateUpload(c echo.Context) error {
+	id, err := s.uploads.create()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "too many pending uploads, please try again later")
+	}
+	return c.JSON(http.StatusCreated, models.SourceUploadResponse{UploadID: id})
+}
+
+// HandleUploadChunk appends the raw request body to a chunked upload. Chunks are appended
+// in the order they are received, so clients must send them one at a time.
+//
+// @HTTP   PUT /api/v1/sources/:upload_id
+// @Accept application/octet-stream
+// @Param  upload_id path string true "Upload ID"
+// @Return 200 {object} models.SourceUploadResponse "Chunk appended, with the total size so far"
+// @Return 404 {object} models.ErrorResponse "Upload not found"
+// @Return 413 {object} models.ErrorResponse "Upload exceeds the maximum source size".
+func (s *Server) HandleUploadChunk(c echo.Context) error {
+	id := c.Param("upload_id")
+	size, err := s.uploads.appendChunk(id, c.Request().Body)
+	switch {
+	case errors.Is(err, ErrUploadNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "upload not found")
+	case errors.Is(err, ErrUploadTooLarge):
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusOK, models.SourceUploadResponse{UploadID: id, Size: size})
+}
+
+// resolveUpload fills the code of a request that references an upload with the uploaded source.
+// Errors are HTTP errors ready to be returned by a handler.
+func (s *Server) resolveUpload(req *models.CompilationRequest) error {
+	if req.UploadID == "" {
+		return nil
+	}
+	if req.Code != "" || req.Archive != "" || req.Gist != "" {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrUploadWithCode.Error())
+	}
+
+	data, exists := s.uploads.get(req.UploadID)
+	if !exists {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%v: %s", ErrUploadNotFound, req.UploadID))
+	}
+	req.Code = base64.StdEncoding.EncodeToString(data)
+	return nil
+}
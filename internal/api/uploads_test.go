@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUploadTestServer returns a server with a stopped worker pool, so submitted jobs stay queued for inspection.
+func newUploadTestServer(maxSourceSize int) (*Server, *httpMockJobStore) {
+	jobs := newHTTPMockJobStore()
+	server := &Server{
+		compiler: &httpMockCompiler{},
+		jobs:     jobs,
+		uploads:  newUploadStore(maxSourceSize),
+	}
+	server.workerPool = NewWorkerPool(1, 10, server)
+	return server, jobs
+}
+
+// serve sends a request through the full Echo stack and returns the recorder.
+func serve(e *echo.Echo, method, target, contentType string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// createUpload starts an upload and returns its ID.
+func createUpload(t *testing.T, e *echo.Echo) string {
+	t.Helper()
+	rec := serve(e, http.MethodPost, "/api/v1/sources", "", nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created models.SourceUploadResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	require.NotEmpty(t, created.UploadID)
+	return created.UploadID
+}
+
+// TestChunkedUpload_CompileByReference tests uploading a source in two chunks and compiling it by upload ID.
+func TestChunkedUpload_CompileByReference(t *testing.T) {
+	server, jobs := newUploadTestServer(DefaultMaxSourceSize)
+	e := NewEchoServer(server, false)
+
+	first := []byte("#include <cstdio>\n")
+	second := []byte("int main() { std::puts(\"chunked\"); }\n")
+
+	uploadID := createUpload(t, e)
+	rec := serve(e, http.MethodPut, "/api/v1/sources/"+uploadID, echo.MIMEOctetStream, first)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(e, http.MethodPut, "/api/v1/sources/"+uploadID, echo.MIMEOctetStream, second)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var appended models.SourceUploadResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &appended))
+	assert.Equal(t, len(first)+len(second), appended.Size)
+
+	body, err := json.Marshal(models.CompilationRequest{UploadID: uploadID, Language: models.LanguageCpp})
+	require.NoError(t, err)
+	rec = serve(e, http.MethodPost, "/api/v1/compile", echo.MIMEApplicationJSON, body)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var response models.JobResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	job, exists := jobs.jobs[response.JobID]
+	require.True(t, exists)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(append(first, second...)), job.Request.Code)
+	assert.Equal(t, uploadID, job.Request.UploadID)
+
+	// The upload is consumed by the compile request
+	rec = serve(e, http.MethodPost, "/api/v1/compile", echo.MIMEApplicationJSON, body)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "upload not found")
+}
+
+// TestChunkedUpload_SizeCap tests that the size cap applies to the total of all chunks.
+func TestChunkedUpload_SizeCap(t *testing.T) {
+	server, _ := newUploadTestServer(10)
+	e := NewEchoServer(server, false)
+	uploadID := createUpload(t, e)
+
+	rec := serve(e, http.MethodPut, "/api/v1/sources/"+uploadID, echo.MIMEOctetStream, []byte("123456"))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(e, http.MethodPut, "/api/v1/sources/"+uploadID, echo.MIMEOctetStream, []byte("789012"))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// The rejected chunk is not appended, so a chunk that fits still does
+	rec = serve(e, http.MethodPut, "/api/v1/sources/"+uploadID, echo.MIMEOctetStream, []byte("7890"))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	data, exists := server.uploads.get(uploadID)
+	require.True(t, exists)
+	assert.Equal(t, "1234567890", string(data))
+}
+
+// TestChunkedUpload_Errors tests unknown uploads and uploads combined with another source.
+func TestChunkedUpload_Errors(t *testing.T) {
+	server, jobs := newUploadTestServer(DefaultMaxSourceSize)
+	e := NewEchoServer(server, false)
+
+	rec := serve(e, http.MethodPut, "/api/v1/sources/missing", echo.MIMEOctetStream, []byte("int x;"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	uploadID := createUpload(t, e)
+	body, err := json.Marshal(models.CompilationRequest{
+		UploadID: uploadID,
+		Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+		Language: models.LanguageCpp,
+	})
+	require.NoError(t, err)
+	rec = serve(e, http.MethodPost, "/api/v1/compile", echo.MIMEApplicationJSON, body)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "upload_id cannot be combined")
+
+	// A batch with a bad reference queues nothing
+	batch, err := json.Marshal(models.BatchCompilationRequest{Requests: []models.CompilationRequest{
+		{UploadID: uploadID, Language: models.LanguageCpp},
+		{UploadID: "missing", Language: models.LanguageCpp},
+	}})
+	require.NoError(t, err)
+	rec = serve(e, http.MethodPost, "/api/v1/compile/batch", echo.MIMEApplicationJSON, batch)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, jobs.jobs)
+	_, exists := server.uploads.get(uploadID)
+	assert.True(t, exists, "The valid upload should be kept for a retry")
+}
+
+// TestUploadStore_PendingLimit tests that expired uploads make room for new ones.
+func TestUploadStore_PendingLimit(t *testing.T) {
+	store := newUploadStore(DefaultMaxSourceSize)
+	for i := 0; i < maxPendingUploads; i++ {
+		_, err := store.create()
+		require.NoError(t, err)
+	}
+	_, err := store.create()
+	require.ErrorIs(t, err, ErrTooManyUploads)
+
+	later := store.now().Add(uploadTTL + 1)
+	store.now = func() time.Time { return later }
+	_, err = store.create()
+	assert.NoError(t, err)
+	assert.Len(t, store.uploads, 1)
+}
@@ -2,8 +2,12 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +15,25 @@ import (
 	"github.com/stlpine/will-it-compile/pkg/models"
 )
 
+// Sentinel errors for job submission.
+var (
+	ErrQueueFull           = errors.New("job queue is full")
+	ErrPoolStopped         = errors.New("worker pool is stopped")
+	ErrInvalidReservations = errors.New("invalid worker reservations")
+
+	// ErrJobCancelled is the error of a cancelled job's result.
+	ErrJobCancelled = errors.New("job cancelled")
+)
+
+const (
+	// compileAverageWeight is the weight of the latest compile in the moving average, so the
+	// estimate follows a change in workload within a few jobs
+	compileAverageWeight = 0.2
+
+	// defaultCompileEstimate is the assumed compile duration before any job has finished
+	defaultCompileEstimate = 2 * time.Second
+)
+
 // WorkerPool manages a pool of workers for processing compilation jobs.
 type WorkerPool struct {
 	// Configuration
@@ -19,6 +42,19 @@ type WorkerPool struct {
 	// Job queue
 	jobQueue chan models.CompilationJob
 
+	// Job IDs in the queue (in order) and being processed (with start time), for snapshots.
+	// stopped is set when the queue is closed, so Submit never sends on a closed channel
+	mu         sync.Mutex
+	queued     []string
+	processing map[string]time.Time
+	stopped    bool
+
+	// Workers reserved per language, so one slow language cannot take every worker. Jobs that would
+	// take a slot still reserved for another language wait in deferred (in queue order) until one frees
+	reservations      map[models.Language]int
+	runningByLanguage map[models.Language]int
+	deferred          []models.CompilationJob
+
 	// Worker tracking
 	activeWorkers   atomic.Int32
 	availableSlots  atomic.Int32
@@ -27,6 +63,24 @@ type WorkerPool struct {
 	totalFailed     atomic.Int64 // Code failed to compile (user's code errors)
 	totalTimeout    atomic.Int64 // Compilation timed out
 	totalErrors     atomic.Int64 // Infrastructure/system errors
+	totalRejected   atomic.Int64 // Submissions turned away (queue full or no workers)
+
+	// Time jobs waited from submission to a worker starting them, in seconds
+	queueWait *histogram
+
+	// Moving average of recent compile durations, for wait estimates. Guarded by mu
+	avgCompile time.Duration
+
+	// Channels closed when a job finishes, for callers awaiting its result. Guarded by mu
+	waiters map[string]chan struct{}
+
+	// Channels of the clients following jobs' progress, closed when the job finishes. Guarded by mu
+	subscribers map[string][]chan JobProgress
+
+	// Compile contexts of the jobs being processed, for Cancel, and the jobs cancelled while still in
+	// the queue channel, which workers drop when they receive them. Guarded by mu
+	jobContexts map[string]jobContext
+	cancelled   map[string]struct{}
 
 	// Server reference for job processing
 	server *Server
@@ -40,6 +94,23 @@ type WorkerPool struct {
 	startTime time.Time
 }
 
+// JobProgress is a progress event of a job in the pool: it started processing (Status), or the
+// compile wrote a chunk of output (Stream and Output).
+type JobProgress struct {
+	Status models.JobStatus
+	Stream string
+	Output string
+}
+
+// progressBuffer is how many progress events a subscriber may fall behind before events are dropped.
+const progressBuffer = 64
+
+// jobContext is the compile context of a job being processed, with its cancel function.
+type jobContext struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
 // WorkerStats represents the current state of the worker pool.
 type WorkerStats struct {
 	MaxWorkers      int       `json:"max_workers"`
@@ -51,22 +122,56 @@ type WorkerStats struct {
 	TotalFailed     int64     `json:"total_failed"`     // Code failed to compile (user errors)
 	TotalTimeout    int64     `json:"total_timeout"`    // Compilation timed out
 	TotalErrors     int64     `json:"total_errors"`     // Infrastructure/system errors
+	TotalRejected   int64     `json:"total_rejected"`   // Submissions turned away (queue full or no workers)
 	Uptime          string    `json:"uptime"`
 	UptimeSeconds   int64     `json:"uptime_seconds"`
 	StartTime       time.Time `json:"start_time"`
 }
 
+// PoolCapacity is the configured capacity of the pool, for clients that pace their submissions to it.
+type PoolCapacity struct {
+	MaxWorkers         int                     `json:"max_workers"`
+	QueueSize          int                     `json:"queue_size"`                    // Jobs that can wait for a worker
+	WorkerReservations map[models.Language]int `json:"worker_reservations,omitempty"` // Workers kept free for a language
+}
+
+// QueueSnapshot is a consistent view of the jobs waiting in and being processed by the pool.
+type QueueSnapshot struct {
+	Queued     []QueuedJob     `json:"queued"`     // In the order they will be processed
+	Processing []ProcessingJob `json:"processing"` // Oldest first
+	Time       time.Time       `json:"time"`
+}
+
+// QueuedJob is a job waiting in the queue.
+type QueuedJob struct {
+	JobID    string `json:"job_id"`
+	Position int    `json:"position"` // 1 = next to be picked up
+}
+
+// ProcessingJob is a job a worker is processing.
+type ProcessingJob struct {
+	JobID     string    `json:"job_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
 // NewWorkerPool creates a new worker pool with the specified number of workers.
 func NewWorkerPool(maxWorkers int, queueSize int, server *Server) *WorkerPool {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	pool := &WorkerPool{
-		maxWorkers: maxWorkers,
-		jobQueue:   make(chan models.CompilationJob, queueSize),
-		server:     server,
-		ctx:        ctx,
-		cancel:     cancel,
-		startTime:  time.Now(),
+		maxWorkers:        maxWorkers,
+		jobQueue:          make(chan models.CompilationJob, queueSize),
+		processing:        make(map[string]time.Time),
+		runningByLanguage: make(map[models.Language]int),
+		queueWait:         newHistogram(queueWaitBuckets),
+		waiters:           make(map[string]chan struct{}),
+		subscribers:       make(map[string][]chan JobProgress),
+		jobContexts:       make(map[string]jobContext),
+		cancelled:         make(map[string]struct{}),
+		server:            server,
+		ctx:               ctx,
+		cancel:            cancel,
+		startTime:         time.Now(),
 	}
 
 	// Initially all workers are available
@@ -79,6 +184,29 @@ func NewWorkerPool(maxWorkers int, queueSize int, server *Server) *WorkerPool {
 	return pool
 }
 
+// SetReservations reserves workers for languages (e.g., 2 for Go), so jobs of other languages never
+// occupy them while they are unused. The reservations must leave at least one worker unreserved.
+// It must be called before Start.
+func (wp *WorkerPool) SetReservations(reservations map[models.Language]int) error {
+	total := 0
+	normalized := make(map[models.Language]int, len(reservations))
+	for language, slots := range reservations {
+		if slots < 0 {
+			return fmt.Errorf("%w: %d workers for %s", ErrInvalidReservations, slots, language)
+		}
+		normalized[language.Normalize()] += slots
+		total += slots
+	}
+	if total > 0 && total >= wp.maxWorkers {
+		return fmt.Errorf("%w: %d reserved workers leave none of %d for other languages", ErrInvalidReservations, total, wp.maxWorkers)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	wp.reservations = normalized
+	return nil
+}
+
 // Start starts all workers in the pool.
 func (wp *WorkerPool) Start() {
 	log.Printf("Starting worker pool with %d workers (queue size: %d)", wp.maxWorkers, cap(wp.jobQueue))
@@ -93,94 +221,428 @@ func (wp *WorkerPool) Start() {
 func (wp *WorkerPool) Stop() {
 	log.Println("Stopping worker pool...")
 	wp.cancel()
-	close(wp.jobQueue)
+
+	wp.mu.Lock()
+	if !wp.stopped {
+		wp.stopped = true
+		close(wp.jobQueue)
+	}
+	wp.mu.Unlock()
+
 	wp.wg.Wait()
 	log.Println("Worker pool stopped")
 }
 
 // Submit submits a job to the worker pool.
-// Returns true if the job was queued, false if the queue is full.
-func (wp *WorkerPool) Submit(job models.CompilationJob) bool {
+// Returns ErrQueueFull if the queue is full, or ErrPoolStopped once the pool is stopping.
+func (wp *WorkerPool) Submit(job models.CompilationJob) error {
+	// Held across the send, so a worker receiving the job waits until it is listed as queued
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.stopped {
+		wp.totalRejected.Add(1)
+		return ErrPoolStopped
+	}
+
+	// Deferred jobs left the channel but still count against the queue size
+	if len(wp.jobQueue)+len(wp.deferred) >= cap(wp.jobQueue) {
+		wp.totalRejected.Add(1)
+		return ErrQueueFull
+	}
+
 	select {
 	case wp.jobQueue <- job:
-		return true
+		wp.queued = append(wp.queued, job.ID)
+		return nil
 	default:
-		// Queue is full
-		return false
+		wp.totalRejected.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// RecordRejection counts a submission that was turned away before reaching the queue
+// (e.g., no workers available).
+func (wp *WorkerPool) RecordRejection() {
+	wp.totalRejected.Add(1)
+}
+
+// recordDuration folds a finished job's compile duration into the moving average.
+func (wp *WorkerPool) recordDuration(d time.Duration) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.avgCompile == 0 {
+		wp.avgCompile = d
+		return
+	}
+	wp.avgCompile += time.Duration(compileAverageWeight * float64(d-wp.avgCompile))
+}
+
+// EstimateWait returns how long a job submitted now is expected to wait for a worker: the jobs
+// ahead of it that cannot start right away, spread over the workers, at the average compile duration.
+// It is a polling hint, not a promise; it ignores how far the running jobs have got.
+func (wp *WorkerPool) EstimateWait() time.Duration {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.maxWorkers <= 0 {
+		return 0
 	}
+	ahead := len(wp.jobQueue) + len(wp.deferred) + len(wp.processing)
+	waiting := ahead - wp.maxWorkers + 1
+	if waiting <= 0 {
+		return 0
+	}
+	avg := wp.avgCompile
+	if avg == 0 {
+		avg = defaultCompileEstimate
+	}
+	return avg * time.Duration(waiting) / time.Duration(wp.maxWorkers)
+}
+
+// QueueCapacity returns the maximum number of jobs that can wait in the queue.
+func (wp *WorkerPool) QueueCapacity() int {
+	return cap(wp.jobQueue)
 }
 
 // GetStats returns the current worker pool statistics.
 func (wp *WorkerPool) GetStats() WorkerStats {
+	wp.mu.Lock()
+	deferred := len(wp.deferred)
+	wp.mu.Unlock()
+
 	uptime := time.Since(wp.startTime)
 	return WorkerStats{
 		MaxWorkers:      wp.maxWorkers,
 		ActiveWorkers:   int(wp.activeWorkers.Load()),
 		AvailableSlots:  int(wp.availableSlots.Load()),
-		QueuedJobs:      len(wp.jobQueue),
+		QueuedJobs:      len(wp.jobQueue) + deferred,
 		TotalProcessed:  wp.totalProcessed.Load(),
 		TotalSuccessful: wp.totalSuccessful.Load(),
 		TotalFailed:     wp.totalFailed.Load(),
 		TotalTimeout:    wp.totalTimeout.Load(),
 		TotalErrors:     wp.totalErrors.Load(),
+		TotalRejected:   wp.totalRejected.Load(),
 		Uptime:          formatUptime(uptime),
 		UptimeSeconds:   int64(uptime.Seconds()),
 		StartTime:       wp.startTime,
 	}
 }
 
-// worker is the main worker loop that processes jobs from the queue.
-func (wp *WorkerPool) worker(id int) {
-	defer wp.wg.Done()
+// Capacity returns the pool's configured number of workers, queue size and worker reservations.
+func (wp *WorkerPool) Capacity() PoolCapacity {
+	wp.mu.Lock()
+	reservations := maps.Clone(wp.reservations)
+	wp.mu.Unlock()
 
-	log.Printf("Worker %d started", id)
+	maps.DeleteFunc(reservations, func(_ models.Language, slots int) bool { return slots == 0 })
+	return PoolCapacity{
+		MaxWorkers:         wp.maxWorkers,
+		QueueSize:          cap(wp.jobQueue),
+		WorkerReservations: reservations,
+	}
+}
+
+// Snapshot returns the queued jobs in order and the jobs being processed, taken under one lock.
+func (wp *WorkerPool) Snapshot() QueueSnapshot {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	snapshot := QueueSnapshot{
+		Queued:     make([]QueuedJob, 0, len(wp.queued)),
+		Processing: make([]ProcessingJob, 0, len(wp.processing)),
+		Time:       time.Now(),
+	}
+	for i, jobID := range wp.queued {
+		snapshot.Queued = append(snapshot.Queued, QueuedJob{JobID: jobID, Position: i + 1})
+	}
+	for jobID, startedAt := range wp.processing {
+		snapshot.Processing = append(snapshot.Processing, ProcessingJob{JobID: jobID, StartedAt: startedAt})
+	}
+	slices.SortFunc(snapshot.Processing, func(a, b ProcessingJob) int {
+		if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.JobID, b.JobID)
+	})
+
+	return snapshot
+}
+
+// next returns the job a worker processes next: the oldest deferred job that now fits the reservations,
+// otherwise the next job from the queue that does, deferring the ones that don't. It returns false once
+// the pool is stopping.
+func (wp *WorkerPool) next(id int) (models.CompilationJob, bool) {
+	if job, ok := wp.startDeferred(); ok {
+		return job, true
+	}
 
 	for {
 		select {
 		case <-wp.ctx.Done():
 			log.Printf("Worker %d stopping", id)
-			return
+			return models.CompilationJob{}, false
 
 		case job, ok := <-wp.jobQueue:
 			if !ok {
 				// Channel closed, exit
 				log.Printf("Worker %d: job queue closed", id)
-				return
+				return models.CompilationJob{}, false
+			}
+			started, deferred := wp.tryStart(job)
+			if started {
+				return job, true
+			}
+			if deferred {
+				log.Printf("Worker %d: deferring job %s, the free workers are reserved for other languages", id, job.ID)
 			}
+		}
+	}
+}
+
+// startDeferred starts the oldest deferred job that fits the reservations, if any.
+func (wp *WorkerPool) startDeferred() (models.CompilationJob, bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	for i, job := range wp.deferred {
+		if wp.fits(job) {
+			wp.deferred = slices.Delete(wp.deferred, i, i+1)
+			wp.markProcessing(job)
+			return job, true
+		}
+	}
+	return models.CompilationJob{}, false
+}
+
+// tryStart starts a job received from the queue, or defers it if it doesn't fit the reservations.
+// A job cancelled while in the queue is dropped, neither started nor deferred.
+func (wp *WorkerPool) tryStart(job models.CompilationJob) (started, deferred bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if _, cancelled := wp.cancelled[job.ID]; cancelled {
+		delete(wp.cancelled, job.ID)
+		return false, false
+	}
+	if !wp.fits(job) {
+		wp.deferred = append(wp.deferred, job)
+		return false, true
+	}
+	wp.markProcessing(job)
+	return true, false
+}
+
+// fits reports whether starting a job leaves enough free workers for the unused reservations of the
+// other languages. Must be called with wp.mu held.
+func (wp *WorkerPool) fits(job models.CompilationJob) bool {
+	language := job.Request.Language.Normalize()
+	free := wp.maxWorkers - len(wp.processing) - 1
+	for reserved, slots := range wp.reservations {
+		if reserved != language {
+			free -= max(0, slots-wp.runningByLanguage[reserved])
+		}
+	}
+	return free >= 0
+}
+
+// markProcessing moves a job being started from the queued list to the processing set.
+// Must be called with wp.mu held.
+func (wp *WorkerPool) markProcessing(job models.CompilationJob) {
+	if i := slices.Index(wp.queued, job.ID); i >= 0 {
+		wp.queued = slices.Delete(wp.queued, i, i+1)
+	}
+	wp.processing[job.ID] = time.Now()
+	wp.runningByLanguage[job.Request.Language.Normalize()]++
+
+	// Not derived from the pool's context: stopping the pool lets running compiles finish
+	ctx, cancel := context.WithCancel(context.Background())
+	wp.jobContexts[job.ID] = jobContext{ctx: ctx, cancel: cancel}
+
+	wp.publish(job.ID, JobProgress{Status: models.StatusProcessing})
+}
+
+// jobContext returns the compile context of a job being processed.
+func (wp *WorkerPool) jobContext(jobID string) context.Context {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if jc, ok := wp.jobContexts[jobID]; ok {
+		return jc.ctx
+	}
+	return context.Background()
+}
+
+// markDone removes a finished job from the processing set and wakes up anyone awaiting it.
+func (wp *WorkerPool) markDone(job models.CompilationJob) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	delete(wp.processing, job.ID)
+	wp.runningByLanguage[job.Request.Language.Normalize()]--
+
+	if jc, ok := wp.jobContexts[job.ID]; ok {
+		jc.cancel()
+		delete(wp.jobContexts, job.ID)
+	}
+	wp.wake(job.ID)
+}
+
+// wake closes the channels of anyone awaiting or following a job. Must be called with wp.mu held.
+func (wp *WorkerPool) wake(jobID string) {
+	if done, ok := wp.waiters[jobID]; ok {
+		close(done)
+		delete(wp.waiters, jobID)
+	}
+	for _, progress := range wp.subscribers[jobID] {
+		close(progress)
+	}
+	delete(wp.subscribers, jobID)
+}
+
+// publish sends a progress event to the subscribers of a job, dropping it for those that have fallen
+// behind. Must be called with wp.mu held.
+func (wp *WorkerPool) publish(jobID string, event JobProgress) {
+	for _, progress := range wp.subscribers[jobID] {
+		select {
+		case progress <- event:
+		default:
+		}
+	}
+}
+
+// publishOutput sends a chunk of a job's compile output to its subscribers.
+func (wp *WorkerPool) publishOutput(jobID, stream, chunk string) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	wp.publish(jobID, JobProgress{Stream: stream, Output: chunk})
+}
+
+// Cancel cancels a job of this pool. A job being processed has its compile context cancelled and is
+// stored as cancelled once the compile returns; a queued job is dropped and stored as cancelled right
+// away. It returns false if the job is neither queued nor processing here (e.g., it already finished,
+// or another instance has it).
+func (wp *WorkerPool) Cancel(jobID string) bool {
+	wp.mu.Lock()
+	if jc, ok := wp.jobContexts[jobID]; ok {
+		jc.cancel()
+		wp.mu.Unlock()
+		return true
+	}
+
+	i := slices.Index(wp.queued, jobID)
+	if i < 0 {
+		wp.mu.Unlock()
+		return false
+	}
+	wp.queued = slices.Delete(wp.queued, i, i+1)
+	if d := slices.IndexFunc(wp.deferred, func(job models.CompilationJob) bool { return job.ID == jobID }); d >= 0 {
+		wp.deferred = slices.Delete(wp.deferred, d, d+1)
+	} else {
+		wp.cancelled[jobID] = struct{}{} // Still in the channel
+	}
+	wp.mu.Unlock()
+
+	if job, exists := wp.server.jobs.Get(jobID); exists {
+		wp.server.storeCancelled(job, 0)
+	}
+
+	wp.mu.Lock()
+	wp.wake(jobID)
+	wp.mu.Unlock()
+	return true
+}
+
+// Await returns a channel that is closed once the job has been processed and its result stored, and a
+// function to stop waiting. A job that finished before Await was called never closes the channel, so
+// callers check the job store after calling it. One caller may await a job at a time.
+func (wp *WorkerPool) Await(jobID string) (<-chan struct{}, func()) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	done := make(chan struct{})
+	wp.waiters[jobID] = done
+	return done, func() {
+		wp.mu.Lock()
+		defer wp.mu.Unlock()
+		if wp.waiters[jobID] == done {
+			delete(wp.waiters, jobID)
+		}
+	}
+}
+
+// Subscribe returns a channel of the progress of a job, closed once the job has been processed and its
+// result stored, and a function to unsubscribe. Events are dropped while the channel is full, so a slow
+// reader misses output but never the close. Like Await, a job that finished before Subscribe was called
+// never closes the channel, so callers check the job store after calling it.
+func (wp *WorkerPool) Subscribe(jobID string) (<-chan JobProgress, func()) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
 
-			// Mark worker as active
-			wp.activeWorkers.Add(1)
-			wp.availableSlots.Add(-1)
-
-			log.Printf("Worker %d: processing job %s", id, job.ID)
-
-			// Process the job
-			wp.server.processJob(job)
-
-			// Update stats
-			wp.totalProcessed.Add(1)
-
-			// Check final job status and update appropriate counter
-			finalJob, exists := wp.server.jobs.Get(job.ID)
-			if exists {
-				switch finalJob.Status {
-				case models.StatusCompleted:
-					wp.totalSuccessful.Add(1)
-				case models.StatusFailed:
-					wp.totalFailed.Add(1)
-				case models.StatusTimeout:
-					wp.totalTimeout.Add(1)
-				case models.StatusError:
-					wp.totalErrors.Add(1)
-				}
+	progress := make(chan JobProgress, progressBuffer)
+	wp.subscribers[jobID] = append(wp.subscribers[jobID], progress)
+	return progress, func() {
+		wp.mu.Lock()
+		defer wp.mu.Unlock()
+		subscribers := wp.subscribers[jobID]
+		if i := slices.Index(subscribers, progress); i >= 0 {
+			subscribers = slices.Delete(subscribers, i, i+1)
+			if len(subscribers) == 0 {
+				delete(wp.subscribers, jobID)
+			} else {
+				wp.subscribers[jobID] = subscribers
 			}
+		}
+	}
+}
+
+// worker is the main worker loop that processes jobs from the queue.
+func (wp *WorkerPool) worker(id int) {
+	defer wp.wg.Done()
 
-			// Mark worker as available
-			wp.activeWorkers.Add(-1)
-			wp.availableSlots.Add(1)
+	log.Printf("Worker %d started", id)
 
-			log.Printf("Worker %d: finished job %s", id, job.ID)
+	for {
+		job, ok := wp.next(id)
+		if !ok {
+			return
 		}
+
+		// Mark worker as active
+		wp.activeWorkers.Add(1)
+		wp.availableSlots.Add(-1)
+
+		log.Printf("Worker %d: processing job %s", id, job.ID)
+
+		// Process the job
+		started := time.Now()
+		wp.server.processJob(wp.jobContext(job.ID), job)
+		wp.recordDuration(time.Since(started))
+		wp.markDone(job)
+
+		// Update stats
+		wp.totalProcessed.Add(1)
+
+		// Check final job status and update appropriate counter
+		finalJob, exists := wp.server.jobs.Get(job.ID)
+		if exists {
+			switch finalJob.Status {
+			case models.StatusCompleted:
+				wp.totalSuccessful.Add(1)
+			case models.StatusFailed:
+				wp.totalFailed.Add(1)
+			case models.StatusTimeout:
+				wp.totalTimeout.Add(1)
+			case models.StatusError:
+				wp.totalErrors.Add(1)
+			}
+		}
+
+		// Mark worker as available
+		wp.activeWorkers.Add(-1)
+		wp.availableSlots.Add(1)
+
+		log.Printf("Worker %d: finished job %s", id, job.ID)
 	}
 }
 
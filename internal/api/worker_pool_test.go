@@ -3,6 +3,12 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"testing/synctest"
 	"time"
@@ -70,8 +76,7 @@ func TestWorkerPool_JobProcessing(t *testing.T) {
 
 		// Store and submit job
 		server.jobs.Store(job)
-		submitted := pool.Submit(job)
-		require.True(t, submitted, "Job should be submitted successfully")
+		require.NoError(t, pool.Submit(job), "Job should be submitted successfully")
 
 		// Wait for job to be processed (instant with virtualized time)
 		time.Sleep(200 * time.Millisecond)
@@ -123,8 +128,7 @@ func TestWorkerPool_ConcurrentJobs(t *testing.T) {
 				},
 			}
 			server.jobs.Store(job)
-			submitted := pool.Submit(job)
-			require.True(t, submitted, "Job %d should be submitted", i)
+			require.NoError(t, pool.Submit(job), "Job %d should be submitted", i)
 		}
 
 		// Wait for all jobs to complete (instant with virtualized time)
@@ -175,13 +179,73 @@ func TestWorkerPool_QueueFull(t *testing.T) {
 		server.jobs.Store(job4)
 
 		// First three should be accepted (1 processing, 2 in queue)
-		assert.True(t, pool.Submit(job1), "First job should be accepted")
+		assert.NoError(t, pool.Submit(job1), "First job should be accepted")
 		time.Sleep(50 * time.Millisecond) // Let first job start processing (instant with virtualized time)
-		assert.True(t, pool.Submit(job2), "Second job should be accepted")
-		assert.True(t, pool.Submit(job3), "Third job should be accepted")
+		assert.NoError(t, pool.Submit(job2), "Second job should be accepted")
+		assert.NoError(t, pool.Submit(job3), "Third job should be accepted")
 
 		// Fourth should be rejected (queue full: 1 processing + 2 queued = capacity reached)
-		assert.False(t, pool.Submit(job4), "Fourth job should be rejected (queue full)")
+		assert.ErrorIs(t, pool.Submit(job4), ErrQueueFull, "Fourth job should be rejected (queue full)")
+	})
+}
+
+func TestWorkerPool_TotalRejected(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		// Create mock compiler with long delay so the queue stays full
+		mockComp := &mockCompiler{
+			compileDelay: 1 * time.Second,
+			shouldFail:   false,
+		}
+
+		server := &Server{
+			compiler: mockComp,
+			jobs:     newJobStore(),
+		}
+
+		// 1 worker + queue size 1 = 2 jobs of capacity
+		pool := NewWorkerPool(1, 1, server)
+		pool.Start()
+		defer pool.Stop()
+
+		newJob := func(id string) models.CompilationJob {
+			job := models.CompilationJob{ID: id, Status: models.StatusQueued, CreatedAt: time.Now(), Request: models.CompilationRequest{Code: "test", Language: models.LanguageCpp}}
+			server.jobs.Store(job)
+			return job
+		}
+
+		require.NoError(t, pool.Submit(newJob("job1")), "First job should be accepted")
+		synctest.Wait() // Let the worker pick up the first job
+		require.NoError(t, pool.Submit(newJob("job2")), "Second job should be queued")
+		assert.Equal(t, int64(0), pool.GetStats().TotalRejected, "Nothing rejected yet")
+
+		// Overflow the queue
+		assert.ErrorIs(t, pool.Submit(newJob("job3")), ErrQueueFull)
+		assert.ErrorIs(t, pool.Submit(newJob("job4")), ErrQueueFull)
+		assert.Equal(t, int64(2), pool.GetStats().TotalRejected, "Both overflow submissions should be counted")
+
+		// Rejections recorded outside Submit (no workers available) are counted too
+		pool.RecordRejection()
+		assert.Equal(t, int64(3), pool.GetStats().TotalRejected)
+	})
+}
+
+func TestWorkerPool_SubmitAfterStop(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &mockCompiler{},
+			jobs:     newJobStore(),
+		}
+
+		pool := NewWorkerPool(1, 1, server)
+		pool.Start()
+		pool.Stop()
+
+		job := models.CompilationJob{ID: "job1", Status: models.StatusQueued, CreatedAt: time.Now(), Request: models.CompilationRequest{Code: "test", Language: models.LanguageCpp}}
+		assert.ErrorIs(t, pool.Submit(job), ErrPoolStopped, "A stopped pool must reject jobs instead of panicking")
+		assert.Equal(t, int64(1), pool.GetStats().TotalRejected)
+
+		// Stopping twice is harmless
+		pool.Stop()
 	})
 }
 
@@ -209,3 +273,286 @@ func TestWorkerPool_Uptime(t *testing.T) {
 	assert.True(t, stats.UptimeSeconds >= 0, "Uptime seconds should be non-negative")
 	assert.True(t, stats.StartTime.After(startTime.Add(-1*time.Second)), "Start time should be recent")
 }
+
+// TestHandleGetInfo verifies that the reported capacity is that of the configured pool.
+func TestHandleGetInfo(t *testing.T) {
+	server := &Server{jobs: newJobStore()}
+	server.workerPool = NewWorkerPool(4, 25, server)
+	require.NoError(t, server.workerPool.SetReservations(map[models.Language]int{models.LanguageGo: 2, models.LanguageC: 0}))
+
+	rec := httptest.NewRecorder()
+	NewEchoServer(server, false).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/info", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var info ServerInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, PoolCapacity{
+		MaxWorkers:         4,
+		QueueSize:          25,
+		WorkerReservations: map[models.Language]int{models.LanguageGo: 2},
+	}, info.Capacity)
+}
+
+func TestWorkerPool_QueueSnapshot(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		// Slow workers keep the queue populated
+		server := &Server{
+			compiler:   &mockCompiler{compileDelay: 1 * time.Second},
+			jobs:       newJobStore(),
+			adminToken: "secret",
+		}
+		pool := NewWorkerPool(2, 10, server)
+		server.workerPool = pool
+		pool.Start()
+		defer pool.Stop()
+
+		e := NewEchoServer(server, false)
+
+		submit := func(id string) {
+			job := models.CompilationJob{ID: id, Status: models.StatusQueued, CreatedAt: time.Now(), Request: models.CompilationRequest{Code: "test", Language: models.LanguageCpp}}
+			server.jobs.Store(job)
+			require.NoError(t, pool.Submit(job))
+		}
+
+		getQueue := func(authorization string) (*httptest.ResponseRecorder, QueueSnapshot) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/queue", nil)
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			var snapshot QueueSnapshot
+			if rec.Code == http.StatusOK {
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+			}
+			return rec, snapshot
+		}
+
+		submit("job1")
+		submit("job2")
+		synctest.Wait() // Both workers pick up a job
+		submit("job3")
+		submit("job4")
+		submit("job5")
+
+		// Requires the admin token
+		rec, _ := getQueue("")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec, _ = getQueue("Bearer wrong")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec, snapshot := getQueue("Bearer secret")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []QueuedJob{
+			{JobID: "job3", Position: 1},
+			{JobID: "job4", Position: 2},
+			{JobID: "job5", Position: 3},
+		}, snapshot.Queued)
+		require.Len(t, snapshot.Processing, 2)
+		assert.Equal(t, "job1", snapshot.Processing[0].JobID)
+		assert.Equal(t, "job2", snapshot.Processing[1].JobID)
+
+		// The first jobs finish and the queue moves up
+		time.Sleep(1 * time.Second)
+		synctest.Wait()
+
+		_, snapshot = getQueue("Bearer secret")
+		assert.Equal(t, []QueuedJob{{JobID: "job5", Position: 1}}, snapshot.Queued)
+		require.Len(t, snapshot.Processing, 2)
+		assert.ElementsMatch(t, []string{"job3", "job4"},
+			[]string{snapshot.Processing[0].JobID, snapshot.Processing[1].JobID})
+
+		// Without a token the endpoint does not exist
+		server.adminToken = ""
+		rec = httptest.NewRecorder()
+		NewEchoServer(server, false).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/queue", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// languageDelayCompiler takes a per-language time to compile, to mix slow and fast builds.
+type languageDelayCompiler struct {
+	mockCompiler
+	delays map[models.Language]time.Duration
+}
+
+func (m *languageDelayCompiler) Compile(ctx context.Context, job models.CompilationJob) models.CompilationResult {
+	time.Sleep(m.delays[job.Request.Language])
+	return models.CompilationResult{Success: true, Compiled: true}
+}
+
+func TestWorkerPool_Reservations(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &languageDelayCompiler{delays: map[models.Language]time.Duration{
+				models.LanguageRust: 10 * time.Second,
+				models.LanguageGo:   100 * time.Millisecond,
+			}},
+			jobs: newJobStore(),
+		}
+		pool := NewWorkerPool(4, 20, server)
+		require.NoError(t, pool.SetReservations(map[models.Language]int{models.LanguageGo: 1}))
+		pool.Start()
+		defer pool.Stop()
+
+		submit := func(id string, language models.Language) {
+			job := models.CompilationJob{ID: id, Status: models.StatusQueued, CreatedAt: time.Now(), Request: models.CompilationRequest{Code: "test", Language: language}}
+			server.jobs.Store(job)
+			require.NoError(t, pool.Submit(job))
+		}
+
+		// A flood of slow Rust builds takes every worker but the reserved one
+		for i := range 10 {
+			submit(fmt.Sprintf("rust-%d", i), models.LanguageRust)
+		}
+		synctest.Wait()
+
+		stats := pool.GetStats()
+		assert.Equal(t, 3, stats.ActiveWorkers, "The Go worker stays free")
+		assert.Equal(t, 7, stats.QueuedJobs, "Deferred jobs are still queued")
+
+		// A Go check is served right away instead of waiting 30s behind the Rust builds
+		submit("go-0", models.LanguageGo)
+		time.Sleep(200 * time.Millisecond)
+		synctest.Wait()
+
+		job, found := server.jobs.Get("go-0")
+		require.True(t, found)
+		assert.Equal(t, models.StatusCompleted, job.Status)
+		assert.Equal(t, int64(1), pool.GetStats().TotalProcessed, "No Rust build has finished yet")
+		assert.Equal(t, 3, pool.GetStats().ActiveWorkers, "Rust does not take the freed Go worker")
+
+		// The Rust builds still all run, three at a time and in order
+		time.Sleep(10 * time.Second)
+		synctest.Wait()
+		snapshot := pool.Snapshot()
+		require.Len(t, snapshot.Processing, 3)
+		assert.ElementsMatch(t, []string{"rust-3", "rust-4", "rust-5"},
+			[]string{snapshot.Processing[0].JobID, snapshot.Processing[1].JobID, snapshot.Processing[2].JobID})
+
+		time.Sleep(30 * time.Second)
+		synctest.Wait()
+		assert.Equal(t, int64(11), pool.GetStats().TotalProcessed)
+	})
+}
+
+func TestWorkerPool_ReservedLanguageUsesFreeWorkers(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &mockCompiler{compileDelay: 1 * time.Second},
+			jobs:     newJobStore(),
+		}
+		pool := NewWorkerPool(3, 10, server)
+		require.NoError(t, pool.SetReservations(map[models.Language]int{models.LanguageGo: 1}))
+		pool.Start()
+		defer pool.Stop()
+
+		for i := range 3 {
+			job := models.CompilationJob{ID: fmt.Sprintf("go-%d", i), Status: models.StatusQueued, CreatedAt: time.Now(), Request: models.CompilationRequest{Code: "test", Language: models.LanguageGo}}
+			server.jobs.Store(job)
+			require.NoError(t, pool.Submit(job))
+		}
+		synctest.Wait()
+
+		assert.Equal(t, 3, pool.GetStats().ActiveWorkers, "A reservation is a floor, not a cap")
+	})
+}
+
+func TestWorkerPool_SetReservations_Invalid(t *testing.T) {
+	pool := NewWorkerPool(3, 10, &Server{})
+
+	assert.ErrorIs(t, pool.SetReservations(map[models.Language]int{models.LanguageGo: 2, models.LanguageRust: 1}), ErrInvalidReservations,
+		"At least one worker must stay unreserved")
+	assert.ErrorIs(t, pool.SetReservations(map[models.Language]int{models.LanguageGo: -1}), ErrInvalidReservations)
+	assert.NoError(t, pool.SetReservations(map[models.Language]int{models.LanguageGo: 2}))
+}
+
+func TestWorkerPool_QueueWaitHistogram(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &mockCompiler{compileDelay: 2 * time.Second},
+			jobs:     newJobStore(),
+		}
+		pool := NewWorkerPool(1, 10, server)
+		server.workerPool = pool
+		pool.Start()
+		defer pool.Stop()
+
+		// With one worker, the jobs queue behind each other: they wait 0s, 2s and 4s
+		for i := range 3 {
+			job := models.CompilationJob{ID: fmt.Sprintf("job-%d", i), Status: models.StatusQueued, CreatedAt: time.Now(), Request: models.CompilationRequest{Code: "test", Language: models.LanguageCpp}}
+			server.jobs.Store(job)
+			require.NoError(t, pool.Submit(job))
+		}
+
+		time.Sleep(10 * time.Second)
+		synctest.Wait()
+		require.Equal(t, int64(3), pool.GetStats().TotalProcessed)
+
+		var b strings.Builder
+		pool.queueWait.write(&b, "queue_wait_seconds", "Queue wait.")
+		metrics := b.String()
+
+		assert.Contains(t, metrics, "# TYPE queue_wait_seconds histogram\n")
+		assert.Contains(t, metrics, "queue_wait_seconds_bucket{le=\"1\"} 1\n", "Only the first job started right away")
+		assert.Contains(t, metrics, "queue_wait_seconds_bucket{le=\"2\"} 2\n")
+		assert.Contains(t, metrics, "queue_wait_seconds_bucket{le=\"5\"} 3\n")
+		assert.Contains(t, metrics, "queue_wait_seconds_bucket{le=\"+Inf\"} 3\n")
+		assert.Contains(t, metrics, "queue_wait_seconds_sum 6\n")
+		assert.Contains(t, metrics, "queue_wait_seconds_count 3\n")
+	})
+}
+
+func TestWorkerPool_EstimateWait(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		server := &Server{
+			compiler: &mockCompiler{compileDelay: 3 * time.Second},
+			jobs:     newJobStore(),
+		}
+		pool := NewWorkerPool(2, 10, server)
+		server.workerPool = pool
+		pool.Start()
+		defer pool.Stop()
+
+		submit := func(i int) {
+			job := models.CompilationJob{ID: fmt.Sprintf("job-%d", i), Status: models.StatusQueued, CreatedAt: time.Now(), Request: models.CompilationRequest{Code: "test", Language: models.LanguageCpp}}
+			server.jobs.Store(job)
+			require.NoError(t, pool.Submit(job))
+			synctest.Wait()
+		}
+
+		assert.Zero(t, pool.EstimateWait(), "An idle pool has a free worker")
+
+		// The estimate grows with the queue; before any compile finishes it assumes the default duration
+		submit(0)
+		assert.Zero(t, pool.EstimateWait(), "One worker is still free")
+		var estimates []time.Duration
+		for i := 1; i <= 5; i++ {
+			submit(i)
+			estimates = append(estimates, pool.EstimateWait())
+		}
+		assert.Equal(t, []time.Duration{
+			defaultCompileEstimate / 2,
+			2 * defaultCompileEstimate / 2,
+			3 * defaultCompileEstimate / 2,
+			4 * defaultCompileEstimate / 2,
+			5 * defaultCompileEstimate / 2,
+		}, estimates)
+
+		// Once the first two jobs finish, the average is the measured 3s and four jobs are left
+		time.Sleep(3 * time.Second)
+		synctest.Wait()
+		assert.Equal(t, 3*3*time.Second/2, pool.EstimateWait())
+
+		// The estimate shrinks as the pool drains
+		time.Sleep(3 * time.Second)
+		synctest.Wait()
+		assert.Equal(t, 3*time.Second/2, pool.EstimateWait())
+
+		time.Sleep(3 * time.Second)
+		synctest.Wait()
+		assert.Zero(t, pool.EstimateWait())
+		assert.Equal(t, int64(6), pool.GetStats().TotalProcessed)
+	})
+}
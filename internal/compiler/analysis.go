@@ -0,0 +1,48 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+)
+
+// buildAnalyzeCommand builds the static analysis command for the environment's analyzer.
+// Both analyzers are made to print GCC-style "file:line:col: severity: message" lines.
+func buildAnalyzeCommand(env models.EnvironmentSpec, sources ...string) string {
+	sourceFilename := strings.Join(sources, " /workspace/")
+
+	switch env.Analyzer {
+	case models.AnalyzerClangTidy:
+		// "--" tells clang-tidy there is no compilation database
+		return fmt.Sprintf("clang-tidy /workspace/%s -- -std=%s", sourceFilename, env.Standard)
+
+	case models.AnalyzerCppcheck:
+		return fmt.Sprintf("cppcheck --quiet --enable=warning,style,performance,portability "+
+			"--template='{file}:{line}:{column}: warning: {message} [{id}]' /workspace/%s", sourceFilename)
+
+	default:
+		return ""
+	}
+}
+
+// analyze runs the environment's static analyzer in a separate container and parses its findings.
+// The compile config is reused without the run step or binary output.
+func (c *Compiler) analyze(ctx context.Context, config runtime.CompilationConfig, env models.EnvironmentSpec, sources []string) ([]models.Diagnostic, error) {
+	config.CompileCommand = buildAnalyzeCommand(env, sources...)
+	config.RunCommand = ""
+	config.OutputPath = ""
+
+	output, err := c.runtime.Compile(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if output.TimedOut {
+		return nil, ErrAnalysisTimeout
+	}
+
+	// clang-tidy reports on stdout, cppcheck on stderr
+	return parseTextDiagnostics(output.Stdout + "\n" + output.Stderr), nil
+}
@@ -0,0 +1,159 @@
+package compiler
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+const (
+	// maxArchiveBytes caps the total extracted size of an archive (same as the source code limit).
+	maxArchiveBytes = 1 * 1024 * 1024
+
+	// maxArchiveFiles caps the number of files in an archive.
+	maxArchiveFiles = 100
+)
+
+// archivePathPattern restricts archive paths to characters that are safe to splice into a shell command.
+var archivePathPattern = regexp.MustCompile(`^[A-Za-z0-9._+/-]+$`)
+
+// extractArchive reads a tar or tar.gz archive into memory, keyed by cleaned relative path.
+// Only regular files and directories are accepted; paths escaping the workspace,
+// links, and archives over maxArchiveBytes or maxArchiveFiles are rejected.
+func extractArchive(data []byte) (map[string]string, error) {
+	var reader io.Reader = bytes.NewReader(data)
+
+	// gzip magic number
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		defer gz.Close() //nolint:errcheck // read-only
+		reader = gz
+	}
+
+	files := make(map[string]string)
+	remaining := int64(maxArchiveBytes)
+	tr := tar.NewReader(reader)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("%w: %s is not a regular file", ErrArchiveUnsafePath, header.Name)
+		}
+
+		name, err := cleanArchivePath(header.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(files) >= maxArchiveFiles {
+			return nil, fmt.Errorf("%w (max %d)", ErrArchiveTooManyFiles, maxArchiveFiles)
+		}
+
+		// Read at most one byte past the budget, so a decompression bomb is never fully inflated
+		content, err := io.ReadAll(io.LimitReader(tr, remaining+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		remaining -= int64(len(content))
+		if remaining < 0 {
+			return nil, fmt.Errorf("%w (max %d bytes extracted)", ErrArchiveTooLarge, maxArchiveBytes)
+		}
+
+		files[name] = string(content)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: archive contains no files", ErrInvalidArchive)
+	}
+
+	return files, nil
+}
+
+// cleanArchivePath normalizes an archive entry name and rejects paths outside the workspace.
+func cleanArchivePath(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %s", ErrArchiveUnsafePath, name)
+	}
+	if !archivePathPattern.MatchString(cleaned) {
+		return "", fmt.Errorf("%w: %s contains unsupported characters", ErrArchiveUnsafePath, name)
+	}
+	return cleaned, nil
+}
+
+// archiveSources returns the files of an archive that are passed to the compiler, in a stable order.
+// C, C++, Objective-C and Go compile every source file; Rust compiles the crate root, which pulls in its modules.
+func archiveSources(language models.Language, files map[string]string) ([]string, error) {
+	var sources []string
+
+	for name := range files {
+		ext := path.Ext(name)
+		switch language {
+		case models.LanguageC:
+			if ext == ".c" {
+				sources = append(sources, name)
+			}
+		case models.LanguageCpp:
+			if ext == ".cpp" || ext == ".cc" || ext == ".cxx" {
+				sources = append(sources, name)
+			}
+		case models.LanguageGo:
+			if ext == ".go" && !strings.HasSuffix(name, "_test.go") {
+				sources = append(sources, name)
+			}
+		case models.LanguageObjC:
+			if ext == ".m" || ext == ".c" {
+				sources = append(sources, name)
+			}
+		case models.LanguageObjCpp:
+			if ext == ".mm" || ext == ".m" || ext == ".cpp" {
+				sources = append(sources, name)
+			}
+		case models.LanguageSwift:
+			if ext == ".swift" {
+				sources = append(sources, name)
+			}
+		case models.LanguageKotlin:
+			if ext == ".kt" {
+				sources = append(sources, name)
+			}
+		case models.LanguageRust:
+			if name == "main.rs" || name == "src/main.rs" {
+				sources = append(sources, name)
+			}
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrArchiveNoSources, language)
+	}
+
+	slices.Sort(sources)
+	if language == models.LanguageRust {
+		sources = sources[:1] // Prefer main.rs over src/main.rs
+	}
+	return sources, nil
+}
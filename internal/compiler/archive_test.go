@@ -0,0 +1,235 @@
+package compiler
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// archiveEntry is a single entry for buildTestArchive.
+type archiveEntry struct {
+	name     string
+	content  string
+	typeflag byte
+}
+
+// buildTestArchive builds a tar archive, gzip-compressed if requested.
+func buildTestArchive(t *testing.T, compress bool, entries ...archiveEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		typeflag := entry.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Typeflag: typeflag}
+		if typeflag == tar.TypeReg {
+			header.Size = int64(len(entry.content))
+		}
+		if typeflag == tar.TypeSymlink {
+			header.Linkname = entry.content
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		if typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(entry.content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+
+	if !compress {
+		return buf.Bytes()
+	}
+
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	_, err := gz.Write(buf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return gzBuf.Bytes()
+}
+
+// TestExtractArchive tests extraction of plain and gzip-compressed tarballs.
+func TestExtractArchive(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		data := buildTestArchive(t, compress,
+			archiveEntry{name: "./src/", typeflag: tar.TypeDir},
+			archiveEntry{name: "./src/main.cpp", content: `#include "util.h"`},
+			archiveEntry{name: "./src/util.h", content: "int util();"},
+		)
+
+		files, err := extractArchive(data)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"src/main.cpp": `#include "util.h"`,
+			"src/util.h":   "int util();",
+		}, files)
+	}
+}
+
+// TestExtractArchive_Rejected tests that malicious or oversized archives are rejected.
+func TestExtractArchive_Rejected(t *testing.T) {
+	tooMany := make([]archiveEntry, maxArchiveFiles+1)
+	for i := range tooMany {
+		tooMany[i] = archiveEntry{name: strings.Repeat("a", i+1) + ".c"}
+	}
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{
+			name:     "parent_traversal",
+			data:     buildTestArchive(t, false, archiveEntry{name: "../../etc/cron.d/evil", content: "x"}),
+			expected: ErrArchiveUnsafePath,
+		},
+		{
+			name:     "nested_traversal",
+			data:     buildTestArchive(t, true, archiveEntry{name: "src/../../evil.c", content: "x"}),
+			expected: ErrArchiveUnsafePath,
+		},
+		{
+			name:     "absolute_path",
+			data:     buildTestArchive(t, false, archiveEntry{name: "/etc/passwd", content: "x"}),
+			expected: ErrArchiveUnsafePath,
+		},
+		{
+			name:     "shell_metacharacters",
+			data:     buildTestArchive(t, false, archiveEntry{name: "a.c;reboot", content: "x"}),
+			expected: ErrArchiveUnsafePath,
+		},
+		{
+			name:     "symlink",
+			data:     buildTestArchive(t, false, archiveEntry{name: "link.c", content: "/etc/passwd", typeflag: tar.TypeSymlink}),
+			expected: ErrArchiveUnsafePath,
+		},
+		{
+			name:     "decompression_bomb",
+			data:     buildTestArchive(t, true, archiveEntry{name: "big.c", content: strings.Repeat("0", maxArchiveBytes+1)}),
+			expected: ErrArchiveTooLarge,
+		},
+		{
+			name:     "too_many_files",
+			data:     buildTestArchive(t, false, tooMany...),
+			expected: ErrArchiveTooManyFiles,
+		},
+		{
+			name:     "not_an_archive",
+			data:     []byte("int main() {}"),
+			expected: ErrInvalidArchive,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := extractArchive(tc.data)
+			assert.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+// TestArchiveSources tests selection of the files passed to the compiler.
+func TestArchiveSources(t *testing.T) {
+	files := map[string]string{
+		"main.cpp":     "",
+		"lib/util.cc":  "",
+		"lib/util.h":   "",
+		"main.go":      "",
+		"main_test.go": "",
+		"src/main.rs":  "",
+		"src/lib.rs":   "",
+	}
+
+	sources, err := archiveSources(models.LanguageCpp, files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lib/util.cc", "main.cpp"}, sources)
+
+	sources, err = archiveSources(models.LanguageGo, files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, sources)
+
+	sources, err = archiveSources(models.LanguageRust, files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src/main.rs"}, sources)
+
+	_, err = archiveSources(models.LanguageC, files)
+	assert.ErrorIs(t, err, ErrArchiveNoSources)
+}
+
+// TestCompile_Archive tests that an archive is passed to the runtime as workspace files.
+func TestCompile_Archive(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	archive := buildTestArchive(t, true,
+		archiveEntry{name: "main.c", content: `#include "util.h"` + "\nint main() { return util(); }"},
+		archiveEntry{name: "util.c", content: "int util() { return 0; }"},
+		archiveEntry{name: "util.h", content: "int util();"},
+	)
+
+	job := models.CompilationJob{
+		ID: "test-archive",
+		Request: models.CompilationRequest{
+			Archive:  base64.StdEncoding.EncodeToString(archive),
+			Language: models.LanguageC,
+			Compiler: models.CompilerGCC13,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Len(t, capturedConfig.Files, 3)
+	assert.Empty(t, capturedConfig.SourceCode)
+	assert.Equal(t, "gcc -std=c17 /workspace/main.c /workspace/util.c -o /workspace/output", capturedConfig.CompileCommand)
+}
+
+// TestCompile_ArchiveRejected tests that a malicious archive never reaches the runtime.
+func TestCompile_ArchiveRejected(t *testing.T) {
+	compileCalled := false
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			compileCalled = true
+			return &runtime.CompilationOutput{}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	archive := buildTestArchive(t, false, archiveEntry{name: "../main.c", content: "int main() {}"})
+
+	job := models.CompilationJob{
+		ID: "test-archive-traversal",
+		Request: models.CompilationRequest{
+			Archive:  base64.StdEncoding.EncodeToString(archive),
+			Language: models.LanguageC,
+			Compiler: models.CompilerGCC13,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error, "unsafe archive entry")
+	assert.False(t, compileCalled)
+}
@@ -0,0 +1,92 @@
+package compiler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+)
+
+// maxCachedResults bounds the number of results kept by resultCache.
+const maxCachedResults = 256
+
+// resultCache is a bounded in-memory cache of compilation results, keyed by the
+// image, compile command and request. When full, the oldest entry is evicted.
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResult
+	order   []string // Insertion order, oldest first
+}
+
+// cachedResult is a cached compilation result and when it was stored.
+type cachedResult struct {
+	result   models.CompilationResult
+	storedAt time.Time
+}
+
+// newResultCache creates an empty result cache.
+func newResultCache() *resultCache {
+	return &resultCache{entries: make(map[string]cachedResult)}
+}
+
+// get returns the cached result for key and how long ago it was stored.
+// A nil cache never hits.
+func (rc *resultCache) get(key string) (models.CompilationResult, time.Duration, bool) {
+	if rc == nil {
+		return models.CompilationResult{}, 0, false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return models.CompilationResult{}, 0, false
+	}
+	return entry.result, time.Since(entry.storedAt), true
+}
+
+// put stores a result, evicting the oldest entry if the cache is full.
+func (rc *resultCache) put(key string, result models.CompilationResult) {
+	if rc == nil {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, exists := rc.entries[key]; !exists {
+		if len(rc.order) >= maxCachedResults {
+			delete(rc.entries, rc.order[0])
+			rc.order = rc.order[1:]
+		}
+		rc.order = append(rc.order, key)
+	}
+	rc.entries[key] = cachedResult{result: result, storedAt: time.Now()}
+}
+
+// resultCacheKey identifies a compilation by everything that affects its outcome.
+// The image and command are included so a config reload never serves stale results,
+// and the workspace files so an edited gist is compiled again. The label only groups jobs, so it is left out.
+func resultCacheKey(config runtime.CompilationConfig, req models.CompilationRequest) string {
+	req.Label = ""
+	data, _ := json.Marshal(struct { //nolint:errcheck // plain struct, cannot fail
+		ImageTag       string
+		CompileCommand string
+		Request        models.CompilationRequest
+		Files          map[string]string
+	}{config.ImageTag, config.CompileCommand, req, config.Files})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// isCacheable reports whether a result may be served again for an identical request.
+// Run mode output can vary between executions, and errors may be transient.
+func isCacheable(req models.CompilationRequest, result models.CompilationResult) bool {
+	return !req.RunRequested() && result.Error == ""
+}
@@ -2,13 +2,20 @@ package compiler
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/stlpine/will-it-compile/internal/ansi"
 	internalruntime "github.com/stlpine/will-it-compile/internal/runtime"
 	"github.com/stlpine/will-it-compile/pkg/models"
 	"github.com/stlpine/will-it-compile/pkg/runtime"
@@ -20,19 +27,95 @@ var (
 	ErrSourceCodeTooLarge     = errors.New("source code too large (max 1MB)")
 	ErrUnsupportedLanguage    = errors.New("unsupported language")
 	ErrUnsupportedEnvironment = errors.New("unsupported environment")
+	ErrCompilerTooOld         = errors.New("compiler version is below the configured minimum")
+	ErrNoJSONDiagnostics      = errors.New("no JSON diagnostics in compiler output")
+	ErrAnalyzerUnavailable    = errors.New("environment has no static analyzer")
+	ErrFormatterUnavailable   = errors.New("environment has no formatter")
+	ErrGNUTimeUnavailable     = errors.New("environment has no /usr/bin/time")
+	ErrSymbolToolUnavailable  = errors.New("environment has no symbol tool")
+	ErrLibcUnavailable        = errors.New("environment has no image for the requested libc")
+	ErrToolchainUnavailable   = errors.New("environment has no such toolchain")
+	ErrAnalysisTimeout        = errors.New("static analysis timeout")
+	ErrDependenciesTimeout    = errors.New("dependency scan timeout")
+	ErrPreprocessTimeout      = errors.New("preprocessing timeout")
+	ErrSymbolsTimeout         = errors.New("symbol listing timeout")
+	ErrFormatCheckTimeout     = errors.New("format check timeout")
+	ErrInvalidArchive         = errors.New("invalid archive")
+	ErrArchiveTooLarge        = errors.New("archive too large")
+	ErrArchiveTooManyFiles    = errors.New("archive contains too many files")
+	ErrArchiveUnsafePath      = errors.New("unsafe archive entry")
+	ErrArchiveNoSources       = errors.New("archive contains no source files")
+)
+
+const (
+	// maxRunOutputSize is the upper bound for a client-requested run output limit (1MB, same as compile output).
+	maxRunOutputSize = 1 * 1024 * 1024
+
+	// binaryOutputPath is where every compile command writes its binary.
+	binaryOutputPath = "/workspace/output"
+
+	// jarOutputPath is where the Kotlin compile command writes its jar, which bundles the Kotlin runtime.
+	jarOutputPath = "/workspace/output.jar"
+
+	// objectOutputPath is where a compile-only command writes the object file of a single source.
+	objectOutputPath = "/workspace/output.o"
+
+	// noSpaceLeftMessage is strerror(ENOSPC), printed by compilers that cannot write intermediates.
+	noSpaceLeftMessage = "No space left on device"
+
+	// seccompKillExitCode is the exit status of a process killed by SIGSYS, i.e. a syscall denied by the seccomp profile.
+	seccompKillExitCode = 128 + 31
+
+	// defaultCompileTimeout applies when a request sets no timeout_seconds.
+	defaultCompileTimeout = 30 * time.Second
+
+	// defaultMaxCompileTimeout is the ceiling on request timeouts when MAX_COMPILE_TIMEOUT is unset.
+	defaultMaxCompileTimeout = 60 * time.Second
+
+	// quickTimeout bounds a quick-mode syntax check; editors want feedback well under the compile timeout.
+	quickTimeout = 5 * time.Second
+
+	// defaultTimeoutEnv is the variable compile scripts read their timeout from, unless the environment names another.
+	defaultTimeoutEnv = "COMPILE_TIMEOUT"
+
+	// compileTimeoutMargin is kept back from the timeout passed to compile scripts, so they can
+	// stop and report before the container is killed.
+	compileTimeoutMargin = 5 * time.Second
+
+	// zigImage is the image of the zig environments (there is no official zig image).
+	zigImage = "euantorano/zig:0.13.0"
 )
 
 // Compiler handles code compilation in isolated environments.
 type Compiler struct {
-	runtime      runtime.CompilationRuntime
+	runtime runtime.CompilationRuntime
+
+	// environments and minVersions can be swapped at runtime by ReloadConfig, so access goes through mu
+	mu           sync.RWMutex
 	environments map[string]models.EnvironmentSpec
+	minVersions  map[string]string // Oldest allowed version per compiler family (e.g., "gcc": "11")
+
+	cache *resultCache // Results of identical earlier requests
+
+	gists *gistClient // Resolves requests that reference a GitHub gist
+
+	tmpSize int64 // Size of the container's in-memory /tmp (runtime.DefaultTmpSize if zero)
+
+	idleTimeout time.Duration // Kill compiles silent for this long (disabled if zero)
+
+	maxTimeout time.Duration // Ceiling on the compile timeout a request may ask for (defaultMaxCompileTimeout if zero)
+
+	maxIncludes int // Cap on include and import statements per request (defaultMaxIncludes if zero)
+
+	pullsMu sync.Mutex
+	pulls   map[string]int // In-progress pulls per image tag
 }
 
-// NewCompiler creates a new compiler instance with auto-detected runtime
+// NewCompiler creates a new compiler instance with the runtime selected by RUNTIME (auto-detected by default)
 // It loads environment configuration from YAML, with hardcoded fallback.
 func NewCompiler() (*Compiler, error) {
-	// Auto-detect runtime (Docker or Kubernetes)
-	rt, err := internalruntime.NewRuntimeAuto("")
+	// Runtime from RUNTIME (docker or kubernetes), auto-detected if unset
+	rt, err := internalruntime.NewRuntime(internalruntime.RuntimeTypeFromEnv(), "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create runtime: %w", err)
 	}
@@ -40,26 +123,39 @@ func NewCompiler() (*Compiler, error) {
 	// Load environments from YAML configuration
 	config, err := LoadDefaultConfig()
 	var environments map[string]models.EnvironmentSpec
+	var minVersions map[string]string
 
 	if err != nil {
 		// Fallback to hardcoded configuration
 		fmt.Printf("Warning: Failed to load config from YAML (%v), using hardcoded configuration\n", err)
 		environments = getHardcodedEnvironments()
+		if err := checkImageAllowlist(environments, imageAllowlistFromEnv()); err != nil {
+			_ = rt.Close() //nolint:errcheck // already in error path
+			return nil, err
+		}
 	} else {
 		environments, err = config.ToEnvironmentSpecs()
 		if err != nil {
 			_ = rt.Close() //nolint:errcheck // already in error path
 			return nil, fmt.Errorf("failed to parse environment specs: %w", err)
 		}
+		minVersions = config.MinCompilerVersions
 	}
 
 	compiler := &Compiler{
 		runtime:      rt,
 		environments: environments,
+		minVersions:  minVersions,
+		cache:        newResultCache(),
+		gists:        newGistClient(),
+		tmpSize:      tmpSizeFromEnv(),
+		idleTimeout:  idleTimeoutFromEnv(),
+		maxTimeout:   maxCompileTimeoutFromEnv(),
+		maxIncludes:  maxIncludesFromEnv(),
 	}
 
 	// Verify required images exist at startup
-	if err := compiler.verifyImages(context.Background()); err != nil {
+	if err := compiler.verifyImages(context.Background(), environments); err != nil {
 		_ = rt.Close() //nolint:errcheck // already in error path
 		return nil, err
 	}
@@ -73,13 +169,100 @@ func NewCompilerWithRuntime(rt runtime.CompilationRuntime) *Compiler {
 	return &Compiler{
 		runtime:      rt,
 		environments: getHardcodedEnvironments(),
+		cache:        newResultCache(),
+		gists:        newGistClient(),
+	}
+}
+
+// tmpSizeFromEnv reads the size of the container's /tmp from TMPFS_SIZE_MB, or returns 0 for the default.
+func tmpSizeFromEnv() int64 {
+	mb, err := strconv.ParseInt(os.Getenv("TMPFS_SIZE_MB"), 10, 64)
+	if err != nil || mb <= 0 {
+		return 0
 	}
+	return mb * 1024 * 1024
+}
+
+// idleTimeoutFromEnv reads the compile idle window from COMPILE_IDLE_TIMEOUT_SECONDS, or returns 0 to disable it.
+func idleTimeoutFromEnv() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("COMPILE_IDLE_TIMEOUT_SECONDS"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// SetIdleTimeout sets how long a compilation may go without output before it is killed (0 = disabled).
+func (c *Compiler) SetIdleTimeout(timeout time.Duration) {
+	c.idleTimeout = timeout
+}
+
+// maxCompileTimeoutFromEnv reads the ceiling on request timeouts from MAX_COMPILE_TIMEOUT in seconds,
+// or returns 0 for the default.
+func maxCompileTimeoutFromEnv() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("MAX_COMPILE_TIMEOUT"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// SetMaxTimeout sets the ceiling on the compile timeout a request may ask for (0 = defaultMaxCompileTimeout).
+func (c *Compiler) SetMaxTimeout(timeout time.Duration) {
+	c.maxTimeout = timeout
+}
+
+// clampTimeout returns the compile timeout for a request in env: its timeout_seconds or the environment's
+// default, capped at the configured ceiling so no request can hold a worker longer. Without a configured
+// ceiling, an environment default above defaultMaxCompileTimeout raises the ceiling to it.
+func (c *Compiler) clampTimeout(req models.CompilationRequest, env models.EnvironmentSpec) time.Duration {
+	timeout := defaultCompileTimeout
+	if env.Timeout > 0 {
+		timeout = env.Timeout
+	}
+
+	ceiling := c.maxTimeout
+	if ceiling <= 0 {
+		ceiling = max(defaultMaxCompileTimeout, timeout)
+	}
+
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+	return min(timeout, ceiling)
+}
+
+// EffectiveTimeout returns the compile timeout a request actually runs with: the clamped timeout of the
+// environment it compiles in, shortened to quickTimeout in quick mode.
+func (c *Compiler) EffectiveTimeout(req models.CompilationRequest) time.Duration {
+	// A request without an environment fails before compiling; the server default bounds the wait for it
+	env, _ := c.selectEnvironment(req) //nolint:errcheck // the zero spec falls back to the default
+	return effectiveTimeout(req, c.clampTimeout(req, env))
+}
+
+// effectiveTimeout shortens a clamped timeout to quickTimeout in quick mode.
+func effectiveTimeout(req models.CompilationRequest, timeout time.Duration) time.Duration {
+	// Quick mode trades the full timeout for fast feedback
+	if req.Quick {
+		timeout = min(timeout, quickTimeout)
+	}
+	return timeout
+}
+
+// ResolveEnvironment returns the environment a request compiles in, as Compile selects it.
+func (c *Compiler) ResolveEnvironment(req models.CompilationRequest) (models.EnvironmentSpec, error) {
+	return c.selectEnvironment(req)
+}
+
+// SetTmpSize sets the size in bytes of the in-memory /tmp of compilation containers (0 = default).
+func (c *Compiler) SetTmpSize(size int64) {
+	c.tmpSize = size
 }
 
 // getHardcodedEnvironments returns the hardcoded fallback environment configuration
 // This is used when YAML config cannot be loaded, or for testing.
 func getHardcodedEnvironments() map[string]models.EnvironmentSpec {
-	return map[string]models.EnvironmentSpec{
+	environments := map[string]models.EnvironmentSpec{
 		"cpp-gcc-13": {
 			Language:     models.LanguageCpp,
 			Compiler:     models.CompilerGCC13,
@@ -88,6 +271,8 @@ func getHardcodedEnvironments() map[string]models.EnvironmentSpec {
 			Architecture: models.ArchX86_64,
 			OS:           models.OSLinux,
 			ImageTag:     "gcc:13",
+			SymbolTool:   models.SymbolToolNm,
+			Libc:         models.LibcGlibc,
 		},
 		"c-gcc-13": {
 			Language:     models.LanguageC,
@@ -97,6 +282,8 @@ func getHardcodedEnvironments() map[string]models.EnvironmentSpec {
 			Architecture: models.ArchX86_64,
 			OS:           models.OSLinux,
 			ImageTag:     "gcc:13",
+			SymbolTool:   models.SymbolToolNm,
+			Libc:         models.LibcGlibc,
 		},
 		"go-go-1.23": {
 			Language:     models.LanguageGo,
@@ -105,6 +292,9 @@ func getHardcodedEnvironments() map[string]models.EnvironmentSpec {
 			Architecture: models.ArchX86_64,
 			OS:           models.OSLinux,
 			ImageTag:     "golang:1.23-alpine",
+			Formatter:    models.FormatterGofmt,
+			SymbolTool:   models.SymbolToolGoNm,
+			Libc:         models.LibcMusl,
 		},
 		"rust-rustc-1.80": {
 			Language:     models.LanguageRust,
@@ -113,8 +303,90 @@ func getHardcodedEnvironments() map[string]models.EnvironmentSpec {
 			Architecture: models.ArchX86_64,
 			OS:           models.OSLinux,
 			ImageTag:     "rust:1.80-alpine",
+			Libc:         models.LibcMusl,
 		},
+		"cpp-clang-16": {
+			Language:     models.LanguageCpp,
+			Compiler:     models.CompilerClang16,
+			Version:      "16",
+			Standard:     models.StandardCpp20,
+			Architecture: models.ArchX86_64,
+			OS:           models.OSLinux,
+			ImageTag:     "silkeh/clang:16",
+			Libc:         models.LibcGlibc,
+		},
+		"c-clang-16": {
+			Language:     models.LanguageC,
+			Compiler:     models.CompilerClang16,
+			Version:      "16",
+			Standard:     models.StandardC17,
+			Architecture: models.ArchX86_64,
+			OS:           models.OSLinux,
+			ImageTag:     "silkeh/clang:16",
+			Libc:         models.LibcGlibc,
+		},
+		"cpp-zig-0.13": {
+			Language:     models.LanguageCpp,
+			Compiler:     models.CompilerZig,
+			Version:      "0.13",
+			Standard:     models.StandardCpp20,
+			Architecture: models.ArchX86_64,
+			OS:           models.OSLinux,
+			ImageTag:     zigImage,
+		},
+		"c-zig-0.13": {
+			Language:     models.LanguageC,
+			Compiler:     models.CompilerZig,
+			Version:      "0.13",
+			Standard:     models.StandardC17,
+			Architecture: models.ArchX86_64,
+			OS:           models.OSLinux,
+			ImageTag:     zigImage,
+		},
+	}
+
+	for key, spec := range environments {
+		spec.Capabilities = environmentCapabilities(spec)
+		environments[key] = spec
+	}
+	return environments
+}
+
+// environmentCapabilities derives the request options an environment supports.
+// It mirrors the checks in validateRequest, selectEnvironment and Compile.
+func environmentCapabilities(spec models.EnvironmentSpec) models.Capabilities {
+	return models.Capabilities{
+		Run:              programCommand(spec.Language) != "",
+		JSONDiagnostics:  emitsJSONDiagnostics(spec),
+		DiagnosticsWidth: spec.Language.IsCFamily(),
+		Linker:           spec.Language.SupportsLinker(),
+		CrossCompile:     spec.Compiler.IsZig(),
+		Analyze:          spec.Language.IsCFamily() && spec.Analyzer != "",
+		Dependencies:     spec.Language.IsCFamily(),
+		WerrorFor:        spec.Language.IsCFamily(),
+		CompileOnly:      spec.Language.IsCFamily(),
+		DialectOptions:   spec.Language.IsCFamily(),
+		Test:             spec.Language.SupportsTests(),
+		CheckFormat:      spec.Formatter != "",
+		Quick:            spec.Language.IsCFamily(),
+		Preprocess:       spec.Language.IsCFamily(),
+		ResourceUsage:    spec.GNUTime,
+		Symbols:          producesBinary(spec.Language) && spec.SymbolTool != "",
+		Flags:            spec.Language.SupportsFlags(),
+		HeaderOnly:       spec.Language.IsCFamily(),
+		Deterministic:    spec.Language.SupportsDeterministic(),
+		Libc:             environmentLibcs(spec),
+		Toolchains:       slices.Sorted(maps.Keys(spec.Toolchains)),
+	}
+}
+
+// environmentLibcs lists the C libraries a request may select, the default image's first.
+// An environment whose libc is not configured offers no choice.
+func environmentLibcs(spec models.EnvironmentSpec) []models.Libc {
+	if spec.Libc == "" {
+		return nil
 	}
+	return append([]models.Libc{spec.Libc}, slices.Sorted(maps.Keys(spec.LibcImages))...)
 }
 
 // Close cleans up resources.
@@ -122,8 +394,48 @@ func (c *Compiler) Close() error {
 	return c.runtime.Close()
 }
 
-// verifyImages checks that all required images exist.
-func (c *Compiler) verifyImages(ctx context.Context) error {
+// RuntimeLatency returns the runtime's latest backend probe latency, if the runtime probes its backend.
+func (c *Compiler) RuntimeLatency() (time.Duration, bool) {
+	reporter, ok := c.runtime.(runtime.LatencyReporter)
+	if !ok {
+		return 0, false
+	}
+	return reporter.PingLatency()
+}
+
+// ReloadConfig re-reads the environments configuration and swaps it in.
+// The images of the new environments and their pinned toolchain versions are verified first; on any error
+// the current configuration is kept.
+func (c *Compiler) ReloadConfig(ctx context.Context, configPath string) error {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	environments, err := config.ToEnvironmentSpecs()
+	if err != nil {
+		return fmt.Errorf("failed to parse environment specs: %w", err)
+	}
+
+	if err := c.verifyImages(ctx, environments); err != nil {
+		return err
+	}
+
+	// Pins are checked like at startup, so a reload cannot swap in images that drifted
+	if err := c.verifyToolchainVersions(ctx, environments, config.MinCompilerVersions); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.environments = environments
+	c.minVersions = config.MinCompilerVersions
+	c.mu.Unlock()
+
+	return nil
+}
+
+// verifyImages checks that all images required by the given environments exist.
+func (c *Compiler) verifyImages(ctx context.Context, environments map[string]models.EnvironmentSpec) error {
 	// In integration tests (CI), we only pull gcc:9 to speed up the pipeline.
 	// MINIMAL_IMAGE_VALIDATION=true checks only for gcc:9 instead of all images.
 	// This must match the image pulled in .github/workflows/pr-ci.yml
@@ -142,14 +454,16 @@ func (c *Compiler) verifyImages(ctx context.Context) error {
 
 	missingImages := []string{}
 
-	// Check each environment's image
-	for envKey, envSpec := range c.environments {
-		exists, err := c.runtime.ImageExists(ctx, envSpec.ImageTag)
-		if err != nil {
-			return fmt.Errorf("failed to check image %s: %w", envSpec.ImageTag, err)
-		}
-		if !exists {
-			missingImages = append(missingImages, fmt.Sprintf("%s (%s)", envSpec.ImageTag, envKey))
+	// Check each environment's images, including its libc alternatives
+	for envKey, envSpec := range environments {
+		for _, image := range environmentImages(envSpec) {
+			exists, err := c.runtime.ImageExists(ctx, image)
+			if err != nil {
+				return fmt.Errorf("failed to check image %s: %w", image, err)
+			}
+			if !exists {
+				missingImages = append(missingImages, fmt.Sprintf("%s (%s)", image, envKey))
+			}
 		}
 	}
 
@@ -167,50 +481,136 @@ func (c *Compiler) verifyImages(ctx context.Context) error {
 	return nil
 }
 
+// failedResult is the result of a job that failed before or instead of producing compiler output.
+func failedResult(job models.CompilationJob, startTime time.Time, err error) models.CompilationResult {
+	return models.CompilationResult{
+		JobID:    job.ID,
+		Success:  false,
+		Compiled: false,
+		Error:    err.Error(),
+		Duration: time.Since(startTime),
+	}
+}
+
 // Compile compiles the given code and returns the result.
 func (c *Compiler) Compile(ctx context.Context, job models.CompilationJob) models.CompilationResult {
+	return c.CompileStreaming(ctx, job, nil)
+}
+
+// CompileStreaming compiles the given code like Compile, passing the compile's output to onOutput
+// as it is written, if the runtime can follow it. Results served from the cache stream nothing.
+func (c *Compiler) CompileStreaming(ctx context.Context, job models.CompilationJob, onOutput runtime.OutputFunc) models.CompilationResult {
 	startTime := time.Now()
 
 	// Validate the request
 	if err := c.validateRequest(job.Request); err != nil {
-		return models.CompilationResult{
-			JobID:    job.ID,
-			Success:  false,
-			Compiled: false,
-			Error:    err.Error(),
-			Duration: time.Since(startTime),
-		}
+		return failedResult(job, startTime, err)
 	}
 
 	// Decode source code
 	sourceCode, err := base64.StdEncoding.DecodeString(job.Request.Code)
 	if err != nil {
-		return models.CompilationResult{
-			JobID:    job.ID,
-			Success:  false,
-			Compiled: false,
-			Error:    "invalid base64 encoding",
-			Duration: time.Since(startTime),
-		}
+		return failedResult(job, startTime, errors.New("invalid base64 encoding"))
 	}
 
 	// Select environment
 	envSpec, err := c.selectEnvironment(job.Request)
 	if err != nil {
-		return models.CompilationResult{
-			JobID:    job.ID,
-			Success:  false,
-			Compiled: false,
-			Error:    err.Error(),
-			Duration: time.Since(startTime),
-		}
+		return failedResult(job, startTime, err)
+	}
+
+	// Static analysis needs a tool in the image; not every environment ships one
+	if job.Request.Analyze && envSpec.Analyzer == "" {
+		return failedResult(job, startTime, fmt.Errorf("%w: %s with %s", ErrAnalyzerUnavailable, envSpec.Language, envSpec.Compiler))
+	}
+
+	// Format checks need a formatter in the image
+	if (job.Request.CheckFormat || job.Request.RequireFormat) && envSpec.Formatter == "" {
+		return failedResult(job, startTime, fmt.Errorf("%w: %s with %s", ErrFormatterUnavailable, envSpec.Language, envSpec.Compiler))
+	}
+
+	// Resource usage is measured by GNU time, which not every image ships
+	if job.Request.ResourceUsage && !envSpec.GNUTime {
+		return failedResult(job, startTime, fmt.Errorf("%w: %s with %s", ErrGNUTimeUnavailable, envSpec.Language, envSpec.Compiler))
+	}
+
+	// Symbols are listed from the binary by a tool in the image
+	if job.Request.Symbols && (!producesBinary(envSpec.Language) || envSpec.SymbolTool == "") {
+		return failedResult(job, startTime, fmt.Errorf("%w: %s with %s", ErrSymbolToolUnavailable, envSpec.Language, envSpec.Compiler))
+	}
+
+	// Apply the environment's run default; resolving it into the request keeps the cache key and cacheability in step
+	if job.Request.Run == nil && envSpec.DefaultRun && !job.Request.RunExcluded() {
+		run := true
+		job.Request.Run = &run
+	}
+
+	// Clamp the requested timeout to the ceiling; likewise resolved into the request for the cache key
+	timeout := c.clampTimeout(job.Request, envSpec)
+	if job.Request.TimeoutSeconds > 0 {
+		job.Request.TimeoutSeconds = int(timeout.Seconds())
 	}
 
 	// Determine source filename based on language
 	sourceFilename := c.getSourceFilename(envSpec.Language)
+	if job.Request.Test && envSpec.Language == models.LanguageGo {
+		sourceFilename = goTestSourceFilename
+	}
+	sources := []string{sourceFilename}
 
-	// Build compile command based on language
-	compileCmd := c.buildCompileCommand(envSpec, sourceFilename)
+	// Archives replace the single source file with an extracted tree
+	var files map[string]string
+	if job.Request.Archive != "" {
+		files, sources, err = c.prepareArchive(job.Request.Archive, envSpec.Language)
+		if err != nil {
+			return failedResult(job, startTime, err)
+		}
+	}
+
+	// Multi-file submissions are written into the workspace like an archive
+	if len(job.Request.Files) > 0 && !job.Request.HeaderOnly {
+		files, sources, err = prepareFiles(job.Request, envSpec.Language)
+		if err != nil {
+			return failedResult(job, startTime, err)
+		}
+	}
+
+	// Gists are fetched from GitHub into the workspace
+	if job.Request.Gist != "" {
+		files, sources, err = c.prepareGist(ctx, job.Request.Gist, envSpec.Language, sourceFilename)
+		if err != nil {
+			return failedResult(job, startTime, err)
+		}
+	}
+
+	// Header-only checks compile a translation unit per header, from code or files
+	if job.Request.HeaderOnly {
+		files, sources, err = prepareHeaderCheck(job.Request, envSpec.Language, string(sourceCode))
+		if err != nil {
+			return failedResult(job, startTime, err)
+		}
+	}
+
+	// Build compile command based on language; compile-only stops before linking,
+	// quick mode and header-only checks stop after parsing, test mode builds and runs the tests instead
+	compileCmd := c.buildCompileCommandForSources(envSpec, sources)
+	switch {
+	case job.Request.HeaderOnly:
+		compileCmd = buildSyntaxCheckCommand(envSpec, sources)
+	case job.Request.CompileOnly:
+		compileCmd = buildObjectCommand(envSpec, sources)
+	case job.Request.Quick:
+		compileCmd = buildSyntaxCheckCommand(envSpec, sources)
+	case job.Request.Test:
+		compileCmd = buildTestCommand(envSpec, sources, files, job.Request.Release)
+	}
+
+	// GNU time reports the compile's resources after its output on stderr
+	if job.Request.ResourceUsage {
+		compileCmd = wrapWithGNUTime(compileCmd)
+	}
+
+	timeout = effectiveTimeout(job.Request, timeout)
 
 	// Prepare runtime configuration
 	config := runtime.CompilationConfig{
@@ -219,41 +619,266 @@ func (c *Compiler) Compile(ctx context.Context, job models.CompilationJob) model
 		SourceCode:     string(sourceCode),
 		SourceFilename: sourceFilename,
 		CompileCommand: compileCmd,
+		CompileScript:  envSpec.CompileScript,
 		WorkDir:        "/workspace",
-		Env:            c.buildEnvVars(envSpec, sourceFilename),
-		Timeout:        30 * time.Second,
+		Env:            c.buildEnvVars(envSpec, sourceFilename, timeout),
+		Timeout:        timeout,
+		Files:          files,
+		TmpSize:        c.tmpSize,
+		IdleTimeout:    c.idleTimeout,
+		CPUShares:      envSpec.CPUShares,
+	}
+
+	// Fix the timestamps a deterministic build would otherwise embed
+	if job.Request.Deterministic {
+		config.Env = append(config.Env, sourceDateEpoch)
+	}
+
+	// Report the binary size for languages that produce one
+	switch {
+	case job.Request.CompileOnly && len(sources) == 1:
+		config.OutputPath = objectOutputPath
+	case job.Request.CompileOnly:
+		// One object per source lands in the workspace; there is no single output to measure
+	case job.Request.Test:
+		// The test binary is not the program
+	case job.Request.Quick, job.Request.HeaderOnly:
+		// Nothing is produced; skipping the output stat keeps the round trip short
+	case producesBinary(envSpec.Language):
+		config.OutputPath = binaryOutputPath
+	}
+
+	// Run mode: execute the binary after a successful compile, with its own limits
+	if job.Request.RunRequested() {
+		config.RunCommand = programCommand(envSpec.Language)
+		config.RunTimeout = runtime.DefaultRunTimeout
+		config.MaxRunOutputSize = runOutputLimit(job.Request.RunOutputLimit)
+		config.PreserveANSI = job.Request.RunStdoutHTML
+	}
+
+	// Identical requests are served from cache
+	cacheKey := resultCacheKey(config, job.Request)
+	if cached, age, ok := c.cache.get(cacheKey); ok {
+		cached.JobID = job.ID
+		cached.Cached = true
+		cached.CacheAge = age
+		return cached
 	}
 
 	// Run compilation
+	config.OnOutput = onOutput
 	output, err := c.runtime.Compile(ctx, config)
 	if err != nil {
-		return models.CompilationResult{
-			JobID:    job.ID,
-			Success:  false,
-			Compiled: false,
-			Error:    fmt.Sprintf("compilation failed: %v", err),
-			Duration: time.Since(startTime),
+		return failedResult(job, startTime, fmt.Errorf("compilation failed: %w", err))
+	}
+
+	// Make the compiler's output valid UTF-8, so results serialize to JSON as the compiler wrote them
+	output.Stdout = toUTF8(output.Stdout, envSpec.OutputEncoding)
+	output.Stderr = toUTF8(output.Stderr, envSpec.OutputEncoding)
+
+	// Colors were kept for the HTML; render it, then strip every stream as the runtime would have
+	var runStdoutHTML string
+	if config.PreserveANSI {
+		runStdoutHTML = ansi.ToHTML(output.RunStdout)
+		for _, stream := range []*string{&output.Stdout, &output.Stderr, &output.RunStdout, &output.RunStderr} {
+			*stream = ansi.Strip(*stream)
 		}
 	}
 
+	// Split the GNU time report off stderr before anything parses it
+	var usage *models.ResourceUsage
+	if job.Request.ResourceUsage {
+		usage, output.Stderr = parseGNUTimeReport(output.Stderr)
+	}
+
 	// Build result
 	result := models.CompilationResult{
-		JobID:    job.ID,
-		Success:  true,
-		Compiled: output.ExitCode == 0,
-		Stdout:   output.Stdout,
-		Stderr:   output.Stderr,
-		ExitCode: output.ExitCode,
-		Duration: output.Duration,
+		JobID:              job.ID,
+		Success:            true,
+		Compiled:           output.ExitCode == 0,
+		Stdout:             output.Stdout,
+		Stderr:             output.Stderr,
+		ExitCode:           output.ExitCode,
+		Duration:           output.Duration,
+		Ran:                output.Ran,
+		RunStdout:          output.RunStdout,
+		RunStderr:          output.RunStderr,
+		RunExitCode:        output.RunExitCode,
+		RunTimedOut:        output.RunTimedOut,
+		BinaryBytes:        output.BinaryBytes,
+		SourceHash:         sourceHash(job.Request, sourceCode, files),
+		OutputHash:         output.BinarySHA256,
+		TerminatedBySignal: output.TerminatedBySignal,
+		ResourceUsage:      usage,
+		RunStdoutHTML:      runStdoutHTML,
+		RunSuccess:         output.Ran && output.RunExitCode == 0 && !output.RunTimedOut,
+		NetworkEnabled:     output.NetworkEnabled,
 	}
 
 	if output.TimedOut {
+		result.TimedOut = true
 		result.Error = "compilation timeout"
+
+		// Runaway template recursion is a classic way for C++ to time out; say so instead of a bare timeout
+		if isCppLanguage(envSpec.Language) && hasTemplateDepthMessage(output.Stderr) {
+			result.Error = "compilation timeout: possible infinite template recursion; " +
+				"consider -ftemplate-depth to fail faster or add a base case to the recursive template"
+		}
+	}
+
+	// A stall cut the hard timeout short; it is still a timeout, but say why it came early
+	if output.IdleTimedOut {
+		result.TimedOut = true
+		result.Error = fmt.Sprintf("compilation timeout: killed after %s without output (idle timeout)", config.IdleTimeout)
+	}
+
+	// Failing tests exit non-zero, but the code compiled if any test ran
+	if job.Request.Test {
+		result.TestsPassed, result.TestsFailed = parseTestCounts(envSpec.Language, output.Stdout)
+		if result.TestsPassed+result.TestsFailed > 0 {
+			result.Compiled = true
+		}
+		result.Tested = result.Compiled
+	}
+
+	// A syscall blocked by the sandbox is a policy issue, not a compile error; the program's is reported
+	// with its run, as it compiled fine
+	switch {
+	case output.ExitCode == seccompKillExitCode:
+		result.Error = "compiler was killed by the sandbox: it attempted a system call blocked by the seccomp policy"
+	case output.Ran && output.RunExitCode == seccompKillExitCode:
+		result.RunError = "program was killed by the sandbox: it attempted a system call blocked by the seccomp policy"
+	}
+
+	// Intermediates filled the in-memory /tmp; the compiler's own ENOSPC message doesn't say which disk
+	if !result.Compiled && strings.Contains(output.Stderr, noSpaceLeftMessage) {
+		result.Error = fmt.Sprintf("compiler ran out of temporary space: /tmp is limited to %d MB; "+
+			"reduce intermediate files (e.g., compile with -pipe or split large sources) or raise TMPFS_SIZE_MB",
+			config.TmpSizeOrDefault()/(1024*1024))
+	}
+
+	// Parse structured diagnostics if the client asked for them; they are the whole point of quick mode
+	// and the input of the HTML rendering
+	diagnosticsFormat := job.Request.DiagnosticsFormat
+	if diagnosticsFormat == "" && (job.Request.Quick || job.Request.DiagnosticsHTML) {
+		diagnosticsFormat = models.DiagnosticsFormatText
+	}
+	if diagnosticsFormat != "" {
+		result.Diagnostics = parseDiagnostics(diagnosticsFormat, envSpec.Language, output.Stderr)
+		result.Errors, result.Warnings, result.Notes = groupDiagnostics(result.Diagnostics)
+	}
+	if job.Request.DiagnosticsHTML {
+		sourceFiles := files
+		if sourceFiles == nil {
+			sourceFiles = map[string]string{sourceFilename: string(sourceCode)}
+		}
+		result.DiagnosticsHTML = renderDiagnosticsHTML(result.Diagnostics, sourceFiles)
+	}
+
+	// Static analysis runs as its own step; findings don't affect Compiled
+	if job.Request.Analyze {
+		analysis, err := c.analyze(ctx, config, envSpec, sources)
+		if err != nil && result.Error == "" {
+			result.Error = fmt.Sprintf("analysis failed: %v", err)
+		}
+		result.Analysis = analysis
+	}
+
+	// Formatting is checked as its own step; differences only fail the compile when required
+	if job.Request.CheckFormat || job.Request.RequireFormat {
+		diff, err := c.checkFormat(ctx, config, envSpec, sources)
+		if err != nil && result.Error == "" {
+			result.Error = fmt.Sprintf("format check failed: %v", err)
+		}
+		result.FormatDiff = diff
+		if diff != "" && job.Request.RequireFormat {
+			result.Compiled = false
+		}
+	}
+
+	// The include list comes from a preprocessor-only pass
+	if job.Request.Dependencies {
+		includes, err := c.dependencies(ctx, config, envSpec, sources)
+		if err != nil && result.Error == "" {
+			result.Error = fmt.Sprintf("dependency scan failed: %v", err)
+		}
+		result.Includes = includes
+	}
+
+	// The preprocessed source is written to a file in its own pass and read back, as it easily outgrows stdout
+	if job.Request.Preprocess {
+		preprocessed, truncated, err := c.preprocess(ctx, config, envSpec, sources)
+		if err != nil && result.Error == "" {
+			result.Error = fmt.Sprintf("preprocessing failed: %v", err)
+		}
+		result.Preprocessed, result.PreprocessedTruncated = preprocessed, truncated
+	}
+
+	// Symbols are listed in their own pass; there is nothing to list when the compile failed
+	if job.Request.Symbols && result.Compiled {
+		symbols, truncated, err := c.symbols(ctx, config, envSpec)
+		if err != nil && result.Error == "" {
+			result.Error = fmt.Sprintf("symbol listing failed: %v", err)
+		}
+		result.Symbols, result.SymbolsTruncated = symbols, truncated
+	}
+
+	if isCacheable(job.Request, result) {
+		c.cache.put(cacheKey, result)
 	}
 
 	return result
 }
 
+// sourceHash returns the hex-encoded SHA-256 of the decoded source, of the decoded archive
+// for archive submissions, or of the files for gists and multi-file submissions, so clients can check that two
+// results compiled the same input.
+func sourceHash(req models.CompilationRequest, sourceCode []byte, files map[string]string) string {
+	hash := sha256.New()
+	switch {
+	case req.Archive != "":
+		// Already validated by prepareArchive
+		sourceCode, _ = base64.StdEncoding.DecodeString(req.Archive) //nolint:errcheck // decoded successfully before
+		hash.Write(sourceCode)
+	case req.Gist != "" || len(req.Files) > 0:
+		for _, name := range slices.Sorted(maps.Keys(files)) {
+			hash.Write([]byte(name + "\x00" + files[name] + "\x00"))
+		}
+	default:
+		hash.Write(sourceCode)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// prepareArchive decodes and extracts a submitted archive and picks the files to compile.
+func (c *Compiler) prepareArchive(encoded string, language models.Language) (map[string]string, []string, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid base64 encoding", ErrInvalidArchive)
+	}
+
+	files, err := extractArchive(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources, err := archiveSources(language, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, sources, nil
+}
+
+// runOutputLimit returns the per-stream run output cap for a requested limit.
+// Zero selects the runtime default; larger values are clamped to maxRunOutputSize.
+func runOutputLimit(requested int) int {
+	if requested <= 0 {
+		return runtime.DefaultMaxRunOutputSize
+	}
+	return min(requested, maxRunOutputSize)
+}
+
 // validateRequest validates the compilation request.
 func (c *Compiler) validateRequest(req models.CompilationRequest) error {
 	// Use the built-in Validate method
@@ -262,14 +887,29 @@ func (c *Compiler) validateRequest(req models.CompilationRequest) error {
 	}
 
 	// Check code size (base64 encoded)
-	if len(req.Code) > 2*1024*1024 { // ~1.5MB source after decoding
+	if len(req.Code) > 2*1024*1024 || len(req.Archive) > 2*1024*1024 { // ~1.5MB source after decoding
+		return ErrSourceCodeTooLarge
+	}
+	filesSize := 0
+	for _, content := range req.Files {
+		filesSize += len(content)
+	}
+	if filesSize > 2*1024*1024 {
 		return ErrSourceCodeTooLarge
 	}
 
+	// Cheap guard against preprocessor bombs
+	if err := c.checkIncludeCount(req); err != nil {
+		return err
+	}
+
 	// Validate language support - check if we have environments for this language
 	normalizedLang := req.Language.Normalize()
 
 	// Check if any environment supports this language
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	hasSupport := false
 	for _, env := range c.environments {
 		if env.Language == normalizedLang {
@@ -299,27 +939,160 @@ func (c *Compiler) selectEnvironment(req models.CompilationRequest) (models.Envi
 	// Build environment key
 	envKey := fmt.Sprintf("%s-%s", language, compiler)
 
+	c.mu.RLock()
 	env, exists := c.environments[envKey]
+	minVersions := c.minVersions
+	c.mu.RUnlock()
 	if !exists {
 		return models.EnvironmentSpec{}, fmt.Errorf("%w: %s with %s", ErrUnsupportedEnvironment, language, compiler)
 	}
 
+	// Reject compilers being phased out
+	if minimum, below := belowMinimumVersion(compiler, minVersions); below {
+		return models.EnvironmentSpec{}, fmt.Errorf("%w: %s (minimum version %s)", ErrCompilerTooOld, compiler, minimum)
+	}
+
+	// Swap in the image built on the requested C library
+	if req.Libc != "" && req.Libc != env.Libc {
+		image, ok := env.LibcImages[req.Libc]
+		if !ok {
+			return models.EnvironmentSpec{}, fmt.Errorf("%w: %s with %s has no %s image", ErrLibcUnavailable, language, compiler, req.Libc)
+		}
+		env.ImageTag = image
+		env.Libc = req.Libc
+	}
+
+	// Swap in the image with the requested sysroot or cross toolchain (validated to C/C++ only)
+	if req.Toolchain != "" {
+		toolchain, ok := env.Toolchains[req.Toolchain]
+		if !ok {
+			available := "none"
+			if len(env.Toolchains) > 0 {
+				available = strings.Join(slices.Sorted(maps.Keys(env.Toolchains)), ", ")
+			}
+			return models.EnvironmentSpec{}, fmt.Errorf("%w: %s with %s has no toolchain %q (available: %s)",
+				ErrToolchainUnavailable, language, compiler, req.Toolchain, available)
+		}
+		env.ImageTag = toolchain.Image
+		if toolchain.Sysroot != "" {
+			env.Flags = append(slices.Clone(env.Flags), "--sysroot="+toolchain.Sysroot)
+		}
+		env.Flags = append(slices.Clone(env.Flags), toolchain.Flags...)
+	}
+
 	// Override standard if specified
 	if req.Standard != "" {
 		env.Standard = req.Standard
 	}
 
+	// Ask GCC for machine-readable diagnostics; other compilers (clang, including zig cc) fall back to text parsing
+	if req.DiagnosticsFormat == models.DiagnosticsFormatJSON && emitsJSONDiagnostics(env) {
+		env.Flags = append(slices.Clone(env.Flags), "-fdiagnostics-format=json")
+	}
+
+	// Select an alternative linker; the image must ship it (validated to C/C++ only)
+	if req.Linker != "" && language.SupportsLinker() {
+		env.Flags = append(slices.Clone(env.Flags), "-fuse-ld="+string(req.Linker))
+	}
+
+	// Wrap diagnostics at the client's column width (gcc and clang); other compilers ignore it
+	if req.DiagnosticsWidth > 0 && language.IsCFamily() {
+		env.Flags = append(slices.Clone(env.Flags), fmt.Sprintf("-fmessage-length=%d", req.DiagnosticsWidth))
+	}
+
+	// Promote selected warnings to errors (validated to C/C++ only)
+	if len(req.WerrorFor) > 0 && language.IsCFamily() {
+		env.Flags = slices.Clone(env.Flags)
+		for _, name := range req.WerrorFor {
+			env.Flags = append(env.Flags, "-Werror="+name)
+		}
+	}
+
+	// Dialect flags (validated against models.AllowedDialectOptions, C/C++ only)
+	if len(req.DialectOptions) > 0 && language.IsCFamily() {
+		env.Flags = append(slices.Clone(env.Flags), req.DialectOptions...)
+	}
+
+	// Release builds enable the language's optimizations
+	if req.Release {
+		env.Flags = append(slices.Clone(env.Flags), releaseFlags(language)...)
+	}
+
+	// Reproducible builds drop what differs between otherwise identical builds (validated to C/C++, Go and Rust)
+	if req.Deterministic {
+		env.Flags = append(slices.Clone(env.Flags), deterministicFlags(env)...)
+	}
+
+	// Extra flags from the request (validated: single flags, no paths, no -o), after the
+	// service's own so a later -W flag can override an earlier one
+	if len(req.Flags) > 0 && language.SupportsFlags() {
+		env.Flags = append(slices.Clone(env.Flags), req.Flags...)
+	}
+
+	// Cross-compile for another target (validated to zig only)
+	if req.Target != "" && env.Compiler.IsZig() {
+		env.Flags = append(slices.Clone(env.Flags), "-target", req.Target)
+	}
+
 	return env, nil
 }
 
+// releaseFlags returns the compiler flags of a release build.
+// Go has no optimization levels; its release build strips the symbol table and debug info.
+func releaseFlags(language models.Language) []string {
+	switch language {
+	case models.LanguageRust:
+		return []string{"-C opt-level=3"}
+	case models.LanguageGo:
+		return []string{"-trimpath", "-ldflags='-s -w'"}
+	case models.LanguageSwift:
+		return []string{"-O"}
+	case models.LanguageKotlin:
+		return nil // kotlinc has no optimization levels
+	default:
+		// gcc, clang and zig cc/c++ drivers
+		return []string{"-O2", "-DNDEBUG"}
+	}
+}
+
+// deterministicFlags returns the compiler flags of a reproducible build: the workspace path is
+// mapped to "." in debug info and macros such as __FILE__, and GCC's random seed, which names
+// symbols of anonymous namespaces, is fixed. Timestamps are fixed by SOURCE_DATE_EPOCH instead.
+func deterministicFlags(env models.EnvironmentSpec) []string {
+	switch env.Language.Normalize() {
+	case models.LanguageGo:
+		if slices.Contains(env.Flags, "-trimpath") { // Already set by a release build
+			return nil
+		}
+		return []string{"-trimpath"}
+	case models.LanguageRust:
+		return []string{"--remap-path-prefix=/workspace=."}
+	default:
+		// clang, including zig cc, derives no names from a random seed and ignores the flag
+		if env.Compiler.IsClang() || env.Compiler.IsZig() {
+			return []string{"-ffile-prefix-map=/workspace=."}
+		}
+		return []string{"-ffile-prefix-map=/workspace=.", "-frandom-seed=will-it-compile"}
+	}
+}
+
+// sourceDateEpoch is the SOURCE_DATE_EPOCH of deterministic builds, which GCC and recent clang
+// versions use for __DATE__ and __TIME__ instead of the current time.
+const sourceDateEpoch = "SOURCE_DATE_EPOCH=0"
+
 // buildEnvVars builds environment variables for the compilation container.
 // Includes common variables and language-specific ones (e.g., GOCACHE for Go).
-func (c *Compiler) buildEnvVars(env models.EnvironmentSpec, sourceFilename string) []string {
+func (c *Compiler) buildEnvVars(env models.EnvironmentSpec, sourceFilename string, timeout time.Duration) []string {
+	timeoutEnv := env.TimeoutEnv
+	if timeoutEnv == "" {
+		timeoutEnv = defaultTimeoutEnv
+	}
+
 	// Common environment variables for all languages
 	envVars := []string{
 		fmt.Sprintf("STANDARD=%s", env.Standard),
 		fmt.Sprintf("SOURCE_FILE=/workspace/%s", sourceFilename),
-		"COMPILE_TIMEOUT=25",
+		fmt.Sprintf("%s=%d", timeoutEnv, compileScriptTimeout(timeout)),
 	}
 
 	// Language-specific environment variables
@@ -338,36 +1111,149 @@ func (c *Compiler) buildEnvVars(env models.EnvironmentSpec, sourceFilename strin
 		)
 	}
 
+	// Zig caches compiled libc/compiler-rt per target
+	if env.Compiler.IsZig() {
+		envVars = append(envVars,
+			"ZIG_GLOBAL_CACHE_DIR=/tmp/zig-cache",
+			"ZIG_LOCAL_CACHE_DIR=/tmp/zig-cache",
+		)
+	}
+
 	return envVars
 }
 
+// templateDepthMessages are printed by GCC and Clang when template instantiation nests too deeply.
+var templateDepthMessages = []string{
+	"template instantiation depth exceeds maximum",            // GCC
+	"recursive template instantiation exceeded maximum depth", // Clang
+}
+
+// hasTemplateDepthMessage reports whether compiler output mentions the template instantiation depth limit.
+func hasTemplateDepthMessage(stderr string) bool {
+	for _, message := range templateDepthMessages {
+		if strings.Contains(stderr, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCppLanguage reports whether the language has C++ templates.
+func isCppLanguage(language models.Language) bool {
+	language = language.Normalize()
+	return language == models.LanguageCpp || language == models.LanguageObjCpp
+}
+
+// compileScriptTimeout returns the timeout in whole seconds passed to compile scripts (at least 1).
+func compileScriptTimeout(timeout time.Duration) int {
+	return max(int((timeout - compileTimeoutMargin).Seconds()), 1)
+}
+
 // buildCompileCommand builds the compilation command based on the environment.
 func (c *Compiler) buildCompileCommand(env models.EnvironmentSpec, sourceFilename string) string {
+	return c.buildCompileCommandForSources(env, []string{sourceFilename})
+}
+
+// buildCompileCommandForSources builds the compilation command for one or more workspace files.
+func (c *Compiler) buildCompileCommandForSources(env models.EnvironmentSpec, sources []string) string {
+	// Extra flags (e.g., diagnostics format) are inserted before the source file
+	flags := ""
+	if len(env.Flags) > 0 {
+		flags = " " + strings.Join(env.Flags, " ")
+	}
+
+	// Source paths, joined so each format string's "/workspace/%s" prefixes the first
+	sourceFilename := strings.Join(sources, " /workspace/")
+
 	// Build command based on language
 	// Note: stderr is NOT redirected to stdout so errors appear in stderr field
 	switch env.Language {
-	case models.LanguageCpp:
-		// C++ compilation with g++
-		return fmt.Sprintf("g++ -std=%s /workspace/%s -o /workspace/output", env.Standard, sourceFilename)
-
-	case models.LanguageC:
-		// C compilation with gcc (not g++)
-		return fmt.Sprintf("gcc -std=%s /workspace/%s -o /workspace/output", env.Standard, sourceFilename)
+	case models.LanguageCpp, models.LanguageC:
+		// C++ with g++, C with gcc (not g++), or clang++/clang or zig c++/zig cc
+		return fmt.Sprintf("%s -std=%s%s /workspace/%s -o /workspace/output", cFamilyDriver(env), env.Standard, flags, sourceFilename)
 
 	case models.LanguageGo:
 		// Go compilation
-		return fmt.Sprintf("go build -o /workspace/output /workspace/%s", sourceFilename)
+		return fmt.Sprintf("go build%s -o /workspace/output /workspace/%s", flags, sourceFilename)
 
 	case models.LanguageRust:
 		// Rust compilation
-		return fmt.Sprintf("rustc /workspace/%s -o /workspace/output", sourceFilename)
+		return fmt.Sprintf("rustc%s /workspace/%s -o /workspace/output", flags, sourceFilename)
+
+	case models.LanguageObjC, models.LanguageObjCpp:
+		// Objective-C/C++ compilation with clang; the runtime is linked after the sources
+		driver := "clang"
+		if env.Language == models.LanguageObjCpp {
+			driver = "clang++"
+		}
+		return fmt.Sprintf("%s -std=%s%s /workspace/%s -o /workspace/output %s", driver, env.Standard, flags, sourceFilename, objcRuntimeFlags(env))
+
+	case models.LanguageSwift:
+		// Swift compilation; main.swift holds the top-level code
+		return fmt.Sprintf("swiftc%s /workspace/%s -o /workspace/output", flags, sourceFilename)
+
+	case models.LanguageKotlin:
+		// Kotlin compilation to a jar that bundles the runtime, so java -jar runs it
+		return fmt.Sprintf("kotlinc%s /workspace/%s -include-runtime -d %s", flags, sourceFilename, jarOutputPath)
 
 	default:
 		// Fallback to C++ (should not happen due to validation)
-		return fmt.Sprintf("g++ -std=%s /workspace/%s -o /workspace/output", env.Standard, sourceFilename)
+		return fmt.Sprintf("g++ -std=%s%s /workspace/%s -o /workspace/output", env.Standard, flags, sourceFilename)
 	}
 }
 
+// buildObjectCommand builds a C or C++ command that compiles without linking, so code
+// without a main function can be checked. -o is only valid with a single source;
+// multiple sources each leave their object file in the working directory.
+func buildObjectCommand(env models.EnvironmentSpec, sources []string) string {
+	flags := ""
+	if len(env.Flags) > 0 {
+		flags = " " + strings.Join(env.Flags, " ")
+	}
+	sourceFilename := strings.Join(sources, " /workspace/")
+
+	if len(sources) == 1 {
+		return fmt.Sprintf("%s -std=%s%s -c /workspace/%s -o %s", cFamilyDriver(env), env.Standard, flags, sourceFilename, objectOutputPath)
+	}
+	return fmt.Sprintf("%s -std=%s%s -c /workspace/%s", cFamilyDriver(env), env.Standard, flags, sourceFilename)
+}
+
+// buildSyntaxCheckCommand builds a command that only parses and type-checks C/C++ sources (-fsyntax-only),
+// producing diagnostics but no object files or binary.
+func buildSyntaxCheckCommand(env models.EnvironmentSpec, sources []string) string {
+	flags := ""
+	if len(env.Flags) > 0 {
+		flags = " " + strings.Join(env.Flags, " ")
+	}
+	sourceFilename := strings.Join(sources, " /workspace/")
+
+	return fmt.Sprintf("%s -std=%s%s -fsyntax-only /workspace/%s", cFamilyDriver(env), env.Standard, flags, sourceFilename)
+}
+
+// cFamilyDriver returns the compiler driver for a C or C++ environment.
+func cFamilyDriver(env models.EnvironmentSpec) string {
+	switch {
+	case env.Language == models.LanguageC && env.Compiler.IsZig():
+		return "zig cc"
+	case env.Language == models.LanguageC && env.Compiler.IsClang():
+		return "clang"
+	case env.Language == models.LanguageC:
+		return "gcc"
+	case env.Compiler.IsZig():
+		return "zig c++"
+	case env.Compiler.IsClang():
+		return "clang++"
+	default:
+		return "g++"
+	}
+}
+
+// emitsJSONDiagnostics reports whether the environment's compiler can write its diagnostics as JSON.
+// Only GCC can: clang, including zig cc, has no JSON diagnostics format.
+func emitsJSONDiagnostics(env models.EnvironmentSpec) bool {
+	return supportsJSONDiagnostics(env.Language) && !env.Compiler.IsZig() && !env.Compiler.IsClang()
+}
+
 // getSourceFilename returns the appropriate source filename based on language.
 func (c *Compiler) getSourceFilename(language models.Language) string {
 	switch language {
@@ -379,16 +1265,61 @@ func (c *Compiler) getSourceFilename(language models.Language) string {
 		return "main.go"
 	case models.LanguageRust:
 		return "main.rs"
+	case models.LanguageObjC:
+		return "main.m"
+	case models.LanguageObjCpp:
+		return "main.mm"
+	case models.LanguageSwift:
+		return "main.swift"
+	case models.LanguageKotlin:
+		return "Main.kt"
 	default:
 		return "source.cpp"
 	}
 }
 
+// objcRuntimeFlags returns the flags selecting and linking the Objective-C runtime.
+// -framework only exists on Apple toolchains, so Foundation is linked that way on macOS
+// environments only; elsewhere the image must provide the GNUstep runtime (libobjc2).
+func objcRuntimeFlags(env models.EnvironmentSpec) string {
+	if env.OS == models.OSMacOS {
+		return "-framework Foundation"
+	}
+	return "-fobjc-runtime=gnustep-2.0 -lobjc"
+}
+
+// producesBinary reports whether the language's compile command writes a binary to binaryOutputPath.
+func producesBinary(language models.Language) bool {
+	switch language {
+	case models.LanguageC, models.LanguageCpp, models.LanguageGo, models.LanguageRust, models.LanguageObjC, models.LanguageObjCpp,
+		models.LanguageSwift:
+		return true
+	default:
+		return false
+	}
+}
+
+// programCommand returns the command that runs the compiled program in run mode (empty if the language's
+// compile produces nothing runnable): the binary itself, or the jar on the JVM.
+func programCommand(language models.Language) string {
+	switch {
+	case producesBinary(language):
+		return binaryOutputPath
+	case language == models.LanguageKotlin:
+		return "java -jar " + jarOutputPath
+	default:
+		return ""
+	}
+}
+
 // GetSupportedEnvironments returns a list of supported environments.
 func (c *Compiler) GetSupportedEnvironments() []models.Environment {
 	// Group environment specs by language
 	langMap := make(map[models.Language]*models.Environment)
 
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	for _, envSpec := range c.environments {
 		lang := envSpec.Language
 
@@ -400,6 +1331,8 @@ func (c *Compiler) GetSupportedEnvironments() []models.Environment {
 				Standards: []string{},
 				OSes:      []string{},
 				Arches:    []string{},
+
+				Capabilities: make(map[string]models.Capabilities),
 			}
 		}
 
@@ -411,6 +1344,15 @@ func (c *Compiler) GetSupportedEnvironments() []models.Environment {
 			env.Compilers = append(env.Compilers, compilerStr)
 		}
 
+		// Options are a property of the compiler within a language
+		env.Capabilities[compilerStr] = envSpec.Capabilities
+		if envSpec.ActualVersion != "" {
+			if env.ActualVersion == nil {
+				env.ActualVersion = make(map[string]string)
+			}
+			env.ActualVersion[compilerStr] = envSpec.ActualVersion
+		}
+
 		// Add standard if not already in list
 		standardStr := string(envSpec.Standard)
 		if standardStr != "" && !contains(env.Standards, standardStr) {
@@ -430,11 +1372,18 @@ func (c *Compiler) GetSupportedEnvironments() []models.Environment {
 		}
 	}
 
-	// Convert map to slice
+	// Convert map to slice, sorted so the listing doesn't follow map iteration order
 	result := make([]models.Environment, 0, len(langMap))
 	for _, env := range langMap {
+		slices.Sort(env.Compilers)
+		slices.Sort(env.Standards)
+		slices.Sort(env.OSes)
+		slices.Sort(env.Arches)
 		result = append(result, *env)
 	}
+	slices.SortFunc(result, func(a, b models.Environment) int {
+		return strings.Compare(a.Language, b.Language)
+	})
 
 	return result
 }
@@ -0,0 +1,84 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+)
+
+// Simulated container costs for the benchmarks below. Parsing is a fraction of a full
+// compile (codegen and linking), and stating the output is an extra backend round trip.
+const (
+	benchSyntaxCheckCost = 500 * time.Microsecond
+	benchFullCompileCost = 3 * time.Millisecond
+	benchOutputStatCost  = 500 * time.Microsecond
+)
+
+// benchStderr is a typical single-error compiler output, so diagnostics parsing is part of the measurement.
+const benchStderr = "/workspace/source.cpp:3:5: error: expected ';' before 'return'\n"
+
+// newBenchCompiler returns a compiler whose mock runtime sleeps for the simulated cost of each command.
+func newBenchCompiler() *Compiler {
+	return NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			cost := benchFullCompileCost
+			if strings.Contains(config.CompileCommand, "-fsyntax-only") {
+				cost = benchSyntaxCheckCost
+			}
+			if config.OutputPath != "" {
+				cost += benchOutputStatCost
+			}
+			time.Sleep(cost)
+			return &runtime.CompilationOutput{ExitCode: 1, Stderr: benchStderr, Duration: cost}, nil
+		},
+	})
+}
+
+// benchmarkCompile compiles a distinct source per iteration (so the result cache never hits) unless cached is set.
+func benchmarkCompile(b *testing.B, quick, cached bool) {
+	compiler := newBenchCompiler()
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		source := "int main() { return 0 }"
+		if !cached {
+			source = fmt.Sprintf("// %d\n%s", i, source)
+		}
+
+		result := compiler.Compile(ctx, models.CompilationJob{
+			ID: fmt.Sprintf("bench-job-%d", i),
+			Request: models.CompilationRequest{
+				Code:              base64.StdEncoding.EncodeToString([]byte(source)),
+				Language:          models.LanguageCpp,
+				Compiler:          models.CompilerGCC13,
+				DiagnosticsFormat: models.DiagnosticsFormatText,
+				Quick:             quick,
+			},
+		})
+		if len(result.Diagnostics) != 1 {
+			b.Fatalf("expected 1 diagnostic, got %d", len(result.Diagnostics))
+		}
+	}
+}
+
+// BenchmarkCompile_Full measures a full compile, which links a binary and stats it.
+func BenchmarkCompile_Full(b *testing.B) {
+	benchmarkCompile(b, false, false)
+}
+
+// BenchmarkCompile_Quick measures a quick-mode syntax check of a changed buffer.
+func BenchmarkCompile_Quick(b *testing.B) {
+	benchmarkCompile(b, true, false)
+}
+
+// BenchmarkCompile_QuickCached measures a quick-mode check of an unchanged buffer, served from the result cache.
+func BenchmarkCompile_QuickCached(b *testing.B) {
+	benchmarkCompile(b, true, true)
+}
@@ -2,11 +2,21 @@ package compiler
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/synctest"
 	"time"
 
+	"github.com/stlpine/will-it-compile/internal/docker"
+	dockerruntime "github.com/stlpine/will-it-compile/internal/runtime/docker"
 	"github.com/stlpine/will-it-compile/pkg/models"
 	"github.com/stlpine/will-it-compile/pkg/runtime"
 	"github.com/stretchr/testify/assert"
@@ -122,6 +132,8 @@ func TestCompile_Timeout(t *testing.T) {
 	assert.True(t, result.Success, "Job should succeed")
 	assert.False(t, result.Compiled, "Expected code not to compile due to timeout")
 	assert.Equal(t, "compilation timeout", result.Error)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, models.StatusTimeout, result.Status())
 }
 
 // TestCompile_RuntimeError tests runtime errors.
@@ -191,570 +203,2752 @@ func TestValidateRequest(t *testing.T) {
 			expectError: false,
 		},
 		{
-			name: "code_too_large",
+			name: "dependencies_not_c_family",
 			request: models.CompilationRequest{
-				Code:     base64.StdEncoding.EncodeToString(make([]byte, 2*1024*1024)),
+				Code:         base64.StdEncoding.EncodeToString([]byte("package main")),
+				Language:     models.LanguageGo,
+				Compiler:     models.CompilerGo123,
+				Dependencies: true,
+			},
+			expectError: true,
+			errorMsg:    "dependency listing is only supported for C and C++",
+		},
+		{
+			name: "werror_for_not_c_family",
+			request: models.CompilationRequest{
+				Code:      base64.StdEncoding.EncodeToString([]byte("fn main() {}")),
+				Language:  models.LanguageRust,
+				Compiler:  models.CompilerRustc180,
+				WerrorFor: []string{"unused"},
+			},
+			expectError: true,
+			errorMsg:    "werror_for is only supported for C and C++",
+		},
+		{
+			name: "werror_for_invalid_name",
+			request: models.CompilationRequest{
+				Code:      base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language:  models.LanguageCpp,
+				Compiler:  models.CompilerGCC13,
+				WerrorFor: []string{"return-type; rm -rf /"},
+			},
+			expectError: true,
+			errorMsg:    "invalid warning name",
+		},
+		{
+			name: "compile_only_not_c_family",
+			request: models.CompilationRequest{
+				Code:        base64.StdEncoding.EncodeToString([]byte("package main")),
+				Language:    models.LanguageGo,
+				Compiler:    models.CompilerGo123,
+				CompileOnly: true,
+			},
+			expectError: true,
+			errorMsg:    "compile_only is only supported for C and C++",
+		},
+		{
+			name: "compile_only_with_run",
+			request: models.CompilationRequest{
+				Code:        base64.StdEncoding.EncodeToString([]byte("int add(int a, int b) { return a + b; }")),
+				Language:    models.LanguageC,
+				Compiler:    models.CompilerGCC13,
+				CompileOnly: true,
+				Run:         boolPtr(true),
+			},
+			expectError: true,
+			errorMsg:    "compile_only produces no executable to run",
+		},
+		{
+			name: "dialect_option_not_allowlisted",
+			request: models.CompilationRequest{
+				Code:           base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language:       models.LanguageCpp,
+				Compiler:       models.CompilerGCC13,
+				DialectOptions: []string{"-fno-rtti", "-fplugin=/tmp/evil.so"},
+			},
+			expectError: true,
+			errorMsg:    "invalid dialect option",
+		},
+		{
+			name: "dialect_options_not_c_family",
+			request: models.CompilationRequest{
+				Code:           base64.StdEncoding.EncodeToString([]byte("package main")),
+				Language:       models.LanguageGo,
+				Compiler:       models.CompilerGo123,
+				DialectOptions: []string{"-fno-exceptions"},
+			},
+			expectError: true,
+			errorMsg:    "dialect options are only supported for C and C++",
+		},
+		{
+			name: "files_with_code",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Files:    map[string]string{"util.c": base64.StdEncoding.EncodeToString([]byte("int util() { return 0; }"))},
+				Language: models.LanguageC,
+			},
+			expectError: true,
+			errorMsg:    "files cannot be combined with code, archive or gist",
+		},
+		{
+			name: "flags_with_path",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
 				Language: models.LanguageCpp,
+				Compiler: models.CompilerGCC13,
+				Flags:    []string{"-Wall", "-I/etc"},
 			},
 			expectError: true,
-			errorMsg:    "too large",
+			errorMsg:    `invalid compiler flag "-I/etc": paths are not allowed`,
 		},
 		{
-			name: "alternative_cpp_syntax",
+			name: "flags_output_file",
 			request: models.CompilationRequest{
-				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
-				Language: models.LanguageCPP,
+				Code:     base64.StdEncoding.EncodeToString([]byte("fn main() {}")),
+				Language: models.LanguageRust,
+				Compiler: models.CompilerRustc180,
+				Flags:    []string{"-oevil"},
+			},
+			expectError: true,
+			errorMsg:    `invalid compiler flag "-oevil": the output file cannot be changed`,
+		},
+		{
+			name: "flags_shell_characters",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language: models.LanguageC,
 				Compiler: models.CompilerGCC13,
+				Flags:    []string{"-Wall;id"},
 			},
-			expectError: false,
+			expectError: true,
+			errorMsg:    `invalid compiler flag "-Wall;id": not an allowed flag for c`,
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			err := compiler.validateRequest(tc.request)
-
-			if tc.expectError {
-				require.Error(t, err, "Expected validation error")
-				assert.Contains(t, err.Error(), tc.errorMsg)
-			} else {
-				assert.NoError(t, err, "Expected no validation error")
-			}
-		})
-	}
-}
-
-// TestSelectEnvironment tests environment selection.
-func TestSelectEnvironment(t *testing.T) {
-	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
-
-	testCases := []struct {
-		name             string
-		request          models.CompilationRequest
-		expectError      bool
-		expectedImageTag string
-		expectedStandard string
-	}{
 		{
-			name: "default_cpp_environment",
+			name: "flags_linker_passthrough",
 			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
 				Language: models.LanguageCpp,
 				Compiler: models.CompilerGCC13,
+				Flags:    []string{"-Wl,-o,evil"},
 			},
-			expectError:      false,
-			expectedImageTag: "gcc:13",
-			expectedStandard: string(models.StandardCpp20),
+			expectError: true,
+			errorMsg:    "not an allowed flag",
 		},
 		{
-			name: "cpp_with_custom_standard",
+			name: "flags_assembler_passthrough",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language: models.LanguageC,
+				Compiler: models.CompilerGCC13,
+				Flags:    []string{"-Wa,-adhln"},
+			},
+			expectError: true,
+			errorMsg:    "not an allowed flag",
+		},
+		{
+			name: "flags_search_prefix",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language: models.LanguageC,
+				Compiler: models.CompilerClang18,
+				Flags:    []string{"-Bprefix"},
+			},
+			expectError: true,
+			errorMsg:    "not an allowed flag",
+		},
+		{
+			name: "flags_specs_file",
 			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
 				Language: models.LanguageCpp,
 				Compiler: models.CompilerGCC13,
-				Standard: models.StandardCpp17,
+				Flags:    []string{"-specs=evil"},
 			},
-			expectError:      false,
-			expectedImageTag: "gcc:13",
-			expectedStandard: string(models.StandardCpp17),
+			expectError: true,
+			errorMsg:    "not an allowed flag",
 		},
 		{
-			name: "alternative_cpp_syntax",
+			name: "flags_compiler_plugin",
 			request: models.CompilationRequest{
-				Language: models.LanguageCPP,
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language: models.LanguageCpp,
 				Compiler: models.CompilerGCC13,
+				Flags:    []string{"-fplugin=evil.so"},
 			},
-			expectError:      false,
-			expectedImageTag: "gcc:13",
+			expectError: true,
+			errorMsg:    "not an allowed flag",
 		},
 		{
-			name: "unsupported_compiler",
+			name: "flags_go_toolexec",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("package main")),
+				Language: models.LanguageGo,
+				Compiler: models.CompilerGo123,
+				Flags:    []string{"-toolexec=evil"},
+			},
+			expectError: true,
+			errorMsg:    "not an allowed flag",
+		},
+		{
+			name: "flags_rust_codegen",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("fn main() {}")),
+				Language: models.LanguageRust,
+				Compiler: models.CompilerRustc180,
+				Flags:    []string{"-Clinker=evil"},
+			},
+			expectError: true,
+			errorMsg:    "not an allowed flag",
+		},
+		{
+			name: "flags_not_supported",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language: models.LanguageObjC,
+				Flags:    []string{"-Wall"},
+			},
+			expectError: true,
+			errorMsg:    "flags are only supported for C, C++, Go and Rust",
+		},
+		{
+			name: "test_not_supported",
 			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
 				Language: models.LanguageCpp,
-				Compiler: "clang-15", // Not in hardcoded environments
+				Test:     true,
 			},
 			expectError: true,
+			errorMsg:    "test mode is only supported for Go and Rust",
 		},
 		{
-			name: "missing_compiler_uses_default",
+			name: "test_with_run",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("package main")),
+				Language: models.LanguageGo,
+				Test:     true,
+				Run:      boolPtr(true),
+			},
+			expectError: true,
+			errorMsg:    "test mode cannot be combined with run",
+		},
+		{
+			name: "quick_not_c_family",
 			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("fn main() {}")),
+				Language: models.LanguageRust,
+				Quick:    true,
+			},
+			expectError: true,
+			errorMsg:    "quick mode is only supported for C and C++",
+		},
+		{
+			name: "quick_with_run",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
 				Language: models.LanguageCpp,
+				Quick:    true,
+				Run:      boolPtr(true),
 			},
-			expectError:      false,
-			expectedImageTag: "gcc:13",
+			expectError: true,
+			errorMsg:    "quick mode cannot be combined with run, test or compile_only",
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			env, err := compiler.selectEnvironment(tc.request)
-
-			if tc.expectError {
-				require.Error(t, err, "Expected environment selection error")
-			} else {
-				require.NoError(t, err, "Expected no environment selection error")
-				assert.Equal(t, tc.expectedImageTag, env.ImageTag)
-				if tc.expectedStandard != "" {
-					assert.Equal(t, tc.expectedStandard, string(env.Standard))
-				}
-			}
-		})
-	}
-}
-
-// TestGetSupportedEnvironments tests the environments list.
-func TestGetSupportedEnvironments(t *testing.T) {
-	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+		{
+			name: "deterministic_swift",
+			request: models.CompilationRequest{
+				Code:          base64.StdEncoding.EncodeToString([]byte("print(\"hello\")")),
+				Language:      models.LanguageSwift,
+				Deterministic: true,
+			},
+			expectError: true,
+			errorMsg:    "deterministic builds are only supported for C, C++, Go and Rust",
+		},
+		{
+			name: "header_only_not_c_family",
+			request: models.CompilationRequest{
+				Code:       base64.StdEncoding.EncodeToString([]byte("package util")),
+				Language:   models.LanguageGo,
+				HeaderOnly: true,
+			},
+			expectError: true,
+			errorMsg:    "header_only is only supported for C and C++",
+		},
+		{
+			name: "header_only_with_gist",
+			request: models.CompilationRequest{
+				Gist:       "octocat/aa5a315d61ae9438b18d",
+				Language:   models.LanguageCpp,
+				HeaderOnly: true,
+			},
+			expectError: true,
+			errorMsg:    "header_only takes its headers from code or files",
+		},
+		{
+			name: "header_only_with_compile_only",
+			request: models.CompilationRequest{
+				Code:        base64.StdEncoding.EncodeToString([]byte("int util();")),
+				Language:    models.LanguageC,
+				HeaderOnly:  true,
+				CompileOnly: true,
+			},
+			expectError: true,
+			errorMsg:    "header_only cannot be combined with run, test, compile_only or quick",
+		},
+		{
+			name: "invalid_libc",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("package main\nfunc main() {}")),
+				Language: models.LanguageGo,
+				Libc:     "uclibc",
+			},
+			expectError: true,
+			errorMsg:    "invalid libc",
+		},
+		{
+			name: "preprocess_not_c_family",
+			request: models.CompilationRequest{
+				Code:       base64.StdEncoding.EncodeToString([]byte("package main\nfunc main() {}")),
+				Language:   models.LanguageGo,
+				Preprocess: true,
+			},
+			expectError: true,
+			errorMsg:    "preprocess is only supported for C and C++",
+		},
+		{
+			name: "gist_with_code",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Gist:     "octocat/aa5a315d61ae9438b18d",
+				Language: models.LanguageCpp,
+			},
+			expectError: true,
+			errorMsg:    "gist cannot be combined with code or archive",
+		},
+		{
+			name: "gist_url",
+			request: models.CompilationRequest{
+				Gist:     "https://gist.github.com/octocat/aa5a315d61ae9438b18d/",
+				Language: models.LanguageCpp,
+			},
+			expectError: false,
+		},
+		{
+			name: "gist_url_other_host",
+			request: models.CompilationRequest{
+				Gist:     "https://169.254.169.254/octocat/aa5a315d61ae9438b18d",
+				Language: models.LanguageCpp,
+			},
+			expectError: true,
+			errorMsg:    "invalid gist reference",
+		},
+		{
+			name: "gist_id_not_hex",
+			request: models.CompilationRequest{
+				Gist:     "octocat/../../repos",
+				Language: models.LanguageCpp,
+			},
+			expectError: true,
+			errorMsg:    "invalid gist reference",
+		},
+		{
+			name: "swift_without_environment",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("print(\"hello\")")),
+				Language: models.LanguageSwift,
+				Compiler: models.CompilerSwiftc510,
+			},
+			expectError: true,
+			errorMsg:    "unsupported language",
+		},
+		{
+			name: "kotlin_without_environment",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("fun main() {}")),
+				Language: models.LanguageKotlin,
+				Compiler: models.CompilerKotlinc20,
+			},
+			expectError: true,
+			errorMsg:    "unsupported language",
+		},
+		{
+			name: "objc_without_environment",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language: models.LanguageObjC,
+				Compiler: models.CompilerClang18,
+			},
+			expectError: true,
+			errorMsg:    "unsupported language",
+		},
+		{
+			name: "code_too_large",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString(make([]byte, 2*1024*1024)),
+				Language: models.LanguageCpp,
+			},
+			expectError: true,
+			errorMsg:    "too large",
+		},
+		{
+			name: "alternative_cpp_syntax",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Language: models.LanguageCPP,
+				Compiler: models.CompilerGCC13,
+			},
+			expectError: false,
+		},
+		{
+			name: "allowed_linker",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Language: models.LanguageC,
+				Compiler: models.CompilerGCC13,
+				Linker:   models.LinkerLLD,
+			},
+			expectError: false,
+		},
+		{
+			name: "linker_not_in_allowlist",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerGCC13,
+				Linker:   "/tmp/evil-ld",
+			},
+			expectError: true,
+			errorMsg:    "invalid linker",
+		},
+		{
+			name: "linker_for_unsupported_language",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("package main")),
+				Language: models.LanguageGo,
+				Compiler: models.CompilerGo123,
+				Linker:   models.LinkerGold,
+			},
+			expectError: true,
+			errorMsg:    "only supported for C and C++",
+		},
+		{
+			name: "diagnostics_width_too_small",
+			request: models.CompilationRequest{
+				Code:             base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Language:         models.LanguageCpp,
+				Compiler:         models.CompilerGCC13,
+				DiagnosticsWidth: 5,
+			},
+			expectError: true,
+			errorMsg:    "invalid diagnostics width",
+		},
+		{
+			name: "diagnostics_width_too_large",
+			request: models.CompilationRequest{
+				Code:             base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Language:         models.LanguageCpp,
+				Compiler:         models.CompilerGCC13,
+				DiagnosticsWidth: 10000,
+			},
+			expectError: true,
+			errorMsg:    "invalid diagnostics width",
+		},
+		{
+			name: "zig_with_target",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerZig,
+				Target:   "aarch64-linux-musl",
+			},
+			expectError: false,
+		},
+		{
+			name: "target_with_gcc",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerGCC13,
+				Target:   "aarch64-linux-musl",
+			},
+			expectError: true,
+			errorMsg:    "only supported with the zig compiler",
+		},
+		{
+			name: "malformed_target",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+				Language: models.LanguageC,
+				Compiler: models.CompilerZig,
+				Target:   "x86_64; rm -rf /",
+			},
+			expectError: true,
+			errorMsg:    "invalid target",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := compiler.validateRequest(tc.request)
+
+			if tc.expectError {
+				require.Error(t, err, "Expected validation error")
+				assert.Contains(t, err.Error(), tc.errorMsg)
+			} else {
+				assert.NoError(t, err, "Expected no validation error")
+			}
+		})
+	}
+}
+
+// TestSelectEnvironment tests environment selection.
+func TestSelectEnvironment(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name             string
+		request          models.CompilationRequest
+		expectError      bool
+		expectedImageTag string
+		expectedStandard string
+	}{
+		{
+			name: "default_cpp_environment",
+			request: models.CompilationRequest{
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerGCC13,
+			},
+			expectError:      false,
+			expectedImageTag: "gcc:13",
+			expectedStandard: string(models.StandardCpp20),
+		},
+		{
+			name: "cpp_with_custom_standard",
+			request: models.CompilationRequest{
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerGCC13,
+				Standard: models.StandardCpp17,
+			},
+			expectError:      false,
+			expectedImageTag: "gcc:13",
+			expectedStandard: string(models.StandardCpp17),
+		},
+		{
+			name: "alternative_cpp_syntax",
+			request: models.CompilationRequest{
+				Language: models.LanguageCPP,
+				Compiler: models.CompilerGCC13,
+			},
+			expectError:      false,
+			expectedImageTag: "gcc:13",
+		},
+		{
+			name: "unsupported_compiler",
+			request: models.CompilationRequest{
+				Language: models.LanguageCpp,
+				Compiler: "clang-15", // Not in hardcoded environments
+			},
+			expectError: true,
+		},
+		{
+			name: "missing_compiler_uses_default",
+			request: models.CompilationRequest{
+				Language: models.LanguageCpp,
+			},
+			expectError:      false,
+			expectedImageTag: "gcc:13",
+		},
+		{
+			name: "zig_c_environment",
+			request: models.CompilationRequest{
+				Language: models.LanguageC,
+				Compiler: models.CompilerZig,
+			},
+			expectError:      false,
+			expectedImageTag: "euantorano/zig:0.13.0",
+			expectedStandard: string(models.StandardC17),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := compiler.selectEnvironment(tc.request)
+
+			if tc.expectError {
+				require.Error(t, err, "Expected environment selection error")
+			} else {
+				require.NoError(t, err, "Expected no environment selection error")
+				assert.Equal(t, tc.expectedImageTag, env.ImageTag)
+				if tc.expectedStandard != "" {
+					assert.Equal(t, tc.expectedStandard, string(env.Standard))
+				}
+			}
+		})
+	}
+}
+
+// TestSelectEnvironment_Linker tests that the linker option becomes a -fuse-ld flag.
+func TestSelectEnvironment_Linker(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name     string
+		language models.Language
+		linker   models.Linker
+		expected string
+	}{
+		{"cpp_lld", models.LanguageCpp, models.LinkerLLD, "g++ -std=c++20 -fuse-ld=lld /workspace/source.cpp -o /workspace/output"},
+		{"c_gold", models.LanguageC, models.LinkerGold, "gcc -std=c17 -fuse-ld=gold /workspace/source.c -o /workspace/output"},
+		{"cpp_default_linker", models.LanguageCpp, "", "g++ -std=c++20 /workspace/source.cpp -o /workspace/output"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := compiler.selectEnvironment(models.CompilationRequest{
+				Language: tc.language,
+				Compiler: models.CompilerGCC13,
+				Linker:   tc.linker,
+			})
+			require.NoError(t, err)
+
+			cmd := compiler.buildCompileCommand(env, compiler.getSourceFilename(tc.language))
+			assert.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+// TestSelectEnvironment_ZigTarget tests that the target option becomes a -target flag for zig.
+func TestSelectEnvironment_ZigTarget(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	env, err := compiler.selectEnvironment(models.CompilationRequest{
+		Language:          models.LanguageCpp,
+		Compiler:          models.CompilerZig,
+		Target:            "x86_64-windows-gnu",
+		DiagnosticsFormat: models.DiagnosticsFormatJSON,
+	})
+	require.NoError(t, err)
+
+	cmd := compiler.buildCompileCommand(env, "source.cpp")
+	assert.Equal(t, "zig c++ -std=c++20 -target x86_64-windows-gnu /workspace/source.cpp -o /workspace/output", cmd)
+	assert.NotContains(t, cmd, "-fdiagnostics-format=json", "zig c++ is clang-based and has no JSON diagnostics")
+}
+
+// TestSelectEnvironment_Clang tests that a clang request runs clang, not g++, without GCC's JSON diagnostics flag.
+func TestSelectEnvironment_Clang(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	for language, expected := range map[models.Language]string{
+		models.LanguageCpp: "clang++ -std=c++20 /workspace/source.cpp -o /workspace/output",
+		models.LanguageC:   "clang -std=c17 /workspace/source.c -o /workspace/output",
+	} {
+		env, err := compiler.selectEnvironment(models.CompilationRequest{
+			Language:          language,
+			Compiler:          models.CompilerClang16,
+			DiagnosticsFormat: models.DiagnosticsFormatJSON,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "silkeh/clang:16", env.ImageTag)
+		assert.False(t, env.Capabilities.JSONDiagnostics)
+
+		cmd := compiler.buildCompileCommand(env, compiler.getSourceFilename(language))
+		assert.Equal(t, expected, cmd)
+	}
+}
+
+// TestSelectEnvironment_DiagnosticsWidth tests that the diagnostics width becomes -fmessage-length for C/C++ only.
+func TestSelectEnvironment_DiagnosticsWidth(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name     string
+		language models.Language
+		compiler models.Compiler
+		expected string
+	}{
+		{"cpp", models.LanguageCpp, models.CompilerGCC13, "g++ -std=c++20 -fmessage-length=80 /workspace/source.cpp -o /workspace/output"},
+		{"c", models.LanguageC, models.CompilerGCC13, "gcc -std=c17 -fmessage-length=80 /workspace/source.c -o /workspace/output"},
+		{"go_ignored", models.LanguageGo, models.CompilerGo123, "go build -o /workspace/output /workspace/main.go"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := compiler.selectEnvironment(models.CompilationRequest{
+				Language:         tc.language,
+				Compiler:         tc.compiler,
+				DiagnosticsWidth: 80,
+			})
+			require.NoError(t, err)
+
+			cmd := compiler.buildCompileCommand(env, compiler.getSourceFilename(tc.language))
+			assert.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+// TestSelectEnvironment_WerrorFor tests that selected warnings are promoted to errors one flag each.
+func TestSelectEnvironment_WerrorFor(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name      string
+		language  models.Language
+		compiler  models.Compiler
+		werrorFor []string
+		expected  string
+	}{
+		{"cpp_single", models.LanguageCpp, models.CompilerGCC13, []string{"return-type"},
+			"g++ -std=c++20 -Werror=return-type /workspace/source.cpp -o /workspace/output"},
+		{"c_multiple", models.LanguageC, models.CompilerGCC13, []string{"implicit-function-declaration", "format-security"},
+			"gcc -std=c17 -Werror=implicit-function-declaration -Werror=format-security /workspace/source.c -o /workspace/output"},
+		{"go_ignored", models.LanguageGo, models.CompilerGo123, []string{"unused"},
+			"go build -o /workspace/output /workspace/main.go"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := compiler.selectEnvironment(models.CompilationRequest{
+				Language:  tc.language,
+				Compiler:  tc.compiler,
+				WerrorFor: tc.werrorFor,
+			})
+			require.NoError(t, err)
+
+			cmd := compiler.buildCompileCommand(env, compiler.getSourceFilename(tc.language))
+			assert.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+// TestSelectEnvironment_DialectOptions tests that allowlisted dialect flags are added in order.
+func TestSelectEnvironment_DialectOptions(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	env, err := compiler.selectEnvironment(models.CompilationRequest{
+		Language:       models.LanguageCpp,
+		Compiler:       models.CompilerGCC13,
+		DialectOptions: []string{"-fno-exceptions", "-fno-rtti", "-ffreestanding"},
+	})
+	require.NoError(t, err)
+
+	cmd := compiler.buildCompileCommand(env, compiler.getSourceFilename(models.LanguageCpp))
+	assert.Equal(t, "g++ -std=c++20 -fno-exceptions -fno-rtti -ffreestanding /workspace/source.cpp -o /workspace/output", cmd)
+
+	// Every allowlisted option passes validation
+	req := models.CompilationRequest{
+		Code:           base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+		Language:       models.LanguageC,
+		DialectOptions: models.AllowedDialectOptions,
+	}
+	assert.NoError(t, req.Validate())
+}
+
+// TestSelectEnvironment_Flags tests that request flags are added after the service's own, for each language.
+func TestSelectEnvironment_Flags(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name     string
+		language models.Language
+		compiler models.Compiler
+		flags    []string
+		expected string
+	}{
+		{"cpp", models.LanguageCpp, models.CompilerGCC13, []string{"-Wall", "-Wextra", "-Werror"},
+			"g++ -std=c++20 -O2 -DNDEBUG -Wall -Wextra -Werror /workspace/source.cpp -o /workspace/output"},
+		{"c", models.LanguageC, models.CompilerGCC13, []string{"-Wformat=2", "-Irelative"},
+			"gcc -std=c17 -O2 -DNDEBUG -Wformat=2 -Irelative /workspace/source.c -o /workspace/output"},
+		{"rust", models.LanguageRust, models.CompilerRustc180, []string{"-Dwarnings"},
+			"rustc -C opt-level=3 -Dwarnings /workspace/main.rs -o /workspace/output"},
+		{"go", models.LanguageGo, models.CompilerGo123, []string{"-race"},
+			"go build -trimpath -ldflags='-s -w' -race -o /workspace/output /workspace/main.go"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("source")),
+				Language: tc.language,
+				Compiler: tc.compiler,
+				Release:  true,
+				Flags:    tc.flags,
+			}
+			require.NoError(t, req.Validate())
+
+			env, err := compiler.selectEnvironment(req)
+			require.NoError(t, err)
+
+			cmd := compiler.buildCompileCommand(env, compiler.getSourceFilename(tc.language))
+			assert.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+// TestSelectEnvironment_Release tests the per-language translation of release builds.
+func TestSelectEnvironment_Release(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name     string
+		language models.Language
+		compiler models.Compiler
+		expected string
+	}{
+		{"cpp", models.LanguageCpp, models.CompilerGCC13, "g++ -std=c++20 -O2 -DNDEBUG /workspace/source.cpp -o /workspace/output"},
+		{"c_zig", models.LanguageC, models.CompilerZig, "zig cc -std=c17 -O2 -DNDEBUG /workspace/source.c -o /workspace/output"},
+		{"rust", models.LanguageRust, models.CompilerRustc180, "rustc -C opt-level=3 /workspace/main.rs -o /workspace/output"},
+		{"go", models.LanguageGo, models.CompilerGo123, "go build -trimpath -ldflags='-s -w' -o /workspace/output /workspace/main.go"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := compiler.selectEnvironment(models.CompilationRequest{
+				Language: tc.language,
+				Compiler: tc.compiler,
+				Release:  true,
+			})
+			require.NoError(t, err)
+
+			cmd := compiler.buildCompileCommand(env, compiler.getSourceFilename(tc.language))
+			assert.Equal(t, tc.expected, cmd)
+		})
+	}
+
+	// Debug builds (the default) add no flags
+	env, err := compiler.selectEnvironment(models.CompilationRequest{Language: models.LanguageRust, Compiler: models.CompilerRustc180})
+	require.NoError(t, err)
+	assert.Empty(t, env.Flags)
+}
+
+// TestSelectEnvironment_Deterministic tests that deterministic builds fix the random seed and strip the workspace path.
+func TestSelectEnvironment_Deterministic(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name     string
+		language models.Language
+		compiler models.Compiler
+		release  bool
+		expected string
+	}{
+		{"cpp", models.LanguageCpp, models.CompilerGCC13, false,
+			"g++ -std=c++20 -ffile-prefix-map=/workspace=. -frandom-seed=will-it-compile /workspace/source.cpp -o /workspace/output"},
+		{"c_clang", models.LanguageC, models.CompilerClang16, false,
+			"clang -std=c17 -ffile-prefix-map=/workspace=. /workspace/source.c -o /workspace/output"},
+		{"rust", models.LanguageRust, models.CompilerRustc180, false,
+			"rustc --remap-path-prefix=/workspace=. /workspace/main.rs -o /workspace/output"},
+		{"go", models.LanguageGo, models.CompilerGo123, false,
+			"go build -trimpath -o /workspace/output /workspace/main.go"},
+		{"go_release", models.LanguageGo, models.CompilerGo123, true,
+			"go build -trimpath -ldflags='-s -w' -o /workspace/output /workspace/main.go"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := compiler.selectEnvironment(models.CompilationRequest{
+				Language:      tc.language,
+				Compiler:      tc.compiler,
+				Release:       tc.release,
+				Deterministic: true,
+			})
+			require.NoError(t, err)
+
+			cmd := compiler.buildCompileCommand(env, compiler.getSourceFilename(tc.language))
+			assert.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+// TestCompile_DeterministicOutputHash tests that deterministic builds fix SOURCE_DATE_EPOCH, so a
+// compiler embedding the build time produces the same binary on every run.
+func TestCompile_DeterministicOutputHash(t *testing.T) {
+	// Embeds the build time unless SOURCE_DATE_EPOCH is set, and a random seed unless one is given
+	builds := 0
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			builds++
+			binary := config.SourceCode
+			if !slices.Contains(config.Env, "SOURCE_DATE_EPOCH=0") {
+				binary += fmt.Sprintf("built at %d", builds)
+			}
+			if !strings.Contains(config.CompileCommand, "-frandom-seed=") {
+				binary += fmt.Sprintf("seed %d", builds)
+			}
+			sum := sha256.Sum256([]byte(binary))
+			return &runtime.CompilationOutput{ExitCode: 0, BinarySHA256: hex.EncodeToString(sum[:])}, nil
+		},
+	}
+
+	// A fresh compiler per run, as the result cache would otherwise serve the second one
+	outputHash := func(deterministic bool) string {
+		result := NewCompilerWithRuntime(mockRuntime).Compile(context.Background(), models.CompilationJob{
+			ID: "test-deterministic",
+			Request: models.CompilationRequest{
+				Code:          base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language:      models.LanguageCpp,
+				Compiler:      models.CompilerGCC13,
+				Deterministic: deterministic,
+			},
+		})
+		require.Empty(t, result.Error)
+		require.NotEmpty(t, result.OutputHash)
+		return result.OutputHash
+	}
+
+	assert.NotEqual(t, outputHash(false), outputHash(false))
+	assert.Equal(t, outputHash(true), outputHash(true))
+}
+
+// TestSelectEnvironment_MinCompilerVersion tests that compilers below the configured minimum are rejected.
+func TestSelectEnvironment_MinCompilerVersion(t *testing.T) {
+	config := Config{
+		Environments: []EnvironmentConfig{
+			{
+				Language: "cpp",
+				Compilers: []CompilerConfig{
+					{Name: "gcc", Version: "9", Image: "gcc:9"},
+					{Name: "gcc", Version: "11", Image: "gcc:11"},
+					{Name: "gcc", Version: "13", Image: "gcc:13"},
+				},
+			},
+			{
+				Language: "rust",
+				Compilers: []CompilerConfig{
+					{Name: "rustc", Version: "1.70", Image: "rust:1.70"},
+					{Name: "rustc", Version: "1.80", Image: "rust:1.80"},
+				},
+			},
+		},
+		MinCompilerVersions: map[string]string{"gcc": "11", "rustc": "1.75"},
+	}
+	environments, err := config.ToEnvironmentSpecs()
+	require.NoError(t, err)
+
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+	compiler.environments = environments
+	compiler.minVersions = config.MinCompilerVersions
+
+	testCases := []struct {
+		name     string
+		language models.Language
+		compiler models.Compiler
+		allowed  bool
+	}{
+		{"gcc_below_minimum", models.LanguageCpp, models.CompilerGCC9, false},
+		{"gcc_at_minimum", models.LanguageCpp, models.CompilerGCC11, true},
+		{"gcc_above_minimum", models.LanguageCpp, models.CompilerGCC13, true},
+		{"rustc_below_minimum", models.LanguageRust, models.CompilerRustc170, false},
+		{"rustc_above_minimum", models.LanguageRust, models.CompilerRustc180, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := compiler.selectEnvironment(models.CompilationRequest{Language: tc.language, Compiler: tc.compiler})
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrCompilerTooOld)
+				assert.Contains(t, err.Error(), string(tc.compiler))
+			}
+		})
+	}
+}
+
+// TestGetSupportedEnvironments tests the environments list.
+func TestGetSupportedEnvironments(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	environments := compiler.GetSupportedEnvironments()
+
+	require.NotEmpty(t, environments, "Expected at least one environment")
+
+	// Check for C++ environment
+	found := false
+	for _, env := range environments {
+		if env.Language == "cpp" {
+			found = true
+			assert.Contains(t, env.Compilers, "gcc-13")
+			assert.Contains(t, env.Standards, "c++20")
+			assert.Contains(t, env.OSes, "linux")
+			assert.Contains(t, env.Arches, "x86_64")
+			assert.True(t, env.Capabilities["gcc-13"].Run)
+			assert.False(t, env.Capabilities["gcc-13"].CrossCompile)
+			assert.True(t, env.Capabilities["zig-0.13"].CrossCompile)
+			break
+		}
+	}
+
+	assert.True(t, found, "Expected C++ environment in list")
+}
+
+// TestCompile_InvalidBase64 tests invalid base64 encoding.
+func TestCompile_InvalidBase64(t *testing.T) {
+	mockRuntime := &runtime.MockRuntime{}
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	job := models.CompilationJob{
+		ID: "test-job-5",
+		Request: models.CompilationRequest{
+			Code:     "not-valid-base64!@#$",
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.False(t, result.Success, "Expected compilation to fail")
+	assert.Contains(t, result.Error, "invalid base64")
+}
+
+// TestCompile_VerifyRuntimeConfig tests that correct config is passed to runtime.
+func TestCompile_VerifyRuntimeConfig(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				Stdout:   "OK",
+				ExitCode: 0,
+				Duration: time.Second,
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	sourceCode := `int main() { return 0; }`
+	encodedCode := base64.StdEncoding.EncodeToString([]byte(sourceCode))
+
+	job := models.CompilationJob{
+		ID: "test-job-6",
+		Request: models.CompilationRequest{
+			Code:     encodedCode,
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+			Standard: models.StandardCpp17,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.True(t, result.Success)
+	assert.Equal(t, "gcc:13", capturedConfig.ImageTag)
+	assert.Equal(t, sourceCode, capturedConfig.SourceCode)
+	assert.Equal(t, "source.cpp", capturedConfig.SourceFilename)
+	assert.Equal(t, "/workspace", capturedConfig.WorkDir)
+	assert.Contains(t, capturedConfig.Env, "STANDARD=c++17")
+	assert.Contains(t, capturedConfig.Env, "SOURCE_FILE=/workspace/source.cpp")
+	assert.Equal(t, job.ID, capturedConfig.JobID)
+	assert.Equal(t, 30*time.Second, capturedConfig.Timeout)
+	assert.Empty(t, capturedConfig.RunCommand, "Run mode should be off by default")
+}
+
+// TestCompile_CLanguage tests C language compilation.
+func TestCompile_CLanguage(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				Stdout:   "C compilation successful",
+				ExitCode: 0,
+				Duration: time.Second,
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	sourceCode := `#include <stdio.h>
+int main() { printf("Hello, C!\\n"); return 0; }`
+	encodedCode := base64.StdEncoding.EncodeToString([]byte(sourceCode))
+
+	job := models.CompilationJob{
+		ID: "test-c-job",
+		Request: models.CompilationRequest{
+			Code:     encodedCode,
+			Language: models.LanguageC,
+			Compiler: models.CompilerGCC13,
+			Standard: models.StandardC17,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.True(t, result.Success, "Expected C compilation to succeed")
+	assert.True(t, result.Compiled, "Expected C code to compile")
+	assert.Equal(t, 0, result.ExitCode)
+
+	// Verify C-specific configuration
+	assert.Equal(t, "source.c", capturedConfig.SourceFilename, "Expected C source filename")
+	assert.Contains(t, capturedConfig.Env, "STANDARD=c17", "Expected C17 standard")
+	assert.Contains(t, capturedConfig.Env, "SOURCE_FILE=/workspace/source.c", "Expected C source file path")
+	assert.Contains(t, capturedConfig.CompileCommand, "source.c", "Expected compile command to use source.c")
+	assert.Contains(t, capturedConfig.CompileCommand, "c17", "Expected compile command to use c17 standard")
+}
+
+// TestCompile_GoLanguage tests Go language compilation.
+func TestCompile_GoLanguage(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				Stdout:   "Go compilation successful",
+				ExitCode: 0,
+				Duration: time.Second,
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	sourceCode := `package main
+import "fmt"
+func main() { fmt.Println("Hello, Go!") }`
+	encodedCode := base64.StdEncoding.EncodeToString([]byte(sourceCode))
+
+	job := models.CompilationJob{
+		ID: "test-go-job",
+		Request: models.CompilationRequest{
+			Code:     encodedCode,
+			Language: models.LanguageGo,
+			Compiler: models.CompilerGo123,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.True(t, result.Success, "Expected Go compilation to succeed")
+	assert.True(t, result.Compiled, "Expected Go code to compile")
+	assert.Equal(t, 0, result.ExitCode)
+
+	// Verify Go-specific configuration
+	assert.Equal(t, "main.go", capturedConfig.SourceFilename, "Expected Go source filename")
+	assert.Contains(t, capturedConfig.Env, "SOURCE_FILE=/workspace/main.go", "Expected Go source file path")
+	assert.Contains(t, capturedConfig.CompileCommand, "go build", "Expected Go build command")
+	assert.Contains(t, capturedConfig.CompileCommand, "main.go", "Expected compile command to use main.go")
+}
+
+// TestCompile_RustLanguage tests Rust language compilation.
+func TestCompile_RustLanguage(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				Stdout:   "Rust compilation successful",
+				ExitCode: 0,
+				Duration: time.Second,
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	sourceCode := `fn main() { println!("Hello, Rust!"); }`
+	encodedCode := base64.StdEncoding.EncodeToString([]byte(sourceCode))
+
+	job := models.CompilationJob{
+		ID: "test-rust-job",
+		Request: models.CompilationRequest{
+			Code:     encodedCode,
+			Language: models.LanguageRust,
+			Compiler: models.CompilerRustc180,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.True(t, result.Success, "Expected Rust compilation to succeed")
+	assert.True(t, result.Compiled, "Expected Rust code to compile")
+	assert.Equal(t, 0, result.ExitCode)
+
+	// Verify Rust-specific configuration
+	assert.Equal(t, "main.rs", capturedConfig.SourceFilename, "Expected Rust source filename")
+	assert.Contains(t, capturedConfig.Env, "SOURCE_FILE=/workspace/main.rs", "Expected Rust source file path")
+	assert.Contains(t, capturedConfig.CompileCommand, "rustc", "Expected rustc command")
+	assert.Contains(t, capturedConfig.CompileCommand, "main.rs", "Expected compile command to use main.rs")
+}
+
+// TestGetSourceFilename tests the source filename mapping for all languages.
+func TestGetSourceFilename(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		language         models.Language
+		expectedFilename string
+	}{
+		{models.LanguageC, "source.c"},
+		{models.LanguageCpp, "source.cpp"},
+		{models.LanguageCPP, "source.cpp"}, // Alternative syntax
+		{models.LanguageGo, "main.go"},
+		{models.LanguageRust, "main.rs"},
+		{models.LanguageObjC, "main.m"},
+		{models.LanguageObjCpp, "main.mm"},
+		{models.LanguageSwift, "main.swift"},
+		{models.LanguageKotlin, "Main.kt"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.language), func(t *testing.T) {
+			filename := compiler.getSourceFilename(tc.language)
+			assert.Equal(t, tc.expectedFilename, filename,
+				"Expected %s to map to %s", tc.language, tc.expectedFilename)
+		})
+	}
+}
+
+// TestBuildCompileCommand tests compile command generation for all languages.
+func TestBuildCompileCommand(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name            string
+		envSpec         models.EnvironmentSpec
+		sourceFilename  string
+		expectedCommand string
+		shouldContain   []string
+	}{
+		{
+			name: "cpp_with_standard",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageCpp,
+				Standard: models.StandardCpp20,
+			},
+			sourceFilename:  "source.cpp",
+			expectedCommand: "g++ -std=c++20 /workspace/source.cpp -o /workspace/output",
+			shouldContain:   []string{"g++", "-std=c++20", "source.cpp"},
+		},
+		{
+			name: "c_with_standard",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageC,
+				Standard: models.StandardC11,
+			},
+			sourceFilename:  "source.c",
+			expectedCommand: "gcc -std=c11 /workspace/source.c -o /workspace/output",
+			shouldContain:   []string{"gcc", "-std=c11", "source.c"},
+		},
+		{
+			name: "go_language",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageGo,
+			},
+			sourceFilename:  "main.go",
+			expectedCommand: "go build -o /workspace/output /workspace/main.go",
+			shouldContain:   []string{"go build", "main.go"},
+		},
+		{
+			name: "rust_language",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageRust,
+			},
+			sourceFilename:  "main.rs",
+			expectedCommand: "rustc /workspace/main.rs -o /workspace/output",
+			shouldContain:   []string{"rustc", "main.rs"},
+		},
+		{
+			name: "cpp_with_extra_flags",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageCpp,
+				Standard: models.StandardCpp17,
+				Flags:    []string{"-fdiagnostics-format=json"},
+			},
+			sourceFilename:  "source.cpp",
+			expectedCommand: "g++ -std=c++17 -fdiagnostics-format=json /workspace/source.cpp -o /workspace/output",
+			shouldContain:   []string{"-fdiagnostics-format=json"},
+		},
+		{
+			name: "cpp_zig",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerZig,
+				Standard: models.StandardCpp20,
+			},
+			sourceFilename:  "source.cpp",
+			expectedCommand: "zig c++ -std=c++20 /workspace/source.cpp -o /workspace/output",
+			shouldContain:   []string{"zig c++"},
+		},
+		{
+			name: "cpp_clang",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerClang16,
+				Standard: models.StandardCpp17,
+			},
+			sourceFilename:  "source.cpp",
+			expectedCommand: "clang++ -std=c++17 /workspace/source.cpp -o /workspace/output",
+			shouldContain:   []string{"clang++"},
+		},
+		{
+			name: "c_clang",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageC,
+				Compiler: models.CompilerClang15,
+				Standard: models.StandardC11,
+			},
+			sourceFilename:  "source.c",
+			expectedCommand: "clang -std=c11 /workspace/source.c -o /workspace/output",
+			shouldContain:   []string{"clang -std=c11"},
+		},
+		{
+			name: "c_zig_with_target",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageC,
+				Compiler: models.CompilerZig,
+				Standard: models.StandardC11,
+				Flags:    []string{"-target", "aarch64-linux-musl"},
+			},
+			sourceFilename:  "source.c",
+			expectedCommand: "zig cc -std=c11 -target aarch64-linux-musl /workspace/source.c -o /workspace/output",
+			shouldContain:   []string{"zig cc", "-target aarch64-linux-musl"},
+		},
+		{
+			name: "objc_linux",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageObjC,
+				Compiler: models.CompilerClang18,
+				Standard: models.StandardC17,
+				OS:       models.OSLinux,
+			},
+			sourceFilename:  "main.m",
+			expectedCommand: "clang -std=c17 /workspace/main.m -o /workspace/output -fobjc-runtime=gnustep-2.0 -lobjc",
+			shouldContain:   []string{"clang", "main.m", "-lobjc"},
+		},
+		{
+			name: "objcpp_macos",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageObjCpp,
+				Compiler: models.CompilerClang18,
+				Standard: models.StandardCpp20,
+				OS:       models.OSMacOS,
+			},
+			sourceFilename:  "main.mm",
+			expectedCommand: "clang++ -std=c++20 /workspace/main.mm -o /workspace/output -framework Foundation",
+			shouldContain:   []string{"clang++", "main.mm", "-framework Foundation"},
+		},
+		{
+			name: "swift_language",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageSwift,
+				Compiler: models.CompilerSwiftc510,
+			},
+			sourceFilename:  "main.swift",
+			expectedCommand: "swiftc /workspace/main.swift -o /workspace/output",
+			shouldContain:   []string{"swiftc", "main.swift"},
+		},
+		{
+			name: "kotlin_language",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageKotlin,
+				Compiler: models.CompilerKotlinc20,
+			},
+			sourceFilename:  "Main.kt",
+			expectedCommand: "kotlinc /workspace/Main.kt -include-runtime -d /workspace/output.jar",
+			shouldContain:   []string{"kotlinc", "Main.kt", "-include-runtime"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			command := compiler.buildCompileCommand(tc.envSpec, tc.sourceFilename)
+			assert.Equal(t, tc.expectedCommand, command, "Compile command mismatch")
+
+			for _, substr := range tc.shouldContain {
+				assert.Contains(t, command, substr,
+					"Expected compile command to contain '%s'", substr)
+			}
+		})
+	}
+}
+
+// TestCFamilyDriver tests that C is compiled with the C driver of each toolchain, never the C++ one,
+// which would link the C++ runtime and accept C++-only code.
+func TestCFamilyDriver(t *testing.T) {
+	tests := []struct {
+		language models.Language
+		compiler models.Compiler
+		expected string
+	}{
+		{models.LanguageC, models.CompilerGCC13, "gcc"},
+		{models.LanguageC, "", "gcc"},
+		{models.LanguageC, models.CompilerClang16, "clang"},
+		{models.LanguageC, models.CompilerZig, "zig cc"},
+		{models.LanguageCpp, models.CompilerGCC13, "g++"},
+		{models.LanguageCpp, models.CompilerClang16, "clang++"},
+		{models.LanguageCpp, models.CompilerZig, "zig c++"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.language)+"_"+string(tt.compiler), func(t *testing.T) {
+			assert.Equal(t, tt.expected, cFamilyDriver(models.EnvironmentSpec{Language: tt.language, Compiler: tt.compiler}))
+		})
+	}
+}
+
+// TestMultiLanguageCompilation tests compilation with different languages in sequence.
+func TestMultiLanguageCompilation(t *testing.T) {
+	testCases := []struct {
+		name           string
+		language       models.Language
+		compiler       models.Compiler
+		standard       models.Standard
+		sourceCode     string
+		expectedFile   string
+		expectedStdEnv string
+	}{
+		{
+			name:           "cpp_compilation",
+			language:       models.LanguageCpp,
+			compiler:       models.CompilerGCC13,
+			standard:       models.StandardCpp17,
+			sourceCode:     `int main() { return 0; }`,
+			expectedFile:   "source.cpp",
+			expectedStdEnv: "STANDARD=c++17",
+		},
+		{
+			name:           "c_compilation",
+			language:       models.LanguageC,
+			compiler:       models.CompilerGCC13,
+			standard:       models.StandardC99,
+			sourceCode:     `int main() { return 0; }`,
+			expectedFile:   "source.c",
+			expectedStdEnv: "STANDARD=c99",
+		},
+		{
+			name:         "go_compilation",
+			language:     models.LanguageGo,
+			compiler:     models.CompilerGo123,
+			sourceCode:   `package main; func main() {}`,
+			expectedFile: "main.go",
+		},
+		{
+			name:         "rust_compilation",
+			language:     models.LanguageRust,
+			compiler:     models.CompilerRustc180,
+			sourceCode:   `fn main() {}`,
+			expectedFile: "main.rs",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var capturedConfig runtime.CompilationConfig
+
+			mockRuntime := &runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					capturedConfig = config
+					return &runtime.CompilationOutput{
+						Stdout:   "Success",
+						ExitCode: 0,
+						Duration: time.Second,
+					}, nil
+				},
+			}
+
+			compiler := NewCompilerWithRuntime(mockRuntime)
+			encodedCode := base64.StdEncoding.EncodeToString([]byte(tc.sourceCode))
+
+			job := models.CompilationJob{
+				ID: "test-multi-" + tc.name,
+				Request: models.CompilationRequest{
+					Code:     encodedCode,
+					Language: tc.language,
+					Compiler: tc.compiler,
+					Standard: tc.standard,
+				},
+			}
+
+			result := compiler.Compile(context.Background(), job)
+
+			assert.True(t, result.Success, "Expected %s compilation to succeed", tc.language)
+			assert.Equal(t, tc.expectedFile, capturedConfig.SourceFilename,
+				"Expected %s to use %s", tc.language, tc.expectedFile)
+
+			if tc.expectedStdEnv != "" {
+				assert.Contains(t, capturedConfig.Env, tc.expectedStdEnv,
+					"Expected %s environment variable", tc.expectedStdEnv)
+			}
+		})
+	}
+}
+
+// TestLanguageValidation tests that all supported languages pass validation.
+func TestLanguageValidation(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	// These should now all pass validation (no longer MVP-restricted)
+	supportedLanguages := []models.Language{
+		models.LanguageC,
+		models.LanguageCpp,
+		models.LanguageCPP, // Alternative syntax
+		models.LanguageGo,
+		models.LanguageRust,
+	}
+
+	for _, lang := range supportedLanguages {
+		t.Run(string(lang), func(t *testing.T) {
+			req := models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("test code")),
+				Language: lang,
+			}
+
+			err := compiler.validateRequest(req)
+			assert.NoError(t, err, "Expected %s to be supported", lang)
+		})
+	}
+}
+
+// TestCompileCommand_EdgeCases tests edge cases in compile command generation.
+func TestCompileCommand_EdgeCases(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	testCases := []struct {
+		name           string
+		envSpec        models.EnvironmentSpec
+		sourceFilename string
+		shouldNotPanic bool
+	}{
+		{
+			name: "empty_standard",
+			envSpec: models.EnvironmentSpec{
+				Language: models.LanguageCpp,
+				Standard: "",
+			},
+			sourceFilename: "source.cpp",
+			shouldNotPanic: true,
+		},
+		{
+			name: "unknown_language_fallback",
+			envSpec: models.EnvironmentSpec{
+				Language: models.Language("unknown"),
+				Standard: models.StandardCpp20,
+			},
+			sourceFilename: "source.cpp",
+			shouldNotPanic: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				command := compiler.buildCompileCommand(tc.envSpec, tc.sourceFilename)
+				assert.NotEmpty(t, command, "Expected non-empty compile command")
+			}, "buildCompileCommand should not panic")
+		})
+	}
+}
+
+// TestCompile_JSONDiagnostics tests that JSON diagnostics are requested and parsed.
+func TestCompile_JSONDiagnostics(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				Stderr:   sampleGCCJSONDiagnostics + "\n",
+				ExitCode: 1,
+				Duration: time.Second,
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	job := models.CompilationJob{
+		ID: "test-json-diagnostics",
+		Request: models.CompilationRequest{
+			Code:              base64.StdEncoding.EncodeToString([]byte("int main() { return 0 }")),
+			Language:          models.LanguageCpp,
+			Compiler:          models.CompilerGCC13,
+			DiagnosticsFormat: models.DiagnosticsFormatJSON,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.True(t, result.Success)
+	assert.False(t, result.Compiled)
+	assert.Contains(t, capturedConfig.CompileCommand, "-fdiagnostics-format=json")
+	require.Len(t, result.Diagnostics, 2)
+	assert.Equal(t, "error", result.Diagnostics[0].Severity)
+	assert.Equal(t, "source.cpp", result.Diagnostics[0].File)
+	assert.Equal(t, 22, result.Diagnostics[0].Column)
+}
+
+// TestCompile_DiagnosticsNotRequested tests that diagnostics are omitted by default.
+func TestCompile_DiagnosticsNotRequested(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				Stderr:   "/workspace/main.go:1:1: error: expected 'package'",
+				ExitCode: 1,
+				Duration: time.Second,
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	job := models.CompilationJob{
+		ID: "test-no-diagnostics",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("func main() {}")),
+			Language: models.LanguageGo,
+			Compiler: models.CompilerGo123,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.NotContains(t, capturedConfig.CompileCommand, "-fdiagnostics-format")
+	assert.Nil(t, result.Diagnostics)
+}
+
+// TestCompile_RunMode tests that run mode configures the run step and maps its output.
+func TestCompile_RunMode(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				ExitCode:    0,
+				Duration:    time.Second,
+				Ran:         true,
+				RunStdout:   "Hello",
+				RunExitCode: runtime.RunTimeoutExitCode,
+				RunTimedOut: true,
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	job := models.CompilationJob{
+		ID: "test-run-mode",
+		Request: models.CompilationRequest{
+			Code:           base64.StdEncoding.EncodeToString([]byte("int main() { for(;;); }")),
+			Language:       models.LanguageCpp,
+			Compiler:       models.CompilerGCC13,
+			Run:            boolPtr(true),
+			RunOutputLimit: 4096,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.Equal(t, "/workspace/output", capturedConfig.RunCommand)
+	assert.Equal(t, runtime.DefaultRunTimeout, capturedConfig.RunTimeout)
+	assert.Equal(t, 4096, capturedConfig.MaxRunOutputSize)
+
+	assert.True(t, result.Compiled)
+	assert.Empty(t, result.Error, "Run timeout is not a compile timeout")
+	assert.True(t, result.Ran)
+	assert.True(t, result.RunTimedOut)
+	assert.Equal(t, "Hello", result.RunStdout)
+	assert.Equal(t, runtime.RunTimeoutExitCode, result.RunExitCode)
+	assert.False(t, capturedConfig.PreserveANSI)
+	assert.Empty(t, result.RunStdoutHTML)
+}
+
+// TestCompile_RunExitStatus tests that the compile and program exit statuses are reported separately,
+// with the job status following the compile step only.
+func TestCompile_RunExitStatus(t *testing.T) {
+	testCases := []struct {
+		name              string
+		output            runtime.CompilationOutput
+		expectCompiled    bool
+		expectExitCode    int
+		expectRan         bool
+		expectRunExitCode int
+		expectRunSuccess  bool
+		expectStatus      models.JobStatus
+	}{
+		{
+			name:             "compile_ok_run_ok",
+			output:           runtime.CompilationOutput{Ran: true, RunStdout: "hello\n"},
+			expectCompiled:   true,
+			expectRan:        true,
+			expectRunSuccess: true,
+			expectStatus:     models.StatusCompleted,
+		},
+		{
+			name:              "compile_ok_run_fail",
+			output:            runtime.CompilationOutput{Ran: true, RunExitCode: 1, RunStderr: "assertion failed\n"},
+			expectCompiled:    true,
+			expectRan:         true,
+			expectRunExitCode: 1,
+			expectStatus:      models.StatusCompleted,
+		},
+		{
+			name:              "compile_ok_run_timeout",
+			output:            runtime.CompilationOutput{Ran: true, RunExitCode: runtime.RunTimeoutExitCode, RunTimedOut: true},
+			expectCompiled:    true,
+			expectRan:         true,
+			expectRunExitCode: runtime.RunTimeoutExitCode,
+			expectStatus:      models.StatusCompleted,
+		},
+		{
+			name:           "compile_fail_no_run",
+			output:         runtime.CompilationOutput{ExitCode: 1, Stderr: "source.cpp:1:1: error: expected ';'\n"},
+			expectExitCode: 1,
+			expectStatus:   models.StatusFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRuntime := &runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					output := tc.output
+					return &output, nil
+				},
+			}
+
+			compiler := NewCompilerWithRuntime(mockRuntime)
+
+			job := models.CompilationJob{
+				ID: "test-run-exit-status",
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 1; }")),
+					Language: models.LanguageCpp,
+					Compiler: models.CompilerGCC13,
+					Run:      boolPtr(true),
+				},
+			}
+
+			result := compiler.Compile(context.Background(), job)
+
+			assert.Equal(t, tc.expectCompiled, result.Compiled)
+			assert.Equal(t, tc.expectExitCode, result.ExitCode)
+			assert.Equal(t, tc.expectRan, result.Ran)
+			assert.Equal(t, tc.expectRunExitCode, result.RunExitCode)
+			assert.Equal(t, tc.expectRunSuccess, result.RunSuccess)
+			assert.Equal(t, tc.expectStatus, result.Status())
+			assert.Empty(t, result.Error)
+		})
+	}
+}
+
+// TestCompile_RunStdoutHTML tests that colored program output is rendered as HTML and stripped everywhere else.
+func TestCompile_RunStdoutHTML(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				Stderr:    "\x1b[1mnote:\x1b[0m built\n",
+				Duration:  time.Second,
+				Ran:       true,
+				RunStdout: "\x1b[31mFAIL\x1b[0m 1 < 2\n",
+				RunStderr: "\x1b[33mwarn\x1b[0m\n",
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	job := models.CompilationJob{
+		ID: "test-run-stdout-html",
+		Request: models.CompilationRequest{
+			Code:          base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+			Language:      models.LanguageCpp,
+			Compiler:      models.CompilerGCC13,
+			Run:           boolPtr(true),
+			RunStdoutHTML: true,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.True(t, capturedConfig.PreserveANSI)
+	assert.Equal(t, `<span class="ansi-fg-red">FAIL</span> 1 &lt; 2`+"\n", result.RunStdoutHTML)
+	assert.Equal(t, "FAIL 1 < 2\n", result.RunStdout)
+	assert.Equal(t, "warn\n", result.RunStderr)
+	assert.Equal(t, "note: built\n", result.Stderr)
+}
+
+// TestCompile_BinaryBytes tests that the binary size reported by the runtime reaches the result.
+func TestCompile_BinaryBytes(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				ExitCode:    0,
+				Duration:    time.Second,
+				BinaryBytes: 16384,
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	job := models.CompilationJob{
+		ID: "test-binary-bytes",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	assert.Equal(t, "/workspace/output", capturedConfig.OutputPath)
+	assert.True(t, result.Compiled)
+	assert.Equal(t, int64(16384), result.BinaryBytes)
+}
+
+// TestCompile_SeccompKill tests that a SIGSYS termination is reported as a sandbox policy violation.
+func TestCompile_SeccompKill(t *testing.T) {
+	testCases := []struct {
+		name     string
+		run      bool
+		output   runtime.CompilationOutput
+		expected string
+	}{
+		{
+			name:     "compiler_killed",
+			output:   runtime.CompilationOutput{ExitCode: 159, Stderr: "Bad system call"},
+			expected: "compiler was killed by the sandbox",
+		},
+		{
+			name:     "program_killed",
+			run:      true,
+			output:   runtime.CompilationOutput{Ran: true, RunExitCode: 159},
+			expected: "program was killed by the sandbox",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRuntime := &runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					output := tc.output
+					return &output, nil
+				},
+			}
+
+			compiler := NewCompilerWithRuntime(mockRuntime)
+
+			job := models.CompilationJob{
+				ID: "test-seccomp",
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+					Language: models.LanguageCpp,
+					Compiler: models.CompilerGCC13,
+					Run:      boolPtr(tc.run),
+				},
+			}
+
+			result := compiler.Compile(context.Background(), job)
+
+			assert.True(t, result.Success, "Sandbox kill is still a completed job")
+			message := result.Error
+			if tc.run {
+				assert.Empty(t, result.Error, "The program's kill is not a compile error")
+				assert.Equal(t, models.StatusCompleted, result.Status())
+				assert.False(t, result.RunSuccess)
+				message = result.RunError
+			}
+			assert.Contains(t, message, tc.expected)
+			assert.Contains(t, message, "seccomp")
+		})
+	}
+}
+
+// TestCompile_TerminatedBySignal tests that a killed compiler is told apart from one returning 137.
+func TestCompile_TerminatedBySignal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   runtime.CompilationOutput
+		expected string
+	}{
+		{
+			name:     "killed",
+			output:   runtime.CompilationOutput{ExitCode: 137, TerminatedBySignal: "SIGKILL"},
+			expected: "SIGKILL",
+		},
+		{
+			name:     "exited_137",
+			output:   runtime.CompilationOutput{ExitCode: 137},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRuntime := &runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					output := tc.output
+					return &output, nil
+				},
+			}
+
+			compiler := NewCompilerWithRuntime(mockRuntime)
 
-	environments := compiler.GetSupportedEnvironments()
+			job := models.CompilationJob{
+				ID: "test-signal",
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+					Language: models.LanguageCpp,
+					Compiler: models.CompilerGCC13,
+				},
+			}
 
-	require.NotEmpty(t, environments, "Expected at least one environment")
+			result := compiler.Compile(context.Background(), job)
 
-	// Check for C++ environment
-	found := false
-	for _, env := range environments {
-		if env.Language == "cpp" {
-			found = true
-			assert.Contains(t, env.Compilers, "gcc-13")
-			assert.Contains(t, env.Standards, "c++20")
-			assert.Contains(t, env.OSes, "linux")
-			assert.Contains(t, env.Arches, "x86_64")
-			break
-		}
+			assert.False(t, result.Compiled)
+			assert.Equal(t, 137, result.ExitCode)
+			assert.Equal(t, tc.expected, result.TerminatedBySignal)
+		})
 	}
-
-	assert.True(t, found, "Expected C++ environment in list")
 }
 
-// TestCompile_InvalidBase64 tests invalid base64 encoding.
-func TestCompile_InvalidBase64(t *testing.T) {
-	mockRuntime := &runtime.MockRuntime{}
+// TestCompile_CompileOnly tests that compile-only mode builds an object file instead of linking.
+func TestCompile_CompileOnly(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0, BinaryBytes: 1024}, nil
+		},
+	}
+
 	compiler := NewCompilerWithRuntime(mockRuntime)
 
 	job := models.CompilationJob{
-		ID: "test-job-5",
+		ID: "test-compile-only",
 		Request: models.CompilationRequest{
-			Code:     "not-valid-base64!@#$",
-			Language: models.LanguageCpp,
-			Compiler: models.CompilerGCC13,
+			Code:        base64.StdEncoding.EncodeToString([]byte("int add(int a, int b) { return a + b; }")),
+			Language:    models.LanguageC,
+			Compiler:    models.CompilerGCC13,
+			CompileOnly: true,
 		},
 	}
 
 	result := compiler.Compile(context.Background(), job)
 
-	assert.False(t, result.Success, "Expected compilation to fail")
-	assert.Contains(t, result.Error, "invalid base64")
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Equal(t, "gcc -std=c17 -c /workspace/source.c -o /workspace/output.o", capturedConfig.CompileCommand)
+	assert.Equal(t, "/workspace/output.o", capturedConfig.OutputPath)
+	assert.Empty(t, capturedConfig.RunCommand)
 }
 
-// TestCompile_VerifyRuntimeConfig tests that correct config is passed to runtime.
-func TestCompile_VerifyRuntimeConfig(t *testing.T) {
+// TestCompile_Quick tests that quick mode only syntax-checks, with a short timeout and diagnostics by default.
+func TestCompile_Quick(t *testing.T) {
 	var capturedConfig runtime.CompilationConfig
-
 	mockRuntime := &runtime.MockRuntime{
 		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
 			capturedConfig = config
 			return &runtime.CompilationOutput{
-				Stdout:   "OK",
-				ExitCode: 0,
-				Duration: time.Second,
+				ExitCode: 1,
+				Stderr:   "/workspace/source.cpp:1:22: error: expected ';' before '}' token\n",
 			}, nil
 		},
 	}
 
 	compiler := NewCompilerWithRuntime(mockRuntime)
 
-	sourceCode := `int main() { return 0; }`
-	encodedCode := base64.StdEncoding.EncodeToString([]byte(sourceCode))
-
-	job := models.CompilationJob{
-		ID: "test-job-6",
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-quick",
 		Request: models.CompilationRequest{
-			Code:     encodedCode,
+			Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0 }")),
 			Language: models.LanguageCpp,
 			Compiler: models.CompilerGCC13,
-			Standard: models.StandardCpp17,
+			Quick:    true,
+		},
+	})
+
+	require.Empty(t, result.Error)
+	assert.False(t, result.Compiled)
+	assert.Equal(t, "g++ -std=c++20 -fsyntax-only /workspace/source.cpp", capturedConfig.CompileCommand)
+	assert.Equal(t, quickTimeout, capturedConfig.Timeout)
+	assert.Empty(t, capturedConfig.OutputPath, "Quick mode produces no artifacts")
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, []string{"source.cpp:1:22: expected ';' before '}' token"}, result.Errors)
+}
+
+// TestCompile_TimeoutEnv tests that the compile timeout is passed under the environment's configured variable.
+func TestCompile_TimeoutEnv(t *testing.T) {
+	config := Config{
+		Environments: []EnvironmentConfig{
+			{
+				Language: "cpp",
+				Compilers: []CompilerConfig{
+					{Name: "gcc", Version: "13", Image: "gcc:13", Standards: []string{"c++20"}},
+					{Name: "gcc", Version: "12", Image: "example/gcc:12", Standards: []string{"c++20"}, TimeoutEnv: "BUILD_TIMEOUT_SECONDS"},
+				},
+			},
 		},
 	}
+	environments, err := config.ToEnvironmentSpecs()
+	require.NoError(t, err)
 
-	result := compiler.Compile(context.Background(), job)
+	var capturedConfig runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	})
+	compiler.environments = environments
 
-	assert.True(t, result.Success)
-	assert.Equal(t, "gcc:13", capturedConfig.ImageTag)
-	assert.Equal(t, sourceCode, capturedConfig.SourceCode)
-	assert.Equal(t, "source.cpp", capturedConfig.SourceFilename)
-	assert.Equal(t, "/workspace", capturedConfig.WorkDir)
-	assert.Contains(t, capturedConfig.Env, "STANDARD=c++17")
-	assert.Contains(t, capturedConfig.Env, "SOURCE_FILE=/workspace/source.cpp")
-	assert.Equal(t, job.ID, capturedConfig.JobID)
-	assert.Equal(t, 30*time.Second, capturedConfig.Timeout)
+	testCases := []struct {
+		name     string
+		compiler models.Compiler
+		quick    bool
+		expected string
+	}{
+		{"default_name", models.CompilerGCC13, false, "COMPILE_TIMEOUT=25"},
+		{"configured_name", models.CompilerGCC12, false, "BUILD_TIMEOUT_SECONDS=25"},
+		{"configured_name_quick", models.CompilerGCC12, true, "BUILD_TIMEOUT_SECONDS=1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-timeout-env",
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+					Language: models.LanguageCpp,
+					Compiler: tc.compiler,
+					Quick:    tc.quick,
+				},
+			})
+
+			require.Empty(t, result.Error)
+			assert.Contains(t, capturedConfig.Env, tc.expected)
+			if tc.compiler == models.CompilerGCC12 {
+				assert.NotContains(t, capturedConfig.Env, "COMPILE_TIMEOUT=25", "Only the configured variable is set")
+			}
+		})
+	}
 }
 
-// TestCompile_CLanguage tests C language compilation.
-func TestCompile_CLanguage(t *testing.T) {
+// TestCompile_CompileScript tests that only environments with a compile script hand it to the runtime,
+// along with their CPU weight.
+func TestCompile_CompileScript(t *testing.T) {
+	environments, err := (&Config{Environments: []EnvironmentConfig{{
+		Language: "cpp",
+		Compilers: []CompilerConfig{
+			{Name: "gcc", Version: "13", Image: "gcc:13", Standards: []string{"c++17"}},
+			{Name: "gcc", Version: "12", Image: "example/gcc-script:12", Standards: []string{"c++17"}, CompileScript: "/usr/bin/compile.sh", CPUShares: 256},
+		},
+	}}}).ToEnvironmentSpecs()
+	require.NoError(t, err)
+
 	var capturedConfig runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	})
+	compiler.environments = environments
+
+	cpuShares := map[models.Compiler]int64{models.CompilerGCC13: 0, models.CompilerGCC12: 256}
+	for compilerName, expected := range map[models.Compiler]string{models.CompilerGCC13: "", models.CompilerGCC12: "/usr/bin/compile.sh"} {
+		result := compiler.Compile(context.Background(), models.CompilationJob{
+			ID: "test-compile-script-" + string(compilerName),
+			Request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language: models.LanguageCpp,
+				Compiler: compilerName,
+			},
+		})
+
+		require.Empty(t, result.Error)
+		assert.Equal(t, expected, capturedConfig.CompileScript, compilerName)
+		assert.Equal(t, cpuShares[compilerName], capturedConfig.CPUShares, compilerName)
+		assert.Equal(t, "g++ -std=c++17 /workspace/source.cpp -o /workspace/output", capturedConfig.CompileCommand)
+	}
+}
+
+// TestBuildObjectCommand tests compile-only commands for single and multiple sources.
+func TestBuildObjectCommand(t *testing.T) {
+	env := models.EnvironmentSpec{Language: models.LanguageCpp, Compiler: models.CompilerGCC13, Standard: models.StandardCpp17, Flags: []string{"-Wall"}}
+	assert.Equal(t, "g++ -std=c++17 -Wall -c /workspace/lib.cpp -o /workspace/output.o",
+		buildObjectCommand(env, []string{"lib.cpp"}))
+	assert.Equal(t, "g++ -std=c++17 -Wall -c /workspace/lib.cpp /workspace/util.cpp",
+		buildObjectCommand(env, []string{"lib.cpp", "util.cpp"}))
+
+	env = models.EnvironmentSpec{Language: models.LanguageC, Compiler: models.CompilerZig, Standard: models.StandardC17}
+	assert.Equal(t, "zig cc -std=c17 -c /workspace/source.c -o /workspace/output.o",
+		buildObjectCommand(env, []string{"source.c"}))
+}
 
+// TestCompile_TmpFull tests that ENOSPC from a full /tmp is explained, using the configured size.
+func TestCompile_TmpFull(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
 	mockRuntime := &runtime.MockRuntime{
 		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
 			capturedConfig = config
 			return &runtime.CompilationOutput{
-				Stdout:   "C compilation successful",
-				ExitCode: 0,
-				Duration: time.Second,
+				ExitCode: 1,
+				Stderr:   "/usr/bin/ld: final link failed: No space left on device\ncollect2: error: ld returned 1 exit status",
 			}, nil
 		},
 	}
 
 	compiler := NewCompilerWithRuntime(mockRuntime)
-
-	sourceCode := `#include <stdio.h>
-int main() { printf("Hello, C!\\n"); return 0; }`
-	encodedCode := base64.StdEncoding.EncodeToString([]byte(sourceCode))
+	compiler.SetTmpSize(256 * 1024 * 1024)
 
 	job := models.CompilationJob{
-		ID: "test-c-job",
+		ID: "test-enospc",
 		Request: models.CompilationRequest{
-			Code:     encodedCode,
-			Language: models.LanguageC,
+			Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+			Language: models.LanguageCpp,
 			Compiler: models.CompilerGCC13,
-			Standard: models.StandardC17,
 		},
 	}
 
 	result := compiler.Compile(context.Background(), job)
 
-	assert.True(t, result.Success, "Expected C compilation to succeed")
-	assert.True(t, result.Compiled, "Expected C code to compile")
-	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, int64(256*1024*1024), capturedConfig.TmpSize)
+	assert.True(t, result.Success, "A full /tmp is still a completed job")
+	assert.False(t, result.Compiled)
+	assert.Contains(t, result.Error, "ran out of temporary space")
+	assert.Contains(t, result.Error, "256 MB")
+	assert.Contains(t, result.Error, "TMPFS_SIZE_MB")
+}
 
-	// Verify C-specific configuration
-	assert.Equal(t, "source.c", capturedConfig.SourceFilename, "Expected C source filename")
-	assert.Contains(t, capturedConfig.Env, "STANDARD=c17", "Expected C17 standard")
-	assert.Contains(t, capturedConfig.Env, "SOURCE_FILE=/workspace/source.c", "Expected C source file path")
-	assert.Contains(t, capturedConfig.CompileCommand, "source.c", "Expected compile command to use source.c")
-	assert.Contains(t, capturedConfig.CompileCommand, "c17", "Expected compile command to use c17 standard")
+// TestCompile_TemplateRecursionTimeout tests that a C++ timeout with template depth errors gets a specific hint.
+func TestCompile_TemplateRecursionTimeout(t *testing.T) {
+	gccStderr := "/workspace/source.cpp: In instantiation of 'struct F<901>':\n" +
+		"/workspace/source.cpp:2:36: error: template instantiation depth exceeds maximum of 900 " +
+		"(use '-ftemplate-depth=' to increase the maximum)\n"
+	clangStderr := "/workspace/source.cpp:2:36: fatal error: recursive template instantiation exceeded maximum depth of 1024\n"
+
+	testCases := []struct {
+		name     string
+		stderr   string
+		timedOut bool
+		expected string
+	}{
+		{"gcc_depth_timeout", gccStderr, true, "possible infinite template recursion"},
+		{"clang_depth_timeout", clangStderr, true, "possible infinite template recursion"},
+		{"plain_timeout", "", true, "compilation timeout"},
+		{"depth_error_without_timeout", gccStderr, false, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					return &runtime.CompilationOutput{ExitCode: 1, Stderr: tc.stderr, TimedOut: tc.timedOut}, nil
+				},
+			})
+
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-template-recursion",
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte("template<int N> struct F { enum { v = F<N+1>::v }; }; int x = F<0>::v;")),
+					Language: models.LanguageCpp,
+					Compiler: models.CompilerGCC13,
+				},
+			})
+
+			assert.Equal(t, tc.stderr, result.Stderr, "Raw output is preserved")
+			if tc.expected == "" {
+				assert.Empty(t, result.Error)
+				return
+			}
+			assert.Contains(t, result.Error, tc.expected)
+			assert.Equal(t, models.StatusTimeout, result.Status(), "The hint must not turn a timeout into an error")
+			if tc.stderr != "" {
+				assert.Contains(t, result.Error, "-ftemplate-depth")
+			}
+		})
+	}
 }
 
-// TestCompile_GoLanguage tests Go language compilation.
-func TestCompile_GoLanguage(t *testing.T) {
-	var capturedConfig runtime.CompilationConfig
+// TestTmpSizeFromEnv tests reading the /tmp size from TMPFS_SIZE_MB.
+func TestTmpSizeFromEnv(t *testing.T) {
+	t.Setenv("TMPFS_SIZE_MB", "128")
+	assert.Equal(t, int64(128*1024*1024), tmpSizeFromEnv())
+
+	t.Setenv("TMPFS_SIZE_MB", "lots")
+	assert.Zero(t, tmpSizeFromEnv())
 
+	t.Setenv("TMPFS_SIZE_MB", "")
+	assert.Zero(t, tmpSizeFromEnv())
+	assert.Equal(t, int64(runtime.DefaultTmpSize), runtime.CompilationConfig{}.TmpSizeOrDefault())
+}
+
+// TestCompile_IdleTimeout tests that the idle window is passed to the runtime and an idle kill is reported as a timeout.
+func TestCompile_IdleTimeout(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
 	mockRuntime := &runtime.MockRuntime{
 		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
 			capturedConfig = config
-			return &runtime.CompilationOutput{
-				Stdout:   "Go compilation successful",
-				ExitCode: 0,
-				Duration: time.Second,
-			}, nil
+			return &runtime.CompilationOutput{ExitCode: 137, IdleTimedOut: true, TerminatedBySignal: "SIGKILL"}, nil
 		},
 	}
 
 	compiler := NewCompilerWithRuntime(mockRuntime)
+	compiler.SetIdleTimeout(5 * time.Second)
 
-	sourceCode := `package main
-import "fmt"
-func main() { fmt.Println("Hello, Go!") }`
-	encodedCode := base64.StdEncoding.EncodeToString([]byte(sourceCode))
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-idle-timeout",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+		},
+	})
+
+	assert.Equal(t, 5*time.Second, capturedConfig.IdleTimeout)
+	assert.Equal(t, "compilation timeout: killed after 5s without output (idle timeout)", result.Error)
+	assert.Equal(t, models.StatusTimeout, result.Status())
+}
+
+// TestIdleTimeoutFromEnv tests reading the idle window from COMPILE_IDLE_TIMEOUT_SECONDS.
+func TestIdleTimeoutFromEnv(t *testing.T) {
+	t.Setenv("COMPILE_IDLE_TIMEOUT_SECONDS", "10")
+	assert.Equal(t, 10*time.Second, idleTimeoutFromEnv())
+
+	t.Setenv("COMPILE_IDLE_TIMEOUT_SECONDS", "-1")
+	assert.Zero(t, idleTimeoutFromEnv())
+
+	t.Setenv("COMPILE_IDLE_TIMEOUT_SECONDS", "")
+	assert.Zero(t, idleTimeoutFromEnv())
+}
+
+// TestCompile_TimeoutClamped tests that the requested or environment timeout is clamped to the configured ceiling,
+// and handed to the compile script.
+func TestCompile_TimeoutClamped(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxTimeout     time.Duration
+		envTimeout     time.Duration
+		timeoutSeconds int
+		quick          bool
+		expected       time.Duration
+	}{
+		{name: "default", expected: 30 * time.Second},
+		{name: "below_ceiling", timeoutSeconds: 45, expected: 45 * time.Second},
+		{name: "above_default_ceiling", timeoutSeconds: 600, expected: 60 * time.Second},
+		{name: "above_configured_ceiling", maxTimeout: 20 * time.Second, timeoutSeconds: 600, expected: 20 * time.Second},
+		{name: "default_above_ceiling", maxTimeout: 10 * time.Second, expected: 10 * time.Second},
+		{name: "quick", timeoutSeconds: 45, quick: true, expected: quickTimeout},
+		{name: "environment", envTimeout: 45 * time.Second, expected: 45 * time.Second},
+		{name: "environment_raises_default_ceiling", envTimeout: 120 * time.Second, timeoutSeconds: 90, expected: 90 * time.Second},
+		{name: "environment_above_configured_ceiling", maxTimeout: 20 * time.Second, envTimeout: 45 * time.Second, expected: 20 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedConfig runtime.CompilationConfig
+			compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					capturedConfig = config
+					return &runtime.CompilationOutput{ExitCode: 0}, nil
+				},
+			})
+			compiler.SetMaxTimeout(tt.maxTimeout)
+			env := compiler.environments["cpp-gcc-13"]
+			env.Timeout = tt.envTimeout
+			compiler.environments["cpp-gcc-13"] = env
+
+			request := models.CompilationRequest{
+				Code:           base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+				Language:       models.LanguageCpp,
+				Compiler:       models.CompilerGCC13,
+				TimeoutSeconds: tt.timeoutSeconds,
+				Quick:          tt.quick,
+			}
+			assert.Equal(t, tt.expected, compiler.EffectiveTimeout(request), "Reported before the compile")
+
+			result := compiler.Compile(context.Background(), models.CompilationJob{ID: "test-timeout-" + tt.name, Request: request})
+
+			require.Empty(t, result.Error)
+			assert.Equal(t, tt.expected, capturedConfig.Timeout)
+			assert.Contains(t, capturedConfig.Env, fmt.Sprintf("COMPILE_TIMEOUT=%d", compileScriptTimeout(tt.expected)))
+		})
+	}
+}
+
+// TestCompile_TimeoutClampedDocker tests that a timeout clamped above the Docker client's own 30s default
+// is how long the Docker runtime waits for the container, so the compile runs as long as reported.
+func TestCompile_TimeoutClampedDocker(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+		var waited time.Duration
+		rt := dockerruntime.NewDockerRuntimeWithClient(&docker.MockDockerClient{
+			RunCompilationFunc: func(ctx context.Context, config docker.CompilationConfig) (*docker.CompilationOutput, error) {
+				<-ctx.Done()
+				waited = time.Since(start)
+				return &docker.CompilationOutput{ExitCode: 137, TimedOut: true}, nil
+			},
+		})
+		defer rt.Close() //nolint:errcheck // test cleanup
+		compiler := NewCompilerWithRuntime(rt)
+		compiler.SetMaxTimeout(90 * time.Second)
+
+		request := models.CompilationRequest{
+			Code:           base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+			Language:       models.LanguageCpp,
+			Compiler:       models.CompilerGCC13,
+			TimeoutSeconds: 600,
+		}
+		require.Equal(t, 90*time.Second, compiler.EffectiveTimeout(request))
+
+		result := compiler.Compile(context.Background(), models.CompilationJob{ID: "test-timeout-docker", Request: request})
+
+		assert.True(t, result.TimedOut)
+		assert.Equal(t, 90*time.Second, waited)
+	})
+}
+
+// TestMaxCompileTimeoutFromEnv tests reading the timeout ceiling from MAX_COMPILE_TIMEOUT.
+func TestMaxCompileTimeoutFromEnv(t *testing.T) {
+	t.Setenv("MAX_COMPILE_TIMEOUT", "90")
+	assert.Equal(t, 90*time.Second, maxCompileTimeoutFromEnv())
+
+	t.Setenv("MAX_COMPILE_TIMEOUT", "0")
+	assert.Zero(t, maxCompileTimeoutFromEnv())
+
+	t.Setenv("MAX_COMPILE_TIMEOUT", "")
+	assert.Zero(t, maxCompileTimeoutFromEnv())
+}
+
+// TestCompile_Hashes tests that the source hash is stable and the output hash comes from the runtime.
+func TestCompile_Hashes(t *testing.T) {
+	source := "int main() { return 0; }"
+	sum := sha256.Sum256([]byte(source))
+	expectedSourceHash := hex.EncodeToString(sum[:])
+
+	compile := func(code string, output runtime.CompilationOutput) models.CompilationResult {
+		mockRuntime := &runtime.MockRuntime{
+			CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+				return &output, nil
+			},
+		}
+		// A fresh compiler per call, so results don't come from the cache
+		return NewCompilerWithRuntime(mockRuntime).Compile(context.Background(), models.CompilationJob{
+			ID: "test-hashes",
+			Request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte(code)),
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerGCC13,
+			},
+		})
+	}
+
+	built := runtime.CompilationOutput{ExitCode: 0, BinaryBytes: 16384, BinarySHA256: "9f86d081884c7d65"}
+	first := compile(source, built)
+	second := compile(source, built)
+
+	assert.Equal(t, expectedSourceHash, first.SourceHash)
+	assert.Equal(t, first.SourceHash, second.SourceHash, "Same source, same hash")
+	assert.Equal(t, "9f86d081884c7d65", first.OutputHash)
+
+	failed := compile(source+" syntax error", runtime.CompilationOutput{ExitCode: 1})
+	assert.NotEqual(t, expectedSourceHash, failed.SourceHash)
+	assert.Empty(t, failed.OutputHash, "No binary, no output hash")
+}
+
+// TestCompile_Analyze tests that analyzer findings are returned separately from the compile result.
+func TestCompile_Analyze(t *testing.T) {
+	const clangTidyOutput = `/workspace/source.cpp:2:9: warning: Value stored to 'x' during its initialization is never read [clang-analyzer-deadcode.DeadStores]
+    2 |     int x = 42;
+      |         ^
+1 warning generated.
+`
+
+	var commands []string
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			commands = append(commands, config.CompileCommand)
+			if strings.HasPrefix(config.CompileCommand, "clang-tidy") {
+				return &runtime.CompilationOutput{ExitCode: 0, Stdout: clangTidyOutput}, nil
+			}
+			return &runtime.CompilationOutput{ExitCode: 0, Duration: time.Second}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+	env := compiler.environments["cpp-gcc-13"]
+	env.Analyzer = models.AnalyzerClangTidy
+	compiler.environments["cpp-gcc-13"] = env
 
 	job := models.CompilationJob{
-		ID: "test-go-job",
+		ID: "test-analyze",
 		Request: models.CompilationRequest{
-			Code:     encodedCode,
-			Language: models.LanguageGo,
-			Compiler: models.CompilerGo123,
+			Code:     base64.StdEncoding.EncodeToString([]byte("int main() {\n    int x = 42;\n}")),
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+			Analyze:  true,
 		},
 	}
 
 	result := compiler.Compile(context.Background(), job)
 
-	assert.True(t, result.Success, "Expected Go compilation to succeed")
-	assert.True(t, result.Compiled, "Expected Go code to compile")
-	assert.Equal(t, 0, result.ExitCode)
+	require.Len(t, commands, 2, "Expected a compile step and an analysis step")
+	assert.Equal(t, "clang-tidy /workspace/source.cpp -- -std=c++20", commands[1])
 
-	// Verify Go-specific configuration
-	assert.Equal(t, "main.go", capturedConfig.SourceFilename, "Expected Go source filename")
-	assert.Contains(t, capturedConfig.Env, "SOURCE_FILE=/workspace/main.go", "Expected Go source file path")
-	assert.Contains(t, capturedConfig.CompileCommand, "go build", "Expected Go build command")
-	assert.Contains(t, capturedConfig.CompileCommand, "main.go", "Expected compile command to use main.go")
+	assert.True(t, result.Compiled)
+	assert.Empty(t, result.Error)
+	assert.Empty(t, result.Diagnostics, "Analyzer findings must not mix with compiler diagnostics")
+	require.Len(t, result.Analysis, 1)
+	assert.Equal(t, models.Diagnostic{
+		File:     "source.cpp",
+		Line:     2,
+		Column:   9,
+		Severity: "warning",
+		Message:  "Value stored to 'x' during its initialization is never read [clang-analyzer-deadcode.DeadStores]",
+	}, result.Analysis[0])
 }
 
-// TestCompile_RustLanguage tests Rust language compilation.
-func TestCompile_RustLanguage(t *testing.T) {
-	var capturedConfig runtime.CompilationConfig
-
+// TestCompile_AnalyzeUnavailable tests that analysis is rejected for environments without an analyzer.
+func TestCompile_AnalyzeUnavailable(t *testing.T) {
+	compileCalled := false
 	mockRuntime := &runtime.MockRuntime{
 		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
-			capturedConfig = config
-			return &runtime.CompilationOutput{
-				Stdout:   "Rust compilation successful",
-				ExitCode: 0,
-				Duration: time.Second,
-			}, nil
+			compileCalled = true
+			return &runtime.CompilationOutput{}, nil
 		},
 	}
 
 	compiler := NewCompilerWithRuntime(mockRuntime)
 
-	sourceCode := `fn main() { println!("Hello, Rust!"); }`
-	encodedCode := base64.StdEncoding.EncodeToString([]byte(sourceCode))
-
 	job := models.CompilationJob{
-		ID: "test-rust-job",
+		ID: "test-analyze-unavailable",
 		Request: models.CompilationRequest{
-			Code:     encodedCode,
-			Language: models.LanguageRust,
-			Compiler: models.CompilerRustc180,
+			Code:     base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+			Analyze:  true,
 		},
 	}
 
 	result := compiler.Compile(context.Background(), job)
 
-	assert.True(t, result.Success, "Expected Rust compilation to succeed")
-	assert.True(t, result.Compiled, "Expected Rust code to compile")
-	assert.Equal(t, 0, result.ExitCode)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error, "no static analyzer")
+	assert.False(t, compileCalled)
+}
+
+// TestBuildAnalyzeCommand tests the analyzer command for each supported tool.
+func TestBuildAnalyzeCommand(t *testing.T) {
+	env := models.EnvironmentSpec{Language: models.LanguageC, Standard: models.StandardC11}
+
+	env.Analyzer = models.AnalyzerClangTidy
+	assert.Equal(t, "clang-tidy /workspace/source.c -- -std=c11", buildAnalyzeCommand(env, "source.c"))
+
+	env.Analyzer = models.AnalyzerCppcheck
+	cmd := buildAnalyzeCommand(env, "source.c")
+	assert.True(t, strings.HasPrefix(cmd, "cppcheck "))
+	assert.Contains(t, cmd, "{file}:{line}:{column}: warning: {message} [{id}]")
+	assert.True(t, strings.HasSuffix(cmd, "/workspace/source.c"))
+
+	env.Analyzer = ""
+	assert.Empty(t, buildAnalyzeCommand(env, "source.c"))
+}
 
-	// Verify Rust-specific configuration
-	assert.Equal(t, "main.rs", capturedConfig.SourceFilename, "Expected Rust source filename")
-	assert.Contains(t, capturedConfig.Env, "SOURCE_FILE=/workspace/main.rs", "Expected Rust source file path")
-	assert.Contains(t, capturedConfig.CompileCommand, "rustc", "Expected rustc command")
-	assert.Contains(t, capturedConfig.CompileCommand, "main.rs", "Expected compile command to use main.rs")
+// TestRunOutputLimit tests defaulting and clamping of the requested run output limit.
+func TestRunOutputLimit(t *testing.T) {
+	assert.Equal(t, runtime.DefaultMaxRunOutputSize, runOutputLimit(0))
+	assert.Equal(t, 100, runOutputLimit(100))
+	assert.Equal(t, maxRunOutputSize, runOutputLimit(10*maxRunOutputSize))
 }
 
-// TestGetSourceFilename tests the source filename mapping for all languages.
-func TestGetSourceFilename(t *testing.T) {
+// reloadTestConfig is an environments.yaml that adds gcc-12 for C++ and drops Go and Rust.
+const reloadTestConfig = `environments:
+  - language: cpp
+    compilers:
+      - name: gcc
+        version: "12"
+        image: gcc:12
+        standards: [c++17, c++20]
+      - name: gcc
+        version: "13"
+        image: gcc:13
+        standards: [c++20]
+`
+
+// TestReloadConfig tests that a reloaded config replaces the environments.
+func TestReloadConfig(t *testing.T) {
+	t.Setenv("MINIMAL_IMAGE_VALIDATION", "false")
+
+	configPath := filepath.Join(t.TempDir(), "environments.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(reloadTestConfig), 0o644))
+
 	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
 
-	testCases := []struct {
-		language         models.Language
-		expectedFilename string
-	}{
-		{models.LanguageC, "source.c"},
-		{models.LanguageCpp, "source.cpp"},
-		{models.LanguageCPP, "source.cpp"}, // Alternative syntax
-		{models.LanguageGo, "main.go"},
-		{models.LanguageRust, "main.rs"},
-	}
+	// Hardcoded environments don't include gcc-12
+	_, err := compiler.selectEnvironment(models.CompilationRequest{Language: models.LanguageCpp, Compiler: models.CompilerGCC12})
+	require.Error(t, err)
 
-	for _, tc := range testCases {
-		t.Run(string(tc.language), func(t *testing.T) {
-			filename := compiler.getSourceFilename(tc.language)
-			assert.Equal(t, tc.expectedFilename, filename,
-				"Expected %s to map to %s", tc.language, tc.expectedFilename)
-		})
-	}
+	err = compiler.ReloadConfig(context.Background(), configPath)
+	require.NoError(t, err)
+
+	env, err := compiler.selectEnvironment(models.CompilationRequest{Language: models.LanguageCpp, Compiler: models.CompilerGCC12})
+	require.NoError(t, err, "gcc-12 should be available after reload")
+	assert.Equal(t, "gcc:12", env.ImageTag)
+
+	environments := compiler.GetSupportedEnvironments()
+	require.Len(t, environments, 1, "Only C++ remains after reload")
+	assert.ElementsMatch(t, []string{"gcc-12", "gcc-13"}, environments[0].Compilers)
+
+	// Go was dropped from the config
+	err = compiler.validateRequest(models.CompilationRequest{
+		Code:     base64.StdEncoding.EncodeToString([]byte("package main")),
+		Language: models.LanguageGo,
+	})
+	assert.ErrorIs(t, err, ErrUnsupportedLanguage)
 }
 
-// TestBuildCompileCommand tests compile command generation for all languages.
-func TestBuildCompileCommand(t *testing.T) {
-	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+// TestReloadConfig_KeepsOldConfigOnFailure tests that failed reloads don't change the environments.
+func TestReloadConfig_KeepsOldConfigOnFailure(t *testing.T) {
+	t.Setenv("MINIMAL_IMAGE_VALIDATION", "false")
 
-	testCases := []struct {
-		name            string
-		envSpec         models.EnvironmentSpec
-		sourceFilename  string
-		expectedCommand string
-		shouldContain   []string
-	}{
-		{
-			name: "cpp_with_standard",
-			envSpec: models.EnvironmentSpec{
-				Language: models.LanguageCpp,
-				Standard: models.StandardCpp20,
-			},
-			sourceFilename:  "source.cpp",
-			expectedCommand: "g++ -std=c++20 /workspace/source.cpp -o /workspace/output",
-			shouldContain:   []string{"g++", "-std=c++20", "source.cpp"},
-		},
-		{
-			name: "c_with_standard",
-			envSpec: models.EnvironmentSpec{
-				Language: models.LanguageC,
-				Standard: models.StandardC11,
-			},
-			sourceFilename:  "source.c",
-			expectedCommand: "gcc -std=c11 /workspace/source.c -o /workspace/output",
-			shouldContain:   []string{"gcc", "-std=c11", "source.c"},
-		},
-		{
-			name: "go_language",
-			envSpec: models.EnvironmentSpec{
-				Language: models.LanguageGo,
-			},
-			sourceFilename:  "main.go",
-			expectedCommand: "go build -o /workspace/output /workspace/main.go",
-			shouldContain:   []string{"go build", "main.go"},
+	configPath := filepath.Join(t.TempDir(), "environments.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(reloadTestConfig), 0o644))
+
+	mockRuntime := &runtime.MockRuntime{
+		ImageExistsFunc: func(ctx context.Context, imageTag string) (bool, error) {
+			return imageTag != "gcc:12", nil // gcc:12 not pulled
 		},
-		{
-			name: "rust_language",
-			envSpec: models.EnvironmentSpec{
-				Language: models.LanguageRust,
-			},
-			sourceFilename:  "main.rs",
-			expectedCommand: "rustc /workspace/main.rs -o /workspace/output",
-			shouldContain:   []string{"rustc", "main.rs"},
+	}
+	compiler := NewCompilerWithRuntime(mockRuntime)
+	before := compiler.GetSupportedEnvironments()
+
+	err := compiler.ReloadConfig(context.Background(), configPath)
+	require.ErrorIs(t, err, ErrMissingRequiredImages)
+	assert.Equal(t, before, compiler.GetSupportedEnvironments(), "Old environments should be kept")
+
+	err = compiler.ReloadConfig(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Equal(t, before, compiler.GetSupportedEnvironments(), "Old environments should be kept")
+}
+
+// TestCompile_Cached tests that an identical second submission is served from cache.
+func TestCompile_Cached(t *testing.T) {
+	compileCalls := 0
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			compileCalls++
+			return &runtime.CompilationOutput{ExitCode: 0, Duration: time.Second}, nil
 		},
 	}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			command := compiler.buildCompileCommand(tc.envSpec, tc.sourceFilename)
-			assert.Equal(t, tc.expectedCommand, command, "Compile command mismatch")
+	compiler := NewCompilerWithRuntime(mockRuntime)
 
-			for _, substr := range tc.shouldContain {
-				assert.Contains(t, command, substr,
-					"Expected compile command to contain '%s'", substr)
-			}
-		})
+	request := models.CompilationRequest{
+		Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+		Language: models.LanguageCpp,
+		Compiler: models.CompilerGCC13,
 	}
+
+	first := compiler.Compile(context.Background(), models.CompilationJob{ID: "job-1", Request: request})
+	assert.False(t, first.Cached)
+
+	second := compiler.Compile(context.Background(), models.CompilationJob{ID: "job-2", Request: request})
+	assert.True(t, second.Cached)
+	assert.True(t, second.Compiled)
+	assert.Equal(t, "job-2", second.JobID)
+	assert.Equal(t, 1, compileCalls)
+
+	// Run mode is never served from cache
+	request.Run = boolPtr(true)
+	compiler.Compile(context.Background(), models.CompilationJob{ID: "job-3", Request: request})
+	third := compiler.Compile(context.Background(), models.CompilationJob{ID: "job-4", Request: request})
+	assert.False(t, third.Cached)
+	assert.Equal(t, 3, compileCalls)
 }
 
-// TestMultiLanguageCompilation tests compilation with different languages in sequence.
-func TestMultiLanguageCompilation(t *testing.T) {
-	testCases := []struct {
-		name           string
-		language       models.Language
-		compiler       models.Compiler
-		standard       models.Standard
-		sourceCode     string
-		expectedFile   string
-		expectedStdEnv string
-	}{
-		{
-			name:           "cpp_compilation",
-			language:       models.LanguageCpp,
-			compiler:       models.CompilerGCC13,
-			standard:       models.StandardCpp17,
-			sourceCode:     `int main() { return 0; }`,
-			expectedFile:   "source.cpp",
-			expectedStdEnv: "STANDARD=c++17",
-		},
-		{
-			name:           "c_compilation",
-			language:       models.LanguageC,
-			compiler:       models.CompilerGCC13,
-			standard:       models.StandardC99,
-			sourceCode:     `int main() { return 0; }`,
-			expectedFile:   "source.c",
-			expectedStdEnv: "STANDARD=c99",
-		},
-		{
-			name:         "go_compilation",
-			language:     models.LanguageGo,
-			compiler:     models.CompilerGo123,
-			sourceCode:   `package main; func main() {}`,
-			expectedFile: "main.go",
+// TestCompile_ObjC tests Objective-C compilation once an environment is configured.
+func TestCompile_ObjC(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
 		},
-		{
-			name:         "rust_compilation",
-			language:     models.LanguageRust,
-			compiler:     models.CompilerRustc180,
-			sourceCode:   `fn main() {}`,
-			expectedFile: "main.rs",
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+	compiler.environments["objc-clang-18"] = models.EnvironmentSpec{
+		Language: models.LanguageObjC,
+		Compiler: models.CompilerClang18,
+		Version:  "18",
+		Standard: models.StandardC17,
+		OS:       models.OSLinux,
+		ImageTag: "will-it-compile/objc-clang:18",
+	}
+
+	job := models.CompilationJob{
+		ID: "test-objc",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("#import <objc/objc.h>\nint main(void) { return 0; }")),
+			Language: models.LanguageObjC,
+			Compiler: models.CompilerClang18,
 		},
 	}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			var capturedConfig runtime.CompilationConfig
+	result := compiler.Compile(context.Background(), job)
 
-			mockRuntime := &runtime.MockRuntime{
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Equal(t, "main.m", capturedConfig.SourceFilename)
+	assert.Equal(t, "clang -std=c17 /workspace/main.m -o /workspace/output -fobjc-runtime=gnustep-2.0 -lobjc", capturedConfig.CompileCommand)
+}
+
+// boolPtr returns a pointer to v, for optional request fields.
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+// TestCompile_NetworkEnabled tests that the result reports the network setting the runtime applied.
+func TestCompile_NetworkEnabled(t *testing.T) {
+	for name, networkEnabled := range map[string]bool{"network_disabled": false, "network_enabled": true} {
+		t.Run(name, func(t *testing.T) {
+			compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
 				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
-					capturedConfig = config
-					return &runtime.CompilationOutput{
-						Stdout:   "Success",
-						ExitCode: 0,
-						Duration: time.Second,
-					}, nil
+					return &runtime.CompilationOutput{ExitCode: 1, Stderr: "go: github.com/pkg/errors: dial tcp: lookup proxy.golang.org", NetworkEnabled: networkEnabled}, nil
 				},
-			}
+			})
 
-			compiler := NewCompilerWithRuntime(mockRuntime)
-			encodedCode := base64.StdEncoding.EncodeToString([]byte(tc.sourceCode))
-
-			job := models.CompilationJob{
-				ID: "test-multi-" + tc.name,
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-network",
 				Request: models.CompilationRequest{
-					Code:     encodedCode,
-					Language: tc.language,
-					Compiler: tc.compiler,
-					Standard: tc.standard,
+					Code:     base64.StdEncoding.EncodeToString([]byte("package main\nimport \"github.com/pkg/errors\"\nfunc main() { _ = errors.New }")),
+					Language: models.LanguageGo,
+					Compiler: models.CompilerGo123,
 				},
-			}
-
-			result := compiler.Compile(context.Background(), job)
+			})
 
-			assert.True(t, result.Success, "Expected %s compilation to succeed", tc.language)
-			assert.Equal(t, tc.expectedFile, capturedConfig.SourceFilename,
-				"Expected %s to use %s", tc.language, tc.expectedFile)
-
-			if tc.expectedStdEnv != "" {
-				assert.Contains(t, capturedConfig.Env, tc.expectedStdEnv,
-					"Expected %s environment variable", tc.expectedStdEnv)
-			}
+			assert.False(t, result.Compiled)
+			assert.Equal(t, networkEnabled, result.NetworkEnabled)
 		})
 	}
 }
 
-// TestLanguageValidation tests that all supported languages pass validation.
-func TestLanguageValidation(t *testing.T) {
-	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
-
-	// These should now all pass validation (no longer MVP-restricted)
-	supportedLanguages := []models.Language{
-		models.LanguageC,
-		models.LanguageCpp,
-		models.LanguageCPP, // Alternative syntax
-		models.LanguageGo,
-		models.LanguageRust,
+// TestCompile_Swift tests Swift compilation once an environment is configured.
+func TestCompile_Swift(t *testing.T) {
+	var capturedConfig runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0, BinaryBytes: 65536}, nil
+		},
+	})
+	compiler.environments["swift-swiftc-5.10"] = models.EnvironmentSpec{
+		Language: models.LanguageSwift,
+		Compiler: models.CompilerSwiftc510,
+		Version:  "5.10",
+		OS:       models.OSLinux,
+		ImageTag: "swift:5.10",
 	}
 
-	for _, lang := range supportedLanguages {
-		t.Run(string(lang), func(t *testing.T) {
-			req := models.CompilationRequest{
-				Code:     base64.StdEncoding.EncodeToString([]byte("test code")),
-				Language: lang,
-			}
-
-			err := compiler.validateRequest(req)
-			assert.NoError(t, err, "Expected %s to be supported", lang)
-		})
-	}
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-swift",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("print(\"hello\")")),
+			Language: models.LanguageSwift,
+			Compiler: models.CompilerSwiftc510,
+			Release:  true,
+		},
+	})
+
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Equal(t, "swift:5.10", capturedConfig.ImageTag)
+	assert.Equal(t, "main.swift", capturedConfig.SourceFilename)
+	assert.Equal(t, "swiftc -O /workspace/main.swift -o /workspace/output", capturedConfig.CompileCommand)
+	assert.Equal(t, binaryOutputPath, capturedConfig.OutputPath)
 }
 
-// TestCompileCommand_EdgeCases tests edge cases in compile command generation.
-func TestCompileCommand_EdgeCases(t *testing.T) {
-	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+// TestCompile_Kotlin tests Kotlin compilation to a jar, with the environment's longer timeout, and running it on the JVM.
+func TestCompile_Kotlin(t *testing.T) {
+	environments, err := (&Config{Environments: []EnvironmentConfig{{
+		Language:  "kotlin",
+		Compilers: []CompilerConfig{{Name: "kotlinc", Version: "2.0", Image: "will-it-compile/kotlinc:2.0", TimeoutSeconds: 120}},
+	}}}).ToEnvironmentSpecs()
+	require.NoError(t, err)
 
-	testCases := []struct {
-		name           string
-		envSpec        models.EnvironmentSpec
-		sourceFilename string
-		shouldNotPanic bool
-	}{
-		{
-			name: "empty_standard",
-			envSpec: models.EnvironmentSpec{
-				Language: models.LanguageCpp,
-				Standard: "",
-			},
-			sourceFilename: "source.cpp",
-			shouldNotPanic: true,
+	var capturedConfig runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0, Ran: true, RunStdout: "hello\n"}, nil
 		},
-		{
-			name: "unknown_language_fallback",
-			envSpec: models.EnvironmentSpec{
-				Language: models.Language("unknown"),
-				Standard: models.StandardCpp20,
-			},
-			sourceFilename: "source.cpp",
-			shouldNotPanic: true,
+	})
+	compiler.environments = environments
+	assert.True(t, environments["kotlin-kotlinc-2.0"].Capabilities.Run)
+
+	run := true
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-kotlin",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("fun main() { println(\"hello\") }")),
+			Language: models.LanguageKotlin,
+			Compiler: models.CompilerKotlinc20,
+			Release:  true,
+			Run:      &run,
 		},
-	}
+	})
+
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Equal(t, "Main.kt", capturedConfig.SourceFilename)
+	assert.Equal(t, "kotlinc /workspace/Main.kt -include-runtime -d /workspace/output.jar", capturedConfig.CompileCommand)
+	assert.Empty(t, capturedConfig.OutputPath, "A jar is not a native binary")
+	assert.Equal(t, "java -jar /workspace/output.jar", capturedConfig.RunCommand)
+	assert.Equal(t, 120*time.Second, capturedConfig.Timeout)
+	assert.Contains(t, capturedConfig.Env, "COMPILE_TIMEOUT=115")
+}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			assert.NotPanics(t, func() {
-				command := compiler.buildCompileCommand(tc.envSpec, tc.sourceFilename)
-				assert.NotEmpty(t, command, "Expected non-empty compile command")
-			}, "buildCompileCommand should not panic")
+// TestCompile_KotlinDocker tests that a kotlinc compile taking longer than the Docker client's own 30s
+// default still completes on the Docker runtime, within the environment's 120s.
+func TestCompile_KotlinDocker(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		environments, err := (&Config{Environments: []EnvironmentConfig{{
+			Language:  "kotlin",
+			Compilers: []CompilerConfig{{Name: "kotlinc", Version: "2.0", Image: "will-it-compile/kotlinc:2.0", TimeoutSeconds: 120}},
+		}}}).ToEnvironmentSpecs()
+		require.NoError(t, err)
+
+		rt := dockerruntime.NewDockerRuntimeWithClient(&docker.MockDockerClient{
+			RunCompilationFunc: func(ctx context.Context, config docker.CompilationConfig) (*docker.CompilationOutput, error) {
+				select {
+				case <-ctx.Done():
+					return &docker.CompilationOutput{ExitCode: 137, TimedOut: true}, nil
+				case <-time.After(90 * time.Second):
+					return &docker.CompilationOutput{ExitCode: 0, Duration: 90 * time.Second}, nil
+				}
+			},
 		})
-	}
+		defer rt.Close() //nolint:errcheck // test cleanup
+		compiler := NewCompilerWithRuntime(rt)
+		compiler.environments = environments
+
+		result := compiler.Compile(context.Background(), models.CompilationJob{
+			ID: "test-kotlin-docker",
+			Request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("fun main() { println(\"hello\") }")),
+				Language: models.LanguageKotlin,
+				Compiler: models.CompilerKotlinc20,
+			},
+		})
+
+		require.Empty(t, result.Error)
+		assert.False(t, result.TimedOut)
+		assert.True(t, result.Compiled)
+	})
 }
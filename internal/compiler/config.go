@@ -3,8 +3,15 @@ package compiler
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/stlpine/will-it-compile/pkg/models"
 	"gopkg.in/yaml.v3"
@@ -19,19 +26,50 @@ var (
 	ErrCompilerVersionRequired   = errors.New("compiler version is required")
 	ErrCompilerImageRequired     = errors.New("compiler image is required")
 	ErrUnsupportedConfigLanguage = errors.New("unsupported language in config")
+	ErrInvalidAnalyzer           = errors.New("invalid analyzer")
+	ErrInvalidFormatter          = errors.New("invalid formatter")
+	ErrInvalidMinVersion         = errors.New("invalid minimum compiler version")
+	ErrInvalidTimeoutEnv         = errors.New("invalid timeout environment variable name")
+	ErrImageNotAllowed           = errors.New("image is not in IMAGE_ALLOWLIST")
+	ErrInvalidImagePattern       = errors.New("invalid IMAGE_ALLOWLIST pattern")
+	ErrInvalidLibc               = errors.New("invalid libc")
+	ErrInvalidToolchainVersion   = errors.New("invalid toolchain version")
+	ErrInvalidSymbolTool         = errors.New("invalid symbol tool")
+	ErrInvalidOutputEncoding     = errors.New("invalid output encoding")
+	ErrInvalidToolchain          = errors.New("invalid toolchain")
+	ErrInvalidCompileScript      = errors.New("invalid compile script")
+	ErrInvalidCPUShares          = errors.New("invalid CPU shares")
+	ErrInvalidCompilerTimeout    = errors.New("invalid compiler timeout")
 )
 
+// Bounds of CompilerConfig.CPUShares, as accepted by Docker.
+const (
+	minCPUShares = 2
+	maxCPUShares = 262144
+)
+
+// envVarNamePattern matches a portable environment variable name.
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Config represents the parsed configuration from environments.yaml.
 type Config struct {
 	Environments []EnvironmentConfig `yaml:"environments"`
 	Limits       LimitsConfig        `yaml:"limits"`
 	RateLimits   RateLimitsConfig    `yaml:"rate_limits"`
+
+	// ImageAllowlist holds glob patterns (e.g., "gcc:*") that environment images must match;
+	// empty allows all images. It comes from IMAGE_ALLOWLIST, not the YAML, so a bad config can't widen it
+	ImageAllowlist []string `yaml:"-"`
+
+	// MinCompilerVersions maps a compiler family (e.g., "gcc") to the oldest version requests may select
+	MinCompilerVersions map[string]string `yaml:"min_compiler_versions"`
 }
 
 // EnvironmentConfig represents a language environment configuration.
 type EnvironmentConfig struct {
-	Language  string           `yaml:"language"`
-	Compilers []CompilerConfig `yaml:"compilers"`
+	Language   string           `yaml:"language"`
+	Compilers  []CompilerConfig `yaml:"compilers"`
+	DefaultRun bool             `yaml:"default_run"` // Run after compiling when a request leaves "run" unset (optional; default off)
 }
 
 // CompilerConfig represents a compiler configuration.
@@ -42,6 +80,40 @@ type CompilerConfig struct {
 	Standards     []string `yaml:"standards"`
 	Architectures []string `yaml:"architectures"`
 	OSes          []string `yaml:"oses"`
+	Analyzer      string   `yaml:"analyzer"`    // Static analyzer shipped in the image (optional)
+	Formatter     string   `yaml:"formatter"`   // Source formatter shipped in the image (optional; Go images always have gofmt)
+	TimeoutEnv    string   `yaml:"timeout_env"` // Variable the image reads the compile timeout from (optional; default COMPILE_TIMEOUT)
+	GNUTime       bool     `yaml:"gnu_time"`    // The image ships GNU time as /usr/bin/time, enabling "resource_usage" (optional)
+	SymbolTool    string   `yaml:"symbol_tool"` // Tool listing a binary's symbols: nm, objdump or go-nm, enabling "symbols" (optional; Go images always have go-nm)
+
+	// OutputEncoding is the encoding of the compiler's output: utf-8 or latin-1 (optional; default utf-8)
+	OutputEncoding string `yaml:"output_encoding"`
+
+	Libc       string            `yaml:"libc"`        // C library of the image: glibc or musl (optional; required with libc_images)
+	LibcImages map[string]string `yaml:"libc_images"` // Images of the same compiler built on another C library, by libc (optional)
+
+	// CompileScript is a script baked into the image (e.g., /usr/bin/compile.sh) that runs the compile, given the
+	// command in COMPILE_COMMAND (optional; by default the command runs with sh -c, which stock images support)
+	CompileScript string `yaml:"compile_script"`
+
+	// CPUShares is the relative CPU weight of the compile containers under contention, 2 to 262144 (optional; default 1024)
+	CPUShares int64 `yaml:"cpu_shares"`
+
+	// TimeoutSeconds is the default compile timeout of the environment, e.g. for slow Rust debug builds (optional; default 30)
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+
+	// ToolchainVersion pins the exact version the compiler in the image must report (e.g., "13.2.0"), checked at startup and reload (optional)
+	ToolchainVersion string `yaml:"toolchain_version"`
+
+	// Toolchains are images with a sysroot or cross toolchain, selected by name with the request "toolchain" option (optional; C/C++ only)
+	Toolchains map[string]ToolchainConfig `yaml:"toolchains"`
+}
+
+// ToolchainConfig represents a sysroot or cross toolchain baked into an image.
+type ToolchainConfig struct {
+	Image   string   `yaml:"image"`   // Image with the toolchain installed
+	Sysroot string   `yaml:"sysroot"` // Absolute path passed as --sysroot (optional)
+	Flags   []string `yaml:"flags"`   // Extra compiler flags, e.g. -mcpu=cortex-a53 (optional)
 }
 
 // LimitsConfig represents resource limits.
@@ -70,6 +142,7 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	config.ImageAllowlist = imageAllowlistFromEnv()
 
 	// Validate the configuration
 	if err := config.Validate(); err != nil {
@@ -102,9 +175,92 @@ func (c *Config) Validate() error {
 			if comp.Image == "" {
 				return fmt.Errorf("%w: environment[%d].compiler[%d]", ErrCompilerImageRequired, i, j)
 			}
+			if !models.Analyzer(comp.Analyzer).Valid() {
+				return fmt.Errorf("%w %q: environment[%d].compiler[%d]", ErrInvalidAnalyzer, comp.Analyzer, i, j)
+			}
+			if !models.Formatter(comp.Formatter).Valid() {
+				return fmt.Errorf("%w %q: environment[%d].compiler[%d]", ErrInvalidFormatter, comp.Formatter, i, j)
+			}
+			if !models.SymbolTool(comp.SymbolTool).Valid() {
+				return fmt.Errorf("%w %q: environment[%d].compiler[%d]", ErrInvalidSymbolTool, comp.SymbolTool, i, j)
+			}
+			if !models.OutputEncoding(comp.OutputEncoding).Valid() {
+				return fmt.Errorf("%w %q: environment[%d].compiler[%d]", ErrInvalidOutputEncoding, comp.OutputEncoding, i, j)
+			}
+			if comp.TimeoutEnv != "" && !envVarNamePattern.MatchString(comp.TimeoutEnv) {
+				return fmt.Errorf("%w %q: environment[%d].compiler[%d]", ErrInvalidTimeoutEnv, comp.TimeoutEnv, i, j)
+			}
+			if err := validateLibc(comp); err != nil {
+				return fmt.Errorf("%w: environment[%d].compiler[%d]", err, i, j)
+			}
+			if _, ok := parseCompilerVersion(comp.ToolchainVersion); comp.ToolchainVersion != "" && !ok {
+				return fmt.Errorf("%w %q: environment[%d].compiler[%d]", ErrInvalidToolchainVersion, comp.ToolchainVersion, i, j)
+			}
+			if err := validateToolchains(models.Language(env.Language), comp); err != nil {
+				return fmt.Errorf("%w: environment[%d].compiler[%d]", err, i, j)
+			}
+			if comp.CompileScript != "" && !compileScriptPattern.MatchString(comp.CompileScript) {
+				return fmt.Errorf("%w %q: environment[%d].compiler[%d]", ErrInvalidCompileScript, comp.CompileScript, i, j)
+			}
+			if comp.CPUShares != 0 && (comp.CPUShares < minCPUShares || comp.CPUShares > maxCPUShares) {
+				return fmt.Errorf("%w %d (must be %d to %d): environment[%d].compiler[%d]", ErrInvalidCPUShares, comp.CPUShares, minCPUShares, maxCPUShares, i, j)
+			}
+			if comp.TimeoutSeconds < 0 {
+				return fmt.Errorf("%w %d (must be positive): environment[%d].compiler[%d]", ErrInvalidCompilerTimeout, comp.TimeoutSeconds, i, j)
+			}
 		}
 	}
 
+	for family, version := range c.MinCompilerVersions {
+		if _, ok := parseCompilerVersion(version); !ok {
+			return fmt.Errorf("%w %q: min_compiler_versions.%s", ErrInvalidMinVersion, version, family)
+		}
+	}
+
+	return nil
+}
+
+// compileScriptPattern matches the absolute path of a compile script; it is run as the container command.
+var compileScriptPattern = regexp.MustCompile(`^/[A-Za-z0-9._/+-]+$`)
+
+// sysrootPattern matches an absolute sysroot path; it is passed to the compile command unquoted.
+var sysrootPattern = regexp.MustCompile(`^/[A-Za-z0-9._/+-]*$`)
+
+// validateToolchains checks the toolchains of a compiler: names requests can send, an image each, and a plain sysroot path.
+func validateToolchains(language models.Language, comp CompilerConfig) error {
+	if len(comp.Toolchains) > 0 && !language.IsCFamily() {
+		return fmt.Errorf("%w: toolchains are only supported for C and C++", ErrInvalidToolchain)
+	}
+	for name, toolchain := range comp.Toolchains {
+		if !models.ToolchainNamePattern.MatchString(name) {
+			return fmt.Errorf("%w %q", ErrInvalidToolchain, name)
+		}
+		if toolchain.Image == "" {
+			return fmt.Errorf("%w %q: image is required", ErrInvalidToolchain, name)
+		}
+		if toolchain.Sysroot != "" && !sysrootPattern.MatchString(toolchain.Sysroot) {
+			return fmt.Errorf("%w %q: invalid sysroot %q", ErrInvalidToolchain, name, toolchain.Sysroot)
+		}
+	}
+	return nil
+}
+
+// validateLibc checks the libc of a compiler's image and of its alternative images.
+func validateLibc(comp CompilerConfig) error {
+	if !models.Libc(comp.Libc).Valid() {
+		return fmt.Errorf("%w %q", ErrInvalidLibc, comp.Libc)
+	}
+	if len(comp.LibcImages) > 0 && comp.Libc == "" {
+		return fmt.Errorf("%w: libc_images requires libc", ErrInvalidLibc)
+	}
+	for libc, image := range comp.LibcImages {
+		if libc == "" || !models.Libc(libc).Valid() || libc == comp.Libc {
+			return fmt.Errorf("%w %q in libc_images", ErrInvalidLibc, libc)
+		}
+		if image == "" {
+			return fmt.Errorf("%w: libc_images.%s", ErrCompilerImageRequired, libc)
+		}
+	}
 	return nil
 }
 
@@ -154,7 +310,7 @@ func (c *Config) ToEnvironmentSpecs() (map[string]models.EnvironmentSpec, error)
 			envKey := fmt.Sprintf("%s-%s", language, compilerID)
 
 			// Create environment spec
-			envSpecs[envKey] = models.EnvironmentSpec{
+			spec := models.EnvironmentSpec{
 				Language:     language,
 				Compiler:     compiler,
 				Version:      compConfig.Version,
@@ -162,13 +318,139 @@ func (c *Config) ToEnvironmentSpecs() (map[string]models.EnvironmentSpec, error)
 				Architecture: defaultArch,
 				OS:           defaultOS,
 				ImageTag:     compConfig.Image,
+				Analyzer:     models.Analyzer(compConfig.Analyzer),
+				Formatter:    models.Formatter(compConfig.Formatter),
+				TimeoutEnv:   compConfig.TimeoutEnv,
+				DefaultRun:   envConfig.DefaultRun,
+				GNUTime:      compConfig.GNUTime,
+				SymbolTool:   models.SymbolTool(compConfig.SymbolTool),
+				Libc:         models.Libc(compConfig.Libc),
+
+				CompileScript:    compConfig.CompileScript,
+				OutputEncoding:   models.OutputEncoding(compConfig.OutputEncoding),
+				ToolchainVersion: compConfig.ToolchainVersion,
+				CPUShares:        compConfig.CPUShares,
+				Timeout:          time.Duration(compConfig.TimeoutSeconds) * time.Second,
+			}
+			if len(compConfig.Toolchains) > 0 {
+				spec.Toolchains = make(map[string]models.Toolchain, len(compConfig.Toolchains))
+				for name, toolchain := range compConfig.Toolchains {
+					spec.Toolchains[name] = models.Toolchain(toolchain)
+				}
 			}
+			if len(compConfig.LibcImages) > 0 {
+				spec.LibcImages = make(map[models.Libc]string, len(compConfig.LibcImages))
+				for libc, image := range compConfig.LibcImages {
+					spec.LibcImages[models.Libc(libc)] = image
+				}
+			}
+			if spec.Formatter == "" && language == models.LanguageGo {
+				spec.Formatter = models.FormatterGofmt // Part of the Go toolchain
+			}
+			if spec.SymbolTool == "" && language == models.LanguageGo {
+				spec.SymbolTool = models.SymbolToolGoNm // Part of the Go toolchain
+			}
+			spec.Capabilities = environmentCapabilities(spec)
+			envSpecs[envKey] = spec
 		}
 	}
 
+	if err := checkImageAllowlist(envSpecs, c.ImageAllowlist); err != nil {
+		return nil, err
+	}
+
 	return envSpecs, nil
 }
 
+// imageAllowlistFromEnv reads the comma-separated image patterns of IMAGE_ALLOWLIST.
+func imageAllowlistFromEnv() []string {
+	var patterns []string
+	for _, pattern := range strings.Split(os.Getenv("IMAGE_ALLOWLIST"), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// checkImageAllowlist returns an error for the first environment whose image matches none of the patterns.
+// Patterns use path.Match syntax, so "*" does not cross a "/" (e.g., "ghcr.io/acme/*").
+func checkImageAllowlist(environments map[string]models.EnvironmentSpec, patterns []string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w %q: %v", ErrInvalidImagePattern, pattern, err)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(environments)) {
+		for _, image := range environmentImages(environments[key]) {
+			allowed := slices.ContainsFunc(patterns, func(pattern string) bool {
+				matched, _ := path.Match(pattern, image) //nolint:errcheck // patterns validated above
+				return matched
+			})
+			if !allowed {
+				return fmt.Errorf("%w: %s (environment %s)", ErrImageNotAllowed, image, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// environmentImages returns every image an environment may run: its own, then its libc alternatives
+// and its toolchain images in order.
+func environmentImages(spec models.EnvironmentSpec) []string {
+	images := []string{spec.ImageTag}
+	for _, libc := range slices.Sorted(maps.Keys(spec.LibcImages)) {
+		images = append(images, spec.LibcImages[libc])
+	}
+	for _, name := range slices.Sorted(maps.Keys(spec.Toolchains)) {
+		images = append(images, spec.Toolchains[name].Image)
+	}
+	return images
+}
+
+// parseCompilerVersion parses a dotted numeric version such as "13" or "1.70".
+func parseCompilerVersion(version string) ([]int, bool) {
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
+
+// belowMinimumVersion reports whether a compiler is older than the minimum configured for its family.
+// Compilers whose family has no minimum, or whose version isn't numeric, are never below it.
+func belowMinimumVersion(compiler models.Compiler, minVersions map[string]string) (string, bool) {
+	family, version, ok := strings.Cut(string(compiler), "-")
+	if !ok {
+		return "", false
+	}
+	minimum, ok := minVersions[family]
+	if !ok {
+		return "", false
+	}
+
+	have, ok := parseCompilerVersion(version)
+	if !ok {
+		return "", false
+	}
+	want, ok := parseCompilerVersion(minimum)
+	if !ok {
+		return "", false
+	}
+	return minimum, slices.Compare(have, want) < 0
+}
+
 // GetDefaultConfigPath returns the default path to the configuration file.
 func GetDefaultConfigPath() string {
 	// Try to find the config file relative to the project root
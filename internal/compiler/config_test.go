@@ -1,11 +1,14 @@
 package compiler
 
 import (
+	"context"
+	"encoding/base64"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -154,6 +157,238 @@ func TestConfigValidate(t *testing.T) {
 			expectErr: true,
 			errMsg:    "image is required",
 		},
+		{
+			name: "invalid_analyzer",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "cpp",
+						Compilers: []CompilerConfig{
+							{Name: "gcc", Version: "13", Image: "gcc:13", Analyzer: "lint-everything"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid analyzer",
+		},
+		{
+			name: "invalid_formatter",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "cpp",
+						Compilers: []CompilerConfig{
+							{Name: "gcc", Version: "13", Image: "gcc:13", Formatter: "prettier"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid formatter",
+		},
+		{
+			name: "invalid_symbol_tool",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "cpp",
+						Compilers: []CompilerConfig{
+							{Name: "gcc", Version: "13", Image: "gcc:13", SymbolTool: "readelf"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid symbol tool",
+		},
+		{
+			name: "invalid_compile_script",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language:  "cpp",
+						Compilers: []CompilerConfig{{Name: "gcc", Version: "13", Image: "gcc:13", CompileScript: "compile.sh; reboot"}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid compile script",
+		},
+		{
+			name: "invalid_cpu_shares",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language:  "cpp",
+						Compilers: []CompilerConfig{{Name: "gcc", Version: "13", Image: "gcc:13", CPUShares: 1}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid CPU shares",
+		},
+		{
+			name: "negative_timeout",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language:  "rust",
+						Compilers: []CompilerConfig{{Name: "rustc", Version: "1.75", Image: "rust:1.75", TimeoutSeconds: -1}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid compiler timeout",
+		},
+		{
+			name: "invalid_output_encoding",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language:  "cpp",
+						Compilers: []CompilerConfig{{Name: "gcc", Version: "13", Image: "gcc:13", OutputEncoding: "shift-jis"}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid output encoding",
+		},
+		{
+			name: "invalid_timeout_env",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language:  "cpp",
+						Compilers: []CompilerConfig{{Name: "gcc", Version: "13", Image: "gcc:13", TimeoutEnv: "TIMEOUT;reboot"}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid timeout environment variable name",
+		},
+		{
+			name: "invalid_min_compiler_version",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language:  "cpp",
+						Compilers: []CompilerConfig{{Name: "gcc", Version: "13", Image: "gcc:13"}},
+					},
+				},
+				MinCompilerVersions: map[string]string{"gcc": "eleven"},
+			},
+			expectErr: true,
+			errMsg:    "invalid minimum compiler version",
+		},
+		{
+			name: "invalid_libc",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language:  "go",
+						Compilers: []CompilerConfig{{Name: "go", Version: "1.23", Image: "golang:1.23-alpine", Libc: "uclibc"}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid libc \"uclibc\"",
+		},
+		{
+			name: "libc_images_without_libc",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "go",
+						Compilers: []CompilerConfig{{
+							Name: "go", Version: "1.23", Image: "golang:1.23-alpine",
+							LibcImages: map[string]string{"glibc": "golang:1.23-bookworm"},
+						}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "libc_images requires libc",
+		},
+		{
+			name: "libc_image_same_as_default",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "go",
+						Compilers: []CompilerConfig{{
+							Name: "go", Version: "1.23", Image: "golang:1.23-alpine", Libc: "musl",
+							LibcImages: map[string]string{"musl": "golang:1.23-alpine3.20"},
+						}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid libc \"musl\" in libc_images",
+		},
+		{
+			name: "invalid_toolchain_version",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "cpp",
+						Compilers: []CompilerConfig{{
+							Name: "gcc", Version: "13", Image: "gcc:13", ToolchainVersion: "13.2.x",
+						}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid toolchain version \"13.2.x\"",
+		},
+		{
+			name: "toolchain_without_image",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "cpp",
+						Compilers: []CompilerConfig{{
+							Name: "gcc", Version: "13", Image: "gcc:13",
+							Toolchains: map[string]ToolchainConfig{"rpi-aarch64": {Sysroot: "/opt/sysroot"}},
+						}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid toolchain \"rpi-aarch64\": image is required",
+		},
+		{
+			name: "toolchain_unsafe_sysroot",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "c",
+						Compilers: []CompilerConfig{{
+							Name: "gcc", Version: "13", Image: "gcc:13",
+							Toolchains: map[string]ToolchainConfig{"board": {Image: "acme/board:1", Sysroot: "/opt/sysroot; rm -rf /"}},
+						}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "invalid sysroot",
+		},
+		{
+			name: "toolchain_not_c_family",
+			config: Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "go",
+						Compilers: []CompilerConfig{{
+							Name: "go", Version: "1.23", Image: "golang:1.23-alpine",
+							Toolchains: map[string]ToolchainConfig{"board": {Image: "acme/board:1"}},
+						}},
+					},
+				},
+			},
+			expectErr: true,
+			errMsg:    "toolchains are only supported for C and C++",
+		},
 	}
 
 	for _, tc := range tests {
@@ -184,6 +419,7 @@ func TestConfigToEnvironmentSpecs(t *testing.T) {
 						Standards:     []string{"c++11", "c++14", "c++17", "c++20", "c++23"},
 						Architectures: []string{"x86_64"},
 						OSes:          []string{"linux"},
+						Analyzer:      "cppcheck",
 					},
 				},
 			},
@@ -204,6 +440,54 @@ func TestConfigToEnvironmentSpecs(t *testing.T) {
 	assert.Equal(t, models.ArchX86_64, spec.Architecture)
 	assert.Equal(t, models.OSLinux, spec.OS)
 	assert.Equal(t, "gcc:13", spec.ImageTag)
+	assert.Equal(t, models.AnalyzerCppcheck, spec.Analyzer)
+	assert.Equal(t, models.Capabilities{
+		Run:              true,
+		JSONDiagnostics:  true,
+		DiagnosticsWidth: true,
+		Linker:           true,
+		Analyze:          true,
+		Dependencies:     true,
+		WerrorFor:        true,
+		CompileOnly:      true,
+		DialectOptions:   true,
+		Quick:            true,
+		Preprocess:       true,
+		Flags:            true,
+		HeaderOnly:       true,
+		Deterministic:    true,
+	}, spec.Capabilities)
+}
+
+// TestConfigToEnvironmentSpecs_Capabilities tests that capabilities follow the configured compiler.
+func TestConfigToEnvironmentSpecs_Capabilities(t *testing.T) {
+	config := Config{
+		Environments: []EnvironmentConfig{
+			{
+				Language: "c",
+				Compilers: []CompilerConfig{
+					{Name: "zig", Version: "0.13", Image: zigImage, Standards: []string{"c17"}},
+				},
+			},
+			{
+				Language: "go",
+				Compilers: []CompilerConfig{
+					{Name: "go", Version: "1.23", Image: "golang:1.23-alpine"},
+				},
+			},
+		},
+	}
+
+	envSpecs, err := config.ToEnvironmentSpecs()
+	require.NoError(t, err)
+
+	zig := envSpecs["c-zig-0.13"].Capabilities
+	assert.True(t, zig.CrossCompile)
+	assert.False(t, zig.JSONDiagnostics, "zig cc does not emit GCC JSON diagnostics")
+	assert.False(t, zig.Analyze, "No analyzer configured")
+
+	goCaps := envSpecs["go-go-1.23"].Capabilities
+	assert.Equal(t, models.Capabilities{Run: true, Test: true, CheckFormat: true, Symbols: true, Flags: true, Deterministic: true}, goCaps, "Go images always have go tool nm")
 }
 
 func TestConfigToEnvironmentSpecs_UnsupportedLanguage(t *testing.T) {
@@ -253,6 +537,290 @@ func TestConfigToEnvironmentSpecs_DefaultValues(t *testing.T) {
 	assert.Equal(t, models.OSLinux, spec.OS, "Should default to Linux")
 }
 
+// TestConfigDefaultRun tests that an environment's default_run applies when a request leaves run unset.
+func TestConfigDefaultRun(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "environments.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`environments:
+  - language: go
+    default_run: true
+    compilers:
+      - name: go
+        version: "1.22"
+        image: golang:1.22
+  - language: cpp
+    compilers:
+      - name: gcc
+        version: "13"
+        image: gcc:13
+`), 0o600))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	environments, err := config.ToEnvironmentSpecs()
+	require.NoError(t, err)
+	assert.True(t, environments["go-go-1.22"].DefaultRun)
+	assert.False(t, environments["cpp-gcc-13"].DefaultRun, "The global default is off")
+
+	var capturedConfig runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	})
+	compiler.environments = environments
+
+	testCases := []struct {
+		name     string
+		language models.Language
+		compiler models.Compiler
+		run      *bool
+		test     bool
+		expected bool
+	}{
+		{"environment_default_on", models.LanguageGo, models.CompilerGo122, nil, false, true},
+		{"request_overrides_default_on", models.LanguageGo, models.CompilerGo122, boolPtr(false), false, false},
+		{"test_mode_skips_default", models.LanguageGo, models.CompilerGo122, nil, true, false},
+		{"environment_default_off", models.LanguageCpp, models.CompilerGCC13, nil, false, false},
+		{"request_overrides_default_off", models.LanguageCpp, models.CompilerGCC13, boolPtr(true), false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-default-run",
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0; }")),
+					Language: tc.language,
+					Compiler: tc.compiler,
+					Run:      tc.run,
+					Test:     tc.test,
+				},
+			})
+
+			require.Empty(t, result.Error)
+			assert.Equal(t, tc.expected, capturedConfig.RunCommand != "", "RunCommand: %q", capturedConfig.RunCommand)
+		})
+	}
+}
+
+// TestConfigLibc tests that the libc option selects the image built on that C library.
+func TestConfigLibc(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "environments.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`environments:
+  - language: go
+    compilers:
+      - name: go
+        version: "1.23"
+        image: golang:1.23-alpine
+        libc: musl
+        libc_images:
+          glibc: golang:1.23-bookworm
+  - language: cpp
+    compilers:
+      - name: gcc
+        version: "13"
+        image: gcc:13
+`), 0o600))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	environments, err := config.ToEnvironmentSpecs()
+	require.NoError(t, err)
+	assert.Equal(t, []models.Libc{models.LibcMusl, models.LibcGlibc}, environments["go-go-1.23"].Capabilities.Libc)
+	assert.Empty(t, environments["cpp-gcc-13"].Capabilities.Libc, "No libc configured, no choice offered")
+
+	var capturedConfig runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	})
+	compiler.environments = environments
+
+	testCases := []struct {
+		name          string
+		language      models.Language
+		compiler      models.Compiler
+		libc          models.Libc
+		expectedImage string
+		errorMsg      string
+	}{
+		{"default_image", models.LanguageGo, models.CompilerGo123, "", "golang:1.23-alpine", ""},
+		{"default_libc", models.LanguageGo, models.CompilerGo123, models.LibcMusl, "golang:1.23-alpine", ""},
+		{"glibc_variant", models.LanguageGo, models.CompilerGo123, models.LibcGlibc, "golang:1.23-bookworm", ""},
+		{"libc_not_configured", models.LanguageCpp, models.CompilerGCC13, models.LibcMusl, "",
+			"environment has no image for the requested libc: cpp with gcc-13 has no musl image"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			capturedConfig = runtime.CompilationConfig{}
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-libc",
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte("package main\nfunc main() {}")),
+					Language: tc.language,
+					Compiler: tc.compiler,
+					Libc:     tc.libc,
+				},
+			})
+
+			if tc.errorMsg != "" {
+				assert.Equal(t, tc.errorMsg, result.Error)
+				assert.Empty(t, capturedConfig.ImageTag, "Nothing is compiled")
+				return
+			}
+			require.Empty(t, result.Error)
+			assert.Equal(t, tc.expectedImage, capturedConfig.ImageTag)
+		})
+	}
+}
+
+// TestConfigToolchain tests that the toolchain option selects the toolchain's image, sysroot and flags.
+func TestConfigToolchain(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "environments.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`environments:
+  - language: c
+    compilers:
+      - name: gcc
+        version: "13"
+        image: gcc:13
+        standards: [c17]
+        toolchains:
+          rpi-aarch64:
+            image: acme/gcc13-rpi:1.0
+            sysroot: /opt/sysroots/rpi-aarch64
+            flags: ["-mcpu=cortex-a53"]
+          stm32:
+            image: acme/gcc13-stm32:1.0
+`), 0o600))
+
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	environments, err := config.ToEnvironmentSpecs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rpi-aarch64", "stm32"}, environments["c-gcc-13"].Capabilities.Toolchains)
+	assert.Equal(t, []string{"gcc:13", "acme/gcc13-rpi:1.0", "acme/gcc13-stm32:1.0"}, environmentImages(environments["c-gcc-13"]))
+
+	var capturedConfig runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	})
+	compiler.environments = environments
+
+	testCases := []struct {
+		name            string
+		toolchain       string
+		expectedImage   string
+		expectedCommand string
+		errorMsg        string
+	}{
+		{"default_image", "", "gcc:13", "gcc -std=c17 /workspace/source.c -o /workspace/output", ""},
+		{"sysroot_and_flags", "rpi-aarch64", "acme/gcc13-rpi:1.0",
+			"gcc -std=c17 --sysroot=/opt/sysroots/rpi-aarch64 -mcpu=cortex-a53 /workspace/source.c -o /workspace/output", ""},
+		{"image_only", "stm32", "acme/gcc13-stm32:1.0", "gcc -std=c17 /workspace/source.c -o /workspace/output", ""},
+		{"unknown_toolchain", "esp32", "", "",
+			`environment has no such toolchain: c with gcc-13 has no toolchain "esp32" (available: rpi-aarch64, stm32)`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			capturedConfig = runtime.CompilationConfig{}
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-toolchain",
+				Request: models.CompilationRequest{
+					Code:      base64.StdEncoding.EncodeToString([]byte("int main(void) { return 0; }")),
+					Language:  models.LanguageC,
+					Compiler:  models.CompilerGCC13,
+					Toolchain: tc.toolchain,
+				},
+			})
+
+			if tc.errorMsg != "" {
+				assert.Equal(t, tc.errorMsg, result.Error)
+				assert.Empty(t, capturedConfig.ImageTag, "Nothing is compiled")
+				return
+			}
+			require.Empty(t, result.Error)
+			assert.Equal(t, tc.expectedImage, capturedConfig.ImageTag)
+			assert.Equal(t, tc.expectedCommand, capturedConfig.CompileCommand)
+		})
+	}
+}
+
+// TestConfigToEnvironmentSpecs_ImageAllowlist tests that environments with images outside the allowlist are rejected.
+func TestConfigToEnvironmentSpecs_ImageAllowlist(t *testing.T) {
+	testCases := []struct {
+		name      string
+		allowlist []string
+		expected  error
+	}{
+		{"empty_allows_all", nil, nil},
+		{"all_allowed", []string{"gcc:*", "ghcr.io/acme/*"}, nil},
+		{"image_not_allowed", []string{"gcc:*"}, ErrImageNotAllowed},
+		{"star_does_not_cross_slash", []string{"gcc:*", "ghcr.io/*"}, ErrImageNotAllowed},
+		{"invalid_pattern", []string{"gcc:["}, ErrInvalidImagePattern},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := Config{
+				Environments: []EnvironmentConfig{
+					{
+						Language: "cpp",
+						Compilers: []CompilerConfig{
+							{Name: "gcc", Version: "13", Image: "gcc:13"},
+							{Name: "clang", Version: "18", Image: "ghcr.io/acme/clang:18"},
+						},
+					},
+				},
+				ImageAllowlist: tc.allowlist,
+			}
+
+			_, err := config.ToEnvironmentSpecs()
+			if tc.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+// TestLoadConfig_ImageAllowlistFromEnv tests that IMAGE_ALLOWLIST is applied to the loaded configuration.
+func TestLoadConfig_ImageAllowlistFromEnv(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "environments.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`environments:
+  - language: c
+    compilers:
+      - name: gcc
+        version: "13"
+        image: gcc:13
+      - name: zig
+        version: "0.13"
+        image: euantorano/zig:0.13.0
+`), 0o600))
+
+	t.Setenv("IMAGE_ALLOWLIST", "gcc:*, euantorano/zig:*")
+	config, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gcc:*", "euantorano/zig:*"}, config.ImageAllowlist)
+	_, err = config.ToEnvironmentSpecs()
+	assert.NoError(t, err)
+
+	t.Setenv("IMAGE_ALLOWLIST", "gcc:*")
+	config, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	_, err = config.ToEnvironmentSpecs()
+	assert.ErrorIs(t, err, ErrImageNotAllowed)
+	assert.Contains(t, err.Error(), "euantorano/zig:0.13.0")
+}
+
 func TestGetDefaultConfigPath(t *testing.T) {
 	path := GetDefaultConfigPath()
 	assert.NotEmpty(t, path)
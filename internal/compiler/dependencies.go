@@ -0,0 +1,71 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+)
+
+// buildDependenciesCommand builds a preprocessor-only command printing make rules for the sources.
+// -MM lists the headers a source includes, leaving out system headers.
+func buildDependenciesCommand(env models.EnvironmentSpec, sources ...string) string {
+	flags := ""
+	if len(env.Flags) > 0 {
+		flags = " " + strings.Join(env.Flags, " ")
+	}
+	sourceFilename := strings.Join(sources, " /workspace/")
+
+	return fmt.Sprintf("%s -std=%s%s -MM /workspace/%s", cFamilyDriver(env), env.Standard, flags, sourceFilename)
+}
+
+// dependencies runs the preprocessor in a separate container and returns the included headers.
+// The compile config is reused without the run step or binary output.
+func (c *Compiler) dependencies(ctx context.Context, config runtime.CompilationConfig, env models.EnvironmentSpec, sources []string) ([]string, error) {
+	config.CompileCommand = buildDependenciesCommand(env, sources...)
+	config.RunCommand = ""
+	config.OutputPath = ""
+
+	output, err := c.runtime.Compile(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if output.TimedOut {
+		return nil, ErrDependenciesTimeout
+	}
+
+	return parseDependencies(output.Stdout, sources), nil
+}
+
+// parseDependencies extracts the prerequisites from -MM make rules such as
+//
+//	source.o: /workspace/source.cpp /workspace/util.h \
+//	  /workspace/lib/config.h
+//
+// Paths are made relative to the workspace; the sources themselves and duplicates are dropped.
+func parseDependencies(output string, sources []string) []string {
+	var includes []string
+
+	// Continuation lines belong to the rule above
+	joined := strings.ReplaceAll(output, "\\\n", " ")
+
+	for _, line := range strings.Split(joined, "\n") {
+		_, prerequisites, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		for _, path := range strings.Fields(prerequisites) {
+			path = strings.TrimPrefix(path, "/workspace/")
+			if slices.Contains(sources, path) || slices.Contains(includes, path) {
+				continue
+			}
+			includes = append(includes, path)
+		}
+	}
+
+	return includes
+}
@@ -0,0 +1,107 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseDependencies tests parsing of -MM make rules.
+func TestParseDependencies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		sources  []string
+		expected []string
+	}{
+		{
+			name:     "no_local_headers",
+			output:   "source.o: /workspace/source.cpp\n",
+			sources:  []string{"source.cpp"},
+			expected: nil,
+		},
+		{
+			name: "continuation_lines",
+			output: "source.o: /workspace/source.cpp /workspace/util.h \\\n" +
+				" /workspace/lib/config.h\n",
+			sources:  []string{"source.cpp"},
+			expected: []string{"util.h", "lib/config.h"},
+		},
+		{
+			name: "multiple_sources_deduplicated",
+			output: "main.o: /workspace/main.c /workspace/util.h\n" +
+				"util.o: /workspace/util.c /workspace/util.h \\\n" +
+				" /workspace/common.h\n",
+			sources:  []string{"main.c", "util.c"},
+			expected: []string{"util.h", "common.h"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseDependencies(tc.output, tc.sources))
+		})
+	}
+}
+
+// TestBuildDependenciesCommand tests the preprocessor command for gcc and zig.
+func TestBuildDependenciesCommand(t *testing.T) {
+	env := models.EnvironmentSpec{Language: models.LanguageCpp, Standard: models.StandardCpp17}
+	assert.Equal(t, "g++ -std=c++17 -MM /workspace/main.cpp /workspace/util.cpp",
+		buildDependenciesCommand(env, "main.cpp", "util.cpp"))
+
+	env = models.EnvironmentSpec{
+		Language: models.LanguageC,
+		Compiler: models.CompilerZig,
+		Standard: models.StandardC11,
+		Flags:    []string{"-target", "aarch64-linux-musl"},
+	}
+	assert.Equal(t, "zig cc -std=c11 -target aarch64-linux-musl -MM /workspace/source.c",
+		buildDependenciesCommand(env, "source.c"))
+}
+
+// TestCompile_Dependencies tests that the include list is returned alongside the compile result.
+func TestCompile_Dependencies(t *testing.T) {
+	var commands []string
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			commands = append(commands, config.CompileCommand)
+			if config.OutputPath == "" {
+				return &runtime.CompilationOutput{
+					Stdout: "main.o: /workspace/main.c /workspace/util.h\n",
+				}, nil
+			}
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	archive := buildTestArchive(t, false,
+		archiveEntry{name: "main.c", content: `#include "util.h"` + "\nint main(void) { return 0; }"},
+		archiveEntry{name: "util.h", content: "int util(void);"},
+	)
+
+	job := models.CompilationJob{
+		ID: "test-dependencies",
+		Request: models.CompilationRequest{
+			Archive:      base64.StdEncoding.EncodeToString(archive),
+			Language:     models.LanguageC,
+			Compiler:     models.CompilerGCC13,
+			Dependencies: true,
+		},
+	}
+
+	result := compiler.Compile(context.Background(), job)
+
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Equal(t, []string{"util.h"}, result.Includes)
+	require.Len(t, commands, 2)
+	assert.Equal(t, "gcc -std=c17 -MM /workspace/main.c", commands[1])
+}
@@ -0,0 +1,208 @@
+package compiler
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// gccTextDiagnostic matches GCC-style diagnostics, e.g.
+// "/workspace/source.cpp:3:5: error: expected ';' before 'return'".
+var gccTextDiagnostic = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)? (fatal error|error|warning|note): (.*)$`)
+
+// rustDiagnostic matches the header line of a rustc diagnostic, e.g. "error[E0308]: mismatched types";
+// its location follows on a "  --> main.rs:3:18" line.
+var rustDiagnostic = regexp.MustCompile(`^(error|warning|note|help)(?:\[(\w+)\])?: (.*)$`)
+
+// rustLocation matches the location line that follows a rustc diagnostic header.
+var rustLocation = regexp.MustCompile(`^\s*--> (.+?):(\d+):(\d+)$`)
+
+// rustSummary matches rustc's closing tallies, which are reported as location-less diagnostics.
+var rustSummary = regexp.MustCompile(`^(aborting due to|\d+ warnings? emitted)`)
+
+// gccJSONDiagnostic is a single entry of GCC's -fdiagnostics-format=json output.
+type gccJSONDiagnostic struct {
+	Kind      string              `json:"kind"`
+	Message   string              `json:"message"`
+	Locations []gccJSONLocation   `json:"locations"`
+	Children  []gccJSONDiagnostic `json:"children"`
+}
+
+// gccJSONLocation is a source range attached to a GCC JSON diagnostic.
+type gccJSONLocation struct {
+	Caret struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	} `json:"caret"`
+}
+
+// supportsJSONDiagnostics reports whether the language's compiler understands -fdiagnostics-format=json.
+func supportsJSONDiagnostics(language models.Language) bool {
+	return language == models.LanguageC || language == models.LanguageCpp
+}
+
+// parseDiagnostics extracts structured diagnostics from compiler stderr.
+// JSON output is preferred when requested; text parsing is used as a fallback.
+// Rust has its own text format.
+func parseDiagnostics(format models.DiagnosticsFormat, language models.Language, stderr string) []models.Diagnostic {
+	if format == models.DiagnosticsFormatJSON && supportsJSONDiagnostics(language) {
+		if diagnostics, err := parseJSONDiagnostics(stderr); err == nil {
+			return diagnostics
+		}
+	}
+	if language == models.LanguageRust {
+		return parseRustDiagnostics(stderr)
+	}
+	return parseTextDiagnostics(stderr)
+}
+
+// parseJSONDiagnostics parses GCC's -fdiagnostics-format=json output.
+// Child diagnostics (usually notes) are flattened after their parent.
+func parseJSONDiagnostics(stderr string) ([]models.Diagnostic, error) {
+	// GCC prints the JSON array on a single line; anything else (e.g. linker output) is ignored
+	var entries []gccJSONDiagnostic
+	found := false
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		if err := json.Unmarshal([]byte(line), &entries); err != nil {
+			return nil, err
+		}
+		found = true
+		break
+	}
+	if !found {
+		return nil, ErrNoJSONDiagnostics
+	}
+
+	diagnostics := []models.Diagnostic{}
+	var flatten func(entries []gccJSONDiagnostic)
+	flatten = func(entries []gccJSONDiagnostic) {
+		for _, entry := range entries {
+			diagnostic := models.Diagnostic{
+				Severity: entry.Kind,
+				Message:  entry.Message,
+			}
+			if len(entry.Locations) > 0 {
+				caret := entry.Locations[0].Caret
+				diagnostic.File = trimWorkspacePath(caret.File)
+				diagnostic.Line = caret.Line
+				diagnostic.Column = caret.Column
+			}
+			diagnostics = append(diagnostics, diagnostic)
+			flatten(entry.Children)
+		}
+	}
+	flatten(entries)
+
+	return diagnostics, nil
+}
+
+// parseTextDiagnostics parses GCC-style "file:line:col: severity: message" lines.
+// Lines that don't match (context, carets, summaries) are skipped.
+func parseTextDiagnostics(stderr string) []models.Diagnostic {
+	var diagnostics []models.Diagnostic
+
+	for _, line := range strings.Split(stderr, "\n") {
+		match := gccTextDiagnostic.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+
+		lineNum, _ := strconv.Atoi(match[2]) //nolint:errcheck // regex guarantees digits
+		column, _ := strconv.Atoi(match[3])  //nolint:errcheck // optional group, 0 if absent
+
+		diagnostics = append(diagnostics, models.Diagnostic{
+			File:     trimWorkspacePath(match[1]),
+			Line:     lineNum,
+			Column:   column,
+			Severity: match[4],
+			Message:  match[5],
+		})
+	}
+
+	return diagnostics
+}
+
+// parseRustDiagnostics parses rustc's "error[E0308]: message" diagnostics and the "--> file:line:col"
+// location that follows each. Source snippets, sub-notes ("= note: ...") and the closing tallies are skipped.
+func parseRustDiagnostics(stderr string) []models.Diagnostic {
+	var diagnostics []models.Diagnostic
+
+	lines := strings.Split(stderr, "\n")
+	for i, line := range lines {
+		match := rustDiagnostic.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match == nil {
+			continue
+		}
+
+		diagnostic := models.Diagnostic{
+			Severity: match[1],
+			Code:     match[2],
+			Message:  match[3],
+		}
+		if i+1 < len(lines) {
+			if location := rustLocation.FindStringSubmatch(strings.TrimRight(lines[i+1], "\r")); location != nil {
+				diagnostic.File = trimWorkspacePath(location[1])
+				diagnostic.Line, _ = strconv.Atoi(location[2])   //nolint:errcheck // regex guarantees digits
+				diagnostic.Column, _ = strconv.Atoi(location[3]) //nolint:errcheck // regex guarantees digits
+			}
+		}
+		if diagnostic.File == "" && rustSummary.MatchString(diagnostic.Message) {
+			continue
+		}
+
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	return diagnostics
+}
+
+// groupDiagnostics splits diagnostics by severity into "file:line:col: message" strings.
+// Fatal errors are grouped with errors; other severities (e.g. remarks) are left out.
+func groupDiagnostics(diagnostics []models.Diagnostic) (errors, warnings, notes []string) {
+	for _, diagnostic := range diagnostics {
+		switch diagnostic.Severity {
+		case "error", "fatal error":
+			errors = append(errors, formatDiagnostic(diagnostic))
+		case "warning":
+			warnings = append(warnings, formatDiagnostic(diagnostic))
+		case "note":
+			notes = append(notes, formatDiagnostic(diagnostic))
+		}
+	}
+	return errors, warnings, notes
+}
+
+// formatDiagnostic renders a diagnostic as "file:line:col: message", omitting unknown location parts.
+func formatDiagnostic(diagnostic models.Diagnostic) string {
+	location := diagnosticLocation(diagnostic)
+	if location == "" {
+		return diagnostic.Message
+	}
+	return location + ": " + diagnostic.Message
+}
+
+// diagnosticLocation renders the "file:line:col" of a diagnostic, omitting unknown parts.
+func diagnosticLocation(diagnostic models.Diagnostic) string {
+	location := diagnostic.File
+	if location != "" && diagnostic.Line > 0 {
+		location += ":" + strconv.Itoa(diagnostic.Line)
+		if diagnostic.Column > 0 {
+			location += ":" + strconv.Itoa(diagnostic.Column)
+		}
+	}
+	return location
+}
+
+// trimWorkspacePath strips the container workspace prefix so paths match the submitted file names.
+func trimWorkspacePath(path string) string {
+	path = strings.TrimPrefix(path, "/tmp/workspace/") // Kubernetes runtime
+	return strings.TrimPrefix(path, "/workspace/")
+}
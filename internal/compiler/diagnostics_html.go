@@ -0,0 +1,71 @@
+package compiler
+
+import (
+	"html"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/internal/ansi"
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// renderDiagnosticsHTML renders diagnostics as an HTML fragment for web clients. Each diagnostic is a
+// div with a "diagnostic-<severity>" class, its severity wrapped in a "severity severity-<severity>" span
+// (e.g. "severity-fatal-error"), and, when the line is found in sources, the source line with a caret under
+// the column. All compiler and source text is escaped; colors left in a message by runtimes that don't strip them
+// become ansi.ToHTML spans. sources maps workspace-relative paths to file contents.
+func renderDiagnosticsHTML(diagnostics []models.Diagnostic, sources map[string]string) string {
+	if len(diagnostics) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<div class="diagnostics">`)
+	for _, diagnostic := range diagnostics {
+		severityClass := html.EscapeString(strings.ReplaceAll(diagnostic.Severity, " ", "-"))
+
+		b.WriteString(`<div class="diagnostic diagnostic-` + severityClass + `">`)
+		if location := diagnosticLocation(diagnostic); location != "" {
+			b.WriteString(`<span class="location">` + html.EscapeString(location) + `:</span> `)
+		}
+		b.WriteString(`<span class="severity severity-` + severityClass + `">` + html.EscapeString(diagnostic.Severity) + `:</span> `)
+		b.WriteString(`<span class="message">` + ansi.ToHTML(diagnostic.Message) + `</span>`)
+		if context, ok := sourceLine(sources, diagnostic.File, diagnostic.Line); ok {
+			b.WriteString(`<pre class="source">` + html.EscapeString(context))
+			if caret := caretLine(context, diagnostic.Column); caret != "" {
+				b.WriteString("\n" + `<span class="caret">` + caret + `</span>`)
+			}
+			b.WriteString(`</pre>`)
+		}
+		b.WriteString(`</div>`)
+	}
+	b.WriteString(`</div>`)
+	return b.String()
+}
+
+// sourceLine returns the 1-based line of a source file, without its line ending.
+func sourceLine(sources map[string]string, file string, line int) (string, bool) {
+	content, ok := sources[file]
+	if !ok || line <= 0 {
+		return "", false
+	}
+	lines := strings.Split(content, "\n")
+	if line > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[line-1], "\r"), true
+}
+
+// caretLine returns the padding and "^" marking a 1-based column of a source line. Tabs before
+// the column are kept so the caret lines up however the client renders them.
+func caretLine(line string, column int) string {
+	if column <= 0 || column > len(line)+1 {
+		return ""
+	}
+	padding := []byte(line[:column-1])
+	for i, c := range padding {
+		if c != '\t' {
+			padding[i] = ' '
+		}
+	}
+	return string(padding) + "^"
+}
@@ -0,0 +1,86 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRenderDiagnosticsHTML tests that severities are wrapped in classed spans and all text is escaped.
+func TestRenderDiagnosticsHTML(t *testing.T) {
+	sources := map[string]string{
+		"source.cpp": "#include <vector>\nint main() {\n\tif (a < b && c > d) return \"<b>\";\n}\n",
+	}
+	diagnostics := []models.Diagnostic{
+		{File: "source.cpp", Line: 3, Column: 6, Severity: "error", Message: "'a' was not declared in this scope; did you mean '<script>'?"},
+		{File: "source.cpp", Line: 1, Column: 10, Severity: "fatal error", Message: "vector & friends: No such file"},
+		{File: "source.cpp", Line: 9, Column: 1, Severity: "note", Message: "line outside the source"},
+		{Severity: "warning", Message: "command-line option \"-x\" is valid for C"},
+	}
+
+	rendered := renderDiagnosticsHTML(diagnostics, sources)
+
+	assert.Equal(t, `<div class="diagnostics">`+
+		`<div class="diagnostic diagnostic-error">`+
+		`<span class="location">source.cpp:3:6:</span> `+
+		`<span class="severity severity-error">error:</span> `+
+		`<span class="message">&#39;a&#39; was not declared in this scope; did you mean &#39;&lt;script&gt;&#39;?</span>`+
+		"<pre class=\"source\">\tif (a &lt; b &amp;&amp; c &gt; d) return &#34;&lt;b&gt;&#34;;\n"+
+		"<span class=\"caret\">\t    ^</span></pre>"+
+		`</div>`+
+		`<div class="diagnostic diagnostic-fatal-error">`+
+		`<span class="location">source.cpp:1:10:</span> `+
+		`<span class="severity severity-fatal-error">fatal error:</span> `+
+		`<span class="message">vector &amp; friends: No such file</span>`+
+		"<pre class=\"source\">#include &lt;vector&gt;\n"+
+		"<span class=\"caret\">         ^</span></pre>"+
+		`</div>`+
+		`<div class="diagnostic diagnostic-note">`+
+		`<span class="location">source.cpp:9:1:</span> `+
+		`<span class="severity severity-note">note:</span> `+
+		`<span class="message">line outside the source</span>`+
+		`</div>`+
+		`<div class="diagnostic diagnostic-warning">`+
+		`<span class="severity severity-warning">warning:</span> `+
+		`<span class="message">command-line option &#34;-x&#34; is valid for C</span>`+
+		`</div>`+
+		`</div>`, rendered)
+	assert.NotContains(t, rendered, "<script>")
+}
+
+// TestRenderDiagnosticsHTML_Empty tests that no diagnostics render nothing.
+func TestRenderDiagnosticsHTML_Empty(t *testing.T) {
+	assert.Empty(t, renderDiagnosticsHTML(nil, nil))
+}
+
+// TestCompile_DiagnosticsHTML tests that diagnostics_html renders text diagnostics with the submitted source as context.
+func TestCompile_DiagnosticsHTML(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			return &runtime.CompilationOutput{
+				ExitCode: 1,
+				Stderr:   "/workspace/source.cpp:1:25: error: expected ';' before '}' token\n",
+			}, nil
+		},
+	})
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-diagnostics-html",
+		Request: models.CompilationRequest{
+			Code:            base64.StdEncoding.EncodeToString([]byte("int main() { return 1 < 2 }")),
+			Language:        models.LanguageCpp,
+			Compiler:        models.CompilerGCC13,
+			DiagnosticsHTML: true,
+		},
+	})
+
+	require.Empty(t, result.Error)
+	require.Len(t, result.Diagnostics, 1, "Text diagnostics are parsed without diagnostics_format")
+	assert.Contains(t, result.DiagnosticsHTML, `<span class="severity severity-error">error:</span>`)
+	assert.Contains(t, result.DiagnosticsHTML, "<pre class=\"source\">int main() { return 1 &lt; 2 }\n")
+}
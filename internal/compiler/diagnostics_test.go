@@ -0,0 +1,160 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleGCCJSONDiagnostics is trimmed output of `g++ -fdiagnostics-format=json` for a missing semicolon.
+const sampleGCCJSONDiagnostics = `[{"kind": "error", "message": "expected ';' before '}' token", "children": [{"kind": "note", "message": "to match this '{'", "locations": [{"caret": {"file": "/workspace/source.cpp", "byte-column": 12, "display-column": 12, "line": 1, "column": 12}}]}], "column-origin": 1, "locations": [{"caret": {"file": "/workspace/source.cpp", "byte-column": 22, "display-column": 22, "line": 1, "column": 22}, "finish": {"file": "/workspace/source.cpp", "byte-column": 23, "display-column": 23, "line": 1, "column": 23}}], "escape-source": false}]`
+
+// TestParseJSONDiagnostics tests parsing of GCC JSON diagnostics.
+func TestParseJSONDiagnostics(t *testing.T) {
+	diagnostics, err := parseJSONDiagnostics(sampleGCCJSONDiagnostics + "\n")
+	require.NoError(t, err)
+	require.Len(t, diagnostics, 2, "Expected parent diagnostic and its child note")
+
+	assert.Equal(t, models.Diagnostic{
+		File:     "source.cpp",
+		Line:     1,
+		Column:   22,
+		Severity: "error",
+		Message:  "expected ';' before '}' token",
+	}, diagnostics[0])
+
+	assert.Equal(t, models.Diagnostic{
+		File:     "source.cpp",
+		Line:     1,
+		Column:   12,
+		Severity: "note",
+		Message:  "to match this '{'",
+	}, diagnostics[1])
+}
+
+// TestParseJSONDiagnostics_Invalid tests that non-JSON output is reported as an error.
+func TestParseJSONDiagnostics_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		stderr string
+	}{
+		{"empty", ""},
+		{"plain_text", "source.cpp:1:22: error: expected ';' before '}' token"},
+		{"malformed_json", `[{"kind": "error",`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseJSONDiagnostics(tc.stderr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestParseTextDiagnostics tests parsing of GCC-style text diagnostics.
+func TestParseTextDiagnostics(t *testing.T) {
+	stderr := `/workspace/source.cpp: In function 'int main()':
+/workspace/source.cpp:3:5: error: expected ';' before 'return'
+    3 |     return 0
+      |     ^~~~~~
+/workspace/source.cpp:2:9: warning: unused variable 'x' [-Wunused-variable]
+collect2: error: ld returned 1 exit status`
+
+	diagnostics := parseTextDiagnostics(stderr)
+	require.Len(t, diagnostics, 2)
+
+	assert.Equal(t, "source.cpp", diagnostics[0].File)
+	assert.Equal(t, 3, diagnostics[0].Line)
+	assert.Equal(t, 5, diagnostics[0].Column)
+	assert.Equal(t, "error", diagnostics[0].Severity)
+	assert.Equal(t, "expected ';' before 'return'", diagnostics[0].Message)
+
+	assert.Equal(t, "warning", diagnostics[1].Severity)
+	assert.Equal(t, "unused variable 'x' [-Wunused-variable]", diagnostics[1].Message)
+}
+
+// TestParseRustDiagnostics tests parsing of rustc diagnostics, with their error codes and locations.
+func TestParseRustDiagnostics(t *testing.T) {
+	stderr := "warning: unused variable: `y`\n" +
+		" --> /workspace/main.rs:2:9\n" +
+		"  |\n" +
+		"2 |     let y = 1;\n" +
+		"  |         ^ help: if this is intentional, prefix it with an underscore: `_y`\n" +
+		"  |\n" +
+		"  = note: `#[warn(unused_variables)]` on by default\n" +
+		"\n" +
+		"error[E0308]: mismatched types\n" +
+		" --> /workspace/main.rs:3:18\n" +
+		"  |\n" +
+		"3 |     let x: i32 = \"hello\";\n" +
+		"  |            ---   ^^^^^^^ expected `i32`, found `&str`\n" +
+		"\n" +
+		"error: linking with `cc` failed: exit status: 1\n" +
+		"\n" +
+		"error: aborting due to 2 previous errors; 1 warning emitted\n" +
+		"\n" +
+		"For more information about this error, try `rustc --explain E0308`.\n"
+
+	diagnostics := parseDiagnostics(models.DiagnosticsFormatText, models.LanguageRust, stderr)
+
+	assert.Equal(t, []models.Diagnostic{
+		{File: "main.rs", Line: 2, Column: 9, Severity: "warning", Message: "unused variable: `y`"},
+		{File: "main.rs", Line: 3, Column: 18, Severity: "error", Message: "mismatched types", Code: "E0308"},
+		{Severity: "error", Message: "linking with `cc` failed: exit status: 1"},
+	}, diagnostics)
+}
+
+// TestParseDiagnostics_Fallback tests that text parsing is used when JSON is unavailable.
+func TestParseDiagnostics_Fallback(t *testing.T) {
+	textOutput := "/workspace/source.c:1:1: error: unknown type name 'foo'"
+
+	testCases := []struct {
+		name     string
+		format   models.DiagnosticsFormat
+		language models.Language
+		stderr   string
+		expected string
+	}{
+		{"json_for_cpp", models.DiagnosticsFormatJSON, models.LanguageCpp, sampleGCCJSONDiagnostics, "expected ';' before '}' token"},
+		{"json_unparseable_falls_back", models.DiagnosticsFormatJSON, models.LanguageC, textOutput, "unknown type name 'foo'"},
+		{"json_unsupported_language", models.DiagnosticsFormatJSON, models.LanguageGo, textOutput, "unknown type name 'foo'"},
+		{"text_format", models.DiagnosticsFormatText, models.LanguageC, textOutput, "unknown type name 'foo'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diagnostics := parseDiagnostics(tc.format, tc.language, tc.stderr)
+			require.NotEmpty(t, diagnostics)
+			assert.Equal(t, tc.expected, diagnostics[0].Message)
+		})
+	}
+}
+
+// TestGroupDiagnostics tests that mixed-severity compiler output is grouped into errors, warnings and notes.
+func TestGroupDiagnostics(t *testing.T) {
+	stderr := `/workspace/source.cpp: In function 'int main()':
+/workspace/source.cpp:3:5: error: expected ';' before 'return'
+/workspace/source.cpp:2:9: warning: unused variable 'x' [-Wunused-variable]
+/workspace/source.cpp:1:10: fatal error: missing.h: No such file or directory
+/workspace/source.cpp:1:12: note: to match this '{'
+collect2: error: ld returned 1 exit status`
+
+	errors, warnings, notes := groupDiagnostics(parseTextDiagnostics(stderr))
+
+	assert.Equal(t, []string{
+		"source.cpp:3:5: expected ';' before 'return'",
+		"source.cpp:1:10: missing.h: No such file or directory",
+	}, errors)
+	assert.Equal(t, []string{"source.cpp:2:9: unused variable 'x' [-Wunused-variable]"}, warnings)
+	assert.Equal(t, []string{"source.cpp:1:12: to match this '{'"}, notes)
+}
+
+// TestGroupDiagnostics_Empty tests that output without diagnostics yields no groups.
+func TestGroupDiagnostics_Empty(t *testing.T) {
+	errors, warnings, notes := groupDiagnostics(nil)
+	assert.Nil(t, errors)
+	assert.Nil(t, warnings)
+	assert.Nil(t, notes)
+}
@@ -0,0 +1,28 @@
+package compiler
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// toUTF8 returns the compiler output as valid UTF-8. Output that already is valid is kept as is;
+// otherwise Latin-1 output is transcoded byte for byte, and anything else has its invalid
+// sequences replaced with U+FFFD, which json.Marshal would otherwise do one byte at a time.
+func toUTF8(output string, encoding models.OutputEncoding) string {
+	if utf8.ValidString(output) {
+		return output
+	}
+
+	if encoding == models.OutputEncodingLatin1 {
+		var b strings.Builder
+		b.Grow(len(output) * 2)
+		for i := 0; i < len(output); i++ {
+			b.WriteRune(rune(output[i]))
+		}
+		return b.String()
+	}
+
+	return strings.ToValidUTF8(output, string(utf8.RuneError))
+}
@@ -0,0 +1,84 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// latin1Diagnostic is a g++ error from a de_DE.ISO-8859-1 locale; 0xE4 is "ä" and 0xAB/0xBB are the guillemets.
+const latin1Diagnostic = "/workspace/source.cpp:1:14: Fehler: \xabx\xbb wurde in diesem G\xfcltigkeitsbereich nicht deklariert; erw\xe4hnt\n"
+
+// TestToUTF8 tests that compiler output is transcoded or repaired into valid UTF-8.
+func TestToUTF8(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		encoding models.OutputEncoding
+		expected string
+	}{
+		{"ascii", "error: expected ';'\n", models.OutputEncodingLatin1, "error: expected ';'\n"},
+		{"valid_utf8_kept", "Fehler: «x» erwähnt\n", models.OutputEncodingLatin1, "Fehler: «x» erwähnt\n"},
+		{"latin1", "Fehler: \xabx\xbb erw\xe4hnt\n", models.OutputEncodingLatin1, "Fehler: «x» erwähnt\n"},
+		{"utf8_replaces_invalid", "Fehler: erw\xe4hnt\n", models.OutputEncodingUTF8, "Fehler: erw�hnt\n"},
+		{"default_replaces_invalid", "Fehler: erw\xe4hnt\n", "", "Fehler: erw�hnt\n"},
+		{"empty", "", models.OutputEncodingLatin1, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted := toUTF8(tc.output, tc.encoding)
+			assert.Equal(t, tc.expected, converted)
+			assert.True(t, utf8.ValidString(converted))
+		})
+	}
+}
+
+// TestCompile_OutputEncoding tests that Latin-1 compiler output is stored as valid UTF-8 and survives JSON.
+func TestCompile_OutputEncoding(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			return &runtime.CompilationOutput{ExitCode: 1, Stderr: latin1Diagnostic}, nil
+		},
+	})
+	environments, err := (&Config{Environments: []EnvironmentConfig{{
+		Language: "cpp",
+		Compilers: []CompilerConfig{
+			{Name: "gcc", Version: "13", Image: "example/gcc-de:13", Standards: []string{"c++17"}, OutputEncoding: "latin-1"},
+			{Name: "gcc", Version: "12", Image: "gcc:12", Standards: []string{"c++17"}},
+		},
+	}}}).ToEnvironmentSpecs()
+	require.NoError(t, err)
+	compiler.environments = environments
+
+	request := models.CompilationRequest{
+		Code:              base64.StdEncoding.EncodeToString([]byte("int main() { x; }")),
+		Language:          models.LanguageCpp,
+		Compiler:          models.CompilerGCC13,
+		DiagnosticsFormat: models.DiagnosticsFormatText,
+	}
+	result := compiler.Compile(context.Background(), models.CompilationJob{ID: "test-latin1", Request: request})
+
+	require.Empty(t, result.Error)
+	assert.Equal(t, "/workspace/source.cpp:1:14: Fehler: «x» wurde in diesem Gültigkeitsbereich nicht deklariert; erwähnt\n", result.Stderr)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	var decoded models.CompilationResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, result.Stderr, decoded.Stderr, "The stderr round-trips through JSON unchanged")
+
+	// Without the setting the invalid bytes are replaced, still leaving valid UTF-8
+	request.Compiler = models.CompilerGCC12
+	result = compiler.Compile(context.Background(), models.CompilationJob{ID: "test-latin1-default", Request: request})
+	require.Empty(t, result.Error)
+	assert.True(t, utf8.ValidString(result.Stderr))
+	assert.Contains(t, result.Stderr, "G�ltigkeitsbereich")
+}
@@ -0,0 +1,96 @@
+package compiler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// Sentinel errors for multi-file submissions.
+var (
+	ErrInvalidFiles  = errors.New("invalid files")
+	ErrFilesTooLarge = errors.New("files too large")
+	ErrFilesMain     = errors.New("exactly one source file must define main")
+)
+
+// mainPatterns match the definition of the entry point, per language. Languages without one
+// (e.g., Swift's top-level code) are not checked.
+var mainPatterns = map[models.Language]*regexp.Regexp{
+	models.LanguageC:      regexp.MustCompile(`\bmain\s*\(`),
+	models.LanguageCpp:    regexp.MustCompile(`\bmain\s*\(`),
+	models.LanguageObjC:   regexp.MustCompile(`\bmain\s*\(`),
+	models.LanguageObjCpp: regexp.MustCompile(`\bmain\s*\(`),
+	models.LanguageGo:     regexp.MustCompile(`\bfunc\s+main\s*\(`),
+	models.LanguageRust:   regexp.MustCompile(`\bfn\s+main\s*\(`),
+}
+
+// prepareFiles decodes the files of a multi-file submission and picks the ones to compile, like an
+// archive: every source file for C, C++ and Go, the crate root for Rust. Filenames must be relative
+// paths inside the workspace, the decoded files share the archive size and count limits, and unless
+// the request builds no program (test or compile_only), exactly one source must define main.
+func prepareFiles(req models.CompilationRequest, language models.Language) (map[string]string, []string, error) {
+	files, err := decodeFiles(req.Files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources, err := archiveSources(language, files)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: no source files for %s", ErrInvalidFiles, language)
+	}
+
+	if pattern, ok := mainPatterns[language]; ok && !req.Test && !req.CompileOnly {
+		var withMain []string
+		for _, name := range sources {
+			if pattern.MatchString(files[name]) {
+				withMain = append(withMain, name)
+			}
+		}
+		switch len(withMain) {
+		case 0:
+			return nil, nil, fmt.Errorf("%w, found none", ErrFilesMain)
+		case 1:
+		default:
+			return nil, nil, fmt.Errorf("%w, found it in %s", ErrFilesMain, strings.Join(withMain, ", "))
+		}
+	}
+
+	return files, sources, nil
+}
+
+// decodeFiles decodes the files of a multi-file submission by cleaned filename. Filenames must be
+// relative paths inside the workspace, and the files share the archive size and count limits.
+func decodeFiles(encoded map[string]string) (map[string]string, error) {
+	if len(encoded) > maxArchiveFiles {
+		return nil, fmt.Errorf("%w: at most %d files are allowed, got %d", ErrInvalidFiles, maxArchiveFiles, len(encoded))
+	}
+
+	files := make(map[string]string, len(encoded))
+	remaining := maxArchiveBytes
+	for _, name := range slices.Sorted(maps.Keys(encoded)) {
+		cleaned, err := cleanArchivePath(name)
+		if err != nil || cleaned != strings.TrimPrefix(name, "./") {
+			return nil, fmt.Errorf("%w: unsafe filename %q", ErrInvalidFiles, name)
+		}
+		if _, exists := files[cleaned]; exists {
+			return nil, fmt.Errorf("%w: duplicate filename %q", ErrInvalidFiles, name)
+		}
+
+		content, err := base64.StdEncoding.DecodeString(encoded[name])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s is not valid base64", ErrInvalidFiles, name)
+		}
+		remaining -= len(content)
+		if remaining < 0 {
+			return nil, fmt.Errorf("%w (max %d bytes in total)", ErrFilesTooLarge, maxArchiveBytes)
+		}
+		files[cleaned] = string(content)
+	}
+	return files, nil
+}
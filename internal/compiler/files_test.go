@@ -0,0 +1,172 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeFiles base64-encodes file contents for CompilationRequest.Files.
+func encodeFiles(files map[string]string) map[string]string {
+	encoded := make(map[string]string, len(files))
+	for name, content := range files {
+		encoded[name] = base64.StdEncoding.EncodeToString([]byte(content))
+	}
+	return encoded
+}
+
+// TestCompile_Files tests that every translation unit of a multi-file submission is compiled together.
+func TestCompile_Files(t *testing.T) {
+	tests := []struct {
+		name     string
+		language models.Language
+		compiler models.Compiler
+		files    map[string]string
+		expected string
+	}{
+		{
+			name:     "cpp",
+			language: models.LanguageCpp,
+			compiler: models.CompilerGCC13,
+			files: map[string]string{
+				"main.cpp":     `#include "lib/util.h"` + "\nint main() { return util(); }",
+				"lib/util.cpp": "int util() { return 0; }",
+				"lib/util.h":   "int util();",
+			},
+			expected: "g++ -std=c++20 /workspace/lib/util.cpp /workspace/main.cpp -o /workspace/output",
+		},
+		{
+			name:     "go",
+			language: models.LanguageGo,
+			compiler: models.CompilerGo123,
+			files: map[string]string{
+				"main.go":  "package main\n\nfunc main() { greet() }",
+				"greet.go": "package main\n\nfunc greet() {}",
+			},
+			expected: "go build -o /workspace/output /workspace/greet.go /workspace/main.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedConfig runtime.CompilationConfig
+			compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					capturedConfig = config
+					return &runtime.CompilationOutput{ExitCode: 0}, nil
+				},
+			})
+
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-files-" + tt.name,
+				Request: models.CompilationRequest{
+					Files:    encodeFiles(tt.files),
+					Language: tt.language,
+					Compiler: tt.compiler,
+				},
+			})
+
+			require.Empty(t, result.Error)
+			assert.True(t, result.Compiled)
+			assert.Equal(t, tt.files, capturedConfig.Files)
+			assert.Equal(t, tt.expected, capturedConfig.CompileCommand)
+		})
+	}
+}
+
+// TestCompile_FilesRejected tests that unsafe, oversized or main-less submissions never reach the runtime.
+func TestCompile_FilesRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		encoded  map[string]string // Sent as is instead of files
+		errorMsg string
+	}{
+		{
+			name:     "parent_directory",
+			files:    map[string]string{"../main.c": "int main() {}"},
+			errorMsg: `unsafe filename "../main.c"`,
+		},
+		{
+			name:     "absolute_path",
+			files:    map[string]string{"/etc/main.c": "int main() {}"},
+			errorMsg: `unsafe filename "/etc/main.c"`,
+		},
+		{
+			name:     "unclean_path",
+			files:    map[string]string{"src/../main.c": "int main() {}"},
+			errorMsg: `unsafe filename "src/../main.c"`,
+		},
+		{
+			name:     "no_main",
+			files:    map[string]string{"a.c": "int a() { return 0; }", "b.c": "int b() { return 0; }"},
+			errorMsg: "exactly one source file must define main, found none",
+		},
+		{
+			name:     "two_mains",
+			files:    map[string]string{"a.c": "int main() { return 0; }", "b.c": "int main(void) { return 1; }"},
+			errorMsg: "exactly one source file must define main, found it in a.c, b.c",
+		},
+		{
+			name:     "too_large",
+			files:    map[string]string{"main.c": "int main() {}", "big.c": strings.Repeat("x", maxArchiveBytes)},
+			errorMsg: "files too large",
+		},
+		{
+			name:     "invalid_base64",
+			encoded:  map[string]string{"main.c": "not base64!"},
+			errorMsg: "main.c is not valid base64",
+		},
+		{
+			name:     "no_sources",
+			files:    map[string]string{"util.h": "int util();"},
+			errorMsg: "no source files for c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compileCalled := false
+			compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					compileCalled = true
+					return &runtime.CompilationOutput{}, nil
+				},
+			})
+
+			files := tt.encoded
+			if files == nil {
+				files = encodeFiles(tt.files)
+			}
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID:      "test-files-" + tt.name,
+				Request: models.CompilationRequest{Files: files, Language: models.LanguageC, Compiler: models.CompilerGCC13},
+			})
+
+			assert.False(t, result.Success)
+			assert.Contains(t, result.Error, tt.errorMsg)
+			assert.False(t, compileCalled)
+		})
+	}
+}
+
+// TestPrepareFiles_CompileOnlyWithoutMain tests that a build producing no program does not need a main.
+func TestPrepareFiles_CompileOnlyWithoutMain(t *testing.T) {
+	req := models.CompilationRequest{
+		Files:       encodeFiles(map[string]string{"a.c": "int a() { return 0; }", "b.c": "int b() { return 0; }"}),
+		Language:    models.LanguageC,
+		CompileOnly: true,
+	}
+
+	files, sources, err := prepareFiles(req, models.LanguageC)
+
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, []string{"a.c", "b.c"}, sources)
+}
@@ -0,0 +1,51 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+)
+
+// buildFormatCommand builds the command that reports formatting differences without changing the sources.
+func buildFormatCommand(env models.EnvironmentSpec, sources ...string) string {
+	sourceFilename := strings.Join(sources, " /workspace/")
+
+	switch env.Formatter {
+	case models.FormatterGofmt:
+		// -d prints a unified diff per unformatted file
+		return fmt.Sprintf("gofmt -d /workspace/%s", sourceFilename)
+
+	case models.FormatterClangFormat:
+		// Prints a warning for every line that would be reformatted
+		return fmt.Sprintf("clang-format --dry-run -Werror /workspace/%s", sourceFilename)
+
+	default:
+		return ""
+	}
+}
+
+// checkFormat runs the environment's formatter in a separate container and returns its report,
+// which is empty for formatted code. The compile config is reused without the run step or binary output.
+func (c *Compiler) checkFormat(ctx context.Context, config runtime.CompilationConfig, env models.EnvironmentSpec, sources []string) (string, error) {
+	config.CompileCommand = buildFormatCommand(env, sources...)
+	config.RunCommand = ""
+	config.OutputPath = ""
+
+	output, err := c.runtime.Compile(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	if output.TimedOut {
+		return "", ErrFormatCheckTimeout
+	}
+
+	// gofmt writes the diff to stdout (stderr only has syntax errors, which the compile already reports);
+	// clang-format writes its findings to stderr
+	if env.Formatter == models.FormatterGofmt {
+		return output.Stdout, nil
+	}
+	return output.Stderr, nil
+}
@@ -0,0 +1,108 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gofmtDiff is gofmt -d output for a file with a misplaced brace.
+const gofmtDiff = `diff /workspace/main.go.orig /workspace/main.go
+--- /workspace/main.go.orig
++++ /workspace/main.go
+@@ -1,4 +1,3 @@
+ package main
+ 
+-func main()
+-{}
++func main() {}
+`
+
+// TestCompile_CheckFormat tests that the formatter runs as a separate step and its diff is reported.
+func TestCompile_CheckFormat(t *testing.T) {
+	testCases := []struct {
+		name             string
+		requireFormat    bool
+		expectedCompiled bool
+	}{
+		{name: "check_only", requireFormat: false, expectedCompiled: true},
+		{name: "required", requireFormat: true, expectedCompiled: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var commands []string
+			mockRuntime := &runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					commands = append(commands, config.CompileCommand)
+					if strings.HasPrefix(config.CompileCommand, "gofmt") {
+						assert.Empty(t, config.OutputPath)
+						return &runtime.CompilationOutput{ExitCode: 0, Stdout: gofmtDiff}, nil
+					}
+					return &runtime.CompilationOutput{ExitCode: 0}, nil
+				},
+			}
+
+			compiler := NewCompilerWithRuntime(mockRuntime)
+
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-check-format-" + tc.name,
+				Request: models.CompilationRequest{
+					Code:          base64.StdEncoding.EncodeToString([]byte("package main\n\nfunc main()\n{}\n")),
+					Language:      models.LanguageGo,
+					Compiler:      models.CompilerGo123,
+					CheckFormat:   !tc.requireFormat,
+					RequireFormat: tc.requireFormat,
+				},
+			})
+
+			require.Empty(t, result.Error)
+			require.Len(t, commands, 2)
+			assert.Equal(t, "gofmt -d /workspace/main.go", commands[1])
+			assert.Equal(t, gofmtDiff, result.FormatDiff)
+			assert.Equal(t, tc.expectedCompiled, result.Compiled)
+		})
+	}
+}
+
+// TestCompile_CheckFormatUnavailable tests that environments without a formatter reject format checks.
+func TestCompile_CheckFormatUnavailable(t *testing.T) {
+	compileCalled := false
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			compileCalled = true
+			return &runtime.CompilationOutput{}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-check-format-unavailable",
+		Request: models.CompilationRequest{
+			Code:        base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+			Language:    models.LanguageCpp,
+			Compiler:    models.CompilerGCC13,
+			CheckFormat: true,
+		},
+	})
+
+	assert.Contains(t, result.Error, "environment has no formatter")
+	assert.False(t, compileCalled)
+}
+
+// TestBuildFormatCommand tests the report-only command of each formatter.
+func TestBuildFormatCommand(t *testing.T) {
+	env := models.EnvironmentSpec{Language: models.LanguageCpp, Formatter: models.FormatterClangFormat}
+	assert.Equal(t, "clang-format --dry-run -Werror /workspace/main.cpp /workspace/util.cpp",
+		buildFormatCommand(env, "main.cpp", "util.cpp"))
+
+	env = models.EnvironmentSpec{Language: models.LanguageGo, Formatter: models.FormatterGofmt}
+	assert.Equal(t, "gofmt -d /workspace/main.go", buildFormatCommand(env, "main.go"))
+}
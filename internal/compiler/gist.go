@@ -0,0 +1,206 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// Sentinel errors for gist resolution.
+var (
+	ErrGistNotFound     = errors.New("gist not found")
+	ErrGistRateLimited  = errors.New("GitHub API rate limit exceeded")
+	ErrGistFetch        = errors.New("failed to fetch gist")
+	ErrGistTooLarge     = errors.New("gist too large")
+	ErrGistTooManyFiles = errors.New("gist contains too many files")
+)
+
+const (
+	// gistAPIURL is the GitHub API that gist IDs are resolved against.
+	gistAPIURL = "https://api.github.com"
+
+	// gistRawHost is the only host raw gist files are fetched from.
+	gistRawHost = "gist.githubusercontent.com"
+
+	// gistFetchTimeout bounds every request to GitHub.
+	gistFetchTimeout = 10 * time.Second
+
+	// maxGistResponseBytes caps the API response; file contents are JSON-escaped, so allow headroom over maxArchiveBytes.
+	maxGistResponseBytes = 4 * maxArchiveBytes
+)
+
+// gistClient resolves gists through the unauthenticated GitHub API.
+// Requests only ever go to apiURL and rawHost over HTTPS, and redirects are not followed.
+type gistClient struct {
+	apiURL     string
+	rawHost    string
+	httpClient *http.Client
+}
+
+// gistResponse is the part of the GitHub "get a gist" response that is used.
+type gistResponse struct {
+	Files map[string]struct {
+		Size      int64  `json:"size"`
+		RawURL    string `json:"raw_url"`
+		Truncated bool   `json:"truncated"`
+		Content   string `json:"content"`
+	} `json:"files"`
+}
+
+// newGistClient creates a client for the public GitHub API.
+func newGistClient() *gistClient {
+	return &gistClient{
+		apiURL:     gistAPIURL,
+		rawHost:    gistRawHost,
+		httpClient: &http.Client{Timeout: gistFetchTimeout},
+	}
+}
+
+// fetch returns the files of a gist keyed by filename.
+// Files over maxArchiveBytes in total or more than maxArchiveFiles are rejected.
+func (gc *gistClient) fetch(ctx context.Context, id string) (map[string]string, error) {
+	resp, err := gc.get(ctx, gc.apiURL+"/gists/"+url.PathEscape(id))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only
+
+	var gist gistResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxGistResponseBytes)).Decode(&gist); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGistFetch, err)
+	}
+
+	if len(gist.Files) == 0 {
+		return nil, fmt.Errorf("%w: gist contains no files", ErrGistFetch)
+	}
+	if len(gist.Files) > maxArchiveFiles {
+		return nil, fmt.Errorf("%w (max %d)", ErrGistTooManyFiles, maxArchiveFiles)
+	}
+
+	// Check the advertised sizes first, so oversized gists are never downloaded
+	var total int64
+	for _, file := range gist.Files {
+		total += file.Size
+	}
+	if total > maxArchiveBytes {
+		return nil, fmt.Errorf("%w (max %d bytes)", ErrGistTooLarge, maxArchiveBytes)
+	}
+
+	files := make(map[string]string, len(gist.Files))
+	remaining := int64(maxArchiveBytes)
+
+	for name, file := range gist.Files {
+		if strings.Contains(name, "/") {
+			return nil, fmt.Errorf("%w: unsupported file name %q", models.ErrInvalidGist, name)
+		}
+		cleaned, err := cleanArchivePath(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unsupported file name %q", models.ErrInvalidGist, name)
+		}
+
+		content := file.Content
+		// The API inlines content up to ~1MB per file; larger files must be fetched raw
+		if file.Truncated {
+			content, err = gc.fetchRaw(ctx, file.RawURL, remaining)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		remaining -= int64(len(content))
+		if remaining < 0 {
+			return nil, fmt.Errorf("%w (max %d bytes)", ErrGistTooLarge, maxArchiveBytes)
+		}
+		files[cleaned] = content
+	}
+
+	return files, nil
+}
+
+// fetchRaw downloads a raw gist file, reading at most one byte past limit.
+func (gc *gistClient) fetchRaw(ctx context.Context, rawURL string, limit int64) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host != gc.rawHost || u.User != nil {
+		return "", fmt.Errorf("%w: unexpected raw URL %q", ErrGistFetch, rawURL)
+	}
+
+	resp, err := gc.get(ctx, u.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrGistFetch, err)
+	}
+	return string(content), nil
+}
+
+// get performs a GET request without following redirects and maps GitHub errors to sentinel errors.
+func (gc *gistClient) get(ctx context.Context, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGistFetch, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	client := *gc.httpClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGistFetch, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return resp, nil
+	case resp.StatusCode == http.StatusNotFound:
+		err = ErrGistNotFound
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		err = ErrGistRateLimited
+	default:
+		err = fmt.Errorf("%w: status %d", ErrGistFetch, resp.StatusCode)
+	}
+	resp.Body.Close() //nolint:errcheck,gosec // error response
+	return nil, err
+}
+
+// prepareGist resolves a gist and picks the files to compile.
+// A single-file gist is compiled as the environment's source file, whatever its name;
+// multi-file gists select their sources like an archive.
+func (c *Compiler) prepareGist(ctx context.Context, ref string, language models.Language, sourceFilename string) (map[string]string, []string, error) {
+	id, err := models.ParseGistID(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := c.gists.fetch(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(files) == 1 {
+		for _, content := range files {
+			return map[string]string{sourceFilename: content}, []string{sourceFilename}, nil
+		}
+	}
+
+	sources, err := archiveSources(language, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, sources, nil
+}
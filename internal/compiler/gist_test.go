@@ -0,0 +1,193 @@
+package compiler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gistFile is a file of a mocked gist.
+type gistFile struct {
+	name      string
+	content   string
+	truncated bool // Serve the content only via raw_url
+}
+
+// newMockGistAPI serves the given files as gist "abc123", with raw files under /raw/.
+// It returns a compiler whose gist client talks to the mock instead of GitHub.
+func newMockGistAPI(t *testing.T, files ...gistFile) (*Compiler, *runtime.CompilationConfig) {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name, ok := strings.CutPrefix(r.URL.Path, "/raw/"); ok {
+			for _, file := range files {
+				if file.name == name {
+					_, _ = w.Write([]byte(file.content)) //nolint:errcheck // test server
+					return
+				}
+			}
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.URL.Path != "/gists/abc123" {
+			http.NotFound(w, r)
+			return
+		}
+
+		response := map[string]map[string]any{"files": {}}
+		for _, file := range files {
+			entry := map[string]any{
+				"size":      len(file.content),
+				"raw_url":   server.URL + "/raw/" + file.name,
+				"truncated": file.truncated,
+			}
+			if !file.truncated {
+				entry["content"] = file.content
+			}
+			response["files"][file.name] = entry
+		}
+		_ = json.NewEncoder(w).Encode(response) //nolint:errcheck // test server
+	}))
+	t.Cleanup(server.Close)
+
+	var captured runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			captured = config
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	})
+	compiler.gists = &gistClient{
+		apiURL:     server.URL,
+		rawHost:    strings.TrimPrefix(server.URL, "https://"),
+		httpClient: server.Client(),
+	}
+
+	return compiler, &captured
+}
+
+// TestCompile_GistSingleFile tests that a single-file gist is compiled as the source file, whatever its name.
+func TestCompile_GistSingleFile(t *testing.T) {
+	compiler, captured := newMockGistAPI(t, gistFile{name: "hello.cc", content: "int main() { return 0; }"})
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-gist",
+		Request: models.CompilationRequest{
+			Gist:     "octocat/abc123",
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+		},
+	})
+
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Equal(t, map[string]string{"source.cpp": "int main() { return 0; }"}, captured.Files)
+	assert.Equal(t, "g++ -std=c++20 /workspace/source.cpp -o /workspace/output", captured.CompileCommand)
+	assert.NotEmpty(t, result.SourceHash)
+}
+
+// TestCompile_GistMultiFile tests that a multi-file gist is compiled like an archive,
+// fetching truncated files from their raw URL.
+func TestCompile_GistMultiFile(t *testing.T) {
+	compiler, captured := newMockGistAPI(t,
+		gistFile{name: "main.c", content: `#include "util.h"` + "\nint main() { return util(); }"},
+		gistFile{name: "util.c", content: "int util() { return 0; }", truncated: true},
+		gistFile{name: "util.h", content: "int util();"},
+	)
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-gist-multi",
+		Request: models.CompilationRequest{
+			Gist:     "https://gist.github.com/octocat/abc123",
+			Language: models.LanguageC,
+			Compiler: models.CompilerGCC13,
+		},
+	})
+
+	require.Empty(t, result.Error)
+	assert.Len(t, captured.Files, 3)
+	assert.Equal(t, "int util() { return 0; }", captured.Files["util.c"])
+	assert.Equal(t, "gcc -std=c17 /workspace/main.c /workspace/util.c -o /workspace/output", captured.CompileCommand)
+}
+
+// TestGistClient_Rejected tests that missing, oversized and redirecting gists are rejected.
+func TestGistClient_Rejected(t *testing.T) {
+	tooMany := make([]gistFile, maxArchiveFiles+1)
+	for i := range tooMany {
+		tooMany[i] = gistFile{name: strings.Repeat("a", i+1) + ".c"}
+	}
+
+	testCases := []struct {
+		name     string
+		id       string
+		files    []gistFile
+		expected error
+	}{
+		{name: "not_found", id: "def456", expected: ErrGistNotFound},
+		{name: "too_many_files", id: "abc123", files: tooMany, expected: ErrGistTooManyFiles},
+		{
+			name:     "too_large",
+			id:       "abc123",
+			files:    []gistFile{{name: "big.c", content: strings.Repeat("0", maxArchiveBytes+1), truncated: true}},
+			expected: ErrGistTooLarge,
+		},
+		{
+			name:     "unsafe_name",
+			id:       "abc123",
+			files:    []gistFile{{name: "a.c;reboot", content: "x"}},
+			expected: models.ErrInvalidGist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			compiler, _ := newMockGistAPI(t, tc.files...)
+			_, err := compiler.gists.fetch(context.Background(), tc.id)
+			assert.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+// TestGistClient_RawURLOtherHost tests that a raw URL outside the raw host is never requested.
+func TestGistClient_RawURLOtherHost(t *testing.T) {
+	requested := false
+	internal := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer internal.Close()
+
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"files":{"main.c":{"size":1,"truncated":true,"raw_url":"` + internal.URL + `/secret"}}}`)) //nolint:errcheck // test server
+	}))
+	defer api.Close()
+
+	client := &gistClient{apiURL: api.URL, rawHost: gistRawHost, httpClient: api.Client()}
+	_, err := client.fetch(context.Background(), "abc123")
+
+	assert.ErrorIs(t, err, ErrGistFetch)
+	assert.False(t, requested)
+}
+
+// TestGistClient_RateLimited tests that GitHub's rate limit response is reported as such.
+func TestGistClient_RateLimited(t *testing.T) {
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer api.Close()
+
+	client := &gistClient{apiURL: api.URL, rawHost: gistRawHost, httpClient: api.Client()}
+	_, err := client.fetch(context.Background(), "abc123")
+
+	assert.ErrorIs(t, err, ErrGistRateLimited)
+}
@@ -0,0 +1,64 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// ErrNoHeaders is returned for a header-only check whose files contain no header.
+var ErrNoHeaders = errors.New("no header files to check")
+
+// headerExtensions are the files a header-only check includes, per language.
+var headerExtensions = map[models.Language][]string{
+	models.LanguageC:   {".h"},
+	models.LanguageCpp: {".h", ".hpp", ".hh", ".hxx"},
+}
+
+// headerFilenames name the header of a header-only check submitted as code.
+var headerFilenames = map[models.Language]string{
+	models.LanguageC:   "header.h",
+	models.LanguageCpp: "header.hpp",
+}
+
+// headerUnitSuffixes name the translation unit generated next to each header, per language.
+var headerUnitSuffixes = map[models.Language]string{
+	models.LanguageC:   ".check.c",
+	models.LanguageCpp: ".check.cpp",
+}
+
+// prepareHeaderCheck lays out a header-only check: the header submitted as code, or the files of a
+// multi-file submission, plus a translation unit per header that includes nothing but that header.
+// Compiling the units shows whether each header compiles on its own, without relying on anything
+// its users happen to include first. It returns the workspace files and the units to compile.
+func prepareHeaderCheck(req models.CompilationRequest, language models.Language, source string) (map[string]string, []string, error) {
+	files := map[string]string{headerFilenames[language]: source}
+	if len(req.Files) > 0 {
+		var err error
+		if files, err = decodeFiles(req.Files); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var units []string
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if !slices.Contains(headerExtensions[language], path.Ext(name)) {
+			continue
+		}
+		unit := name + headerUnitSuffixes[language]
+		if _, exists := files[unit]; exists {
+			return nil, nil, fmt.Errorf("%w: %s is reserved for the check of %s", ErrInvalidFiles, unit, name)
+		}
+		files[unit] = fmt.Sprintf("#include \"%s\"\n", path.Base(name))
+		units = append(units, unit)
+	}
+
+	if len(units) == 0 {
+		return nil, nil, fmt.Errorf("%w for %s (expected %v)", ErrNoHeaders, language, headerExtensions[language])
+	}
+	return files, units, nil
+}
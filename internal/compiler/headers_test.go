@@ -0,0 +1,106 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompile_HeaderOnly tests that a header-only check syntax-checks a translation unit per header
+// and reports the diagnostics of a broken header.
+func TestCompile_HeaderOnly(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       models.CompilationRequest
+		expectedFiles map[string]string
+		expectedCmd   string
+		compiled      bool
+	}{
+		{
+			name: "clean_header_as_code",
+			request: models.CompilationRequest{
+				Code:     base64.StdEncoding.EncodeToString([]byte("#pragma once\ninline int util() { return 0; }\n")),
+				Language: models.LanguageCpp,
+				Compiler: models.CompilerGCC13,
+			},
+			expectedFiles: map[string]string{
+				"header.hpp":           "#pragma once\ninline int util() { return 0; }\n",
+				"header.hpp.check.cpp": "#include \"header.hpp\"\n",
+			},
+			expectedCmd: "g++ -std=c++20 -fsyntax-only /workspace/header.hpp.check.cpp",
+			compiled:    true,
+		},
+		{
+			name: "broken_header_in_files",
+			request: models.CompilationRequest{
+				Files: encodeFiles(map[string]string{
+					"include/util.h": "size_t util(void);\n", // Missing <stddef.h>
+					"include/ok.h":   "int ok(void);\n",
+					"src/util.c":     "#include \"util.h\"\n",
+				}),
+				Language: models.LanguageC,
+				Compiler: models.CompilerGCC13,
+			},
+			expectedFiles: map[string]string{
+				"include/util.h":         "size_t util(void);\n",
+				"include/util.h.check.c": "#include \"util.h\"\n",
+				"include/ok.h":           "int ok(void);\n",
+				"include/ok.h.check.c":   "#include \"ok.h\"\n",
+				"src/util.c":             "#include \"util.h\"\n",
+			},
+			expectedCmd: "gcc -std=c17 -fsyntax-only /workspace/include/ok.h.check.c /workspace/include/util.h.check.c",
+			compiled:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedConfig runtime.CompilationConfig
+			compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					capturedConfig = config
+					for name, content := range config.Files {
+						if strings.Contains(content, "size_t") {
+							return &runtime.CompilationOutput{
+								ExitCode: 1,
+								Stderr:   "/workspace/" + name + ":1:1: error: unknown type name 'size_t'\n",
+							}, nil
+						}
+					}
+					return &runtime.CompilationOutput{ExitCode: 0}, nil
+				},
+			})
+
+			tt.request.HeaderOnly = true
+			result := compiler.Compile(context.Background(), models.CompilationJob{ID: "test-header-only-" + tt.name, Request: tt.request})
+
+			require.Empty(t, result.Error)
+			assert.Equal(t, tt.compiled, result.Compiled)
+			assert.Equal(t, tt.expectedFiles, capturedConfig.Files)
+			assert.Equal(t, tt.expectedCmd, capturedConfig.CompileCommand)
+			assert.Empty(t, capturedConfig.OutputPath, "A header-only check produces nothing")
+			if !tt.compiled {
+				assert.Contains(t, result.Stderr, "/workspace/include/util.h:1:1: error: unknown type name 'size_t'")
+			}
+		})
+	}
+}
+
+// TestPrepareHeaderCheck_NoHeaders tests that files without a header are rejected.
+func TestPrepareHeaderCheck_NoHeaders(t *testing.T) {
+	req := models.CompilationRequest{
+		Files:      encodeFiles(map[string]string{"util.hpp.txt": "int util();", "util.cpp": "int util() { return 0; }"}),
+		Language:   models.LanguageCpp,
+		HeaderOnly: true,
+	}
+
+	_, _, err := prepareHeaderCheck(req, models.LanguageCpp, "")
+
+	require.ErrorIs(t, err, ErrNoHeaders)
+}
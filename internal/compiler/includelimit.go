@@ -0,0 +1,67 @@
+package compiler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// ErrTooManyIncludes is returned for sources with more include or import statements than allowed.
+var ErrTooManyIncludes = errors.New("too many include/import statements")
+
+// defaultMaxIncludes is the cap on include and import statements per request, unless MAX_INCLUDES sets another.
+// Real programs stay far below it; thousands of includes are a preprocessor bomb.
+const defaultMaxIncludes = 500
+
+// includeStatement matches a line holding a C-family #include/#import directive or an import statement
+// (Go, Swift, Kotlin).
+var includeStatement = regexp.MustCompile(`(?m)^[ \t]*(?:#[ \t]*(?:include|include_next|import)\b|import\b)`)
+
+// maxIncludesFromEnv reads the cap on include and import statements from MAX_INCLUDES, or returns 0 for the default.
+func maxIncludesFromEnv() int {
+	limit, err := strconv.Atoi(os.Getenv("MAX_INCLUDES"))
+	if err != nil || limit <= 0 {
+		return 0
+	}
+	return limit
+}
+
+// SetMaxIncludes sets the cap on include and import statements per request (0 = defaultMaxIncludes).
+func (c *Compiler) SetMaxIncludes(limit int) {
+	c.maxIncludes = limit
+}
+
+// checkIncludeCount rejects a request whose decoded code or files hold more include and import statements
+// than the cap, before anything reaches a container. Sources that fail to decode are left to the compile,
+// which reports them; archives and gists are only unpacked then, and are not counted.
+func (c *Compiler) checkIncludeCount(req models.CompilationRequest) error {
+	limit := c.maxIncludes
+	if limit <= 0 {
+		limit = defaultMaxIncludes
+	}
+
+	count := 0
+	if code, err := base64.StdEncoding.DecodeString(req.Code); err == nil {
+		count += countIncludes(string(code))
+	}
+	if files, err := decodeFiles(req.Files); err == nil {
+		for _, content := range files {
+			count += countIncludes(content)
+		}
+	}
+
+	if count > limit {
+		return fmt.Errorf("%w: %d, the maximum is %d", ErrTooManyIncludes, count, limit)
+	}
+	return nil
+}
+
+// countIncludes returns the number of include and import statements in source.
+func countIncludes(source string) int {
+	return len(includeStatement.FindAllStringIndex(source, -1))
+}
@@ -0,0 +1,91 @@
+package compiler
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateRequest_IncludeCap tests that sources over the include cap are rejected, and those at or
+// under it are not, counting the code and every file of a multi-file submission.
+func TestValidateRequest_IncludeCap(t *testing.T) {
+	includes := func(n int) string {
+		return strings.Repeat("#include <vector>\n", n) + "int main() { return 0; }\n"
+	}
+
+	tests := []struct {
+		name        string
+		maxIncludes int
+		request     models.CompilationRequest
+		errorMsg    string
+	}{
+		{
+			name:    "under_default_cap",
+			request: models.CompilationRequest{Code: base64.StdEncoding.EncodeToString([]byte(includes(defaultMaxIncludes))), Language: models.LanguageCpp},
+		},
+		{
+			name:     "over_default_cap",
+			request:  models.CompilationRequest{Code: base64.StdEncoding.EncodeToString([]byte(includes(defaultMaxIncludes + 1))), Language: models.LanguageCpp},
+			errorMsg: "too many include/import statements: 501, the maximum is 500",
+		},
+		{
+			name:        "over_configured_cap",
+			maxIncludes: 2,
+			request:     models.CompilationRequest{Code: base64.StdEncoding.EncodeToString([]byte("#import <Foundation/Foundation.h>\n  # include <stdio.h>\n#include_next <stdlib.h>\nint main() {}")), Language: models.LanguageC},
+			errorMsg:    "too many include/import statements: 3, the maximum is 2",
+		},
+		{
+			name:        "files_counted_together",
+			maxIncludes: 3,
+			request: models.CompilationRequest{
+				Files:    encodeFiles(map[string]string{"main.c": includes(2), "util.c": includes(2)}),
+				Language: models.LanguageC,
+			},
+			errorMsg: "too many include/import statements: 4, the maximum is 3",
+		},
+		{
+			name:        "go_imports",
+			maxIncludes: 1,
+			request:     models.CompilationRequest{Code: base64.StdEncoding.EncodeToString([]byte("package main\n\nimport \"fmt\"\nimport \"os\"\n\nfunc main() { important := 1; _ = important }")), Language: models.LanguageGo},
+			errorMsg:    "too many include/import statements: 2, the maximum is 1",
+		},
+		{
+			name:        "not_statements",
+			maxIncludes: 1,
+			request:     models.CompilationRequest{Code: base64.StdEncoding.EncodeToString([]byte("// #include <a.h>\nint import_count; /* import */\nint main() { return 0; }")), Language: models.LanguageC},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+			compiler.SetMaxIncludes(tt.maxIncludes)
+
+			err := compiler.validateRequest(tt.request)
+
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrTooManyIncludes)
+			assert.EqualError(t, err, tt.errorMsg)
+		})
+	}
+}
+
+// TestMaxIncludesFromEnv tests reading the include cap from MAX_INCLUDES.
+func TestMaxIncludesFromEnv(t *testing.T) {
+	t.Setenv("MAX_INCLUDES", "50")
+	assert.Equal(t, 50, maxIncludesFromEnv())
+
+	t.Setenv("MAX_INCLUDES", "-1")
+	assert.Zero(t, maxIncludesFromEnv())
+
+	t.Setenv("MAX_INCLUDES", "")
+	assert.Zero(t, maxIncludesFromEnv())
+}
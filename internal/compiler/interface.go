@@ -2,8 +2,10 @@ package compiler
 
 import (
 	"context"
+	"time"
 
 	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
 )
 
 // CompilerInterface defines the interface for compilation services.
@@ -22,3 +24,103 @@ type CompilerInterface interface {
 
 // Ensure *Compiler implements CompilerInterface
 var _ CompilerInterface = (*Compiler)(nil)
+
+// ConfigReloader is implemented by compilers whose environments can be
+// reloaded at runtime (e.g., on SIGHUP) without restarting the server.
+type ConfigReloader interface {
+	// ReloadConfig re-reads the environments configuration from configPath
+	ReloadConfig(ctx context.Context, configPath string) error
+}
+
+// Ensure *Compiler implements ConfigReloader
+var _ ConfigReloader = (*Compiler)(nil)
+
+// SelfTester is implemented by compilers that can check at startup that every
+// environment actually compiles, not just that its image exists.
+type SelfTester interface {
+	// SelfTest compiles a trivial program per environment and reports the ones that fail
+	SelfTest(ctx context.Context) error
+}
+
+// Ensure *Compiler implements SelfTester
+var _ SelfTester = (*Compiler)(nil)
+
+// Warmer is implemented by compilers that can prime every environment at startup,
+// so the first user compile does not pay for cold images and caches.
+type Warmer interface {
+	// Warmup runs a trivial compile per environment and reports the ones that could not run
+	Warmup(ctx context.Context) error
+}
+
+// Ensure *Compiler implements Warmer
+var _ Warmer = (*Compiler)(nil)
+
+// ToolchainVerifier is implemented by compilers that can check at startup that each image
+// still ships the exact toolchain version pinned in its environment.
+type ToolchainVerifier interface {
+	// VerifyToolchainVersions probes the pinned environments and reports the ones that drifted
+	VerifyToolchainVersions(ctx context.Context) error
+}
+
+// Ensure *Compiler implements ToolchainVerifier
+var _ ToolchainVerifier = (*Compiler)(nil)
+
+// VersionProber is implemented by compilers that can record the version each environment's
+// image actually reports, to catch images rebuilt with another compiler than configured.
+type VersionProber interface {
+	// ProbeVersions probes every environment's compiler and reports the ones that could not be probed
+	ProbeVersions(ctx context.Context) error
+}
+
+// Ensure *Compiler implements VersionProber
+var _ VersionProber = (*Compiler)(nil)
+
+// RuntimeLatencyReporter is implemented by compilers that can report the latency
+// of their runtime's backend (e.g., the Docker daemon) for health checks.
+type RuntimeLatencyReporter interface {
+	// RuntimeLatency returns the latest probe latency, or false if it is unknown
+	RuntimeLatency() (time.Duration, bool)
+}
+
+// Ensure *Compiler implements RuntimeLatencyReporter
+var _ RuntimeLatencyReporter = (*Compiler)(nil)
+
+// TimeoutResolver is implemented by compilers that can report the timeout a request will
+// actually run with, after clamping and quick mode, so callers can size their own waits.
+type TimeoutResolver interface {
+	// EffectiveTimeout returns the compile timeout of req
+	EffectiveTimeout(req models.CompilationRequest) time.Duration
+}
+
+// Ensure *Compiler implements TimeoutResolver
+var _ TimeoutResolver = (*Compiler)(nil)
+
+// ImagePullReporter is implemented by compilers that know which images are being pulled, so that
+// requests for them can be turned away until the pull is done instead of stalling in the queue.
+type ImagePullReporter interface {
+	// ImagePulling reports whether the image req compiles in is being pulled
+	ImagePulling(req models.CompilationRequest) bool
+}
+
+// Ensure *Compiler implements ImagePullReporter
+var _ ImagePullReporter = (*Compiler)(nil)
+
+// EnvironmentResolver is implemented by compilers that can report the environment a request
+// compiles in, with the image, standard and flags it resolves to.
+type EnvironmentResolver interface {
+	// ResolveEnvironment returns the environment of req
+	ResolveEnvironment(req models.CompilationRequest) (models.EnvironmentSpec, error)
+}
+
+// Ensure *Compiler implements EnvironmentResolver
+var _ EnvironmentResolver = (*Compiler)(nil)
+
+// OutputStreamer is implemented by compilers that can pass on a job's compiler output while it
+// compiles, for clients following the job live.
+type OutputStreamer interface {
+	// CompileStreaming is Compile, also calling onOutput with the output as it is written
+	CompileStreaming(ctx context.Context, job models.CompilationJob, onOutput runtime.OutputFunc) models.CompilationResult
+}
+
+// Ensure *Compiler implements OutputStreamer
+var _ OutputStreamer = (*Compiler)(nil)
@@ -0,0 +1,44 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+)
+
+// preprocessedOutputPath is where the preprocessor writes the expanded sources.
+const preprocessedOutputPath = "/workspace/output.i"
+
+// buildPreprocessCommand builds a command writing the preprocessed sources to preprocessedOutputPath.
+// With several sources, gcc writes each expansion in turn; -o is not allowed for multiple inputs, so redirect.
+func buildPreprocessCommand(env models.EnvironmentSpec, sources ...string) string {
+	flags := ""
+	if len(env.Flags) > 0 {
+		flags = " " + strings.Join(env.Flags, " ")
+	}
+	sourceFilename := strings.Join(sources, " /workspace/")
+
+	return fmt.Sprintf("%s -std=%s%s -E /workspace/%s > %s", cFamilyDriver(env), env.Standard, flags, sourceFilename, preprocessedOutputPath)
+}
+
+// preprocess runs the preprocessor in a separate container and returns the preprocessed source,
+// read back as an artifact, and whether it was truncated.
+func (c *Compiler) preprocess(ctx context.Context, config runtime.CompilationConfig, env models.EnvironmentSpec, sources []string) (string, bool, error) {
+	config.CompileCommand = buildPreprocessCommand(env, sources...)
+	config.RunCommand = ""
+	config.OutputPath = ""
+	config.ArtifactPath = preprocessedOutputPath
+
+	output, err := c.runtime.Compile(ctx, config)
+	if err != nil {
+		return "", false, err
+	}
+	if output.TimedOut {
+		return "", false, ErrPreprocessTimeout
+	}
+
+	return output.Artifact, output.ArtifactTruncated, nil
+}
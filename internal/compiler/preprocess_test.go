@@ -0,0 +1,84 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildPreprocessCommand tests preprocessor commands for single and multiple sources.
+func TestBuildPreprocessCommand(t *testing.T) {
+	env := models.EnvironmentSpec{Language: models.LanguageCpp, Compiler: models.CompilerGCC13, Standard: models.StandardCpp20, Flags: []string{"-DDEBUG"}}
+	assert.Equal(t, "g++ -std=c++20 -DDEBUG -E /workspace/source.cpp > /workspace/output.i",
+		buildPreprocessCommand(env, "source.cpp"))
+	assert.Equal(t, "g++ -std=c++20 -DDEBUG -E /workspace/main.cpp /workspace/util.cpp > /workspace/output.i",
+		buildPreprocessCommand(env, "main.cpp", "util.cpp"))
+}
+
+// TestCompile_Preprocess tests that the preprocessed source is read back as an artifact when requested.
+func TestCompile_Preprocess(t *testing.T) {
+	expanded := "# 1 \"/workspace/source.c\"\nint main(void) { return 2 * (1 + 1); }\n"
+
+	var configs []runtime.CompilationConfig
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			configs = append(configs, config)
+			if config.ArtifactPath != "" {
+				return &runtime.CompilationOutput{Artifact: expanded, ArtifactTruncated: true}, nil
+			}
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-preprocess",
+		Request: models.CompilationRequest{
+			Code:       base64.StdEncoding.EncodeToString([]byte("#define TWICE(x) 2 * (x)\nint main(void) { return TWICE(1 + 1); }")),
+			Language:   models.LanguageC,
+			Compiler:   models.CompilerGCC13,
+			Preprocess: true,
+		},
+	})
+
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Equal(t, expanded, result.Preprocessed)
+	assert.True(t, result.PreprocessedTruncated)
+
+	require.Len(t, configs, 2)
+	assert.Empty(t, configs[0].ArtifactPath, "The compile itself reads no artifact")
+	assert.Equal(t, "gcc -std=c17 -E /workspace/source.c > /workspace/output.i", configs[1].CompileCommand)
+	assert.Equal(t, "/workspace/output.i", configs[1].ArtifactPath)
+	assert.Empty(t, configs[1].OutputPath)
+}
+
+// TestCompile_PreprocessNotRequested tests that no preprocessing pass runs by default.
+func TestCompile_PreprocessNotRequested(t *testing.T) {
+	calls := 0
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			calls++
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	})
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-no-preprocess",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("int main(void) { return 0; }")),
+			Language: models.LanguageC,
+			Compiler: models.CompilerGCC13,
+		},
+	})
+
+	require.Empty(t, result.Error)
+	assert.Empty(t, result.Preprocessed)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,45 @@
+package compiler
+
+import (
+	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// PullRetryAfter is how long clients are told to wait before retrying a request whose image is being pulled.
+// Pulls take from seconds to minutes; a short interval lets clients in soon after a fast one.
+const PullRetryAfter = 10 * time.Second
+
+// StartPull marks an image as being pulled, so that requests for it are turned away instead of
+// queued behind the pull. Pulls of the same image nest: it is pulling until every one has finished.
+func (c *Compiler) StartPull(imageTag string) {
+	c.pullsMu.Lock()
+	defer c.pullsMu.Unlock()
+	if c.pulls == nil {
+		c.pulls = make(map[string]int)
+	}
+	c.pulls[imageTag]++
+}
+
+// FinishPull marks a pull started with StartPull as finished, whether it succeeded or not.
+func (c *Compiler) FinishPull(imageTag string) {
+	c.pullsMu.Lock()
+	defer c.pullsMu.Unlock()
+	if c.pulls[imageTag] <= 1 {
+		delete(c.pulls, imageTag)
+		return
+	}
+	c.pulls[imageTag]--
+}
+
+// ImagePulling reports whether the image of the environment req compiles in is being pulled.
+// Requests that do not resolve to an environment are not pulling; the compile rejects them.
+func (c *Compiler) ImagePulling(req models.CompilationRequest) bool {
+	env, err := c.selectEnvironment(req)
+	if err != nil {
+		return false
+	}
+	c.pullsMu.Lock()
+	defer c.pullsMu.Unlock()
+	return c.pulls[env.ImageTag] > 0
+}
@@ -0,0 +1,31 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestImagePulling tests that a request is pulling while any pull of its environment's image is in progress.
+func TestImagePulling(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+	cpp := models.CompilationRequest{Language: models.LanguageCpp, Compiler: models.CompilerGCC13}
+	golang := models.CompilationRequest{Language: models.LanguageGo, Compiler: models.CompilerGo123}
+	assert.False(t, compiler.ImagePulling(cpp))
+
+	compiler.StartPull("gcc:13")
+	compiler.StartPull("gcc:13")
+	assert.True(t, compiler.ImagePulling(cpp))
+	assert.False(t, compiler.ImagePulling(golang), "Another image")
+	assert.False(t, compiler.ImagePulling(models.CompilationRequest{Language: "cobol"}), "No environment")
+
+	compiler.FinishPull("gcc:13")
+	assert.True(t, compiler.ImagePulling(cpp), "One pull is still in progress")
+	compiler.FinishPull("gcc:13")
+	assert.False(t, compiler.ImagePulling(cpp))
+
+	compiler.FinishPull("gcc:13")
+	assert.False(t, compiler.ImagePulling(cpp), "Finishing twice does not go negative")
+}
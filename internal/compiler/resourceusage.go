@@ -0,0 +1,54 @@
+package compiler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// gnuTimeReportStart begins the report GNU time -v appends to stderr.
+const gnuTimeReportStart = "\tCommand being timed: "
+
+// wrapWithGNUTime runs the compile command under GNU time -v. The command goes through its own
+// shell, so pipelines and && chains are measured as a whole.
+func wrapWithGNUTime(command string) string {
+	return "/usr/bin/time -v sh -c '" + strings.ReplaceAll(command, "'", `'\''`) + "'"
+}
+
+// parseGNUTimeReport extracts the resource usage from a GNU time -v report at the end of stderr
+// and returns stderr without it. The usage is nil when stderr holds no report, e.g. when the
+// compile was killed before time could print one.
+func parseGNUTimeReport(stderr string) (*models.ResourceUsage, string) {
+	start := strings.LastIndex(stderr, gnuTimeReportStart)
+	if start == -1 || (start > 0 && stderr[start-1] != '\n') {
+		return nil, stderr
+	}
+	report, rest := stderr[start:], stderr[:start]
+
+	// A failing command adds one status line before the report
+	if line := strings.LastIndex(strings.TrimSuffix(rest, "\n"), "\n") + 1; line < len(rest) {
+		status := rest[line:]
+		if strings.HasPrefix(status, "Command exited with non-zero status ") || strings.HasPrefix(status, "Command terminated by signal ") {
+			rest = rest[:line]
+		}
+	}
+
+	var usage models.ResourceUsage
+	for _, line := range strings.Split(report, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "): ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "User time (seconds":
+			usage.UserSeconds, _ = strconv.ParseFloat(value, 64) //nolint:errcheck // malformed fields stay zero
+		case "System time (seconds":
+			usage.SystemSeconds, _ = strconv.ParseFloat(value, 64) //nolint:errcheck // malformed fields stay zero
+		case "Maximum resident set size (kbytes":
+			usage.MaxRSSKB, _ = strconv.ParseInt(value, 10, 64) //nolint:errcheck // malformed fields stay zero
+		}
+	}
+
+	return &usage, rest
+}
@@ -0,0 +1,120 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleGNUTimeReport is /usr/bin/time -v output for a small g++ compile.
+const sampleGNUTimeReport = "\tCommand being timed: \"sh -c g++ -std=c++17 /workspace/source.cpp -o /workspace/output\"\n" +
+	"\tUser time (seconds): 0.41\n" +
+	"\tSystem time (seconds): 0.06\n" +
+	"\tPercent of CPU this job got: 97%\n" +
+	"\tElapsed (wall clock) time (h:mm:ss or m:ss): 0:00.48\n" +
+	"\tAverage shared text size (kbytes): 0\n" +
+	"\tAverage unshared data size (kbytes): 0\n" +
+	"\tAverage stack size (kbytes): 0\n" +
+	"\tAverage total size (kbytes): 0\n" +
+	"\tMaximum resident set size (kbytes): 98304\n" +
+	"\tAverage resident set size (kbytes): 0\n" +
+	"\tMajor (requiring I/O) page faults: 0\n" +
+	"\tMinor (reclaiming a frame) page faults: 24511\n" +
+	"\tVoluntary context switches: 31\n" +
+	"\tInvoluntary context switches: 5\n" +
+	"\tSwaps: 0\n" +
+	"\tFile system inputs: 0\n" +
+	"\tFile system outputs: 1032\n" +
+	"\tSocket messages sent: 0\n" +
+	"\tSocket messages received: 0\n" +
+	"\tSignals delivered: 0\n" +
+	"\tPage size (bytes): 4096\n" +
+	"\tExit status: 0\n"
+
+// TestParseGNUTimeReport tests that the report is parsed and removed from the compiler's stderr.
+func TestParseGNUTimeReport(t *testing.T) {
+	diagnostic := "/workspace/source.cpp:3:5: error: expected ';' before 'return'\n"
+	expected := &models.ResourceUsage{MaxRSSKB: 98304, UserSeconds: 0.41, SystemSeconds: 0.06}
+
+	testCases := []struct {
+		name           string
+		stderr         string
+		expectedUsage  *models.ResourceUsage
+		expectedStderr string
+	}{
+		{"report_only", sampleGNUTimeReport, expected, ""},
+		{"after_diagnostics", "/workspace/source.cpp:1:1: warning: unused\n" + sampleGNUTimeReport, expected, "/workspace/source.cpp:1:1: warning: unused\n"},
+		{"failed_compile", diagnostic + "Command exited with non-zero status 1\n" + sampleGNUTimeReport, expected, diagnostic},
+		{"killed_compile", "Command terminated by signal 9\n" + sampleGNUTimeReport, expected, ""},
+		{"no_report", diagnostic, nil, diagnostic},
+		{"not_at_line_start", "note:" + sampleGNUTimeReport, nil, "note:" + sampleGNUTimeReport},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, stderr := parseGNUTimeReport(tc.stderr)
+			assert.Equal(t, tc.expectedUsage, usage)
+			assert.Equal(t, tc.expectedStderr, stderr)
+		})
+	}
+}
+
+// TestWrapWithGNUTime tests that the wrapped command survives single quotes.
+func TestWrapWithGNUTime(t *testing.T) {
+	assert.Equal(t, "/usr/bin/time -v sh -c 'g++ /workspace/source.cpp -o /workspace/output'",
+		wrapWithGNUTime("g++ /workspace/source.cpp -o /workspace/output"))
+	assert.Equal(t, `/usr/bin/time -v sh -c 'go build -ldflags='\''-s -w'\'' ./...'`,
+		wrapWithGNUTime("go build -ldflags='-s -w' ./..."))
+}
+
+// TestCompile_ResourceUsage tests that resource usage is reported for images with GNU time and rejected otherwise.
+func TestCompile_ResourceUsage(t *testing.T) {
+	diagnostic := "/workspace/source.cpp:1:14: error: expected ';' before '}' token\n"
+
+	var capturedConfig runtime.CompilationConfig
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			capturedConfig = config
+			return &runtime.CompilationOutput{
+				ExitCode: 1,
+				Stderr:   diagnostic + "Command exited with non-zero status 1\n" + sampleGNUTimeReport,
+			}, nil
+		},
+	})
+	environments, err := (&Config{Environments: []EnvironmentConfig{{
+		Language: "cpp",
+		Compilers: []CompilerConfig{
+			{Name: "gcc", Version: "13", Image: "example/gcc-time:13", Standards: []string{"c++17"}, GNUTime: true},
+			{Name: "gcc", Version: "12", Image: "gcc:12", Standards: []string{"c++17"}},
+		},
+	}}}).ToEnvironmentSpecs()
+	require.NoError(t, err)
+	compiler.environments = environments
+	assert.True(t, environments["cpp-gcc-13"].Capabilities.ResourceUsage)
+	assert.False(t, environments["cpp-gcc-12"].Capabilities.ResourceUsage)
+
+	request := models.CompilationRequest{
+		Code:              base64.StdEncoding.EncodeToString([]byte("int main() {}")),
+		Language:          models.LanguageCpp,
+		Compiler:          models.CompilerGCC13,
+		DiagnosticsFormat: models.DiagnosticsFormatText,
+		ResourceUsage:     true,
+	}
+	result := compiler.Compile(context.Background(), models.CompilationJob{ID: "test-resource-usage", Request: request})
+
+	require.Empty(t, result.Error)
+	assert.Equal(t, "/usr/bin/time -v sh -c 'g++ -std=c++17 /workspace/source.cpp -o /workspace/output'", capturedConfig.CompileCommand)
+	assert.Equal(t, &models.ResourceUsage{MaxRSSKB: 98304, UserSeconds: 0.41, SystemSeconds: 0.06}, result.ResourceUsage)
+	assert.Equal(t, diagnostic, result.Stderr, "The time report is not part of the compiler output")
+	assert.Len(t, result.Diagnostics, 1)
+
+	request.Compiler = models.CompilerGCC12
+	result = compiler.Compile(context.Background(), models.CompilationJob{ID: "test-resource-usage-unavailable", Request: request})
+	assert.Contains(t, result.Error, "environment has no /usr/bin/time")
+	assert.Nil(t, result.ResourceUsage)
+}
@@ -0,0 +1,109 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// ErrSelfTestFailed is returned by SelfTest when an environment cannot compile a hello-world program.
+var ErrSelfTestFailed = errors.New("self-test failed")
+
+// helloWorldSources are the trivial programs compiled by SelfTest, per language.
+var helloWorldSources = map[models.Language]string{
+	models.LanguageC:      "#include <stdio.h>\nint main(void) { puts(\"hello\"); return 0; }\n",
+	models.LanguageCpp:    "#include <iostream>\nint main() { std::cout << \"hello\" << std::endl; return 0; }\n",
+	models.LanguageGo:     "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hello\") }\n",
+	models.LanguageRust:   "fn main() { println!(\"hello\"); }\n",
+	models.LanguageObjC:   "#include <stdio.h>\nint main(void) { puts(\"hello\"); return 0; }\n",
+	models.LanguageObjCpp: "#include <iostream>\nint main() { std::cout << \"hello\" << std::endl; return 0; }\n",
+	models.LanguageSwift:  "print(\"hello\")\n",
+	models.LanguageKotlin: "fun main() { println(\"hello\") }\n",
+}
+
+// SelfTest compiles a hello-world program in every environment, so an image that exists but lacks
+// its toolchain is caught at startup rather than by the first user. Environments below the
+// configured minimum compiler version are skipped, since requests cannot select them.
+// The error lists every failing environment.
+func (c *Compiler) SelfTest(ctx context.Context) error {
+	var failures []string
+	for _, hello := range c.helloWorldJobs("self-test-") {
+		result := c.Compile(ctx, hello.job)
+		if reason := selfTestFailure(result); reason != "" {
+			failures = append(failures, fmt.Sprintf("%s (%s): %s", hello.key, hello.env.ImageTag, reason))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w:\n  - %s", ErrSelfTestFailed, strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+// helloWorldJob is the hello-world compile of one environment.
+type helloWorldJob struct {
+	key string
+	env models.EnvironmentSpec
+	job models.CompilationJob
+}
+
+// helloWorldJobs returns a hello-world compile for every environment requests can select, sorted by
+// environment key, with job IDs prefixed by idPrefix. Environments below the configured minimum
+// compiler version are skipped.
+func (c *Compiler) helloWorldJobs(idPrefix string) []helloWorldJob {
+	c.mu.RLock()
+	environments := c.environments
+	minVersions := c.minVersions
+	c.mu.RUnlock()
+
+	keys := make([]string, 0, len(environments))
+	for key := range environments {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	jobs := make([]helloWorldJob, 0, len(keys))
+	for _, key := range keys {
+		env := environments[key]
+		if _, below := belowMinimumVersion(env.Compiler, minVersions); below {
+			continue
+		}
+		source, ok := helloWorldSources[env.Language]
+		if !ok {
+			continue
+		}
+		jobs = append(jobs, helloWorldJob{
+			key: key,
+			env: env,
+			job: models.CompilationJob{
+				ID: idPrefix + key,
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte(source)),
+					Language: env.Language,
+					Compiler: env.Compiler,
+				},
+			},
+		})
+	}
+	return jobs
+}
+
+// selfTestFailure describes why a hello-world compile failed, or returns "" if it compiled.
+func selfTestFailure(result models.CompilationResult) string {
+	if result.Error != "" {
+		return result.Error
+	}
+	if result.Compiled {
+		return ""
+	}
+	if stderr := strings.TrimSpace(result.Stderr); stderr != "" {
+		firstLine, _, _ := strings.Cut(stderr, "\n")
+		return fmt.Sprintf("exit code %d: %s", result.ExitCode, firstLine)
+	}
+	return fmt.Sprintf("exit code %d", result.ExitCode)
+}
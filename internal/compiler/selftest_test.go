@@ -0,0 +1,50 @@
+package compiler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSelfTest_Passes tests that the self-test compiles once per environment and passes when all compile.
+func TestSelfTest_Passes(t *testing.T) {
+	images := map[string]int{}
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			images[config.ImageTag]++
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+	require.NoError(t, compiler.SelfTest(context.Background()))
+
+	total := 0
+	for _, count := range images {
+		total += count
+	}
+	assert.Equal(t, len(compiler.environments), total)
+}
+
+// TestSelfTest_ReportsFailingEnvironment tests that an environment whose image cannot compile fails the self-test.
+func TestSelfTest_ReportsFailingEnvironment(t *testing.T) {
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			if config.ImageTag == "rust:1.80-alpine" {
+				return &runtime.CompilationOutput{ExitCode: 127, Stderr: "sh: rustc: not found\n"}, nil
+			}
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+	err := compiler.SelfTest(context.Background())
+
+	require.ErrorIs(t, err, ErrSelfTestFailed)
+	assert.Contains(t, err.Error(), "rust-rustc-1.80 (rust:1.80-alpine)")
+	assert.Contains(t, err.Error(), "rustc: not found")
+	assert.NotContains(t, err.Error(), "cpp-gcc-13", "Only the failing environment is reported")
+}
@@ -0,0 +1,112 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+)
+
+// maxSymbols caps the symbol list; a statically linked binary easily has tens of thousands.
+const maxSymbols = 1000
+
+var (
+	// nmLinePattern matches nm and go tool nm lines such as "0000000000001139 T main",
+	// where undefined symbols have no address: "                 U printf".
+	nmLinePattern = regexp.MustCompile(`^\s*(?:[0-9a-fA-F]+\s+)?[A-Za-z?-]\s+(.+)$`)
+
+	// objdumpLinePattern matches objdump -t lines: address, seven flag characters, section, size and name, e.g.
+	// "0000000000001139 g     F .text	0000000000000016              main".
+	objdumpLinePattern = regexp.MustCompile(`^[0-9a-fA-F]+ (.{7}) \S+\s+[0-9a-fA-F]+\s+(.+)$`)
+
+	// symbolVersionPrefix matches the version objdump prints before a dynamic symbol, e.g. "GLIBC_2.2.5 " or "(GLIBC_2.2.5) ".
+	symbolVersionPrefix = regexp.MustCompile(`^\(?[A-Z][A-Z0-9_]*_[0-9][0-9.]*\)?\s+`)
+
+	// symbolVersionSuffix matches the version nm appends to a dynamic symbol, e.g. "@GLIBC_2.2.5" or "@@GLIBC_2.34".
+	symbolVersionSuffix = regexp.MustCompile(`@@?[A-Z][A-Z0-9_]*_[0-9][0-9.]*$`)
+)
+
+// buildSymbolsCommand builds a command listing the symbols of the binary built by compileCmd.
+// The pass runs in a fresh container, so the binary is rebuilt first with its output silenced.
+func buildSymbolsCommand(tool models.SymbolTool, compileCmd string) string {
+	var list string
+	switch tool {
+	case models.SymbolToolObjdump:
+		list = "objdump -t -C " + binaryOutputPath
+	case models.SymbolToolGoNm:
+		list = "go tool nm " + binaryOutputPath
+	default:
+		list = "nm -C " + binaryOutputPath
+	}
+
+	return fmt.Sprintf("{ %s; } >/dev/null 2>&1 && %s", compileCmd, list)
+}
+
+// symbols lists the binary's symbols in a separate container and returns them with whether
+// the list was truncated. A stripped binary has none, which is not an error.
+func (c *Compiler) symbols(ctx context.Context, config runtime.CompilationConfig, env models.EnvironmentSpec) ([]string, bool, error) {
+	config.CompileCommand = buildSymbolsCommand(env.SymbolTool, config.CompileCommand)
+	config.RunCommand = ""
+	config.OutputPath = ""
+
+	output, err := c.runtime.Compile(ctx, config)
+	if err != nil {
+		return nil, false, err
+	}
+	if output.TimedOut {
+		return nil, false, ErrSymbolsTimeout
+	}
+	if output.ExitCode != 0 && !strings.Contains(output.Stderr, "no symbols") {
+		return nil, false, fmt.Errorf("%s exited with code %d: %s", env.SymbolTool, output.ExitCode, strings.TrimSpace(output.Stderr))
+	}
+
+	symbols, truncated := parseSymbols(env.SymbolTool, output.Stdout)
+	return symbols, truncated, nil
+}
+
+// parseSymbols extracts the symbol names from the output of a symbol tool, dropping
+// version suffixes and duplicates, and caps the list at maxSymbols.
+func parseSymbols(tool models.SymbolTool, output string) ([]string, bool) {
+	var symbols []string
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(output, "\n") {
+		name, ok := parseSymbolLine(tool, strings.TrimRight(line, "\r"))
+		if !ok || seen[name] {
+			continue
+		}
+		if len(symbols) == maxSymbols {
+			return symbols, true
+		}
+		seen[name] = true
+		symbols = append(symbols, name)
+	}
+
+	return symbols, false
+}
+
+// parseSymbolLine returns the symbol name on one line of symbol tool output.
+func parseSymbolLine(tool models.SymbolTool, line string) (string, bool) {
+	var name string
+	if tool == models.SymbolToolObjdump {
+		match := objdumpLinePattern.FindStringSubmatch(line)
+		// Debugging and file symbols (e.g. "df *ABS*") are not part of the program, like in nm
+		if match == nil || strings.ContainsAny(match[1], "df") {
+			return "", false
+		}
+		name = strings.TrimPrefix(strings.TrimSpace(match[2]), ".hidden ")
+		name = symbolVersionPrefix.ReplaceAllString(name, "")
+	} else {
+		match := nmLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			return "", false
+		}
+		name = match[1]
+	}
+
+	name = strings.TrimSpace(symbolVersionSuffix.ReplaceAllString(name, ""))
+	return name, name != ""
+}
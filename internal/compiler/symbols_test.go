@@ -0,0 +1,169 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleNmOutput is nm -C output of a small C++ program linked against glibc.
+const sampleNmOutput = `0000000000004010 B __bss_start
+                 w __cxa_finalize@GLIBC_2.2.5
+0000000000004000 D __data_start
+                 w __gmon_start__
+0000000000001139 T main
+                 U printf@GLIBC_2.2.5
+0000000000001150 T greet(std::string const&, int)
+                 U std::ios_base::Init::Init()@GLIBCXX_3.4
+0000000000001040 T _start
+`
+
+// TestParseSymbols_Nm tests that names are taken from nm output without addresses, types or versions.
+func TestParseSymbols_Nm(t *testing.T) {
+	symbols, truncated := parseSymbols(models.SymbolToolNm, sampleNmOutput)
+
+	assert.False(t, truncated)
+	assert.Equal(t, []string{
+		"__bss_start",
+		"__cxa_finalize",
+		"__data_start",
+		"__gmon_start__",
+		"main",
+		"printf",
+		"greet(std::string const&, int)",
+		"std::ios_base::Init::Init()",
+		"_start",
+	}, symbols)
+}
+
+// TestParseSymbols_GoNm tests go tool nm output, whose addresses are not padded.
+func TestParseSymbols_GoNm(t *testing.T) {
+	output := "  4a3f20 T main.main\n  4a3f60 T main.init\n  52c0a0 D runtime.buildVersion\n         U _cgo_init\n"
+
+	symbols, truncated := parseSymbols(models.SymbolToolGoNm, output)
+
+	assert.False(t, truncated)
+	assert.Equal(t, []string{"main.main", "main.init", "runtime.buildVersion", "_cgo_init"}, symbols)
+}
+
+// TestParseSymbols_Objdump tests objdump -t output, skipping file and section symbols.
+func TestParseSymbols_Objdump(t *testing.T) {
+	output := `
+/workspace/output:     file format elf64-x86-64
+
+SYMBOL TABLE:
+0000000000000318 l    d  .interp	0000000000000000              .interp
+0000000000000000 l    df *ABS*	0000000000000000              source.c
+0000000000000000       F *UND*	0000000000000000              printf@GLIBC_2.2.5
+0000000000000000       F *UND*	0000000000000000              GLIBC_2.2.5 puts
+0000000000004008 g     O .data	0000000000000000              .hidden __dso_handle
+0000000000001139 g     F .text	0000000000000016              main
+`
+
+	symbols, truncated := parseSymbols(models.SymbolToolObjdump, output)
+
+	assert.False(t, truncated)
+	assert.Equal(t, []string{"printf", "puts", "__dso_handle", "main"}, symbols)
+}
+
+// TestParseSymbols_Cap tests that the list is capped at maxSymbols and duplicates are dropped.
+func TestParseSymbols_Cap(t *testing.T) {
+	var output strings.Builder
+	for i := range maxSymbols + 10 {
+		fmt.Fprintf(&output, "%016x T fn%d\n%016x T fn%d\n", i, i, i, i)
+	}
+
+	symbols, truncated := parseSymbols(models.SymbolToolNm, output.String())
+
+	assert.True(t, truncated)
+	assert.Len(t, symbols, maxSymbols)
+	assert.Equal(t, "fn0", symbols[0])
+	assert.Equal(t, "fn1", symbols[1])
+}
+
+// TestCompile_Symbols tests that symbols are listed from the binary in a separate pass.
+func TestCompile_Symbols(t *testing.T) {
+	var configs []runtime.CompilationConfig
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			configs = append(configs, config)
+			if strings.Contains(config.CompileCommand, "nm -C") {
+				return &runtime.CompilationOutput{Stdout: sampleNmOutput}, nil
+			}
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-symbols",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("#include <cstdio>\nint main() { printf(\"hi\"); }")),
+			Language: models.LanguageCpp,
+			Compiler: models.CompilerGCC13,
+			Symbols:  true,
+		},
+	})
+
+	require.Empty(t, result.Error)
+	assert.True(t, result.Compiled)
+	assert.Contains(t, result.Symbols, "main")
+	assert.Contains(t, result.Symbols, "printf")
+	assert.False(t, result.SymbolsTruncated)
+
+	require.Len(t, configs, 2)
+	assert.Equal(t, "{ "+configs[0].CompileCommand+"; } >/dev/null 2>&1 && nm -C /workspace/output", configs[1].CompileCommand)
+	assert.Empty(t, configs[1].OutputPath)
+	assert.Empty(t, configs[1].RunCommand)
+}
+
+// TestCompile_SymbolsSkippedOnFailure tests that no symbol pass runs when the compile fails.
+func TestCompile_SymbolsSkippedOnFailure(t *testing.T) {
+	calls := 0
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			calls++
+			return &runtime.CompilationOutput{ExitCode: 1, Stderr: "error: expected ';'"}, nil
+		},
+	})
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-symbols-failed",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("int main() { return 0 }")),
+			Language: models.LanguageC,
+			Compiler: models.CompilerGCC13,
+			Symbols:  true,
+		},
+	})
+
+	assert.False(t, result.Compiled)
+	assert.Empty(t, result.Symbols)
+	assert.Equal(t, 1, calls)
+}
+
+// TestCompile_SymbolToolUnavailable tests that symbols are rejected for an image without a symbol tool.
+func TestCompile_SymbolToolUnavailable(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-no-symbol-tool",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("fn main() {}")),
+			Language: models.LanguageRust,
+			Compiler: models.CompilerRustc180,
+			Symbols:  true,
+		},
+	})
+
+	assert.False(t, result.Compiled)
+	assert.Contains(t, result.Error, ErrSymbolToolUnavailable.Error())
+}
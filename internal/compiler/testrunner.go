@@ -0,0 +1,74 @@
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+)
+
+// goTestSourceFilename is the file a single Go source is written to in test mode,
+// since go test only picks up tests from _test.go files.
+const goTestSourceFilename = "main_test.go"
+
+// rustTestResultPattern matches the summary line printed by every Rust test binary, e.g.
+// "test result: FAILED. 3 passed; 1 failed; 0 ignored; 0 measured; 0 filtered out".
+var rustTestResultPattern = regexp.MustCompile(`(?m)^test result: \w+\. (\d+) passed; (\d+) failed;`)
+
+// buildTestCommand builds the command that compiles and runs the tests of the workspace.
+// Go tests the packages of the workspace, creating a module if there is no go.mod.
+// Rust uses cargo for a Cargo project and otherwise builds the crate root with rustc --test.
+// Cargo ignores compiler flags, so a release build is selected with --release instead.
+func buildTestCommand(env models.EnvironmentSpec, sources []string, files map[string]string, release bool) string {
+	flags := ""
+	if len(env.Flags) > 0 {
+		flags = " " + strings.Join(env.Flags, " ")
+	}
+
+	switch env.Language {
+	case models.LanguageGo:
+		return fmt.Sprintf("cd /workspace && { [ -f go.mod ] || go mod init workspace >/dev/null 2>&1; } && go test -v%s ./...", flags)
+
+	case models.LanguageRust:
+		if _, ok := files["Cargo.toml"]; ok {
+			if release {
+				return "cargo test --offline --release --manifest-path /workspace/Cargo.toml"
+			}
+			return "cargo test --offline --manifest-path /workspace/Cargo.toml"
+		}
+		return fmt.Sprintf("rustc --test%s /workspace/%s -o %s && %s", flags, sources[0], binaryOutputPath, binaryOutputPath)
+
+	default:
+		// Should not happen due to validation
+		return ""
+	}
+}
+
+// parseTestCounts counts the passed and failed tests in test runner output.
+// Go's -v output reports each top-level test as "--- PASS: Name" or "--- FAIL: Name"
+// (subtests are indented and not counted); Rust prints one summary line per test binary.
+func parseTestCounts(language models.Language, output string) (passed, failed int) {
+	switch language {
+	case models.LanguageGo:
+		for _, line := range strings.Split(output, "\n") {
+			switch {
+			case strings.HasPrefix(line, "--- PASS: "):
+				passed++
+			case strings.HasPrefix(line, "--- FAIL: "):
+				failed++
+			}
+		}
+
+	case models.LanguageRust:
+		for _, match := range rustTestResultPattern.FindAllStringSubmatch(output, -1) {
+			p, _ := strconv.Atoi(match[1]) //nolint:errcheck // matched digits
+			f, _ := strconv.Atoi(match[2]) //nolint:errcheck // matched digits
+			passed += p
+			failed += f
+		}
+	}
+
+	return passed, failed
+}
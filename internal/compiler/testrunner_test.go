@@ -0,0 +1,134 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompile_TestMode tests that test mode selects the test runner and reports the test counts.
+func TestCompile_TestMode(t *testing.T) {
+	testCases := []struct {
+		name           string
+		language       models.Language
+		compiler       models.Compiler
+		stdout         string
+		expectedCmd    string
+		expectedSource string
+		expectedPassed int
+		expectedFailed int
+	}{
+		{
+			name:     "go",
+			language: models.LanguageGo,
+			compiler: models.CompilerGo123,
+			stdout: "=== RUN   TestAdd\n--- PASS: TestAdd (0.00s)\n" +
+				"=== RUN   TestTable\n    --- PASS: TestTable/zero (0.00s)\n    --- FAIL: TestTable/negative (0.00s)\n--- FAIL: TestTable (0.00s)\n" +
+				"FAIL\nFAIL\tworkspace\t0.003s\n",
+			expectedCmd:    "cd /workspace && { [ -f go.mod ] || go mod init workspace >/dev/null 2>&1; } && go test -v ./...",
+			expectedSource: "main_test.go",
+			expectedPassed: 1,
+			expectedFailed: 1,
+		},
+		{
+			name:     "rust",
+			language: models.LanguageRust,
+			compiler: models.CompilerRustc180,
+			stdout: "running 3 tests\ntest tests::add ... ok\ntest tests::sub ... ok\ntest tests::div ... FAILED\n\n" +
+				"test result: FAILED. 2 passed; 1 failed; 0 ignored; 0 measured; 0 filtered out; finished in 0.00s\n",
+			expectedCmd:    "rustc --test /workspace/main.rs -o /workspace/output && /workspace/output",
+			expectedSource: "main.rs",
+			expectedPassed: 2,
+			expectedFailed: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var capturedConfig runtime.CompilationConfig
+			mockRuntime := &runtime.MockRuntime{
+				CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+					capturedConfig = config
+					return &runtime.CompilationOutput{ExitCode: 1, Stdout: tc.stdout}, nil
+				},
+			}
+
+			compiler := NewCompilerWithRuntime(mockRuntime)
+
+			result := compiler.Compile(context.Background(), models.CompilationJob{
+				ID: "test-test-mode-" + tc.name,
+				Request: models.CompilationRequest{
+					Code:     base64.StdEncoding.EncodeToString([]byte("tests")),
+					Language: tc.language,
+					Compiler: tc.compiler,
+					Test:     true,
+				},
+			})
+
+			require.Empty(t, result.Error)
+			assert.Equal(t, tc.expectedCmd, capturedConfig.CompileCommand)
+			assert.Equal(t, tc.expectedSource, capturedConfig.SourceFilename)
+			assert.Empty(t, capturedConfig.OutputPath, "The test binary is not reported as the program")
+			assert.True(t, result.Compiled, "Failing tests still compiled")
+			assert.True(t, result.Tested)
+			assert.Equal(t, tc.expectedPassed, result.TestsPassed)
+			assert.Equal(t, tc.expectedFailed, result.TestsFailed)
+			assert.Equal(t, models.StatusCompleted, result.Status())
+		})
+	}
+}
+
+// TestCompile_TestModeBuildFailure tests that a build error in test mode is a compile failure.
+func TestCompile_TestModeBuildFailure(t *testing.T) {
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			return &runtime.CompilationOutput{
+				ExitCode: 1,
+				Stdout:   "FAIL\tworkspace [build failed]\n",
+				Stderr:   "./main_test.go:5:2: undefined: add\n",
+			}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+
+	result := compiler.Compile(context.Background(), models.CompilationJob{
+		ID: "test-test-mode-build-failure",
+		Request: models.CompilationRequest{
+			Code:     base64.StdEncoding.EncodeToString([]byte("package main")),
+			Language: models.LanguageGo,
+			Compiler: models.CompilerGo123,
+			Test:     true,
+		},
+	})
+
+	assert.False(t, result.Compiled)
+	assert.False(t, result.Tested)
+	assert.Equal(t, models.StatusFailed, result.Status())
+}
+
+// TestBuildTestCommand_Cargo tests that a Cargo project is tested with cargo.
+func TestBuildTestCommand_Cargo(t *testing.T) {
+	env := models.EnvironmentSpec{Language: models.LanguageRust, Compiler: models.CompilerRustc180}
+	files := map[string]string{"Cargo.toml": "[package]", "src/main.rs": "fn main() {}"}
+
+	assert.Equal(t, "cargo test --offline --manifest-path /workspace/Cargo.toml",
+		buildTestCommand(env, []string{"src/main.rs"}, files, false))
+	assert.Equal(t, "cargo test --offline --release --manifest-path /workspace/Cargo.toml",
+		buildTestCommand(env, []string{"src/main.rs"}, files, true))
+}
+
+// TestParseTestCounts_MultipleBinaries tests that Rust counts are summed over test binaries.
+func TestParseTestCounts_MultipleBinaries(t *testing.T) {
+	output := "test result: ok. 4 passed; 0 failed; 0 ignored; 0 measured; 0 filtered out\n" +
+		"test result: FAILED. 1 passed; 2 failed; 0 ignored; 0 measured; 0 filtered out\n"
+
+	passed, failed := parseTestCounts(models.LanguageRust, output)
+	assert.Equal(t, 5, passed)
+	assert.Equal(t, 2, failed)
+}
@@ -0,0 +1,178 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+)
+
+// Sentinel errors for toolchain version checks.
+var (
+	// ErrToolchainVersionMismatch is returned by VerifyToolchainVersions when an image's compiler
+	// reports a version other than the one pinned in its environment, or cannot be probed.
+	ErrToolchainVersionMismatch = errors.New("toolchain version mismatch")
+	ErrToolchainProbeFailed     = errors.New("toolchain version probe failed")
+)
+
+// toolchainProbeTimeout bounds a single version probe; printing a version is near-instant.
+const toolchainProbeTimeout = 10 * time.Second
+
+// toolchainVersionPattern matches the first dotted version in version output, e.g. "13.2.0" in
+// "g++ (GCC) 13.2.0" or "1.23.4" in "go version go1.23.4 linux/amd64".
+var toolchainVersionPattern = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+// toolchainVersionCommand returns the command that prints the version of an environment's compiler.
+func toolchainVersionCommand(env models.EnvironmentSpec) string {
+	switch {
+	case env.Compiler.IsZig():
+		return "zig version" // zig cc/c++ --version reports the bundled clang instead
+	case env.Language == models.LanguageGo:
+		return "go version"
+	case env.Language == models.LanguageRust:
+		return "rustc --version"
+	case env.Language == models.LanguageObjC:
+		return "clang --version"
+	case env.Language == models.LanguageObjCpp:
+		return "clang++ --version"
+	case env.Language == models.LanguageSwift:
+		return "swiftc --version"
+	case env.Language == models.LanguageKotlin:
+		return "kotlinc -version"
+	default:
+		return cFamilyDriver(env) + " --version"
+	}
+}
+
+// parseToolchainVersion returns the version in the first line of version output.
+func parseToolchainVersion(output string) (string, bool) {
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	version := toolchainVersionPattern.FindString(firstLine)
+	return version, version != ""
+}
+
+// ProbeToolchainVersion runs the compiler of an environment in the given image and returns the
+// version it reports.
+func (c *Compiler) ProbeToolchainVersion(ctx context.Context, env models.EnvironmentSpec, image string) (string, error) {
+	sourceFilename := c.getSourceFilename(env.Language)
+	output, err := c.runtime.Compile(ctx, runtime.CompilationConfig{
+		JobID:          "toolchain-probe",
+		ImageTag:       image,
+		SourceFilename: sourceFilename,
+		CompileCommand: toolchainVersionCommand(env),
+		WorkDir:        "/workspace",
+		Env:            c.buildEnvVars(env, sourceFilename, toolchainProbeTimeout),
+		Timeout:        toolchainProbeTimeout,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrToolchainProbeFailed, err)
+	}
+	if output.ExitCode != 0 {
+		return "", fmt.Errorf("%w: %s exited with code %d: %s", ErrToolchainProbeFailed,
+			toolchainVersionCommand(env), output.ExitCode, strings.TrimSpace(output.Stderr))
+	}
+
+	version, ok := parseToolchainVersion(output.Stdout)
+	if !ok {
+		return "", fmt.Errorf("%w: no version in %s output", ErrToolchainProbeFailed, toolchainVersionCommand(env))
+	}
+	return version, nil
+}
+
+// VerifyToolchainVersions probes every image of the environments that pin a toolchain version,
+// so a floating tag (e.g. gcc:13) that drifted to another patch release is caught at startup.
+// Environments below the configured minimum compiler version are skipped, like in SelfTest.
+// The error lists every mismatching or unprobeable image.
+func (c *Compiler) VerifyToolchainVersions(ctx context.Context) error {
+	c.mu.RLock()
+	environments := c.environments
+	minVersions := c.minVersions
+	c.mu.RUnlock()
+
+	return c.verifyToolchainVersions(ctx, environments, minVersions)
+}
+
+// verifyToolchainVersions is VerifyToolchainVersions for the given environments, so a reloaded
+// config can be checked before it replaces the current one.
+func (c *Compiler) verifyToolchainVersions(ctx context.Context, environments map[string]models.EnvironmentSpec, minVersions map[string]string) error {
+	var failures []string
+	for _, key := range slices.Sorted(maps.Keys(environments)) {
+		env := environments[key]
+		if env.ToolchainVersion == "" {
+			continue
+		}
+		if _, below := belowMinimumVersion(env.Compiler, minVersions); below {
+			continue
+		}
+
+		for _, image := range environmentImages(env) {
+			version, err := c.ProbeToolchainVersion(ctx, env, image)
+			switch {
+			case err != nil:
+				failures = append(failures, fmt.Sprintf("%s (%s): %v", key, image, err))
+			case version != env.ToolchainVersion:
+				failures = append(failures, fmt.Sprintf("%s (%s): reports %s, pinned %s", key, image, version, env.ToolchainVersion))
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w:\n  - %s", ErrToolchainVersionMismatch, strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+// ProbeVersions runs the compiler of every environment once in its image and records the version
+// it reports as the environment's ActualVersion, so an environment configured as gcc 13 whose image
+// was rebuilt with gcc 14 shows in the environments listing. Environments below the configured
+// minimum compiler version are skipped. Environments that cannot be probed keep an empty
+// ActualVersion; the error lists them.
+func (c *Compiler) ProbeVersions(ctx context.Context) error {
+	c.mu.RLock()
+	environments := c.environments
+	minVersions := c.minVersions
+	c.mu.RUnlock()
+
+	versions := make(map[string]string)
+	var failures []string
+	for _, key := range slices.Sorted(maps.Keys(environments)) {
+		env := environments[key]
+		if _, below := belowMinimumVersion(env.Compiler, minVersions); below {
+			continue
+		}
+
+		version, err := c.ProbeToolchainVersion(ctx, env, env.ImageTag)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s): %v", key, env.ImageTag, err))
+			continue
+		}
+		versions[key] = version
+	}
+
+	// Recorded into a copy, as other callers iterate a snapshot of the map without the lock; an
+	// environment that a reload meanwhile moved to another image keeps its version unset
+	c.mu.Lock()
+	probed := maps.Clone(c.environments)
+	for key, version := range versions {
+		env, exists := probed[key]
+		if !exists || env.ImageTag != environments[key].ImageTag {
+			continue
+		}
+		env.ActualVersion = version
+		probed[key] = env
+	}
+	c.environments = probed
+	c.mu.Unlock()
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w:\n  - %s", ErrToolchainProbeFailed, strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
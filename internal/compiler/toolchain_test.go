@@ -0,0 +1,175 @@
+package compiler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionProbeRuntime returns a mock runtime that answers version probes with the output for each image.
+func versionProbeRuntime(outputs map[string]string, commands *[]string) *runtime.MockRuntime {
+	return &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			*commands = append(*commands, config.CompileCommand)
+			return &runtime.CompilationOutput{Stdout: outputs[config.ImageTag]}, nil
+		},
+	}
+}
+
+// TestVerifyToolchainVersions_Match tests that only pinned environments are probed and matching versions pass.
+func TestVerifyToolchainVersions_Match(t *testing.T) {
+	var commands []string
+	compiler := NewCompilerWithRuntime(versionProbeRuntime(map[string]string{
+		"gcc:13": "g++ (GCC) 13.2.0\nCopyright (C) 2023 Free Software Foundation, Inc.\n",
+	}, &commands))
+
+	env := compiler.environments["cpp-gcc-13"]
+	env.ToolchainVersion = "13.2.0"
+	compiler.environments["cpp-gcc-13"] = env
+
+	require.NoError(t, compiler.VerifyToolchainVersions(context.Background()))
+	assert.Equal(t, []string{"g++ --version"}, commands)
+}
+
+// TestVerifyToolchainVersions_Mismatch tests that an image that drifted to another patch release fails startup.
+func TestVerifyToolchainVersions_Mismatch(t *testing.T) {
+	var commands []string
+	compiler := NewCompilerWithRuntime(versionProbeRuntime(map[string]string{
+		"gcc:13":           "g++ (GCC) 13.3.0\n",
+		"rust:1.80-alpine": "rustc 1.80.1 (3f5fd8dd4 2024-08-06)\n",
+	}, &commands))
+
+	for key, version := range map[string]string{"cpp-gcc-13": "13.2.0", "rust-rustc-1.80": "1.80.1"} {
+		env := compiler.environments[key]
+		env.ToolchainVersion = version
+		compiler.environments[key] = env
+	}
+
+	err := compiler.VerifyToolchainVersions(context.Background())
+
+	require.ErrorIs(t, err, ErrToolchainVersionMismatch)
+	assert.Contains(t, err.Error(), "cpp-gcc-13 (gcc:13): reports 13.3.0, pinned 13.2.0")
+	assert.NotContains(t, err.Error(), "rust-rustc-1.80", "Only the drifted environment is reported")
+}
+
+// TestVerifyToolchainVersions_ProbeFailure tests that an image whose compiler prints no version fails startup.
+func TestVerifyToolchainVersions_ProbeFailure(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			return &runtime.CompilationOutput{ExitCode: 127, Stderr: "sh: g++: not found\n"}, nil
+		},
+	})
+
+	env := compiler.environments["cpp-gcc-13"]
+	env.ToolchainVersion = "13.2.0"
+	compiler.environments["cpp-gcc-13"] = env
+
+	err := compiler.VerifyToolchainVersions(context.Background())
+
+	require.ErrorIs(t, err, ErrToolchainVersionMismatch)
+	assert.Contains(t, err.Error(), "g++: not found")
+}
+
+// TestReloadConfig_ToolchainVersionDrift tests that a reload whose pinned image drifted keeps the old config.
+func TestReloadConfig_ToolchainVersionDrift(t *testing.T) {
+	t.Setenv("MINIMAL_IMAGE_VALIDATION", "false")
+
+	configPath := filepath.Join(t.TempDir(), "environments.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`environments:
+  - language: cpp
+    compilers:
+      - name: gcc
+        version: "12"
+        image: gcc:12
+        standards: [c++17]
+        toolchain_version: "12.3.0"
+`), 0o644))
+
+	var commands []string
+	compiler := NewCompilerWithRuntime(versionProbeRuntime(map[string]string{"gcc:12": "g++ (GCC) 12.4.0\n"}, &commands))
+	before := compiler.GetSupportedEnvironments()
+
+	err := compiler.ReloadConfig(context.Background(), configPath)
+
+	require.ErrorIs(t, err, ErrToolchainVersionMismatch)
+	assert.Contains(t, err.Error(), "cpp-gcc-12 (gcc:12): reports 12.4.0, pinned 12.3.0")
+	assert.Equal(t, before, compiler.GetSupportedEnvironments(), "Old environments should be kept")
+}
+
+// TestProbeVersions tests that the version each image reports is listed per compiler, even when it drifted
+// from the configured one, and that an unprobeable image is reported without a version.
+func TestProbeVersions(t *testing.T) {
+	var commands []string
+	compiler := NewCompilerWithRuntime(versionProbeRuntime(map[string]string{
+		"gcc:13":           "g++ (GCC) 14.1.0\n",
+		"rust:1.80-alpine": "rustc 1.80.1 (3f5fd8dd4 2024-08-06)\n",
+	}, &commands))
+	compiler.environments = map[string]models.EnvironmentSpec{
+		"cpp-gcc-13":      compiler.environments["cpp-gcc-13"],
+		"rust-rustc-1.80": compiler.environments["rust-rustc-1.80"],
+		"go-go-1.23":      compiler.environments["go-go-1.23"],
+	}
+
+	err := compiler.ProbeVersions(context.Background())
+
+	require.ErrorIs(t, err, ErrToolchainProbeFailed)
+	assert.Contains(t, err.Error(), "go-go-1.23")
+	assert.Equal(t, []string{"g++ --version", "go version", "rustc --version"}, commands, "One probe per environment, in key order")
+
+	actual := make(map[string]map[string]string)
+	for _, env := range compiler.GetSupportedEnvironments() {
+		actual[env.Language] = env.ActualVersion
+	}
+	assert.Equal(t, map[string]string{"gcc-13": "14.1.0"}, actual["cpp"], "The drifted version is reported as is")
+	assert.Equal(t, map[string]string{"rustc-1.80": "1.80.1"}, actual["rust"])
+	assert.Nil(t, actual["go"], "An unprobeable image has no version")
+}
+
+// TestParseToolchainVersion tests version extraction from the version output of each toolchain.
+func TestParseToolchainVersion(t *testing.T) {
+	testCases := []struct {
+		output   string
+		expected string
+	}{
+		{"g++ (GCC) 13.2.0\nCopyright (C) 2023 Free Software Foundation, Inc.\n", "13.2.0"},
+		{"gcc (Debian 12.2.0-14) 12.2.0\n", "12.2.0"},
+		{"go version go1.23.4 linux/amd64\n", "1.23.4"},
+		{"rustc 1.80.1 (3f5fd8dd4 2024-08-06)\n", "1.80.1"},
+		{"0.13.0\n", "0.13.0"},
+		{"Debian clang version 14.0.6\n", "14.0.6"},
+	}
+
+	for _, tc := range testCases {
+		version, ok := parseToolchainVersion(tc.output)
+		assert.True(t, ok, tc.output)
+		assert.Equal(t, tc.expected, version)
+	}
+
+	_, ok := parseToolchainVersion("sh: g++: not found\n")
+	assert.False(t, ok)
+}
+
+// TestToolchainVersionCommand tests that each environment is probed with its own compiler.
+func TestToolchainVersionCommand(t *testing.T) {
+	compiler := NewCompilerWithRuntime(&runtime.MockRuntime{})
+
+	expected := map[string]string{
+		"cpp-gcc-13":      "g++ --version",
+		"c-gcc-13":        "gcc --version",
+		"go-go-1.23":      "go version",
+		"rust-rustc-1.80": "rustc --version",
+		"cpp-zig-0.13":    "zig version",
+		"cpp-clang-16":    "clang++ --version",
+		"c-clang-16":      "clang --version",
+	}
+	for key, command := range expected {
+		assert.Equal(t, command, toolchainVersionCommand(compiler.environments[key]), key)
+	}
+	assert.Equal(t, "clang++ --version", toolchainVersionCommand(models.EnvironmentSpec{Language: models.LanguageObjCpp}))
+}
@@ -0,0 +1,32 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrWarmupIncomplete is returned by Warmup when some environments could not run their warm-up compile.
+var ErrWarmupIncomplete = errors.New("warm-up incomplete")
+
+// Warmup runs one hello-world compile in every environment requests can select, so the image
+// layers, the runtime and the toolchain's own caches are hot before the first user compile.
+// Unlike SelfTest it does not judge the result: a program that fails to compile still warmed
+// the environment. Only environments whose compile could not run at all are reported.
+func (c *Compiler) Warmup(ctx context.Context) error {
+	var failures []string
+	for _, hello := range c.helloWorldJobs("warmup-") {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if result := c.Compile(ctx, hello.job); result.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s (%s): %s", hello.key, hello.env.ImageTag, result.Error))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w:\n  - %s", ErrWarmupIncomplete, strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWarmup_OneCompilePerEnvironment tests that the warm-up compiles exactly once in every environment.
+func TestWarmup_OneCompilePerEnvironment(t *testing.T) {
+	jobs := map[string]int{}
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			jobs[config.JobID]++
+			return &runtime.CompilationOutput{ExitCode: 1, Stderr: "error: something\n"}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+	require.NoError(t, compiler.Warmup(context.Background()), "A failed compile still warms the environment")
+
+	require.Len(t, jobs, len(compiler.environments))
+	for key := range compiler.environments {
+		assert.Equal(t, 1, jobs["warmup-"+key], key)
+	}
+}
+
+// TestWarmup_ReportsUnreachableEnvironment tests that an environment whose compile cannot run is reported.
+func TestWarmup_ReportsUnreachableEnvironment(t *testing.T) {
+	mockRuntime := &runtime.MockRuntime{
+		CompileFunc: func(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
+			if config.ImageTag == "rust:1.80-alpine" {
+				return nil, errors.New("image not found")
+			}
+			return &runtime.CompilationOutput{ExitCode: 0}, nil
+		},
+	}
+
+	compiler := NewCompilerWithRuntime(mockRuntime)
+	err := compiler.Warmup(context.Background())
+
+	require.ErrorIs(t, err, ErrWarmupIncomplete)
+	assert.Contains(t, err.Error(), "rust-rustc-1.80 (rust:1.80-alpine)")
+	assert.NotContains(t, err.Error(), "cpp-gcc-13")
+}
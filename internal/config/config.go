@@ -2,6 +2,8 @@ package config
 
 import (
 	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
 )
 
 // Config holds the application configuration.
@@ -17,12 +19,37 @@ type Config struct {
 
 	// Compilation configuration
 	Compilation CompilationConfig
+
+	// Job storage configuration (applies to all stores)
+	Storage StorageConfig
 }
 
 // ServerConfig holds HTTP server settings.
 type ServerConfig struct {
 	Port        int
 	Environment string // "development" or "production"
+
+	// MaxBatchSize is the maximum number of requests in one batch submission
+	MaxBatchSize int
+
+	// AdminToken is the bearer token for operator endpoints (empty disables them)
+	AdminToken string
+
+	// APIKeys are rate limited per key at APIKeyRateLimit requests per minute instead of per IP
+	APIKeys         []string
+	APIKeyRateLimit int
+
+	// TrustedAPIKeys bypass rate limiting and the daily quota
+	TrustedAPIKeys []string
+
+	// DailyQuota caps compiles per IP or API key per UTC day (0 disables it)
+	DailyQuota int
+
+	// SelfTest compiles a hello-world program per environment at startup and refuses to start if any fails
+	SelfTest bool
+
+	// Warmup runs a hello-world compile per environment at startup to prime the image and toolchain caches
+	Warmup bool
 }
 
 // RedisConfig holds Redis connection settings.
@@ -53,6 +80,15 @@ type RedisConfig struct {
 
 	// JobTTL is the time-to-live for job data
 	JobTTL time.Duration
+
+	// KeyPrefix namespaces all keys (e.g., "tenant-a:") so deployments can share one Redis
+	KeyPrefix string
+
+	// SlidingTTL renews a job's TTL whenever it is read, so jobs being polled do not expire mid-poll
+	SlidingTTL bool
+
+	// MaxJobAge bounds how long SlidingTTL can keep a job after its submission
+	MaxJobAge time.Duration
 }
 
 // WorkerPoolConfig holds worker pool settings.
@@ -62,6 +98,13 @@ type WorkerPoolConfig struct {
 
 	// QueueSize is the size of the job queue buffer
 	QueueSize int
+
+	// StaleJobGracePeriod is how long a processing job's heartbeat may go
+	// unrefreshed before the job is re-enqueued on startup
+	StaleJobGracePeriod time.Duration
+
+	// Reservations is the number of workers reserved per language (none by default)
+	Reservations map[models.Language]int
 }
 
 // CompilationConfig holds compilation-specific settings.
@@ -73,12 +116,29 @@ type CompilationConfig struct {
 	Timeout time.Duration
 }
 
+// StorageConfig holds settings shared by all job stores.
+type StorageConfig struct {
+	// MaxStoredOutputBytes caps each stored output stream of a result (0 = unlimited)
+	// This is separate from the compile-time output cap
+	MaxStoredOutputBytes int
+
+	// ResultTTLByStatus is how long results are kept, by final job status (e.g., keep failures longer for debugging)
+	// Statuses without an entry use Redis.JobTTL in Redis and are never evicted from memory
+	ResultTTLByStatus map[models.JobStatus]time.Duration
+
+	// MaxMemoryJobs caps the jobs kept by the in-memory store; past it, the oldest finished jobs are evicted
+	// with their results (0 = unlimited)
+	MaxMemoryJobs int
+}
+
 // DefaultConfig returns a configuration with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:        8080,
-			Environment: "development",
+			Port:            8080,
+			Environment:     "development",
+			MaxBatchSize:    10,
+			APIKeyRateLimit: 60,
 		},
 		Redis: RedisConfig{
 			Enabled:      false,
@@ -90,14 +150,19 @@ func DefaultConfig() *Config {
 			ReadTimeout:  3 * time.Second,
 			WriteTimeout: 3 * time.Second,
 			JobTTL:       24 * time.Hour,
+			MaxJobAge:    7 * 24 * time.Hour,
 		},
 		Workers: WorkerPoolConfig{
-			MaxWorkers: 5,
-			QueueSize:  100,
+			MaxWorkers:          5,
+			QueueSize:           100,
+			StaleJobGracePeriod: 2 * time.Minute,
 		},
 		Compilation: CompilationConfig{
 			MaxSourceSize: 1 * 1024 * 1024, // 1MB
 			Timeout:       30 * time.Second,
 		},
+		Storage: StorageConfig{
+			MaxMemoryJobs: 10000,
+		},
 	}
 }
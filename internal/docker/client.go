@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/stlpine/will-it-compile/internal/ansi"
 )
 
 const (
@@ -24,12 +27,13 @@ const (
 	MaxOutputSize = 1 * 1024 * 1024   // 1MB output
 
 	// Timeouts.
-	MaxCompilationTime = 30 * time.Second
+	MaxCompilationTime = 30 * time.Second // Used when the context of a compilation has no deadline
 )
 
 // Client wraps the Docker client with secure container operations.
 type Client struct {
-	cli *client.Client
+	cli         *client.Client
+	pullTimeout time.Duration // Upper bound for PullImage (DefaultPullTimeout if zero)
 }
 
 // NewClient creates a new Docker client.
@@ -47,6 +51,14 @@ func (c *Client) Close() error {
 	return c.cli.Close()
 }
 
+// Ping checks that the Docker daemon is reachable; it is the cheapest API call.
+func (c *Client) Ping(ctx context.Context) error {
+	if _, err := c.cli.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping docker daemon: %w", err)
+	}
+	return nil
+}
+
 // ImageExists checks if a Docker image exists locally.
 func (c *Client) ImageExists(ctx context.Context, imageTag string) (bool, error) {
 	_, err := c.cli.ImageInspect(ctx, imageTag)
@@ -66,29 +78,65 @@ type CompilationConfig struct {
 	SourceFilename  string // Name of the source file (e.g., "source.cpp", "main.go", "main.rs")
 	WorkDir         string
 	Env             []string
-	CompileCommand  string // Shell command to run compilation (e.g., "g++ -std=c++17 source.cpp -o output")
-	SecurityOptPath string // Path to seccomp profile
+	CompileCommand  string            // Shell command to run compilation (e.g., "g++ -std=c++17 source.cpp -o output")
+	CompileScript   string            // Script in the image to run instead of sh -c, given the command in CompileCommandEnv (optional)
+	SecurityOptPath string            // Path to seccomp profile
+	OutputPath      string            // Binary to stat after the container exits (optional)
+	ArtifactPath    string            // Text file to read back after the container exits (optional)
+	MaxArtifactSize int64             // Bytes of the artifact to keep
+	Files           map[string]string // Workspace files by relative path; replaces SourceCode when set
+	TmpSize         int64             // Size of the /tmp tmpfs in bytes (DefaultTmpSize if zero)
+	IdleTimeout     time.Duration     // Kill the container after this long without output (disabled if zero)
+	CPUShares       int64             // Relative CPU weight under contention (Docker's default of 1024 if zero)
+	PreserveANSI    bool              // Keep escape sequences in the output instead of stripping them
+
+	OnOutput func(stream, chunk string) // Receives stdout and stderr chunks as they are written (optional)
 }
 
+// CompileCommandEnv passes the compile command to CompilationConfig.CompileScript.
+const CompileCommandEnv = "COMPILE_COMMAND"
+
+// DefaultTmpSize is the size of the /tmp tmpfs when CompilationConfig.TmpSize is not set.
+const DefaultTmpSize = 64 * 1024 * 1024
+
 // CompilationOutput holds the output from a compilation.
 type CompilationOutput struct {
-	Stdout   string
-	Stderr   string
-	ExitCode int
-	Duration time.Duration
-	TimedOut bool
+	Stdout      string
+	Stderr      string
+	ExitCode    int
+	Duration    time.Duration
+	TimedOut    bool
+	BinaryBytes int64 // Size of OutputPath, 0 if not produced
+
+	TerminatedBySignal string // "SIGKILL" if the container was killed (timeout or OOM), empty otherwise
+	BinarySHA256       string // Hex-encoded SHA-256 of OutputPath, empty if not produced
+
+	Artifact          string // Content of ArtifactPath, empty if not produced
+	ArtifactTruncated bool   // Artifact was cut off at MaxArtifactSize
+
+	IdleTimedOut bool // Killed after IdleTimeout without output
+
+	NetworkEnabled bool // The container had network access
+}
+
+// compilationContext bounds a compilation by MaxCompilationTime, unless ctx already has a deadline,
+// such as the compile timeout of the environment, which may well be longer.
+func compilationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, MaxCompilationTime)
 }
 
 // RunCompilation creates and runs a secure container for compilation.
 func (c *Client) RunCompilation(ctx context.Context, config CompilationConfig) (*CompilationOutput, error) {
 	startTime := time.Now()
 
-	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(ctx, MaxCompilationTime)
+	ctx, cancel := compilationContext(ctx)
 	defer cancel()
 
 	// Create container with security constraints
-	containerID, err := c.createSecureContainer(ctx, config)
+	containerID, containerConfig, err := c.createSecureContainer(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create container: %w", err)
 	}
@@ -107,11 +155,34 @@ func (c *Client) RunCompilation(ctx context.Context, config CompilationConfig) (
 		return nil, fmt.Errorf("failed to start container: %w", err)
 	}
 
-	// Wait for container to finish or timeout
+	// Follow the output to notice a container that has gone silent and to pass it on as it is written;
+	// a nil channel never fires
+	var idle <-chan struct{}
+	if config.IdleTimeout > 0 || config.OnOutput != nil {
+		if follow, err := c.cli.ContainerLogs(ctx, containerID, container.LogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Follow:     true,
+		}); err == nil {
+			defer follow.Close() //nolint:errcheck // read-only operation
+			var logs io.Reader = follow
+			if config.OnOutput != nil {
+				logs = streamOutput(follow, config.OnOutput, config.PreserveANSI)
+			}
+			if config.IdleTimeout > 0 {
+				idle = watchIdle(logs, config.IdleTimeout)
+			} else {
+				go io.Copy(io.Discard, logs) //nolint:errcheck // ends when the container stops or follow is closed
+			}
+		}
+	}
+
+	// Wait for container to finish, time out or go idle
 	statusCh, errCh := c.cli.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
 
 	var exitCode int64
 	timedOut := false
+	idleTimedOut := false
 
 	select {
 	case err := <-errCh:
@@ -125,28 +196,103 @@ func (c *Client) RunCompilation(ctx context.Context, config CompilationConfig) (
 		timedOut = true
 		killCtx := context.WithoutCancel(ctx)
 		_ = c.cli.ContainerKill(killCtx, containerID, "SIGKILL") //nolint:errcheck // best effort kill
+	case <-idle:
+		// No output for the idle window - kill the container before the full timeout
+		idleTimedOut = true
+		killCtx := context.WithoutCancel(ctx)
+		_ = c.cli.ContainerKill(killCtx, containerID, "SIGKILL") //nolint:errcheck // best effort kill
 	}
 
 	// Collect output - use context without cancel to ensure we can collect output even after timeout
 	outputCtx := context.WithoutCancel(ctx)
-	stdout, stderr, err := c.collectOutput(outputCtx, containerID)
+	stdout, stderr, err := c.collectOutput(outputCtx, containerID, config.PreserveANSI)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect output: %w", err)
 	}
 
+	// A kill by us (timeout or idle) or by the kernel (OOM) is not the compiler's own exit status
+	terminatedBySignal := ""
+	if timedOut || idleTimedOut {
+		terminatedBySignal = "SIGKILL"
+	} else if inspect, err := c.cli.ContainerInspect(outputCtx, containerID); err == nil &&
+		inspect.ContainerJSONBase != nil && inspect.State != nil && inspect.State.OOMKilled {
+		terminatedBySignal = "SIGKILL"
+	}
+
+	// Stat and hash the produced binary before the container is removed; a failed compile leaves none behind
+	var binaryBytes int64
+	var binarySHA256 string
+	if config.OutputPath != "" {
+		if stat, err := c.cli.ContainerStatPath(outputCtx, containerID, config.OutputPath); err == nil {
+			binaryBytes = stat.Size
+			binarySHA256 = c.hashContainerFile(outputCtx, containerID, config.OutputPath)
+		}
+	}
+
+	// Read the artifact back the same way; like the binary, it is missing after a failed compile
+	var artifact string
+	var artifactTruncated bool
+	if config.ArtifactPath != "" {
+		artifact, artifactTruncated = c.readContainerFile(outputCtx, containerID, config.ArtifactPath, config.MaxArtifactSize)
+	}
+
 	duration := time.Since(startTime)
 
 	return &CompilationOutput{
-		Stdout:   stdout,
-		Stderr:   stderr,
-		ExitCode: int(exitCode),
-		Duration: duration,
-		TimedOut: timedOut,
+		Stdout:      stdout,
+		Stderr:      stderr,
+		ExitCode:    int(exitCode),
+		Duration:    duration,
+		TimedOut:    timedOut,
+		BinaryBytes: binaryBytes,
+
+		TerminatedBySignal: terminatedBySignal,
+		BinarySHA256:       binarySHA256,
+
+		Artifact:          artifact,
+		ArtifactTruncated: artifactTruncated,
+
+		IdleTimedOut: idleTimedOut,
+
+		NetworkEnabled: !containerConfig.NetworkDisabled,
 	}, nil
 }
 
+// readContainerFile returns up to limit bytes of a file in the container, and whether it was cut off.
+// Like the hash, the artifact is informational: a file that cannot be read is reported as empty.
+func (c *Client) readContainerFile(ctx context.Context, containerID, filePath string, limit int64) (string, bool) {
+	reader, _, err := c.cli.CopyFromContainer(ctx, containerID, filePath)
+	if err != nil {
+		return "", false
+	}
+	defer reader.Close() //nolint:errcheck // read-only
+
+	content, truncated, err := readFromTar(reader, limit)
+	if err != nil {
+		return "", false
+	}
+	return content, truncated
+}
+
+// hashContainerFile returns the SHA-256 of a file in the container, or "" if it cannot be read.
+// The hash is informational, so failures don't fail the compilation.
+func (c *Client) hashContainerFile(ctx context.Context, containerID, filePath string) string {
+	reader, _, err := c.cli.CopyFromContainer(ctx, containerID, filePath)
+	if err != nil {
+		return ""
+	}
+	defer reader.Close() //nolint:errcheck // read-only
+
+	hash, err := sha256FromTar(reader)
+	if err != nil {
+		return ""
+	}
+	return hash
+}
+
 // createSecureContainer creates a container with all security constraints.
-func (c *Client) createSecureContainer(ctx context.Context, config CompilationConfig) (string, error) {
+// It returns the container configuration it was created with, so callers can report what was applied.
+func (c *Client) createSecureContainer(ctx context.Context, config CompilationConfig) (string, *container.Config, error) {
 	// Security options
 	securityOpt := []string{
 		"no-new-privileges",
@@ -157,21 +303,11 @@ func (c *Client) createSecureContainer(ctx context.Context, config CompilationCo
 		securityOpt = append(securityOpt, "seccomp="+config.SecurityOptPath)
 	}
 
-	// Container configuration
-	// Use inline shell command for official images (they don't have compile.sh)
-	// Run as root (official images don't have compiler user)
-	// Use the compile command from config, or fallback to default C++ command
-	compileCmd := config.CompileCommand
-	if compileCmd == "" {
-		compileCmd = "g++ -std=${CPP_STANDARD:-c++17} ${SOURCE_FILE} -o /workspace/output"
-	}
+	containerConfig := newContainerConfig(config)
 
-	containerConfig := &container.Config{
-		Image:           config.ImageTag,
-		Cmd:             []string{"/bin/sh", "-c", compileCmd},
-		WorkingDir:      "/workspace",
-		NetworkDisabled: true,
-		Env:             config.Env,
+	tmpSize := config.TmpSize
+	if tmpSize <= 0 {
+		tmpSize = DefaultTmpSize
 	}
 
 	// Host configuration with resource limits and security
@@ -180,13 +316,14 @@ func (c *Client) createSecureContainer(ctx context.Context, config CompilationCo
 			Memory:     MaxMemory,
 			MemorySwap: MaxMemorySwap,
 			CPUQuota:   MaxCPUQuota,
+			CPUShares:  config.CPUShares,
 			PidsLimit:  func() *int64 { v := int64(MaxPidsLimit); return &v }(),
 		},
 		SecurityOpt:    securityOpt,
 		ReadonlyRootfs: false,           // Must be false to copy files before start
 		CapDrop:        []string{"ALL"}, // Drop all capabilities
 		Tmpfs: map[string]string{
-			"/tmp": "rw,noexec,nosuid,size=64m",
+			"/tmp": fmt.Sprintf("rw,noexec,nosuid,size=%d", tmpSize),
 		},
 		// Ensure no mounts from host
 		Mounts: []mount.Mount{},
@@ -195,7 +332,7 @@ func (c *Client) createSecureContainer(ctx context.Context, config CompilationCo
 	// Create the container
 	resp, err := c.cli.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	// Determine source filename (default to source.cpp if not specified)
@@ -204,20 +341,53 @@ func (c *Client) createSecureContainer(ctx context.Context, config CompilationCo
 		sourceFilename = "source.cpp"
 	}
 
+	// Multi-file submissions (archives) replace the single source file
+	files := config.Files
+	if len(files) == 0 {
+		files = map[string]string{sourceFilename: config.SourceCode}
+	}
+
 	// Copy source code into container
-	if err := c.copySourceToContainer(ctx, resp.ID, config.SourceCode, sourceFilename); err != nil {
+	if err := c.copySourceToContainer(ctx, resp.ID, files); err != nil {
 		// Cleanup on error
 		_ = c.cli.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{Force: true}) //nolint:errcheck // already in error path
-		return "", fmt.Errorf("failed to copy source code: %w", err)
+		return "", nil, fmt.Errorf("failed to copy source code: %w", err)
+	}
+
+	return resp.ID, containerConfig, nil
+}
+
+// newContainerConfig returns the container configuration for a compilation.
+// The compile command runs through sh -c, so stock upstream images (e.g., gcc:13) work as they are;
+// images with a compile script run that instead, with the command in CompileCommandEnv. It runs as
+// root, since stock images have no compiler user. Without a compile command it falls back to the
+// default C++ command.
+func newContainerConfig(config CompilationConfig) *container.Config {
+	compileCmd := config.CompileCommand
+	if compileCmd == "" {
+		compileCmd = "g++ -std=${CPP_STANDARD:-c++17} ${SOURCE_FILE} -o /workspace/output"
+	}
+
+	cmd := []string{"/bin/sh", "-c", compileCmd}
+	env := config.Env
+	if config.CompileScript != "" {
+		cmd = []string{config.CompileScript}
+		env = append(slices.Clone(config.Env), CompileCommandEnv+"="+compileCmd)
 	}
 
-	return resp.ID, nil
+	return &container.Config{
+		Image:           config.ImageTag,
+		Cmd:             cmd,
+		WorkingDir:      "/workspace",
+		NetworkDisabled: true,
+		Env:             env,
+	}
 }
 
-// copySourceToContainer copies source code into the container.
-func (c *Client) copySourceToContainer(ctx context.Context, containerID, sourceCode, sourceFilename string) error {
+// copySourceToContainer copies the source files into the container's workspace.
+func (c *Client) copySourceToContainer(ctx context.Context, containerID string, files map[string]string) error {
 	// Create a tar archive with the source code
-	tarContent, err := createSourceTar(sourceCode, sourceFilename)
+	tarContent, err := createSourceTar(files)
 	if err != nil {
 		return err
 	}
@@ -226,8 +396,8 @@ func (c *Client) copySourceToContainer(ctx context.Context, containerID, sourceC
 	return c.cli.CopyToContainer(ctx, containerID, "/workspace", tarContent, container.CopyToContainerOptions{})
 }
 
-// collectOutput retrieves stdout and stderr from the container.
-func (c *Client) collectOutput(ctx context.Context, containerID string) (string, string, error) {
+// collectOutput retrieves stdout and stderr from the container, stripping escape sequences unless preserveANSI is set.
+func (c *Client) collectOutput(ctx context.Context, containerID string, preserveANSI bool) (string, string, error) {
 	logs, err := c.cli.ContainerLogs(ctx, containerID, container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
@@ -246,13 +416,15 @@ func (c *Client) collectOutput(ctx context.Context, containerID string) (string,
 		return "", "", err
 	}
 
-	return sanitizeOutput(stdoutBuf.String()), sanitizeOutput(stderrBuf.String()), nil
+	return sanitizeOutput(stdoutBuf.String(), preserveANSI), sanitizeOutput(stderrBuf.String(), preserveANSI), nil
 }
 
 // sanitizeOutput removes potentially dangerous content from output.
-func sanitizeOutput(output string) string {
+func sanitizeOutput(output string, preserveANSI bool) string {
 	// Remove ANSI escape sequences
-	output = removeANSIEscapes(output)
+	if !preserveANSI {
+		output = ansi.Strip(output)
+	}
 
 	// Truncate if too long
 	if len(output) > MaxOutputSize {
@@ -262,29 +434,6 @@ func sanitizeOutput(output string) string {
 	return output
 }
 
-// removeANSIEscapes removes ANSI escape sequences.
-func removeANSIEscapes(s string) string {
-	// Simple implementation - in production, use a library like github.com/acarl005/stripansi
-	result := strings.Builder{}
-	inEscape := false
-
-	for _, r := range s {
-		if r == '\x1b' {
-			inEscape = true
-			continue
-		}
-		if inEscape {
-			if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') {
-				inEscape = false
-			}
-			continue
-		}
-		result.WriteRune(r)
-	}
-
-	return result.String()
-}
-
 // limitedWriter wraps a strings.Builder with a size limit.
 type limitedWriter struct {
 	strings.Builder
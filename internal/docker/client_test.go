@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewContainerConfig tests that the compile command runs through sh -c, with no script in the image.
+func TestNewContainerConfig(t *testing.T) {
+	config := newContainerConfig(CompilationConfig{
+		ImageTag:       "gcc:13",
+		CompileCommand: "gcc -std=c17 /workspace/source.c -o /workspace/output",
+		Env:            []string{"SOURCE_FILE=source.c"},
+	})
+
+	assert.Equal(t, "gcc:13", config.Image)
+	assert.Equal(t, []string{"/bin/sh", "-c", "gcc -std=c17 /workspace/source.c -o /workspace/output"}, []string(config.Cmd))
+	assert.Equal(t, "/workspace", config.WorkingDir)
+	assert.Equal(t, []string{"SOURCE_FILE=source.c"}, config.Env)
+	assert.True(t, config.NetworkDisabled)
+}
+
+// TestNewContainerConfig_DefaultCommand tests the fallback when no compile command is given.
+func TestNewContainerConfig_DefaultCommand(t *testing.T) {
+	config := newContainerConfig(CompilationConfig{ImageTag: "gcc:13"})
+
+	assert.Equal(t, []string{"/bin/sh", "-c", "g++ -std=${CPP_STANDARD:-c++17} ${SOURCE_FILE} -o /workspace/output"}, []string(config.Cmd))
+}
+
+// TestNewContainerConfig_CompileScript tests that an image's compile script runs instead of sh -c, given the command.
+func TestNewContainerConfig_CompileScript(t *testing.T) {
+	env := []string{"SOURCE_FILE=source.c"}
+	config := newContainerConfig(CompilationConfig{
+		ImageTag:       "example/gcc-script:13",
+		CompileCommand: "gcc -std=c17 /workspace/source.c -o /workspace/output",
+		CompileScript:  "/usr/bin/compile.sh",
+		Env:            env,
+	})
+
+	assert.Equal(t, []string{"/usr/bin/compile.sh"}, []string(config.Cmd))
+	assert.Equal(t, []string{"SOURCE_FILE=source.c", "COMPILE_COMMAND=gcc -std=c17 /workspace/source.c -o /workspace/output"}, config.Env)
+	assert.Equal(t, []string{"SOURCE_FILE=source.c"}, env, "The caller's environment is not modified")
+}
+
+// TestCreateSecureContainer_ReturnsAppliedConfig tests that the returned config is the one the container was created with.
+func TestCreateSecureContainer_ReturnsAppliedConfig(t *testing.T) {
+	var created container.Config
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/containers/create"):
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"Id":"compile-1"}`)) //nolint:errcheck // test server
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/containers/compile-1/archive"):
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected Docker API call %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")), client.WithVersion("1.43"))
+	require.NoError(t, err)
+	c := &Client{cli: cli}
+
+	id, applied, err := c.createSecureContainer(context.Background(), CompilationConfig{
+		ImageTag:       "gcc:13",
+		SourceCode:     "int main() {}",
+		CompileCommand: "g++ /workspace/source.cpp -o /workspace/output",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "compile-1", id)
+	assert.True(t, created.NetworkDisabled, "The container is created without network")
+	assert.Equal(t, created.NetworkDisabled, applied.NetworkDisabled)
+	assert.Equal(t, created.Cmd, applied.Cmd)
+}
+
+// TestCreateSecureContainer_CPUShares tests that the CPU weight is set on the container, and left to
+// Docker's default when not configured.
+func TestCreateSecureContainer_CPUShares(t *testing.T) {
+	for _, shares := range []int64{0, 256} {
+		var created struct {
+			HostConfig container.HostConfig
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/containers/create"):
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"Id":"compile-1"}`)) //nolint:errcheck // test server
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+
+		cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")), client.WithVersion("1.43"))
+		require.NoError(t, err)
+		c := &Client{cli: cli}
+
+		_, _, err = c.createSecureContainer(context.Background(), CompilationConfig{
+			ImageTag:       "gcc:13",
+			SourceCode:     "int main() {}",
+			CompileCommand: "g++ /workspace/source.cpp -o /workspace/output",
+			CPUShares:      shares,
+		})
+		server.Close()
+
+		require.NoError(t, err)
+		assert.Equal(t, shares, created.HostConfig.CPUShares)
+		assert.Equal(t, int64(MaxCPUQuota), created.HostConfig.CPUQuota, "The hard CPU limit still applies")
+	}
+}
+
+// TestCompilationContext tests that a compilation without a deadline is bounded by MaxCompilationTime,
+// and that a longer deadline of its own is kept rather than cut to it.
+func TestCompilationContext(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := compilationContext(context.Background())
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.Equal(t, MaxCompilationTime, time.Until(deadline))
+
+		parent, cancelParent := context.WithTimeout(context.Background(), 90*time.Second)
+		defer cancelParent()
+		ctx, cancel = compilationContext(parent)
+		defer cancel()
+		deadline, ok = ctx.Deadline()
+		require.True(t, ok)
+		assert.Equal(t, 90*time.Second, time.Until(deadline))
+	})
+}
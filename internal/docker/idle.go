@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"io"
+	"time"
+)
+
+// watchIdle reads r until it ends and returns a channel that is closed if no bytes arrive
+// for idle. The watch stops without firing when r ends, so closing r cancels it.
+func watchIdle(r io.Reader, idle time.Duration) <-chan struct{} {
+	activity := make(chan struct{}, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				select {
+				case activity <- struct{}{}:
+				default: // A pending signal already resets the timer
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	idleCh := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(idle)
+		defer timer.Stop()
+		for {
+			select {
+			case <-activity:
+				timer.Reset(idle)
+			case <-timer.C:
+				close(idleCh)
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return idleCh
+}
@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatchIdle_SilentStall tests that a stream that stops producing output fires the idle
+// signal well before the hard timeout.
+func TestWatchIdle_SilentStall(t *testing.T) {
+	const idleTimeout = 50 * time.Millisecond
+	const hardTimeout = 2 * time.Second
+
+	r, w := io.Pipe()
+	defer r.Close()
+
+	start := time.Now()
+	idle := watchIdle(r, idleTimeout)
+
+	// Some output, then the compiler hangs (e.g., on a resolver) without exiting
+	_, err := w.Write([]byte("Compiling source.cpp\n"))
+	require.NoError(t, err)
+
+	select {
+	case <-idle:
+		assert.Less(t, time.Since(start), hardTimeout)
+		assert.GreaterOrEqual(t, time.Since(start), idleTimeout)
+	case <-time.After(hardTimeout):
+		t.Fatal("Idle timeout did not fire before the hard timeout")
+	}
+}
+
+// TestWatchIdle_SteadyOutput tests that output arriving within the window keeps the watch from firing.
+func TestWatchIdle_SteadyOutput(t *testing.T) {
+	const idleTimeout = 100 * time.Millisecond
+
+	r, w := io.Pipe()
+	defer r.Close()
+	idle := watchIdle(r, idleTimeout)
+
+	// Output for three idle windows in total, never silent for a whole one
+	for i := 0; i < 15; i++ {
+		_, err := w.Write([]byte("."))
+		require.NoError(t, err)
+		time.Sleep(idleTimeout / 5)
+	}
+
+	select {
+	case <-idle:
+		t.Fatal("Idle timeout fired while output was still arriving")
+	default:
+	}
+}
+
+// TestWatchIdle_StreamEnds tests that a stream ending (the container exited) cancels the watch.
+func TestWatchIdle_StreamEnds(t *testing.T) {
+	const idleTimeout = 50 * time.Millisecond
+
+	r, w := io.Pipe()
+	idle := watchIdle(r, idleTimeout)
+	require.NoError(t, w.Close())
+
+	select {
+	case <-idle:
+		t.Fatal("Idle timeout fired after the stream ended")
+	case <-time.After(3 * idleTimeout):
+	}
+}
@@ -7,6 +7,7 @@ import "context"
 type DockerClient interface {
 	RunCompilation(ctx context.Context, config CompilationConfig) (*CompilationOutput, error)
 	ImageExists(ctx context.Context, imageTag string) (bool, error)
+	Ping(ctx context.Context) error
 	Close() error
 }
 
@@ -9,6 +9,7 @@ import (
 type MockDockerClient struct {
 	RunCompilationFunc func(ctx context.Context, config CompilationConfig) (*CompilationOutput, error)
 	ImageExistsFunc    func(ctx context.Context, imageTag string) (bool, error)
+	PingFunc           func(ctx context.Context) error
 	CloseFunc          func() error
 }
 
@@ -36,6 +37,15 @@ func (m *MockDockerClient) ImageExists(ctx context.Context, imageTag string) (bo
 	return true, nil
 }
 
+// Ping calls the mock function.
+func (m *MockDockerClient) Ping(ctx context.Context) error {
+	if m.PingFunc != nil {
+		return m.PingFunc(ctx)
+	}
+	// Default behavior: daemon is reachable
+	return nil
+}
+
 // Close calls the mock function.
 func (m *MockDockerClient) Close() error {
 	if m.CloseFunc != nil {
@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+// DefaultPullTimeout bounds an image pull when no explicit timeout is set.
+const DefaultPullTimeout = 10 * time.Minute
+
+// Sentinel errors for image pulls.
+var (
+	ErrPullTimeout = errors.New("image pull timed out")
+	ErrPullFailed  = errors.New("image pull failed")
+)
+
+// PullProgress is a single progress update from an image pull.
+type PullProgress struct {
+	ID      string // Layer ID (empty for image-level status)
+	Status  string // e.g., "Downloading", "Pull complete"
+	Current int64  // Bytes transferred so far (0 if unknown)
+	Total   int64  // Total bytes (0 if unknown)
+}
+
+// pullMessage is one line of the JSON stream returned by the image pull API.
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+// SetPullTimeout sets the maximum duration of PullImage. Zero selects DefaultPullTimeout.
+func (c *Client) SetPullTimeout(timeout time.Duration) {
+	c.pullTimeout = timeout
+}
+
+// PullImage pulls an image, reporting progress to onProgress (may be nil).
+// The pull stops when ctx is cancelled or the pull timeout elapses, in which case ErrPullTimeout is returned.
+func (c *Client) PullImage(ctx context.Context, imageTag string, onProgress func(PullProgress)) error {
+	timeout := c.pullTimeout
+	if timeout <= 0 {
+		timeout = DefaultPullTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	reader, err := c.cli.ImagePull(ctx, imageTag, image.PullOptions{})
+	if err != nil {
+		return pullError(ctx, imageTag, err)
+	}
+
+	if err := streamPullProgress(ctx, reader, onProgress); err != nil {
+		return pullError(ctx, imageTag, err)
+	}
+	return nil
+}
+
+// streamPullProgress decodes pull progress messages until the stream ends.
+// The reader is closed when ctx is done, which unblocks a pending read and stops the pull.
+func streamPullProgress(ctx context.Context, reader io.ReadCloser, onProgress func(PullProgress)) error {
+	defer reader.Close() //nolint:errcheck // best effort close
+	stop := context.AfterFunc(ctx, func() {
+		_ = reader.Close() //nolint:errcheck // unblocks the decoder
+	})
+	defer stop()
+
+	decoder := json.NewDecoder(reader)
+	for {
+		var msg pullMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+
+		// Registry errors (e.g., unknown tag) arrive in-band
+		if msg.Error != "" {
+			return fmt.Errorf("%w: %s", ErrPullFailed, msg.Error)
+		}
+
+		if onProgress != nil {
+			onProgress(PullProgress{
+				ID:      msg.ID,
+				Status:  msg.Status,
+				Current: msg.ProgressDetail.Current,
+				Total:   msg.ProgressDetail.Total,
+			})
+		}
+	}
+}
+
+// pullError maps a pull failure to ErrPullTimeout when the deadline elapsed.
+func pullError(ctx context.Context, imageTag string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", ErrPullTimeout, imageTag)
+	}
+	if errors.Is(err, ErrPullFailed) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	return fmt.Errorf("failed to pull image %s: %w", imageTag, err)
+}
@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStreamPullProgress tests parsing of the pull progress stream.
+func TestStreamPullProgress(t *testing.T) {
+	stream := `{"status":"Pulling from library/gcc","id":"13"}
+{"status":"Downloading","progressDetail":{"current":1024,"total":4096},"id":"a1b2c3"}
+{"status":"Pull complete","progressDetail":{},"id":"a1b2c3"}
+`
+
+	var updates []PullProgress
+	err := streamPullProgress(context.Background(), io.NopCloser(strings.NewReader(stream)), func(p PullProgress) {
+		updates = append(updates, p)
+	})
+	require.NoError(t, err)
+	require.Len(t, updates, 3)
+
+	assert.Equal(t, PullProgress{ID: "a1b2c3", Status: "Downloading", Current: 1024, Total: 4096}, updates[1])
+	assert.Equal(t, "Pull complete", updates[2].Status)
+}
+
+// TestStreamPullProgress_Error tests that in-band registry errors fail the pull.
+func TestStreamPullProgress_Error(t *testing.T) {
+	stream := `{"error":"manifest for gcc:99 not found"}` + "\n"
+
+	err := streamPullProgress(context.Background(), io.NopCloser(strings.NewReader(stream)), nil)
+	require.ErrorIs(t, err, ErrPullFailed)
+	assert.Contains(t, err.Error(), "manifest for gcc:99 not found")
+}
+
+// TestStreamPullProgress_Cancel tests that cancelling the context stops a pull that is still streaming.
+func TestStreamPullProgress_Cancel(t *testing.T) {
+	reader, writer := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	writeErr := make(chan error, 1)
+	go func() {
+		_, _ = io.WriteString(writer, `{"status":"Downloading","id":"a1b2c3"}`+"\n") //nolint:errcheck // checked via the second write
+		// The pull is stopped, so nobody reads this
+		_, err := io.WriteString(writer, `{"status":"Pull complete","id":"a1b2c3"}`+"\n")
+		writeErr <- err
+	}()
+
+	var updates int
+	err := streamPullProgress(ctx, reader, func(PullProgress) {
+		updates++
+		cancel()
+	})
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, updates)
+	assert.ErrorIs(t, <-writeErr, io.ErrClosedPipe, "Stream should be closed on cancellation")
+}
+
+// TestPullError tests mapping of pull failures to ErrPullTimeout.
+func TestPullError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+
+	err := pullError(ctx, "gcc:13", context.DeadlineExceeded)
+	require.ErrorIs(t, err, ErrPullTimeout)
+	assert.Contains(t, err.Error(), "gcc:13")
+
+	err = pullError(context.Background(), "gcc:13", io.ErrUnexpectedEOF)
+	assert.NotErrorIs(t, err, ErrPullTimeout)
+}
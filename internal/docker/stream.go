@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"io"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stlpine/will-it-compile/internal/ansi"
+)
+
+// streamOutput returns a reader of the followed, multiplexed container logs r that also passes every
+// chunk of stdout and stderr to onOutput as it is read, sanitized like the collected output. Each
+// stream is passed on up to MaxOutputSize, like the collected output; the rest is only read.
+func streamOutput(r io.Reader, onOutput func(stream, chunk string), preserveANSI bool) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		stdout := &outputForwarder{stream: "stdout", onOutput: onOutput, preserveANSI: preserveANSI}
+		stderr := &outputForwarder{stream: "stderr", onOutput: onOutput, preserveANSI: preserveANSI}
+		_, err := stdcopy.StdCopy(stdout, stderr, pr)
+		pr.CloseWithError(err) // Unblocks the tee if the demultiplexing failed
+	}()
+	return &teeCloser{r: r, w: pw}
+}
+
+// teeCloser is an io.TeeReader that closes w once r ends.
+type teeCloser struct {
+	r io.Reader
+	w *io.PipeWriter
+}
+
+func (t *teeCloser) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		_, _ = t.w.Write(p[:n]) //nolint:errcheck // a failed demultiplexer only stops forwarding
+	}
+	if err != nil {
+		t.w.CloseWithError(err)
+	}
+	return n, err
+}
+
+// outputForwarder passes the chunks written to it to onOutput, up to MaxOutputSize bytes.
+type outputForwarder struct {
+	stream       string
+	onOutput     func(stream, chunk string)
+	preserveANSI bool
+	written      int
+}
+
+func (f *outputForwarder) Write(p []byte) (int, error) {
+	remaining := MaxOutputSize - f.written
+	if remaining <= 0 {
+		return len(p), nil
+	}
+	chunk := p[:min(len(p), remaining)]
+	f.written += len(chunk)
+
+	output := string(chunk)
+	if !f.preserveANSI {
+		output = ansi.Strip(output)
+	}
+	if output != "" {
+		f.onOutput(f.stream, output)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,44 @@
+package docker
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStreamOutput tests that the demultiplexed chunks reach the callback, stripped of escape
+// sequences, while the logs still read through unchanged.
+func TestStreamOutput(t *testing.T) {
+	var logs bytes.Buffer
+	_, err := stdcopy.NewStdWriter(&logs, stdcopy.Stdout).Write([]byte("Compiling source.cpp\n"))
+	require.NoError(t, err)
+	_, err = stdcopy.NewStdWriter(&logs, stdcopy.Stderr).Write([]byte("\x1b[31merror\x1b[0m: expected ';'\n"))
+	require.NoError(t, err)
+	raw := logs.Bytes()
+
+	var mu sync.Mutex
+	var chunks []string
+	done := make(chan struct{})
+	r := streamOutput(bytes.NewReader(raw), func(stream, chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		chunks = append(chunks, stream+": "+chunk)
+		if len(chunks) == 2 {
+			close(done)
+		}
+	}, false)
+
+	read, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, raw, read)
+
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"stdout: Compiling source.cpp\n", "stderr: error: expected ';'\n"}, chunks)
+}
@@ -3,31 +3,72 @@ package docker
 import (
 	"archive/tar"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
+	"path"
+	"slices"
 	"time"
 )
 
-// createSourceTar creates a tar archive containing the source code.
-func createSourceTar(sourceCode, filename string) (io.Reader, error) {
+// ErrNoFileInTar is returned when a tar stream from the container holds no regular file.
+var ErrNoFileInTar = errors.New("no regular file in tar stream")
+
+// createSourceTar creates a tar archive containing the source files, keyed by path relative to the workspace.
+// Parent directories of nested paths are added so they are created in the container.
+func createSourceTar(files map[string]string) (io.Reader, error) {
 	buf := new(bytes.Buffer)
 	tw := tar.NewWriter(buf)
+	now := time.Now()
 
-	// Create tar header
-	header := &tar.Header{
-		Name:    filename,
-		Mode:    0o644,
-		Size:    int64(len(sourceCode)),
-		ModTime: time.Now(),
+	// Sort for a deterministic archive
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
 	}
+	slices.Sort(names)
 
-	// Write header
-	if err := tw.WriteHeader(header); err != nil {
-		return nil, err
-	}
+	dirs := make(map[string]bool)
+	for _, name := range names {
+		// Write headers for parent directories not yet in the archive
+		var parents []string
+		for dir := path.Dir(name); dir != "." && dir != "/" && !dirs[dir]; dir = path.Dir(dir) {
+			parents = append(parents, dir)
+			dirs[dir] = true
+		}
+		slices.Reverse(parents)
+		for _, dir := range parents {
+			if err := tw.WriteHeader(&tar.Header{
+				Name:     dir + "/",
+				Typeflag: tar.TypeDir,
+				Mode:     0o755,
+				ModTime:  now,
+			}); err != nil {
+				return nil, err
+			}
+		}
 
-	// Write content
-	if _, err := tw.Write([]byte(sourceCode)); err != nil {
-		return nil, err
+		content := files[name]
+
+		// Create tar header
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}
+
+		// Write header
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+
+		// Write content
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
 	}
 
 	// Close tar writer
@@ -37,3 +78,50 @@ func createSourceTar(sourceCode, filename string) (io.Reader, error) {
 
 	return buf, nil
 }
+
+// sha256FromTar returns the hex-encoded SHA-256 of the first regular file in a tar stream,
+// as returned by CopyFromContainer for a single file.
+func sha256FromTar(r io.Reader) (string, error) {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return "", ErrNoFileInTar
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read tar stream: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		hash := sha256.New()
+		if _, err := io.Copy(hash, tr); err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+}
+
+// readFromTar returns up to limit bytes of the first regular file in a tar stream, and whether it was cut off.
+func readFromTar(r io.Reader, limit int64) (string, bool, error) {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return "", false, ErrNoFileInTar
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read tar stream: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tr, limit))
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		return string(content), header.Size > limit, nil
+	}
+}
@@ -0,0 +1,59 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSha256FromTar tests hashing the file of a CopyFromContainer tar stream.
+func TestSha256FromTar(t *testing.T) {
+	content := []byte("\x7fELF binary")
+	sum := sha256.Sum256(content)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "output", Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	hash, err := sha256FromTar(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sum[:]), hash)
+
+	var empty bytes.Buffer
+	require.NoError(t, tar.NewWriter(&empty).Close())
+	_, err = sha256FromTar(&empty)
+	assert.ErrorIs(t, err, ErrNoFileInTar)
+}
+
+// TestReadFromTar tests reading the file of a CopyFromContainer tar stream, capped at a limit.
+func TestReadFromTar(t *testing.T) {
+	content := "# 1 \"/workspace/source.c\"\nint main(void) { return 0; }\n"
+
+	newTar := func() *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "output.i", Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		return &buf
+	}
+
+	read, truncated, err := readFromTar(newTar(), 1024)
+	require.NoError(t, err)
+	assert.Equal(t, content, read)
+	assert.False(t, truncated)
+
+	read, truncated, err = readFromTar(newTar(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, content[:10], read)
+	assert.True(t, truncated)
+}
@@ -2,16 +2,40 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/stlpine/will-it-compile/internal/docker"
 	"github.com/stlpine/will-it-compile/pkg/runtime"
 )
 
+// Sentinel errors for Docker runtime.
+var (
+	ErrImageArchMismatch = errors.New("image architecture does not match the host")
+)
+
+const (
+	// pingInterval is how often the Docker daemon's latency is probed.
+	pingInterval = 10 * time.Second
+
+	// pingTimeout bounds a single probe; a daemon slower than this is reported as unreachable.
+	pingTimeout = 5 * time.Second
+)
+
 // DockerRuntime implements CompilationRuntime using Docker
 // This is used for local development and single-server deployments.
 type DockerRuntime struct {
 	client docker.DockerClient
+
+	pingLatency atomic.Int64 // Latest probe duration in nanoseconds, -1 if unknown
+	stopProbe   chan struct{}
+	probeDone   chan struct{}
+	closeOnce   sync.Once
 }
 
 // NewDockerRuntime creates a new Docker-based compilation runtime.
@@ -21,21 +45,88 @@ func NewDockerRuntime() (*DockerRuntime, error) {
 		return nil, fmt.Errorf("failed to create docker client: %w", err)
 	}
 
-	return &DockerRuntime{
-		client: client,
-	}, nil
+	return newDockerRuntime(client), nil
+}
+
+// NewDockerRuntimeWithClient creates a Docker runtime with a custom client (useful for testing).
+func NewDockerRuntimeWithClient(client docker.DockerClient) *DockerRuntime {
+	return newDockerRuntime(client)
+}
+
+// newDockerRuntime creates the runtime and starts its background latency prober.
+func newDockerRuntime(client docker.DockerClient) *DockerRuntime {
+	d := &DockerRuntime{
+		client:    client,
+		stopProbe: make(chan struct{}),
+		probeDone: make(chan struct{}),
+	}
+	d.pingLatency.Store(-1)
+
+	go d.probeLatency(pingInterval)
+
+	return d
+}
+
+// probeLatency times a Docker ping immediately and then every interval until Close.
+func (d *DockerRuntime) probeLatency(interval time.Duration) {
+	defer close(d.probeDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d.ping()
+
+		select {
+		case <-d.stopProbe:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ping times a single Docker ping and records the result.
+func (d *DockerRuntime) ping() {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := d.client.Ping(ctx); err != nil {
+		d.pingLatency.Store(-1)
+		return
+	}
+	d.pingLatency.Store(int64(time.Since(start)))
+}
+
+// PingLatency returns the duration of the latest Docker ping, or false if it failed or has not run yet.
+func (d *DockerRuntime) PingLatency() (time.Duration, bool) {
+	latency := d.pingLatency.Load()
+	if latency < 0 {
+		return 0, false
+	}
+	return time.Duration(latency), true
 }
 
 // Compile runs compilation using Docker containers.
 func (d *DockerRuntime) Compile(ctx context.Context, config runtime.CompilationConfig) (*runtime.CompilationOutput, error) {
 	// Convert runtime.CompilationConfig to docker.CompilationConfig
 	dockerConfig := docker.CompilationConfig{
-		ImageTag:       config.ImageTag,
-		SourceCode:     config.SourceCode,
-		SourceFilename: config.SourceFilename,
-		WorkDir:        config.WorkDir,
-		Env:            config.Env,
-		CompileCommand: config.CompileCommand,
+		ImageTag:        config.ImageTag,
+		SourceCode:      config.SourceCode,
+		SourceFilename:  config.SourceFilename,
+		WorkDir:         config.WorkDir,
+		Env:             config.Env,
+		CompileCommand:  runtime.WithRunStep(config), // Appends the run step in run mode
+		CompileScript:   config.CompileScript,
+		OutputPath:      config.OutputPath,
+		Files:           config.Files,
+		ArtifactPath:    config.ArtifactPath,
+		MaxArtifactSize: runtime.MaxArtifactSize,
+		TmpSize:         config.TmpSizeOrDefault(),
+		IdleTimeout:     config.IdleTimeout,
+		CPUShares:       config.CPUShares,
+		PreserveANSI:    config.PreserveANSI,
+		OnOutput:        config.OnOutput,
 	}
 
 	// Apply timeout if specified
@@ -48,17 +139,52 @@ func (d *DockerRuntime) Compile(ctx context.Context, config runtime.CompilationC
 	// Run compilation using Docker
 	output, err := d.client.RunCompilation(ctx, dockerConfig)
 	if err != nil {
+		if isExecFormatError(err.Error()) {
+			return nil, archMismatchError(config.ImageTag)
+		}
 		return nil, fmt.Errorf("docker compilation failed: %w", err)
 	}
 
 	// Convert docker.CompilationOutput to runtime.CompilationOutput
-	return &runtime.CompilationOutput{
-		Stdout:   output.Stdout,
-		Stderr:   output.Stderr,
-		ExitCode: output.ExitCode,
-		Duration: output.Duration,
-		TimedOut: output.TimedOut,
-	}, nil
+	result := &runtime.CompilationOutput{
+		Stdout:      output.Stdout,
+		Stderr:      output.Stderr,
+		ExitCode:    output.ExitCode,
+		Duration:    output.Duration,
+		TimedOut:    output.TimedOut,
+		BinaryBytes: output.BinaryBytes,
+
+		IdleTimedOut: output.IdleTimedOut,
+
+		TerminatedBySignal: output.TerminatedBySignal,
+		BinarySHA256:       output.BinarySHA256,
+
+		Artifact:          output.Artifact,
+		ArtifactTruncated: output.ArtifactTruncated,
+
+		NetworkEnabled: output.NetworkEnabled,
+	}
+
+	// Separate program output from compiler output (no-op unless the program ran)
+	runtime.SplitRunOutput(result, config.MaxRunOutputSize)
+
+	// The container started but its shell could not be executed (e.g., amd64-only image on arm64)
+	if !result.Ran && result.ExitCode != 0 && isExecFormatError(result.Stderr) {
+		return nil, archMismatchError(config.ImageTag)
+	}
+
+	return result, nil
+}
+
+// isExecFormatError reports whether a message is the kernel's ENOEXEC for a foreign-architecture binary.
+func isExecFormatError(msg string) bool {
+	return strings.Contains(msg, "exec format error")
+}
+
+// archMismatchError explains an exec format error in terms of the image and host architecture.
+func archMismatchError(imageTag string) error {
+	return fmt.Errorf("%w: %s cannot run on this %s/%s host; use an image built for this architecture (e.g., docker pull --platform %s/%s %s)",
+		ErrImageArchMismatch, imageTag, goruntime.GOOS, goruntime.GOARCH, goruntime.GOOS, goruntime.GOARCH, imageTag)
 }
 
 // ImageExists checks if a Docker image exists locally.
@@ -66,10 +192,17 @@ func (d *DockerRuntime) ImageExists(ctx context.Context, imageTag string) (bool,
 	return d.client.ImageExists(ctx, imageTag)
 }
 
-// Close cleans up Docker client resources.
+// Close stops the latency prober and cleans up Docker client resources.
 func (d *DockerRuntime) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.stopProbe)
+		<-d.probeDone
+	})
 	return d.client.Close()
 }
 
-// Ensure DockerRuntime implements CompilationRuntime.
-var _ runtime.CompilationRuntime = (*DockerRuntime)(nil)
+// Ensure DockerRuntime implements CompilationRuntime and LatencyReporter.
+var (
+	_ runtime.CompilationRuntime = (*DockerRuntime)(nil)
+	_ runtime.LatencyReporter    = (*DockerRuntime)(nil)
+)
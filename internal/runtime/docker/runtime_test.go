@@ -0,0 +1,164 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stlpine/will-it-compile/internal/docker"
+	"github.com/stlpine/will-it-compile/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompile_ExecFormatError tests that an image built for another architecture yields a clear error.
+func TestCompile_ExecFormatError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		output *docker.CompilationOutput
+		err    error
+	}{
+		{
+			name: "start_error",
+			err:  errors.New(`failed to start container: exec /bin/sh: exec format error`),
+		},
+		{
+			name:   "exit_error",
+			output: &docker.CompilationOutput{ExitCode: 255, Stderr: "exec /bin/sh: exec format error\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &docker.MockDockerClient{
+				RunCompilationFunc: func(ctx context.Context, config docker.CompilationConfig) (*docker.CompilationOutput, error) {
+					return tc.output, tc.err
+				},
+			}
+			rt := NewDockerRuntimeWithClient(client)
+
+			_, err := rt.Compile(context.Background(), runtime.CompilationConfig{ImageTag: "gcc:13"})
+
+			require.ErrorIs(t, err, ErrImageArchMismatch)
+			assert.Contains(t, err.Error(), "gcc:13")
+			assert.Contains(t, err.Error(), "--platform")
+		})
+	}
+}
+
+// TestCompile_CompileErrorIsNotArchMismatch tests that ordinary compile failures are passed through.
+func TestCompile_CompileErrorIsNotArchMismatch(t *testing.T) {
+	client := &docker.MockDockerClient{
+		RunCompilationFunc: func(ctx context.Context, config docker.CompilationConfig) (*docker.CompilationOutput, error) {
+			return &docker.CompilationOutput{ExitCode: 1, Stderr: "source.cpp:1:1: error: expected ';'"}, nil
+		},
+	}
+	rt := NewDockerRuntimeWithClient(client)
+
+	output, err := rt.Compile(context.Background(), runtime.CompilationConfig{ImageTag: "gcc:13"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, output.ExitCode)
+}
+
+// TestPingLatency tests that the prober measures the daemon's ping latency and reports failures as unknown.
+func TestPingLatency(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var pingDelay atomic.Int64
+		var pingFails atomic.Bool
+		pingDelay.Store(int64(40 * time.Millisecond))
+
+		client := &docker.MockDockerClient{
+			PingFunc: func(ctx context.Context) error {
+				time.Sleep(time.Duration(pingDelay.Load()))
+				if pingFails.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+		}
+		rt := NewDockerRuntimeWithClient(client)
+		defer rt.Close() //nolint:errcheck // test cleanup
+
+		// The first probe runs immediately
+		time.Sleep(40 * time.Millisecond)
+		synctest.Wait()
+		latency, ok := rt.PingLatency()
+		require.True(t, ok)
+		assert.Equal(t, 40*time.Millisecond, latency)
+
+		// A slower daemon is picked up on the next probe
+		pingDelay.Store(int64(250 * time.Millisecond))
+		time.Sleep(pingInterval + 250*time.Millisecond)
+		synctest.Wait()
+		latency, ok = rt.PingLatency()
+		require.True(t, ok)
+		assert.Equal(t, 250*time.Millisecond, latency)
+
+		// An unreachable daemon has no latency
+		pingFails.Store(true)
+		time.Sleep(pingInterval)
+		synctest.Wait()
+		_, ok = rt.PingLatency()
+		assert.False(t, ok)
+	})
+}
+
+// TestCompile_NetworkEnabled tests that the client's network setting is passed through.
+func TestCompile_NetworkEnabled(t *testing.T) {
+	client := &docker.MockDockerClient{
+		RunCompilationFunc: func(ctx context.Context, config docker.CompilationConfig) (*docker.CompilationOutput, error) {
+			return &docker.CompilationOutput{NetworkEnabled: true}, nil
+		},
+	}
+	rt := NewDockerRuntimeWithClient(client)
+
+	output, err := rt.Compile(context.Background(), runtime.CompilationConfig{ImageTag: "gcc:13"})
+
+	require.NoError(t, err)
+	assert.True(t, output.NetworkEnabled)
+}
+
+// TestCompile_CPUShares tests that the configured CPU weight reaches the Docker client.
+func TestCompile_CPUShares(t *testing.T) {
+	var capturedConfig docker.CompilationConfig
+	client := &docker.MockDockerClient{
+		RunCompilationFunc: func(ctx context.Context, config docker.CompilationConfig) (*docker.CompilationOutput, error) {
+			capturedConfig = config
+			return &docker.CompilationOutput{}, nil
+		},
+	}
+	rt := NewDockerRuntimeWithClient(client)
+
+	_, err := rt.Compile(context.Background(), runtime.CompilationConfig{ImageTag: "gcc:13", CPUShares: 256})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(256), capturedConfig.CPUShares)
+}
+
+// TestCompile_Timeout tests that the compile timeout, even above the Docker client's own 30s default,
+// is the deadline the container is waited for.
+func TestCompile_Timeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+		var waited time.Duration
+		client := &docker.MockDockerClient{
+			RunCompilationFunc: func(ctx context.Context, config docker.CompilationConfig) (*docker.CompilationOutput, error) {
+				<-ctx.Done()
+				waited = time.Since(start)
+				return &docker.CompilationOutput{TimedOut: true}, nil
+			},
+		}
+		rt := NewDockerRuntimeWithClient(client)
+		defer rt.Close() //nolint:errcheck // test cleanup
+
+		output, err := rt.Compile(context.Background(), runtime.CompilationConfig{ImageTag: "rust:1.75", Timeout: 90 * time.Second})
+
+		require.NoError(t, err)
+		assert.True(t, output.TimedOut)
+		assert.Equal(t, 90*time.Second, waited)
+	})
+}
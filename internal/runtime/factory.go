@@ -70,6 +70,14 @@ func NewRuntimeAuto(namespace string) (pkgruntime.CompilationRuntime, error) {
 	return dockerruntime.NewDockerRuntime()
 }
 
+// RuntimeTypeFromEnv returns the runtime selected by the RUNTIME environment variable, or auto-detection if unset.
+func RuntimeTypeFromEnv() RuntimeType {
+	if runtimeType := os.Getenv("RUNTIME"); runtimeType != "" {
+		return RuntimeType(runtimeType)
+	}
+	return RuntimeTypeAuto
+}
+
 // GetRuntimeType returns the runtime type that would be selected by auto-detection.
 func GetRuntimeType() RuntimeType {
 	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
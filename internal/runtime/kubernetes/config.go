@@ -0,0 +1,118 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+)
+
+// Sentinel errors for cluster configuration.
+var (
+	ErrNoClusterConfig   = errors.New("no Kubernetes cluster configuration found")
+	ErrInvalidKubeconfig = errors.New("invalid kubeconfig")
+)
+
+// loadClusterConfig returns the in-cluster config when running in a pod, and otherwise
+// the current context of the kubeconfig, so developers can target a remote cluster.
+func loadClusterConfig() (*rest.Config, error) {
+	config, inClusterErr := rest.InClusterConfig()
+	if inClusterErr == nil {
+		return config, nil
+	}
+
+	home, _ := os.UserHomeDir() //nolint:errcheck // no home means no default kubeconfig
+	path := selectKubeconfig(os.Getenv("KUBECONFIG"), home, fileExists)
+	if path == "" {
+		return nil, fmt.Errorf("%w: not running inside Kubernetes (%v) and no kubeconfig was found via KUBECONFIG or ~/.kube/config; "+
+			"set RUNTIME=docker to use the local Docker daemon instead", ErrNoClusterConfig, inClusterErr)
+	}
+
+	return loadKubeconfig(path)
+}
+
+// selectKubeconfig picks the kubeconfig to use: the first existing file listed in KUBECONFIG,
+// or ~/.kube/config when KUBECONFIG is unset. It returns "" when there is none.
+func selectKubeconfig(kubeconfigEnv, home string, exists func(string) bool) string {
+	if kubeconfigEnv != "" {
+		for _, path := range filepath.SplitList(kubeconfigEnv) {
+			if path != "" && exists(path) {
+				return path
+			}
+		}
+		return ""
+	}
+
+	if home == "" {
+		return ""
+	}
+	if path := filepath.Join(home, ".kube", "config"); exists(path) {
+		return path
+	}
+	return ""
+}
+
+// fileExists reports whether path is an existing regular file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// loadKubeconfig builds a client config from the current context of a kubeconfig file.
+// It is a subset of clientcmd (which would pull in extra dependencies): tokens, client
+// certificates, basic auth and exec credential plugins are supported; auth providers are not.
+func loadKubeconfig(path string) (*rest.Config, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path comes from KUBECONFIG or the user's home
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	decoded, _, err := clientcmdlatest.Codec.Decode(data, &schema.GroupVersionKind{Version: clientcmdlatest.Version, Kind: "Config"}, clientcmdapi.NewConfig())
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrInvalidKubeconfig, path, err)
+	}
+	kubeconfig, ok := decoded.(*clientcmdapi.Config)
+	if !ok {
+		return nil, fmt.Errorf("%w %s: unexpected object", ErrInvalidKubeconfig, path)
+	}
+
+	kubeContext, ok := kubeconfig.Contexts[kubeconfig.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("%w %s: current context %q not found", ErrInvalidKubeconfig, path, kubeconfig.CurrentContext)
+	}
+	cluster, ok := kubeconfig.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("%w %s: cluster %q not found", ErrInvalidKubeconfig, path, kubeContext.Cluster)
+	}
+	user, ok := kubeconfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		user = clientcmdapi.NewAuthInfo() // Anonymous
+	}
+	if user.AuthProvider != nil {
+		return nil, fmt.Errorf("%w %s: auth provider %q is not supported, use an exec plugin", ErrInvalidKubeconfig, path, user.AuthProvider.Name)
+	}
+
+	return &rest.Config{
+		Host:            cluster.Server,
+		BearerToken:     user.Token,
+		BearerTokenFile: user.TokenFile,
+		Username:        user.Username,
+		Password:        user.Password,
+		ExecProvider:    user.Exec,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure:   cluster.InsecureSkipTLSVerify,
+			ServerName: cluster.TLSServerName,
+			CAFile:     cluster.CertificateAuthority,
+			CAData:     cluster.CertificateAuthorityData,
+			CertFile:   user.ClientCertificate,
+			CertData:   user.ClientCertificateData,
+			KeyFile:    user.ClientKey,
+			KeyData:    user.ClientKeyData,
+		},
+	}, nil
+}
@@ -0,0 +1,128 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSelectKubeconfig tests which kubeconfig is used outside a cluster.
+func TestSelectKubeconfig(t *testing.T) {
+	existing := map[string]bool{
+		"/etc/kube/dev.yaml":       true,
+		"/home/dev/.kube/config":   true,
+		"/home/other/.kube/config": false,
+	}
+	exists := func(path string) bool { return existing[path] }
+
+	testCases := []struct {
+		name     string
+		env      string
+		home     string
+		expected string
+	}{
+		{"kubeconfig_env", "/etc/kube/dev.yaml", "/home/dev", "/etc/kube/dev.yaml"},
+		{"first_existing_in_list", "/etc/kube/missing.yaml" + string(filepath.ListSeparator) + "/etc/kube/dev.yaml", "/home/dev", "/etc/kube/dev.yaml"},
+		{"env_without_existing_file", "/etc/kube/missing.yaml", "/home/dev", ""},
+		{"home_default", "", "/home/dev", "/home/dev/.kube/config"},
+		{"no_home_default", "", "/home/other", ""},
+		{"no_home", "", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, selectKubeconfig(tc.env, tc.home, exists))
+		})
+	}
+}
+
+// TestLoadClusterConfig_NoConfig tests that the error outside a cluster without a kubeconfig suggests Docker.
+func TestLoadClusterConfig_NoConfig(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	_, err := loadClusterConfig()
+	require.ErrorIs(t, err, ErrNoClusterConfig)
+	assert.Contains(t, err.Error(), "RUNTIME=docker")
+}
+
+// TestLoadKubeconfig tests that the current context of a kubeconfig is turned into a client config.
+func TestLoadKubeconfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+current-context: dev
+contexts:
+- name: dev
+  context:
+    cluster: remote
+    user: developer
+- name: prod
+  context:
+    cluster: other
+    user: admin
+clusters:
+- name: remote
+  cluster:
+    server: https://k8s.example.com:6443
+    certificate-authority-data: Y2EtZGF0YQ==
+- name: other
+  cluster:
+    server: https://prod.example.com
+users:
+- name: developer
+  user:
+    token: dev-token
+- name: admin
+  user:
+    token: admin-token
+`), 0o600))
+
+	config, err := loadKubeconfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://k8s.example.com:6443", config.Host)
+	assert.Equal(t, "dev-token", config.BearerToken)
+	assert.Equal(t, []byte("ca-data"), config.CAData)
+}
+
+// TestLoadKubeconfig_Invalid tests that unusable kubeconfigs are rejected.
+func TestLoadKubeconfig_Invalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"malformed", "clusters: [:"},
+		{"missing_context", "apiVersion: v1\nkind: Config\ncurrent-context: dev\n"},
+		{
+			name: "auth_provider",
+			content: `apiVersion: v1
+kind: Config
+current-context: dev
+contexts:
+- name: dev
+  context: {cluster: remote, user: developer}
+clusters:
+- name: remote
+  cluster: {server: "https://k8s.example.com"}
+users:
+- name: developer
+  user:
+    auth-provider: {name: gcp}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			_, err := loadKubeconfig(path)
+			assert.ErrorIs(t, err, ErrInvalidKubeconfig)
+		})
+	}
+}
@@ -5,16 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/stlpine/will-it-compile/pkg/runtime"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 // Sentinel errors for Kubernetes runtime.
@@ -44,11 +46,11 @@ type KubernetesRuntime struct {
 }
 
 // NewKubernetesRuntime creates a new Kubernetes-based compilation runtime.
+// It uses the in-cluster config inside a pod, and the kubeconfig (KUBECONFIG or ~/.kube/config) outside.
 func NewKubernetesRuntime(namespace string) (*KubernetesRuntime, error) {
-	// Use in-cluster config (when running inside K8s)
-	config, err := rest.InClusterConfig()
+	config, err := loadClusterConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get in-cluster config: %w (are you running inside Kubernetes?)", err)
+		return nil, err
 	}
 
 	// Create Kubernetes clientset
@@ -105,10 +107,45 @@ func (k *KubernetesRuntime) Compile(ctx context.Context, config runtime.Compilat
 
 	output.Duration = time.Since(startTime)
 	output.TimedOut = timedOut
+	output.NetworkEnabled = !k.egressIsolated(ctx, job.Spec.Template.Labels)
+
+	// Separate program output from compiler output (no-op unless the program ran)
+	runtime.SplitRunOutput(output, config.MaxRunOutputSize)
 
 	return output, nil
 }
 
+// egressIsolated reports whether the NetworkPolicies of the namespace deny all egress to pods with the
+// given labels: at least one selects them with an Egress policy type, and none of those allows any egress.
+// Nothing in the pod spec can take the cluster network away, so without such a policy the pod has it.
+// Policies that cannot be listed count as no isolation, rather than claiming one that may not exist.
+func (k *KubernetesRuntime) egressIsolated(ctx context.Context, podLabels map[string]string) bool {
+	policies, err := k.clientset.NetworkingV1().NetworkPolicies(k.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false
+	}
+	return egressIsolatedBy(policies.Items, podLabels)
+}
+
+// egressIsolatedBy is egressIsolated for a list of policies.
+func egressIsolatedBy(policies []networkingv1.NetworkPolicy, podLabels map[string]string) bool {
+	isolated := false
+	for _, policy := range policies {
+		if !slices.Contains(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress) {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil || !selector.Matches(labels.Set(podLabels)) {
+			continue
+		}
+		if len(policy.Spec.Egress) > 0 {
+			return false // Policies are additive: any allowed egress reaches the pod
+		}
+		isolated = true
+	}
+	return isolated
+}
+
 // ImageExists checks if a container image exists
 // Note: In K8s, we rely on image pull policy and let K8s handle image verification.
 func (k *KubernetesRuntime) ImageExists(ctx context.Context, imageTag string) (bool, error) {
@@ -140,9 +177,7 @@ func (k *KubernetesRuntime) createSourceConfigMap(ctx context.Context, config ru
 				"managed-by": "will-it-compile",
 			},
 		},
-		Data: map[string]string{
-			k.getSourceFilename(config): config.SourceCode,
-		},
+		Data: k.sourceData(config),
 	}
 
 	_, err := k.clientset.CoreV1().ConfigMaps(k.namespace).Create(ctx, configMap, metav1.CreateOptions{})
@@ -194,7 +229,7 @@ func (k *KubernetesRuntime) createCompilationJob(ctx context.Context, config run
 							// Use shell to run the compile command (same as Docker runtime)
 							// Copy source from read-only ConfigMap to writable /tmp, then compile
 							Command: []string{"/bin/sh", "-c", k.buildCompileScript(config)},
-							Env:     k.convertEnv(config.Env),
+							Env:     k.convertEnv(k.compileEnv(config)),
 							Resources: corev1.ResourceRequirements{
 								Limits: corev1.ResourceList{
 									corev1.ResourceCPU:    resource.MustParse(MaxCPU),
@@ -235,6 +270,7 @@ func (k *KubernetesRuntime) createCompilationJob(ctx context.Context, config run
 									LocalObjectReference: corev1.LocalObjectReference{
 										Name: "source-" + config.JobID,
 									},
+									Items: k.sourceItems(config),
 								},
 							},
 						},
@@ -243,7 +279,7 @@ func (k *KubernetesRuntime) createCompilationJob(ctx context.Context, config run
 							VolumeSource: corev1.VolumeSource{
 								EmptyDir: &corev1.EmptyDirVolumeSource{
 									Medium:    corev1.StorageMediumMemory,
-									SizeLimit: resource.NewQuantity(64*1024*1024, resource.BinarySI),
+									SizeLimit: resource.NewQuantity(config.TmpSizeOrDefault(), resource.BinarySI),
 								},
 							},
 						},
@@ -280,8 +316,9 @@ func (k *KubernetesRuntime) waitForJobCompletion(ctx context.Context, jobName st
 				// Channel closed, check if it was due to timeout
 				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 					return &runtime.CompilationOutput{
-						Stderr:   "Compilation timeout",
-						ExitCode: 137, // SIGKILL
+						Stderr:             "Compilation timeout",
+						ExitCode:           137, // SIGKILL
+						TerminatedBySignal: "SIGKILL",
 					}, true, nil
 				}
 				return nil, false, ErrWatchChannelClosed
@@ -317,8 +354,9 @@ func (k *KubernetesRuntime) waitForJobCompletion(ctx context.Context, jobName st
 		case <-ctx.Done():
 			// Timeout occurred
 			return &runtime.CompilationOutput{
-				Stderr:   "Compilation timeout",
-				ExitCode: 137,
+				Stderr:             "Compilation timeout",
+				ExitCode:           137,
+				TerminatedBySignal: "SIGKILL",
 			}, true, nil
 		}
 	}
@@ -336,11 +374,13 @@ func (k *KubernetesRuntime) getJobOutput(ctx context.Context, jobName string) (*
 
 	pod := pods.Items[0]
 
-	// Get container exit code
+	// Get container exit code, and the signal if the container was killed
 	exitCode := 0
+	terminatedBySignal := ""
 	if len(pod.Status.ContainerStatuses) > 0 {
 		if terminated := pod.Status.ContainerStatuses[0].State.Terminated; terminated != nil {
 			exitCode = int(terminated.ExitCode)
+			terminatedBySignal = terminationSignal(terminated)
 		}
 	}
 
@@ -371,12 +411,40 @@ func (k *KubernetesRuntime) getJobOutput(ctx context.Context, jobName string) (*
 
 	// Split stdout/stderr (if needed, for now we treat all as stdout)
 	return &runtime.CompilationOutput{
-		Stdout:   output,
-		Stderr:   "",
-		ExitCode: exitCode,
+		Stdout:             output,
+		Stderr:             "",
+		ExitCode:           exitCode,
+		TerminatedBySignal: terminatedBySignal,
 	}, nil
 }
 
+// terminationSignal names the signal that killed a container, or returns "" if it exited on its own.
+// The kernel OOM killer is reported as a reason rather than a signal.
+func terminationSignal(terminated *corev1.ContainerStateTerminated) string {
+	if terminated.Reason == "OOMKilled" {
+		return "SIGKILL"
+	}
+	if terminated.Signal == 0 {
+		return ""
+	}
+	if name, ok := signalNames[terminated.Signal]; ok {
+		return name
+	}
+	return fmt.Sprintf("signal %d", terminated.Signal)
+}
+
+// signalNames maps the signals a compiler is commonly killed by to their names.
+var signalNames = map[int32]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	6:  "SIGABRT",
+	9:  "SIGKILL",
+	11: "SIGSEGV",
+	15: "SIGTERM",
+	24: "SIGXCPU",
+	25: "SIGXFSZ",
+}
+
 // cleanup removes the ConfigMap and Job resources.
 func (k *KubernetesRuntime) cleanup(ctx context.Context, jobID string) {
 	deletePolicy := metav1.DeletePropagationForeground
@@ -415,31 +483,92 @@ func (k *KubernetesRuntime) getSourceFilename(config runtime.CompilationConfig)
 	return "source.cpp"
 }
 
+// sourceData returns the ConfigMap data for the source files.
+// ConfigMap keys cannot contain "/", so multi-file submissions use generated keys
+// that sourceItems maps back to their paths.
+func (k *KubernetesRuntime) sourceData(config runtime.CompilationConfig) map[string]string {
+	if len(config.Files) == 0 {
+		return map[string]string{k.getSourceFilename(config): config.SourceCode}
+	}
+
+	data := make(map[string]string, len(config.Files))
+	for i, path := range sortedPaths(config.Files) {
+		data[fmt.Sprintf("file-%d", i)] = config.Files[path]
+	}
+	return data
+}
+
+// sourceItems maps the generated ConfigMap keys of multi-file submissions to their workspace paths.
+// Single-file submissions need no mapping (nil projects every key as-is).
+func (k *KubernetesRuntime) sourceItems(config runtime.CompilationConfig) []corev1.KeyToPath {
+	if len(config.Files) == 0 {
+		return nil
+	}
+
+	items := make([]corev1.KeyToPath, 0, len(config.Files))
+	for i, path := range sortedPaths(config.Files) {
+		items = append(items, corev1.KeyToPath{Key: fmt.Sprintf("file-%d", i), Path: path})
+	}
+	return items
+}
+
+// sortedPaths returns the file paths in a stable order, so keys match between data and items.
+func sortedPaths(files map[string]string) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
 // buildCompileScript creates a shell script that:
 // 1. Copies source from read-only /source to writable /tmp/workspace
-// 2. Runs the compile command with /workspace paths rewritten to /tmp/workspace
+// 2. Runs the compile command with /workspace paths rewritten to /tmp/workspace, or the image's compile script
 //
 // The compile command from compiler.go uses /workspace paths, but in Kubernetes
 // we use /tmp/workspace as the writable workspace (source is mounted read-only).
 func (k *KubernetesRuntime) buildCompileScript(config runtime.CompilationConfig) string {
 	sourceFilename := k.getSourceFilename(config)
 
-	// Rewrite /workspace paths to /tmp/workspace for Kubernetes environment
-	// The source ConfigMap is mounted read-only at /source, so we copy to /tmp/workspace
-	compileCmd := strings.ReplaceAll(config.CompileCommand, "/workspace/", "/tmp/workspace/")
+	// The image's compile script runs the command itself, from the workspace
+	compileCmd := k.compileCommand(config)
+	if config.CompileScript != "" {
+		compileCmd = "cd /tmp/workspace && " + config.CompileScript
+	}
 
 	// Build the script:
 	// - Create /tmp/workspace as writable workspace
 	// - Copy source file from read-only /source to /tmp/workspace
-	// - Run the compile command (with paths rewritten)
+	// - Run the compile command, plus the run step in run mode (with paths rewritten)
 	script := fmt.Sprintf("mkdir -p /tmp/workspace && cp /source/%s /tmp/workspace/ && %s",
 		sourceFilename,
 		compileCmd,
 	)
 
+	// Multi-file submissions copy the whole tree; -L resolves the ConfigMap's symlinks
+	if len(config.Files) > 0 {
+		script = fmt.Sprintf("mkdir -p /tmp/workspace && cp -RL /source/. /tmp/workspace/ && %s", compileCmd)
+	}
+
 	return script
 }
 
+// compileCommand returns the compile command, plus the run step in run mode, with /workspace paths
+// rewritten to /tmp/workspace: the source ConfigMap is mounted read-only at /source, so the
+// sources are copied to /tmp/workspace.
+func (k *KubernetesRuntime) compileCommand(config runtime.CompilationConfig) string {
+	return strings.ReplaceAll(runtime.WithRunStep(config), "/workspace/", "/tmp/workspace/")
+}
+
+// compileEnv returns the container environment, with the compile command for the image's compile script.
+func (k *KubernetesRuntime) compileEnv(config runtime.CompilationConfig) []string {
+	if config.CompileScript == "" {
+		return config.Env
+	}
+	return append(slices.Clone(config.Env), runtime.CompileCommandEnv+"="+k.compileCommand(config))
+}
+
 // ptr is a helper to get pointer to a value.
 func ptr[T any](v T) *T {
 	return &v
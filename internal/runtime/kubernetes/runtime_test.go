@@ -0,0 +1,45 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestEgressIsolatedBy tests which NetworkPolicies take the network away from compile pods.
+func TestEgressIsolatedBy(t *testing.T) {
+	podLabels := map[string]string{"app": "will-it-compile", "component": "compiler", "job-id": "abc"}
+	policy := func(selector map[string]string, types []networkingv1.PolicyType, egress ...networkingv1.NetworkPolicyEgressRule) networkingv1.NetworkPolicy {
+		return networkingv1.NetworkPolicy{Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: selector},
+			PolicyTypes: types,
+			Egress:      egress,
+		}}
+	}
+	egressOnly := []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+	compilers := map[string]string{"component": "compiler"}
+
+	testCases := []struct {
+		name     string
+		policies []networkingv1.NetworkPolicy
+		expected bool
+	}{
+		{"no_policies", nil, false},
+		{"deny_all_egress", []networkingv1.NetworkPolicy{policy(compilers, egressOnly)}, true},
+		{"all_pods", []networkingv1.NetworkPolicy{policy(nil, egressOnly)}, true},
+		{"other_pods", []networkingv1.NetworkPolicy{policy(map[string]string{"component": "api"}, egressOnly)}, false},
+		{"ingress_only", []networkingv1.NetworkPolicy{policy(compilers, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress})}, false},
+		{"another_policy_allows_egress", []networkingv1.NetworkPolicy{
+			policy(compilers, egressOnly),
+			policy(compilers, egressOnly, networkingv1.NetworkPolicyEgressRule{}),
+		}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, egressIsolatedBy(tc.policies, podLabels))
+		})
+	}
+}
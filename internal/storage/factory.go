@@ -19,10 +19,16 @@ func NewJobStore(cfg *config.Config) (JobStore, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create Redis store: %w", err)
 		}
+		store.SetMaxStoredOutputBytes(cfg.Storage.MaxStoredOutputBytes)
+		store.SetResultTTLs(cfg.Storage.ResultTTLByStatus)
 		log.Printf("Redis job store initialized successfully (TTL: %s)", cfg.Redis.JobTTL)
 		return store, nil
 	}
 
 	log.Println("Using in-memory job store (not suitable for production)")
-	return memory.NewStore(), nil
+	store := memory.NewStore()
+	store.SetMaxStoredOutputBytes(cfg.Storage.MaxStoredOutputBytes)
+	store.SetResultTTLs(cfg.Storage.ResultTTLByStatus)
+	store.SetMaxJobs(cfg.Storage.MaxMemoryJobs)
+	return store, nil
 }
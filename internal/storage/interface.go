@@ -4,6 +4,9 @@ import (
 	"github.com/stlpine/will-it-compile/pkg/models"
 )
 
+// ListFilter selects the jobs returned by JobStore.List.
+type ListFilter = models.JobFilter
+
 // JobStore defines the interface for job storage implementations.
 // This abstraction allows switching between in-memory (development)
 // and Redis/database (production) storage without changing business logic.
@@ -22,6 +25,16 @@ type JobStore interface {
 	// Returns the result and true if found, zero value and false if not found.
 	GetResult(jobID string) (models.CompilationResult, bool)
 
+	// List returns the stored jobs matching the filter, newest first.
+	List(filter ListFilter) ([]models.CompilationJob, error)
+
 	// Close releases any resources held by the store.
 	Close() error
 }
+
+// JobLister is implemented by stores that can enumerate jobs by status.
+// It is used on startup to re-enqueue jobs left unfinished by a previous instance.
+type JobLister interface {
+	// ListByStatus returns all stored jobs currently in the given status.
+	ListByStatus(status models.JobStatus) ([]models.CompilationJob, error)
+}
@@ -1,7 +1,9 @@
 package memory
 
 import (
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/stlpine/will-it-compile/pkg/models"
 )
@@ -12,25 +14,82 @@ import (
 type Store struct {
 	mu      sync.RWMutex
 	jobs    map[string]models.CompilationJob
-	results map[string]models.CompilationResult
+	results map[string]storedResult
+
+	maxOutputBytes int                                // Per-stream cap applied in StoreResult (0 = unlimited)
+	resultTTLs     map[models.JobStatus]time.Duration // Result age limit by final status (no entry = kept)
+	maxJobs        int                                // Jobs kept before the oldest finished ones are evicted (0 = unlimited)
+}
+
+// storedResult is a compilation result and when it was stored.
+type storedResult struct {
+	result   models.CompilationResult
+	storedAt time.Time
 }
 
 // NewStore creates a new in-memory job store.
 func NewStore() *Store {
 	return &Store{
 		jobs:    make(map[string]models.CompilationJob),
-		results: make(map[string]models.CompilationResult),
+		results: make(map[string]storedResult),
 	}
 }
 
-// Store saves or updates a job.
+// SetMaxStoredOutputBytes caps each output stream of stored results (0 = unlimited).
+// It must be called before the store is used.
+func (s *Store) SetMaxStoredOutputBytes(maxBytes int) {
+	s.maxOutputBytes = maxBytes
+}
+
+// SetResultTTLs sets how long results are kept by final job status; statuses without an entry are never evicted.
+// It must be called before the store is used.
+func (s *Store) SetResultTTLs(ttls map[models.JobStatus]time.Duration) {
+	s.resultTTLs = ttls
+}
+
+// SetMaxJobs caps the number of jobs kept (0 = unlimited). Past it, the oldest finished jobs are evicted
+// along with their results; queued and processing jobs are never evicted.
+// It must be called before the store is used.
+func (s *Store) SetMaxJobs(maxJobs int) {
+	s.maxJobs = maxJobs
+}
+
+// Store saves or updates a job, evicting the oldest finished jobs if a new one takes the store over its cap.
 func (s *Store) Store(job models.CompilationJob) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	_, exists := s.jobs[job.ID]
 	s.jobs[job.ID] = job
+	if !exists {
+		s.evictOldest()
+	}
 	return nil
 }
 
+// evictOldest removes the oldest finished jobs, by creation time, and their results until the store is back
+// within maxJobs, or no finished job is left. The caller must hold the write lock.
+func (s *Store) evictOldest() {
+	excess := len(s.jobs) - s.maxJobs
+	if s.maxJobs <= 0 || excess <= 0 {
+		return
+	}
+
+	var finished []models.CompilationJob
+	for _, job := range s.jobs {
+		if job.Status.Finished() {
+			finished = append(finished, job)
+		}
+	}
+	slices.SortFunc(finished, func(a, b models.CompilationJob) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	for _, job := range finished[:min(excess, len(finished))] {
+		delete(s.jobs, job.ID)
+		delete(s.results, job.ID)
+	}
+}
+
 // Get retrieves a job by ID.
 func (s *Store) Get(jobID string) (models.CompilationJob, bool) {
 	s.mu.RLock()
@@ -39,11 +98,46 @@ func (s *Store) Get(jobID string) (models.CompilationJob, bool) {
 	return job, exists
 }
 
-// StoreResult saves a compilation result.
+// ListByStatus returns all jobs currently in the given status.
+func (s *Store) ListByStatus(status models.JobStatus) ([]models.CompilationJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var jobs []models.CompilationJob
+	for _, job := range s.jobs {
+		if job.Status == status {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// List returns the jobs matching the filter, newest first.
+func (s *Store) List(filter models.JobFilter) ([]models.CompilationJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jobs := make([]models.CompilationJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if filter.Status == "" || job.Status == filter.Status {
+			jobs = append(jobs, job)
+		}
+	}
+	return filter.Page(jobs), nil
+}
+
+// StoreResult saves a compilation result, evicting results older than their status allows.
 func (s *Store) StoreResult(jobID string, result models.CompilationResult) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.results[jobID] = result
+
+	now := time.Now()
+	for id, stored := range s.results {
+		if s.expired(stored, now) {
+			delete(s.results, id)
+		}
+	}
+
+	result.TruncateOutput(s.maxOutputBytes)
+	s.results[jobID] = storedResult{result: result, storedAt: now}
 	return nil
 }
 
@@ -51,8 +145,17 @@ func (s *Store) StoreResult(jobID string, result models.CompilationResult) error
 func (s *Store) GetResult(jobID string) (models.CompilationResult, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	result, exists := s.results[jobID]
-	return result, exists
+	stored, exists := s.results[jobID]
+	if !exists || s.expired(stored, time.Now()) {
+		return models.CompilationResult{}, false
+	}
+	return stored.result, true
+}
+
+// expired reports whether a result has outlived the TTL of its status.
+func (s *Store) expired(stored storedResult, now time.Time) bool {
+	ttl, ok := s.resultTTLs[stored.result.Status()]
+	return ok && now.Sub(stored.storedAt) > ttl
 }
 
 // Close releases any resources (no-op for memory store).
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stlpine/will-it-compile/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStore_MaxJobs tests that storing one job over the cap evicts the oldest finished job and its result,
+// never a queued or processing one.
+func TestStore_MaxJobs(t *testing.T) {
+	const maxJobs = 3
+	store := NewStore()
+	store.SetMaxJobs(maxJobs)
+
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	statuses := []models.JobStatus{models.StatusProcessing, models.StatusCompleted, models.StatusFailed}
+	for i, status := range statuses {
+		id := fmt.Sprintf("job-%d", i)
+		require.NoError(t, store.Store(models.CompilationJob{ID: id, Status: status, CreatedAt: created.Add(time.Duration(i) * time.Minute)}))
+		require.NoError(t, store.StoreResult(id, models.CompilationResult{JobID: id}))
+	}
+
+	// Updating a stored job does not count as a new one
+	require.NoError(t, store.Store(models.CompilationJob{ID: "job-2", Status: models.StatusFailed, CreatedAt: created.Add(2 * time.Minute)}))
+	_, exists := store.Get("job-1")
+	assert.True(t, exists)
+
+	// The oldest job is still processing, so the oldest finished one goes, with its result
+	require.NoError(t, store.Store(models.CompilationJob{ID: "job-3", Status: models.StatusQueued, CreatedAt: created.Add(3 * time.Minute)}))
+
+	_, exists = store.Get("job-1")
+	assert.False(t, exists)
+	_, exists = store.GetResult("job-1")
+	assert.False(t, exists)
+
+	for _, id := range []string{"job-0", "job-2", "job-3"} {
+		_, exists := store.Get(id)
+		assert.True(t, exists, id)
+	}
+	jobs, err := store.List(models.JobFilter{})
+	require.NoError(t, err)
+	assert.Len(t, jobs, maxJobs)
+}
+
+// TestStore_MaxJobsOnlyActive tests that the cap is exceeded rather than evicting active jobs.
+func TestStore_MaxJobsOnlyActive(t *testing.T) {
+	store := NewStore()
+	store.SetMaxJobs(1)
+
+	require.NoError(t, store.Store(models.CompilationJob{ID: "job-0", Status: models.StatusProcessing, CreatedAt: time.Now()}))
+	require.NoError(t, store.Store(models.CompilationJob{ID: "job-1", Status: models.StatusQueued, CreatedAt: time.Now()}))
+
+	jobs, err := store.List(models.JobFilter{})
+	require.NoError(t, err)
+	assert.Len(t, jobs, 2)
+}
+
+// TestStore_Unlimited tests that without a cap no job is evicted.
+func TestStore_Unlimited(t *testing.T) {
+	store := NewStore()
+	for i := range 100 {
+		require.NoError(t, store.Store(models.CompilationJob{ID: fmt.Sprintf("job-%d", i), Status: models.StatusCompleted}))
+	}
+
+	jobs, err := store.List(models.JobFilter{Limit: 1000})
+	require.NoError(t, err)
+	assert.Len(t, jobs, 100)
+}
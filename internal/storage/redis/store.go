@@ -18,6 +18,13 @@ type Store struct {
 	client *redis.Client
 	ctx    context.Context
 	ttl    time.Duration
+
+	keyPrefix      string // Prepended to every key, isolating deployments that share a Redis
+	maxOutputBytes int    // Per-stream cap applied in StoreResult (0 = unlimited)
+
+	resultTTLs map[models.JobStatus]time.Duration // Result TTL by final status, overriding ttl
+
+	maxJobAge time.Duration // Bound on TTLs renewed on access, from submission (0 = TTLs are never renewed)
 }
 
 // NewStore creates a new Redis job store.
@@ -27,11 +34,16 @@ func NewStore(cfg config.RedisConfig) (*Store, error) {
 		return nil, err
 	}
 
-	return &Store{
-		client: client.GetClient(),
-		ctx:    context.Background(),
-		ttl:    cfg.JobTTL,
-	}, nil
+	store := &Store{
+		client:    client.GetClient(),
+		ctx:       context.Background(),
+		ttl:       cfg.JobTTL,
+		keyPrefix: cfg.KeyPrefix,
+	}
+	if cfg.SlidingTTL {
+		store.maxJobAge = cfg.MaxJobAge
+	}
+	return store, nil
 }
 
 // NewStoreWithClient creates a new Redis job store with an existing client.
@@ -44,6 +56,30 @@ func NewStoreWithClient(client *redis.Client, ttl time.Duration) *Store {
 	}
 }
 
+// SetMaxStoredOutputBytes caps each output stream of stored results (0 = unlimited).
+// It must be called before the store is used.
+func (s *Store) SetMaxStoredOutputBytes(maxBytes int) {
+	s.maxOutputBytes = maxBytes
+}
+
+// SetKeyPrefix namespaces all keys of the store (empty keeps the unprefixed keys).
+// It must be called before the store is used.
+func (s *Store) SetKeyPrefix(prefix string) {
+	s.keyPrefix = prefix
+}
+
+// SetResultTTLs sets how long results are kept by final job status; statuses without an entry use the job TTL.
+// It must be called before the store is used.
+func (s *Store) SetResultTTLs(ttls map[models.JobStatus]time.Duration) {
+	s.resultTTLs = ttls
+}
+
+// SetSlidingTTL renews the TTL of a job and its result whenever they are read, until maxAge after the
+// job's submission (0 disables renewal). It must be called before the store is used.
+func (s *Store) SetSlidingTTL(maxAge time.Duration) {
+	s.maxJobAge = maxAge
+}
+
 // Store saves or updates a job.
 func (s *Store) Store(job models.CompilationJob) error {
 	key := s.jobKey(job.ID)
@@ -52,6 +88,7 @@ func (s *Store) Store(job models.CompilationJob) error {
 	createdAt := job.CreatedAt.Format(time.RFC3339Nano)
 	startedAt := ""
 	completedAt := ""
+	lastHeartbeat := ""
 
 	if job.StartedAt != nil {
 		startedAt = job.StartedAt.Format(time.RFC3339Nano)
@@ -59,6 +96,9 @@ func (s *Store) Store(job models.CompilationJob) error {
 	if job.CompletedAt != nil {
 		completedAt = job.CompletedAt.Format(time.RFC3339Nano)
 	}
+	if job.LastHeartbeat != nil {
+		lastHeartbeat = job.LastHeartbeat.Format(time.RFC3339Nano)
+	}
 
 	// Serialize request as JSON
 	requestJSON, err := json.Marshal(job.Request)
@@ -68,12 +108,13 @@ func (s *Store) Store(job models.CompilationJob) error {
 
 	// Store as hash
 	err = s.client.HSet(s.ctx, key, map[string]interface{}{
-		"id":           job.ID,
-		"request":      string(requestJSON),
-		"status":       string(job.Status),
-		"created_at":   createdAt,
-		"started_at":   startedAt,
-		"completed_at": completedAt,
+		"id":             job.ID,
+		"request":        string(requestJSON),
+		"status":         string(job.Status),
+		"created_at":     createdAt,
+		"started_at":     startedAt,
+		"completed_at":   completedAt,
+		"last_heartbeat": lastHeartbeat,
 	}).Err()
 	if err != nil {
 		return fmt.Errorf("failed to store job %s: %w", job.ID, err)
@@ -122,35 +163,154 @@ func (s *Store) Get(jobID string) (models.CompilationJob, bool) {
 		}
 	}
 
+	if result["last_heartbeat"] != "" {
+		if lastHeartbeat, err := time.Parse(time.RFC3339Nano, result["last_heartbeat"]); err == nil {
+			job.LastHeartbeat = &lastHeartbeat
+		}
+	}
+
 	// Parse request
 	if err := json.Unmarshal([]byte(result["request"]), &job.Request); err != nil {
 		return models.CompilationJob{}, false
 	}
 
+	// A job being watched stays alive; a finished one as long as its result
+	s.renewTTL(job.CreatedAt, s.statusTTL(job.Status), key)
+
 	return job, true
 }
 
+// ListByStatus returns all jobs currently in the given status.
+// The status index is never pruned when a job moves on, so each job's
+// current status is re-checked; expired jobs are skipped.
+func (s *Store) ListByStatus(status models.JobStatus) ([]models.CompilationJob, error) {
+	jobIDs, err := s.client.SMembers(s.ctx, s.statusIndexKey(status)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s jobs: %w", status, err)
+	}
+
+	var jobs []models.CompilationJob
+	for _, jobID := range jobIDs {
+		job, exists := s.Get(jobID)
+		if exists && job.Status == status {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// List returns the jobs matching the filter, newest first, from the status indexes.
+// Without a status filter every status index is read.
+func (s *Store) List(filter models.JobFilter) ([]models.CompilationJob, error) {
+	statuses := models.JobStatuses
+	if filter.Status != "" {
+		statuses = []models.JobStatus{filter.Status}
+	}
+
+	var jobs []models.CompilationJob
+	for _, status := range statuses {
+		statusJobs, err := s.ListByStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, statusJobs...)
+	}
+	return filter.Page(jobs), nil
+}
+
 // StoreResult saves a compilation result.
 func (s *Store) StoreResult(jobID string, result models.CompilationResult) error {
 	key := s.resultKey(jobID)
 
+	// Cap retained output before it is written
+	result.TruncateOutput(s.maxOutputBytes)
+
+	// Serialize diagnostics (flat and grouped), analyzer findings, includes and symbols as JSON
+	diagnosticsJSON, err := json.Marshal(result.Diagnostics)
+	if err != nil {
+		return fmt.Errorf("failed to serialize diagnostics: %w", err)
+	}
+	analysisJSON, err := json.Marshal(result.Analysis)
+	if err != nil {
+		return fmt.Errorf("failed to serialize analysis: %w", err)
+	}
+	includesJSON, err := json.Marshal(result.Includes)
+	if err != nil {
+		return fmt.Errorf("failed to serialize includes: %w", err)
+	}
+	symbolsJSON, err := json.Marshal(result.Symbols)
+	if err != nil {
+		return fmt.Errorf("failed to serialize symbols: %w", err)
+	}
+	errorsJSON, err := json.Marshal(result.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to serialize errors: %w", err)
+	}
+	warningsJSON, err := json.Marshal(result.Warnings)
+	if err != nil {
+		return fmt.Errorf("failed to serialize warnings: %w", err)
+	}
+	notesJSON, err := json.Marshal(result.Notes)
+	if err != nil {
+		return fmt.Errorf("failed to serialize notes: %w", err)
+	}
+	resourceUsageJSON, err := json.Marshal(result.ResourceUsage)
+	if err != nil {
+		return fmt.Errorf("failed to serialize resource usage: %w", err)
+	}
+
 	// Store as hash
-	err := s.client.HSet(s.ctx, key, map[string]interface{}{
-		"job_id":    result.JobID,
-		"success":   result.Success,
-		"compiled":  result.Compiled,
-		"stdout":    result.Stdout,
-		"stderr":    result.Stderr,
-		"exit_code": result.ExitCode,
-		"duration":  result.Duration.Nanoseconds(),
-		"error":     result.Error,
+	err = s.client.HSet(s.ctx, key, map[string]interface{}{
+		"job_id":                 result.JobID,
+		"success":                result.Success,
+		"compiled":               result.Compiled,
+		"stdout":                 result.Stdout,
+		"stderr":                 result.Stderr,
+		"exit_code":              result.ExitCode,
+		"duration":               result.Duration.Nanoseconds(),
+		"error":                  result.Error,
+		"timed_out":              result.TimedOut,
+		"cancelled":              result.Cancelled,
+		"diagnostics":            string(diagnosticsJSON),
+		"errors":                 string(errorsJSON),
+		"warnings":               string(warningsJSON),
+		"notes":                  string(notesJSON),
+		"diagnostics_html":       result.DiagnosticsHTML,
+		"ran":                    result.Ran,
+		"run_stdout":             result.RunStdout,
+		"run_stderr":             result.RunStderr,
+		"run_stdout_html":        result.RunStdoutHTML,
+		"run_exit_code":          result.RunExitCode,
+		"run_timed_out":          result.RunTimedOut,
+		"run_success":            result.RunSuccess,
+		"run_error":              result.RunError,
+		"binary_bytes":           result.BinaryBytes,
+		"analysis":               string(analysisJSON),
+		"cached":                 result.Cached,
+		"cache_age":              result.CacheAge.Nanoseconds(),
+		"includes":               string(includesJSON),
+		"source_hash":            result.SourceHash,
+		"output_hash":            result.OutputHash,
+		"tested":                 result.Tested,
+		"tests_passed":           result.TestsPassed,
+		"tests_failed":           result.TestsFailed,
+		"format_diff":            result.FormatDiff,
+		"preprocessed":           result.Preprocessed,
+		"preprocessed_truncated": result.PreprocessedTruncated,
+		"terminated_by_signal":   result.TerminatedBySignal,
+		"resource_usage":         string(resourceUsageJSON),
+		"symbols":                string(symbolsJSON),
+		"symbols_truncated":      result.SymbolsTruncated,
+		"network_enabled":        result.NetworkEnabled,
 	}).Err()
 	if err != nil {
 		return fmt.Errorf("failed to store result for job %s: %w", jobID, err)
 	}
 
-	// Set TTL
-	s.client.Expire(s.ctx, key, s.ttl)
+	// Set TTL by final status; the job is kept as long as its result, so the result stays reachable
+	ttl := s.statusTTL(result.Status())
+	s.client.Expire(s.ctx, key, ttl)
+	s.client.Expire(s.ctx, s.jobKey(jobID), ttl)
 
 	return nil
 }
@@ -166,10 +326,20 @@ func (s *Store) GetResult(jobID string) (models.CompilationResult, bool) {
 
 	// Parse result from hash
 	compilationResult := models.CompilationResult{
-		JobID:  result["job_id"],
-		Stdout: result["stdout"],
-		Stderr: result["stderr"],
-		Error:  result["error"],
+		JobID:              result["job_id"],
+		Stdout:             result["stdout"],
+		Stderr:             result["stderr"],
+		Error:              result["error"],
+		RunStdout:          result["run_stdout"],
+		RunStderr:          result["run_stderr"],
+		TerminatedBySignal: result["terminated_by_signal"],
+		SourceHash:         result["source_hash"],
+		OutputHash:         result["output_hash"],
+		FormatDiff:         result["format_diff"],
+		Preprocessed:       result["preprocessed"],
+		DiagnosticsHTML:    result["diagnostics_html"],
+		RunStdoutHTML:      result["run_stdout_html"],
+		RunError:           result["run_error"],
 	}
 
 	// Parse boolean fields
@@ -181,19 +351,141 @@ func (s *Store) GetResult(jobID string) (models.CompilationResult, bool) {
 		compilationResult.Compiled = compiled
 	}
 
+	if timedOut, err := strconv.ParseBool(result["timed_out"]); err == nil {
+		compilationResult.TimedOut = timedOut
+	}
+	if cancelled, err := strconv.ParseBool(result["cancelled"]); err == nil {
+		compilationResult.Cancelled = cancelled
+	}
+
+	if ran, err := strconv.ParseBool(result["ran"]); err == nil {
+		compilationResult.Ran = ran
+	}
+
+	if runTimedOut, err := strconv.ParseBool(result["run_timed_out"]); err == nil {
+		compilationResult.RunTimedOut = runTimedOut
+	}
+
+	if runSuccess, err := strconv.ParseBool(result["run_success"]); err == nil {
+		compilationResult.RunSuccess = runSuccess
+	}
+
+	if cached, err := strconv.ParseBool(result["cached"]); err == nil {
+		compilationResult.Cached = cached
+	}
+
+	if tested, err := strconv.ParseBool(result["tested"]); err == nil {
+		compilationResult.Tested = tested
+	}
+
+	if preprocessedTruncated, err := strconv.ParseBool(result["preprocessed_truncated"]); err == nil {
+		compilationResult.PreprocessedTruncated = preprocessedTruncated
+	}
+
+	if symbolsTruncated, err := strconv.ParseBool(result["symbols_truncated"]); err == nil {
+		compilationResult.SymbolsTruncated = symbolsTruncated
+	}
+
+	if networkEnabled, err := strconv.ParseBool(result["network_enabled"]); err == nil {
+		compilationResult.NetworkEnabled = networkEnabled
+	}
+
 	// Parse integer fields
 	if exitCode, err := strconv.Atoi(result["exit_code"]); err == nil {
 		compilationResult.ExitCode = exitCode
 	}
 
+	if runExitCode, err := strconv.Atoi(result["run_exit_code"]); err == nil {
+		compilationResult.RunExitCode = runExitCode
+	}
+
+	if testsPassed, err := strconv.Atoi(result["tests_passed"]); err == nil {
+		compilationResult.TestsPassed = testsPassed
+	}
+
+	if testsFailed, err := strconv.Atoi(result["tests_failed"]); err == nil {
+		compilationResult.TestsFailed = testsFailed
+	}
+
+	if binaryBytes, err := strconv.ParseInt(result["binary_bytes"], 10, 64); err == nil {
+		compilationResult.BinaryBytes = binaryBytes
+	}
+
 	// Parse duration
 	if durationNs, err := strconv.ParseInt(result["duration"], 10, 64); err == nil {
 		compilationResult.Duration = time.Duration(durationNs)
 	}
 
+	if cacheAgeNs, err := strconv.ParseInt(result["cache_age"], 10, 64); err == nil {
+		compilationResult.CacheAge = time.Duration(cacheAgeNs)
+	}
+
+	// Parse diagnostics (absent for results stored before diagnostics existed)
+	if diagnostics := result["diagnostics"]; diagnostics != "" {
+		_ = json.Unmarshal([]byte(diagnostics), &compilationResult.Diagnostics) //nolint:errcheck // best effort, raw stderr is still available
+	}
+
+	if errorsJSON := result["errors"]; errorsJSON != "" {
+		_ = json.Unmarshal([]byte(errorsJSON), &compilationResult.Errors) //nolint:errcheck // best effort
+	}
+
+	if warnings := result["warnings"]; warnings != "" {
+		_ = json.Unmarshal([]byte(warnings), &compilationResult.Warnings) //nolint:errcheck // best effort
+	}
+
+	if notes := result["notes"]; notes != "" {
+		_ = json.Unmarshal([]byte(notes), &compilationResult.Notes) //nolint:errcheck // best effort
+	}
+
+	if analysis := result["analysis"]; analysis != "" {
+		_ = json.Unmarshal([]byte(analysis), &compilationResult.Analysis) //nolint:errcheck // best effort
+	}
+
+	if includes := result["includes"]; includes != "" {
+		_ = json.Unmarshal([]byte(includes), &compilationResult.Includes) //nolint:errcheck // best effort
+	}
+
+	if symbols := result["symbols"]; symbols != "" {
+		_ = json.Unmarshal([]byte(symbols), &compilationResult.Symbols) //nolint:errcheck // best effort
+	}
+
+	if resourceUsage := result["resource_usage"]; resourceUsage != "" {
+		_ = json.Unmarshal([]byte(resourceUsage), &compilationResult.ResourceUsage) //nolint:errcheck // best effort
+	}
+
+	// The result and its job are renewed together, bounded by the job's submission time
+	if s.maxJobAge > 0 {
+		if createdAt, err := time.Parse(time.RFC3339Nano, s.client.HGet(s.ctx, s.jobKey(jobID), "created_at").Val()); err == nil {
+			s.renewTTL(createdAt, s.statusTTL(compilationResult.Status()), key, s.jobKey(jobID))
+		}
+	}
+
 	return compilationResult, true
 }
 
+// statusTTL returns how long a job and its result are kept in status: its result TTL, or the job TTL.
+func (s *Store) statusTTL(status models.JobStatus) time.Duration {
+	if ttl, ok := s.resultTTLs[status]; ok {
+		return ttl
+	}
+	return s.ttl
+}
+
+// renewTTL extends the TTL of keys to ttl from now when sliding TTLs are enabled, but never past
+// maxJobAge after createdAt, and never shortens a TTL.
+func (s *Store) renewTTL(createdAt time.Time, ttl time.Duration, keys ...string) {
+	if s.maxJobAge <= 0 {
+		return
+	}
+	ttl = min(ttl, s.maxJobAge-time.Since(createdAt))
+	if ttl <= 0 {
+		return
+	}
+	for _, key := range keys {
+		s.client.ExpireGT(s.ctx, key, ttl)
+	}
+}
+
 // Close releases Redis connection.
 func (s *Store) Close() error {
 	return s.client.Close()
@@ -202,13 +494,13 @@ func (s *Store) Close() error {
 // Helper functions for Redis key generation
 
 func (s *Store) jobKey(jobID string) string {
-	return fmt.Sprintf("job:%s", jobID)
+	return fmt.Sprintf("%sjob:%s", s.keyPrefix, jobID)
 }
 
 func (s *Store) resultKey(jobID string) string {
-	return fmt.Sprintf("result:%s", jobID)
+	return fmt.Sprintf("%sresult:%s", s.keyPrefix, jobID)
 }
 
 func (s *Store) statusIndexKey(status models.JobStatus) string {
-	return fmt.Sprintf("job:index:status:%s", status)
+	return fmt.Sprintf("%sjob:index:status:%s", s.keyPrefix, status)
 }
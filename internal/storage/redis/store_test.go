@@ -1,6 +1,8 @@
 package redis
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -220,3 +222,379 @@ func TestRedisStore_FailedCompilation(t *testing.T) {
 	assert.Equal(t, 1, retrievedResult.ExitCode)
 	assert.Contains(t, retrievedResult.Stderr, "error")
 }
+
+func TestRedisStore_TimeoutResult(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+
+	// A timeout and an infrastructure error are told apart from a clean failure by their error
+	results := []models.CompilationResult{
+		{JobID: "test-job-timeout", Success: true, ExitCode: 137, Error: "compilation timeout", TimedOut: true, TerminatedBySignal: "SIGKILL"},
+		{JobID: "test-job-error", Error: "compilation failed: docker unavailable"},
+	}
+	for _, result := range results {
+		require.NoError(t, store.StoreResult(result.JobID, result))
+
+		retrieved, found := store.GetResult(result.JobID)
+		require.True(t, found)
+		assert.Equal(t, result.JobID, retrieved.JobID)
+		assert.Equal(t, result.Error, retrieved.Error)
+		assert.Equal(t, result.Status(), retrieved.Status())
+	}
+
+	retrieved, _ := store.GetResult("test-job-timeout")
+	assert.True(t, retrieved.TimedOut)
+	assert.Equal(t, 137, retrieved.ExitCode)
+	assert.Equal(t, "SIGKILL", retrieved.TerminatedBySignal)
+}
+
+func TestRedisStore_ResultDiagnostics(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+
+	result := models.CompilationResult{
+		JobID:    "test-job-diagnostics",
+		Success:  true,
+		Compiled: false,
+		ExitCode: 1,
+		Diagnostics: []models.Diagnostic{
+			{File: "source.cpp", Line: 3, Column: 5, Severity: "error", Message: "expected ';' before 'return'"},
+		},
+		Errors: []string{"source.cpp:3:5: expected ';' before 'return'"},
+		Analysis: []models.Diagnostic{
+			{File: "source.cpp", Line: 2, Column: 9, Severity: "warning", Message: "unused variable 'x' [unusedVariable]"},
+		},
+	}
+
+	err := store.StoreResult("test-job-diagnostics", result)
+	require.NoError(t, err)
+
+	retrieved, found := store.GetResult("test-job-diagnostics")
+	assert.True(t, found)
+	assert.Equal(t, result.Diagnostics, retrieved.Diagnostics)
+	assert.Equal(t, result.Errors, retrieved.Errors)
+	assert.Empty(t, retrieved.Warnings)
+	assert.Empty(t, retrieved.Notes)
+	assert.Equal(t, result.Analysis, retrieved.Analysis)
+}
+
+func TestRedisStore_RunResult(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+
+	result := models.CompilationResult{
+		JobID:       "test-job-run",
+		Success:     true,
+		Compiled:    true,
+		Ran:         true,
+		RunStdout:   "Hello, World!",
+		RunStderr:   "warning from program",
+		RunExitCode: 124,
+		RunTimedOut: true,
+		BinaryBytes: 16384,
+	}
+
+	err := store.StoreResult("test-job-run", result)
+	require.NoError(t, err)
+
+	retrieved, found := store.GetResult("test-job-run")
+	assert.True(t, found)
+	assert.True(t, retrieved.Ran)
+	assert.Equal(t, result.RunStdout, retrieved.RunStdout)
+	assert.Equal(t, result.RunStderr, retrieved.RunStderr)
+	assert.Equal(t, result.RunExitCode, retrieved.RunExitCode)
+	assert.True(t, retrieved.RunTimedOut)
+	assert.Equal(t, result.BinaryBytes, retrieved.BinaryBytes)
+}
+
+func TestRedisStore_MaxStoredOutputBytes(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+
+	store.SetMaxStoredOutputBytes(16)
+
+	result := models.CompilationResult{
+		JobID:    "test-job-large",
+		Success:  true,
+		Compiled: false,
+		Stdout:   "short",
+		Stderr:   strings.Repeat("e", 1024),
+	}
+
+	err := store.StoreResult("test-job-large", result)
+	require.NoError(t, err)
+
+	retrieved, found := store.GetResult("test-job-large")
+	assert.True(t, found)
+	assert.Equal(t, "short", retrieved.Stdout, "Output under the cap is stored as-is")
+	assert.True(t, strings.HasPrefix(retrieved.Stderr, strings.Repeat("e", 16)+"\n"))
+	assert.Contains(t, retrieved.Stderr, "output truncated")
+	assert.Less(t, len(retrieved.Stderr), 1024)
+}
+
+func TestRedisStore_ListByStatus(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+
+	heartbeat := time.Now()
+	processing := models.CompilationJob{
+		ID:            "job-processing",
+		Status:        models.StatusProcessing,
+		CreatedAt:     time.Now(),
+		LastHeartbeat: &heartbeat,
+	}
+	require.NoError(t, store.Store(processing))
+
+	// Moves through processing, so it stays in that status index
+	finished := models.CompilationJob{ID: "job-finished", Status: models.StatusProcessing, CreatedAt: time.Now()}
+	require.NoError(t, store.Store(finished))
+	finished.Status = models.StatusCompleted
+	require.NoError(t, store.Store(finished))
+
+	jobs, err := store.ListByStatus(models.StatusProcessing)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "job-processing", jobs[0].ID)
+	require.NotNil(t, jobs[0].LastHeartbeat)
+	assert.True(t, heartbeat.Equal(*jobs[0].LastHeartbeat))
+}
+
+func TestRedisStore_List(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i, status := range []models.JobStatus{models.StatusCompleted, models.StatusFailed, models.StatusCompleted, models.StatusQueued} {
+		job := models.CompilationJob{ID: fmt.Sprintf("job-%d", i), Status: models.StatusQueued, CreatedAt: created.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, store.Store(job))
+		job.Status = status
+		require.NoError(t, store.Store(job))
+	}
+
+	ids := func(jobs []models.CompilationJob) []string {
+		var ids []string
+		for _, job := range jobs {
+			ids = append(ids, job.ID)
+		}
+		return ids
+	}
+
+	// A job listed under an earlier status index is only listed once, under its current status
+	jobs, err := store.List(models.JobFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-3", "job-2", "job-1", "job-0"}, ids(jobs))
+
+	jobs, err = store.List(models.JobFilter{Status: models.StatusCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-2", "job-0"}, ids(jobs))
+
+	jobs, err = store.List(models.JobFilter{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job-2", "job-1"}, ids(jobs))
+
+	jobs, err = store.List(models.JobFilter{Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestRedisStore_CachedResult(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+
+	result := models.CompilationResult{
+		JobID:                 "test-job-cached",
+		Success:               true,
+		Compiled:              true,
+		Cached:                true,
+		CacheAge:              90 * time.Second,
+		Includes:              []string{"util.h", "lib/config.h"},
+		ExitCode:              137,
+		TerminatedBySignal:    "SIGKILL",
+		SourceHash:            "0a1b2c",
+		OutputHash:            "3d4e5f",
+		Tested:                true,
+		TestsPassed:           3,
+		TestsFailed:           1,
+		FormatDiff:            "-func main()\n-{}\n+func main() {}\n",
+		Preprocessed:          "int main() {}\n",
+		PreprocessedTruncated: true,
+		DiagnosticsHTML:       `<div class="diagnostics"></div>`,
+		RunStdoutHTML:         `<span class="ansi-fg-green">ok</span>`,
+		RunSuccess:            true,
+		RunError:              "program was killed by the sandbox",
+		Symbols:               []string{"main", "printf"},
+		SymbolsTruncated:      true,
+		NetworkEnabled:        true,
+		ResourceUsage:         &models.ResourceUsage{MaxRSSKB: 98304, UserSeconds: 0.41, SystemSeconds: 0.06},
+	}
+
+	require.NoError(t, store.StoreResult("test-job-cached", result))
+
+	retrieved, found := store.GetResult("test-job-cached")
+	assert.True(t, found)
+	assert.True(t, retrieved.Cached)
+	assert.Equal(t, result.CacheAge, retrieved.CacheAge)
+	assert.Equal(t, result.Includes, retrieved.Includes)
+	assert.Equal(t, "SIGKILL", retrieved.TerminatedBySignal)
+	assert.Equal(t, result.SourceHash, retrieved.SourceHash)
+	assert.Equal(t, result.OutputHash, retrieved.OutputHash)
+	assert.True(t, retrieved.Tested)
+	assert.Equal(t, 3, retrieved.TestsPassed)
+	assert.Equal(t, 1, retrieved.TestsFailed)
+	assert.Equal(t, result.FormatDiff, retrieved.FormatDiff)
+	assert.Equal(t, result.Preprocessed, retrieved.Preprocessed)
+	assert.True(t, retrieved.PreprocessedTruncated)
+	assert.Equal(t, result.DiagnosticsHTML, retrieved.DiagnosticsHTML)
+	assert.Equal(t, result.RunStdoutHTML, retrieved.RunStdoutHTML)
+	assert.True(t, retrieved.RunSuccess)
+	assert.Equal(t, result.RunError, retrieved.RunError)
+	assert.Equal(t, result.Symbols, retrieved.Symbols)
+	assert.True(t, retrieved.SymbolsTruncated)
+	assert.True(t, retrieved.NetworkEnabled)
+	assert.Equal(t, result.ResourceUsage, retrieved.ResourceUsage)
+}
+
+func TestRedisStore_KeyPrefix(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+	store.SetKeyPrefix("tenant-a:")
+
+	other := NewStoreWithClient(redis.NewClient(&redis.Options{Addr: mr.Addr()}), 24*time.Hour)
+	defer other.Close() //nolint:errcheck // test cleanup
+	other.SetKeyPrefix("tenant-b:")
+
+	job := models.CompilationJob{
+		ID:        "test-job-prefixed",
+		Status:    models.StatusQueued,
+		CreatedAt: time.Now(),
+	}
+	require.NoError(t, store.Store(job))
+	require.NoError(t, store.StoreResult(job.ID, models.CompilationResult{JobID: job.ID, Success: true}))
+
+	// Every key carries the prefix
+	assert.True(t, mr.Exists("tenant-a:job:test-job-prefixed"))
+	assert.True(t, mr.Exists("tenant-a:result:test-job-prefixed"))
+	assert.True(t, mr.Exists("tenant-a:job:index:status:queued"))
+	assert.False(t, mr.Exists("job:test-job-prefixed"))
+
+	// The other tenant sees none of it
+	_, found := other.Get(job.ID)
+	assert.False(t, found)
+	_, found = other.GetResult(job.ID)
+	assert.False(t, found)
+	jobs, err := other.ListByStatus(models.StatusQueued)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	_, found = store.Get(job.ID)
+	assert.True(t, found)
+}
+
+func TestRedisStore_ResultTTLByStatus(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+	store.SetResultTTLs(map[models.JobStatus]time.Duration{
+		models.StatusCompleted: time.Hour,
+		models.StatusFailed:    7 * 24 * time.Hour,
+		models.StatusTimeout:   2 * time.Hour,
+	})
+
+	completed := models.CompilationResult{JobID: "test-job-completed", Success: true, Compiled: true}
+	failed := models.CompilationResult{JobID: "test-job-failed", Success: true, Compiled: false, ExitCode: 1}
+	infraError := models.CompilationResult{JobID: "test-job-error", Error: "compilation failed: docker unavailable"}
+	timedOut := models.CompilationResult{JobID: "test-job-timeout", Success: true, Error: "compilation timeout", TimedOut: true}
+	cancelled := models.CompilationResult{JobID: "test-job-cancelled", Error: "job cancelled", Cancelled: true}
+
+	for _, result := range []models.CompilationResult{completed, failed, infraError, timedOut, cancelled} {
+		require.NoError(t, store.Store(models.CompilationJob{ID: result.JobID, Status: result.Status(), CreatedAt: time.Now()}))
+		require.NoError(t, store.StoreResult(result.JobID, result))
+	}
+
+	assert.Equal(t, time.Hour, mr.TTL("result:test-job-completed"))
+	assert.Equal(t, 7*24*time.Hour, mr.TTL("result:test-job-failed"))
+	assert.Greater(t, mr.TTL("result:test-job-failed"), mr.TTL("result:test-job-completed"))
+
+	// The job outlives its default TTL along with the result
+	assert.Equal(t, 7*24*time.Hour, mr.TTL("job:test-job-failed"))
+
+	// Statuses without an entry keep the job TTL
+	assert.Equal(t, 24*time.Hour, mr.TTL("result:test-job-error"))
+
+	// Timeouts are classified by the stored flag, not the error text
+	assert.Equal(t, 2*time.Hour, mr.TTL("result:test-job-timeout"))
+	retrieved, found := store.GetResult("test-job-timeout")
+	require.True(t, found)
+	assert.True(t, retrieved.TimedOut)
+	assert.Equal(t, models.StatusTimeout, retrieved.Status())
+
+	// Likewise cancellations
+	retrieved, found = store.GetResult("test-job-cancelled")
+	require.True(t, found)
+	assert.Equal(t, models.StatusCancelled, retrieved.Status())
+
+	// Once the completed result expires, the failed one is still there
+	mr.FastForward(2 * time.Hour)
+	_, found = store.GetResult("test-job-completed")
+	assert.False(t, found)
+	_, found = store.GetResult("test-job-failed")
+	assert.True(t, found)
+}
+
+func TestRedisStore_SlidingTTL(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+	store.ttl = time.Hour
+	store.SetSlidingTTL(3 * time.Hour)
+
+	// A job being polled has its TTL renewed on every read
+	require.NoError(t, store.Store(models.CompilationJob{ID: "test-job-watched", Status: models.StatusProcessing, CreatedAt: time.Now()}))
+	mr.FastForward(50 * time.Minute)
+	_, found := store.Get("test-job-watched")
+	require.True(t, found)
+	assert.Equal(t, time.Hour, mr.TTL("job:test-job-watched"))
+
+	// Reading the result renews the result and its job
+	result := models.CompilationResult{JobID: "test-job-watched", Success: true, Compiled: true}
+	require.NoError(t, store.StoreResult(result.JobID, result))
+	mr.FastForward(50 * time.Minute)
+	_, found = store.GetResult("test-job-watched")
+	require.True(t, found)
+	assert.Equal(t, time.Hour, mr.TTL("result:test-job-watched"))
+	assert.Equal(t, time.Hour, mr.TTL("job:test-job-watched"))
+
+	// Renewal stops at the max age from submission
+	require.NoError(t, store.Store(models.CompilationJob{ID: "test-job-old", Status: models.StatusProcessing, CreatedAt: time.Now().Add(-150 * time.Minute)}))
+	mr.FastForward(50 * time.Minute)
+	_, found = store.Get("test-job-old")
+	require.True(t, found)
+	ttl := mr.TTL("job:test-job-old")
+	assert.Greater(t, ttl, 20*time.Minute)
+	assert.LessOrEqual(t, ttl, 30*time.Minute)
+
+	mr.FastForward(31 * time.Minute)
+	_, found = store.Get("test-job-old")
+	assert.False(t, found)
+}
+
+func TestRedisStore_NoSlidingTTL(t *testing.T) {
+	store, mr := setupTestStore(t)
+	defer mr.Close()
+	defer store.Close() //nolint:errcheck // test cleanup
+
+	// By default a read leaves the TTL from submission
+	require.NoError(t, store.Store(models.CompilationJob{ID: "test-job-fixed", Status: models.StatusQueued, CreatedAt: time.Now()}))
+	mr.FastForward(time.Hour)
+	_, found := store.Get("test-job-fixed")
+	require.True(t, found)
+	assert.Equal(t, 23*time.Hour, mr.TTL("job:test-job-fixed"))
+}
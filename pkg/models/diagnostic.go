@@ -0,0 +1,11 @@
+package models
+
+// Diagnostic is a single structured compiler message (error, warning, note).
+type Diagnostic struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"` // e.g., "error", "warning", "note"
+	Message  string `json:"message"`
+	Code     string `json:"code,omitempty"` // Compiler's error code, e.g. "E0308" for rustc (empty if none)
+}
@@ -0,0 +1,30 @@
+package models
+
+// ResultDiff compares the result of a job against the result of a baseline job,
+// e.g., the same code before a change.
+type ResultDiff struct {
+	JobID     string `json:"job_id"`
+	Against   string `json:"against"`   // Baseline job
+	Changed   bool   `json:"changed"`   // Compiled status, exit code or diagnostics differ
+	Regressed bool   `json:"regressed"` // The baseline compiled and this job does not
+
+	Compiled BoolChange `json:"compiled"`
+	ExitCode IntChange  `json:"exit_code"`
+
+	// Diagnostics are matched by file, severity and message, ignoring line and column,
+	// so code moving around does not show up as a change
+	AddedDiagnostics   []Diagnostic `json:"added_diagnostics,omitempty"`   // In this job but not the baseline
+	RemovedDiagnostics []Diagnostic `json:"removed_diagnostics,omitempty"` // In the baseline but not this job
+}
+
+// BoolChange is a boolean result field in the baseline (before) and this job (after).
+type BoolChange struct {
+	Before bool `json:"before"`
+	After  bool `json:"after"`
+}
+
+// IntChange is an integer result field in the baseline (before) and this job (after).
+type IntChange struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"slices"
+	"strings"
+)
+
 // Language represents a programming language.
 type Language string
 
@@ -9,12 +14,23 @@ const (
 	LanguageCPP  Language = "c++" // Alias for cpp
 	LanguageGo   Language = "go"
 	LanguageRust Language = "rust"
+
+	// Objective-C and Objective-C++ (clang with an Objective-C runtime in the image)
+	LanguageObjC   Language = "objc"
+	LanguageObjCpp Language = "objcpp"
+
+	// Swift (swiftc from the official swift image)
+	LanguageSwift Language = "swift"
+
+	// Kotlin (kotlinc on the JVM, compiled to a runnable jar)
+	LanguageKotlin Language = "kotlin"
 )
 
 // Valid returns true if the language is valid.
 func (l Language) Valid() bool {
 	switch l {
-	case LanguageC, LanguageCpp, LanguageCPP, LanguageGo, LanguageRust:
+	case LanguageC, LanguageCpp, LanguageCPP, LanguageGo, LanguageRust, LanguageObjC, LanguageObjCpp, LanguageSwift,
+		LanguageKotlin:
 		return true
 	default:
 		return false
@@ -29,6 +45,37 @@ func (l Language) Normalize() Language {
 	return l
 }
 
+// SupportsLinker reports whether the language's compiler driver accepts -fuse-ld (gcc/clang).
+func (l Language) SupportsLinker() bool {
+	return l.IsCFamily()
+}
+
+// SupportsTests reports whether the language has a built-in test runner (go test, rustc --test).
+func (l Language) SupportsTests() bool {
+	return l == LanguageGo || l == LanguageRust
+}
+
+// SupportsFlags reports whether the language's compiler accepts extra flags from the request
+// (gcc/clang, go build, rustc).
+func (l Language) SupportsFlags() bool {
+	return l.IsCFamily() || l == LanguageGo || l == LanguageRust
+}
+
+// SupportsDeterministic reports whether the language's builds can be made reproducible by flags.
+func (l Language) SupportsDeterministic() bool {
+	return l.IsCFamily() || l == LanguageGo || l == LanguageRust
+}
+
+// IsCFamily reports whether the language is C or C++ (including aliases).
+func (l Language) IsCFamily() bool {
+	switch l.Normalize() {
+	case LanguageC, LanguageCpp:
+		return true
+	default:
+		return false
+	}
+}
+
 // Compiler represents a compiler.
 type Compiler string
 
@@ -50,6 +97,20 @@ const (
 	CompilerRustc170 Compiler = "rustc-1.70"
 	CompilerRustc175 Compiler = "rustc-1.75"
 	CompilerRustc180 Compiler = "rustc-1.80"
+
+	// Zig (zig cc / zig c++ as a cross-compiling C/C++ backend)
+	CompilerZig Compiler = "zig-0.13"
+
+	// Clang versions (C/C++, and Objective-C / Objective-C++ with clang-18)
+	CompilerClang15 Compiler = "clang-15"
+	CompilerClang16 Compiler = "clang-16"
+	CompilerClang18 Compiler = "clang-18"
+
+	// Swift versions
+	CompilerSwiftc510 Compiler = "swiftc-5.10"
+
+	// Kotlin versions
+	CompilerKotlinc20 Compiler = "kotlinc-2.0"
 )
 
 // Valid returns true if the compiler is valid.
@@ -64,11 +125,33 @@ func (c Compiler) Valid() bool {
 	// Rust versions
 	case CompilerRustc170, CompilerRustc175, CompilerRustc180:
 		return true
+	// Zig versions
+	case CompilerZig:
+		return true
+	// Clang versions
+	case CompilerClang15, CompilerClang16, CompilerClang18:
+		return true
+	// Swift versions
+	case CompilerSwiftc510:
+		return true
+	// Kotlin versions
+	case CompilerKotlinc20:
+		return true
 	default:
 		return false
 	}
 }
 
+// IsZig reports whether the compiler is a zig toolchain (any version).
+func (c Compiler) IsZig() bool {
+	return strings.HasPrefix(string(c), "zig-")
+}
+
+// IsClang reports whether the compiler is clang (any version).
+func (c Compiler) IsClang() bool {
+	return strings.HasPrefix(string(c), "clang-")
+}
+
 // Standard represents a language standard (for C and C++).
 type Standard string
 
@@ -144,6 +227,88 @@ func (o OS) Valid() bool {
 	}
 }
 
+// DiagnosticsFormat represents the format in which the compiler emits diagnostics.
+type DiagnosticsFormat string
+
+const (
+	DiagnosticsFormatText DiagnosticsFormat = "text"
+	DiagnosticsFormatJSON DiagnosticsFormat = "json" // -fdiagnostics-format=json (GCC only)
+)
+
+// Valid returns true if the diagnostics format is valid.
+func (f DiagnosticsFormat) Valid() bool {
+	switch f {
+	case DiagnosticsFormatText, DiagnosticsFormatJSON:
+		return true
+	case "": // Empty is valid (will use default)
+		return true
+	default:
+		return false
+	}
+}
+
+// Linker represents an alternative linker passed to the compiler driver via -fuse-ld.
+type Linker string
+
+const (
+	LinkerBFD  Linker = "bfd"
+	LinkerGold Linker = "gold"
+	LinkerLLD  Linker = "lld"
+	LinkerMold Linker = "mold"
+)
+
+// Valid returns true if the linker is in the allowlist.
+func (l Linker) Valid() bool {
+	switch l {
+	case LinkerBFD, LinkerGold, LinkerLLD, LinkerMold:
+		return true
+	case "": // Empty is valid (compiler default)
+		return true
+	default:
+		return false
+	}
+}
+
+// Libc represents the C library an environment's image is built on.
+type Libc string
+
+const (
+	LibcGlibc Libc = "glibc" // Debian-based images (e.g., golang:1.23-bookworm)
+	LibcMusl  Libc = "musl"  // Alpine-based images (e.g., golang:1.23-alpine)
+)
+
+// Valid returns true if the C library is supported.
+func (l Libc) Valid() bool {
+	switch l {
+	case LibcGlibc, LibcMusl:
+		return true
+	case "": // Empty is valid (environment's default image)
+		return true
+	default:
+		return false
+	}
+}
+
+// Analyzer represents a static analysis tool shipped in an environment's image.
+type Analyzer string
+
+const (
+	AnalyzerClangTidy Analyzer = "clang-tidy"
+	AnalyzerCppcheck  Analyzer = "cppcheck"
+)
+
+// Valid returns true if the analyzer is supported.
+func (a Analyzer) Valid() bool {
+	switch a {
+	case AnalyzerClangTidy, AnalyzerCppcheck:
+		return true
+	case "": // Empty is valid (no analyzer available)
+		return true
+	default:
+		return false
+	}
+}
+
 // JobStatus represents the current status of a compilation job.
 type JobStatus string
 
@@ -154,4 +319,81 @@ const (
 	StatusFailed     JobStatus = "failed"    // Code failed to compile (syntax/linker errors)
 	StatusTimeout    JobStatus = "timeout"   // Compilation timed out
 	StatusError      JobStatus = "error"     // Infrastructure/system error
+	StatusCancelled  JobStatus = "cancelled" // Cancelled before finishing, e.g. superseded by a newer job
+)
+
+// JobStatuses lists every job status.
+var JobStatuses = []JobStatus{
+	StatusQueued, StatusProcessing, StatusCompleted, StatusFailed, StatusTimeout, StatusError, StatusCancelled,
+}
+
+// Valid returns true if the status is a known job status.
+func (s JobStatus) Valid() bool {
+	return slices.Contains(JobStatuses, s)
+}
+
+// Finished reports whether a job in this status is done, i.e. no longer queued or processing.
+func (s JobStatus) Finished() bool {
+	return s != StatusQueued && s != StatusProcessing
+}
+
+// Formatter represents a source formatter shipped in an environment's image.
+type Formatter string
+
+const (
+	FormatterGofmt       Formatter = "gofmt"
+	FormatterClangFormat Formatter = "clang-format"
 )
+
+// Valid returns true if the formatter is supported.
+func (f Formatter) Valid() bool {
+	switch f {
+	case FormatterGofmt, FormatterClangFormat:
+		return true
+	case "": // Empty is valid (no formatter available)
+		return true
+	default:
+		return false
+	}
+}
+
+// SymbolTool represents the tool that lists a binary's symbols in an environment's image.
+type SymbolTool string
+
+const (
+	SymbolToolNm      SymbolTool = "nm"      // GNU/LLVM nm
+	SymbolToolObjdump SymbolTool = "objdump" // objdump -t, for images without nm
+	SymbolToolGoNm    SymbolTool = "go-nm"   // go tool nm, part of the Go toolchain
+)
+
+// Valid returns true if the symbol tool is supported.
+func (t SymbolTool) Valid() bool {
+	switch t {
+	case SymbolToolNm, SymbolToolObjdump, SymbolToolGoNm:
+		return true
+	case "": // Empty is valid (no symbol tool available)
+		return true
+	default:
+		return false
+	}
+}
+
+// OutputEncoding represents the character encoding of the compiler output of an environment's image.
+type OutputEncoding string
+
+const (
+	OutputEncodingUTF8   OutputEncoding = "utf-8"   // Default; invalid sequences are replaced
+	OutputEncodingLatin1 OutputEncoding = "latin-1" // ISO-8859-1, from images with a Latin-1 locale
+)
+
+// Valid returns true if the output encoding is supported.
+func (e OutputEncoding) Valid() bool {
+	switch e {
+	case OutputEncodingUTF8, OutputEncodingLatin1:
+		return true
+	case "": // Empty is valid (defaults to UTF-8)
+		return true
+	default:
+		return false
+	}
+}
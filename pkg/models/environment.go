@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // EnvironmentSpec describes a compilation environment.
 type EnvironmentSpec struct {
 	Language     Language     `json:"language"`
@@ -10,6 +12,62 @@ type EnvironmentSpec struct {
 	OS           OS           `json:"os"`
 	ImageTag     string       `json:"image_tag"` // Docker image tag
 	Flags        []string     `json:"flags,omitempty"`
+	Analyzer     Analyzer     `json:"analyzer,omitempty"`    // Static analyzer in the image (empty if none)
+	Formatter    Formatter    `json:"formatter,omitempty"`   // Source formatter in the image (empty if none)
+	TimeoutEnv   string       `json:"timeout_env,omitempty"` // Variable the image reads the compile timeout from (COMPILE_TIMEOUT if empty)
+	DefaultRun   bool         `json:"default_run,omitempty"` // Run the program after compiling when the request leaves "run" unset
+	GNUTime      bool         `json:"gnu_time,omitempty"`    // The image ships GNU time as /usr/bin/time
+	SymbolTool   SymbolTool   `json:"symbol_tool,omitempty"` // Tool listing the binary's symbols (empty if none)
+
+	OutputEncoding OutputEncoding `json:"output_encoding,omitempty"` // Encoding of the compiler's output (UTF-8 if empty)
+
+	Capabilities Capabilities `json:"capabilities"`
+
+	Libc       Libc            `json:"libc,omitempty"`        // C library of ImageTag (empty if not configured)
+	LibcImages map[Libc]string `json:"libc_images,omitempty"` // Images of the same compiler built on another C library
+
+	CompileScript    string `json:"compile_script,omitempty"`    // Script in the image that runs the compile (empty runs it with sh -c)
+	ToolchainVersion string `json:"toolchain_version,omitempty"` // Exact version the image's compiler must report (empty if not pinned)
+	ActualVersion    string `json:"actual_version,omitempty"`    // Version the image's compiler reported at startup (empty if not probed)
+
+	CPUShares int64         `json:"cpu_shares,omitempty"` // Relative CPU weight of the compile containers (the runtime's default if zero)
+	Timeout   time.Duration `json:"-"`                    // Default compile timeout of the environment (the server's default if zero)
+
+	Toolchains map[string]Toolchain `json:"toolchains,omitempty"` // Sysroots and toolchains selectable by the request "toolchain" option, by name
+}
+
+// Toolchain is a sysroot or cross toolchain baked into an image, e.g. for an embedded board.
+type Toolchain struct {
+	Image   string   `json:"image"`             // Image with the toolchain installed
+	Sysroot string   `json:"sysroot,omitempty"` // Passed to the compiler as --sysroot (empty for none)
+	Flags   []string `json:"flags,omitempty"`   // Extra compiler flags of the toolchain, e.g. "-mcpu=cortex-a53"
+}
+
+// Capabilities describes which request options an environment supports,
+// so clients can disable the ones that would be rejected or ignored.
+type Capabilities struct {
+	Run              bool `json:"run"`               // "run": execute the binary after compiling
+	JSONDiagnostics  bool `json:"json_diagnostics"`  // "diagnostics_format": "json" is produced by the compiler
+	DiagnosticsWidth bool `json:"diagnostics_width"` // "diagnostics_width" is honored
+	Linker           bool `json:"linker"`            // "linker" selects an alternative linker
+	CrossCompile     bool `json:"cross_compile"`     // "target" cross-compiles for another triple
+	Analyze          bool `json:"analyze"`           // "analyze" runs a static analyzer
+	Dependencies     bool `json:"dependencies"`      // "dependencies" reports included headers
+	WerrorFor        bool `json:"werror_for"`        // "werror_for" promotes selected warnings to errors
+	CompileOnly      bool `json:"compile_only"`      // "compile_only" stops after producing an object file
+	DialectOptions   bool `json:"dialect_options"`   // "dialect_options" adds allowlisted dialect flags
+	Test             bool `json:"test"`              // "test" runs the language's test runner
+	CheckFormat      bool `json:"check_format"`      // "check_format" reports formatting differences
+	Quick            bool `json:"quick"`             // "quick" syntax-checks only, for low-latency editor feedback
+	Preprocess       bool `json:"preprocess"`        // "preprocess" returns the preprocessed source
+	ResourceUsage    bool `json:"resource_usage"`    // "resource_usage" reports the compiler's memory and CPU time
+	Symbols          bool `json:"symbols"`           // "symbols" lists the symbols of the produced binary
+	Flags            bool `json:"flags"`             // "flags" adds extra compiler flags such as -Wall
+	HeaderOnly       bool `json:"header_only"`       // "header_only" checks that headers compile when included
+	Deterministic    bool `json:"deterministic"`     // "deterministic" makes the build reproducible
+
+	Libc       []Libc   `json:"libc,omitempty"`       // "libc" values with an image, the default image's first
+	Toolchains []string `json:"toolchains,omitempty"` // "toolchain" values, in name order
 }
 
 // Environment represents a supported compilation environment.
@@ -19,4 +77,7 @@ type Environment struct {
 	Standards []string `json:"standards,omitempty"`
 	OSes      []string `json:"oses"`
 	Arches    []string `json:"architectures"`
+
+	Capabilities  map[string]Capabilities `json:"capabilities,omitempty"`   // Keyed by compiler
+	ActualVersion map[string]string       `json:"actual_version,omitempty"` // Version each compiler's image reported at startup, keyed by compiler
 }
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 // CompilationJob represents a job to be processed.
 type CompilationJob struct {
@@ -10,10 +14,166 @@ type CompilationJob struct {
 	CreatedAt   time.Time          `json:"created_at"`
 	StartedAt   *time.Time         `json:"started_at,omitempty"`
 	CompletedAt *time.Time         `json:"completed_at,omitempty"`
+
+	// LastHeartbeat is refreshed by the worker while the job is processing,
+	// so a restarted instance can tell live jobs from abandoned ones.
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+}
+
+// JobFilter selects a page of jobs: the jobs in a status, newest first, from an offset.
+type JobFilter struct {
+	// Status keeps only jobs in this status (empty keeps all)
+	Status JobStatus
+	// Limit caps the jobs returned (0 = no limit)
+	Limit int
+	// Offset skips this many jobs of the ordered list
+	Offset int
+}
+
+// Page orders jobs newest first, by creation time then ID, and returns the page the filter selects.
+// The jobs must already be narrowed down to the filter's status.
+func (f JobFilter) Page(jobs []CompilationJob) []CompilationJob {
+	slices.SortFunc(jobs, func(a, b CompilationJob) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	if f.Offset >= len(jobs) {
+		return []CompilationJob{}
+	}
+	jobs = jobs[max(f.Offset, 0):]
+	if f.Limit > 0 && f.Limit < len(jobs) {
+		jobs = jobs[:f.Limit]
+	}
+	return jobs
+}
+
+// JobSummary describes a job in a job listing.
+type JobSummary struct {
+	JobID     string    `json:"job_id"`
+	Status    JobStatus `json:"status"`
+	Language  Language  `json:"language"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JobListResponse is a page of the job listing, newest first.
+type JobListResponse struct {
+	Jobs   []JobSummary `json:"jobs"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+	// HasMore is true if jobs follow this page
+	HasMore bool `json:"has_more"`
+}
+
+// BatchCompilationRequest submits several compilation requests at once.
+type BatchCompilationRequest struct {
+	Requests []CompilationRequest `json:"requests"`
+}
+
+// BatchJobResponse is returned when a batch is queued, with one job per request, in order.
+type BatchJobResponse struct {
+	Jobs []JobResponse `json:"jobs"`
+}
+
+// BenchCompilationRequest compiles one request under several standards, to compare their compile times.
+// The request's own standard is ignored.
+type BenchCompilationRequest struct {
+	Request   CompilationRequest `json:"request"`
+	Standards []Standard         `json:"standards"`
+}
+
+// BenchResult is the outcome of a bench's compile under one standard.
+type BenchResult struct {
+	Standard Standard      `json:"standard"`
+	JobID    string        `json:"job_id"`
+	Compiled bool          `json:"compiled"`
+	Duration time.Duration `json:"duration"`
+	Cached   bool          `json:"cached,omitempty"` // Duration is that of an identical earlier compile
+	Error    string        `json:"error,omitempty"`
+}
+
+// BenchResponse lists a bench's compile times, in the order of the requested standards.
+type BenchResponse struct {
+	Results []BenchResult `json:"results"`
+}
+
+// CancelJobsResponse lists the jobs cancelled by label.
+type CancelJobsResponse struct {
+	Label     string   `json:"label"`
+	Cancelled int      `json:"cancelled"`
+	JobIDs    []string `json:"job_ids"`
 }
 
 // JobResponse is returned when a job is created.
 type JobResponse struct {
 	JobID  string    `json:"job_id"`
 	Status JobStatus `json:"status"`
+	// EstimatedWaitMs is how long a newly submitted job is expected to wait for a worker, from the
+	// queue depth and recent compile durations. Only set when the job is accepted; 0 means a worker is free
+	EstimatedWaitMs int64 `json:"estimated_wait_ms,omitempty"`
+	// Request is the request as the server understood it, with defaults filled and without its sources.
+	// Only set when submitted with ?echo=true, to debug client serialization
+	Request *CompilationRequest `json:"request,omitempty"`
+}
+
+// SourceUploadResponse is returned when a chunked upload is created or a chunk is appended.
+type SourceUploadResponse struct {
+	UploadID string `json:"upload_id"`
+	Size     int    `json:"size"` // Bytes received so far
+}
+
+// JobEvent is one status change in a job's timeline.
+type JobEvent struct {
+	Status    JobStatus     `json:"status"`
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  time.Duration `json:"duration,omitempty"` // Time spent in this status until the next event (omitted for the current one)
+}
+
+// JobEventsResponse lists a job's status changes in order.
+type JobEventsResponse struct {
+	JobID  string     `json:"job_id"`
+	Events []JobEvent `json:"events"`
+}
+
+// JobDetails is a job's whole lifecycle in one payload, to save clients a round trip per part.
+type JobDetails struct {
+	Job         CompilationJob     `json:"job"`                   // Request sources are omitted unless SourceIncluded
+	Events      []JobEvent         `json:"events"`                // Status changes, as from the events endpoint
+	Environment *EnvironmentSpec   `json:"environment,omitempty"` // Environment the request resolves to (omitted if it no longer resolves)
+	Result      *CompilationResult `json:"result,omitempty"`      // Omitted until the job finishes
+
+	SourceIncluded bool `json:"source_included"` // Job.Request has its code, archive, files and gist
+}
+
+// JobOutputChunk is a chunk of compiler output streamed while a job compiles.
+type JobOutputChunk struct {
+	Stream string `json:"stream"` // "stdout" or "stderr"
+	Data   string `json:"data"`
+}
+
+// Events returns the job's timeline from its recorded timestamps: queued, processing,
+// and its final status once completed. Timestamps never go backwards, even if the
+// instances that recorded them disagree on the time.
+func (j CompilationJob) Events() []JobEvent {
+	events := []JobEvent{{Status: StatusQueued, Timestamp: j.CreatedAt}}
+
+	appendEvent := func(status JobStatus, timestamp time.Time) {
+		previous := &events[len(events)-1]
+		if timestamp.Before(previous.Timestamp) {
+			timestamp = previous.Timestamp
+		}
+		previous.Duration = timestamp.Sub(previous.Timestamp)
+		events = append(events, JobEvent{Status: status, Timestamp: timestamp})
+	}
+
+	if j.StartedAt != nil {
+		appendEvent(StatusProcessing, *j.StartedAt)
+	}
+	if j.CompletedAt != nil {
+		appendEvent(j.Status, *j.CompletedAt)
+	}
+
+	return events
 }
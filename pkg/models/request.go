@@ -3,34 +3,221 @@ package models
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 )
 
 // Sentinel errors for request validation.
 var (
-	ErrSourceCodeRequired  = errors.New("source code is required")
-	ErrInvalidLanguage     = errors.New("invalid language")
-	ErrInvalidStandard     = errors.New("invalid standard")
-	ErrInvalidArchitecture = errors.New("invalid architecture")
-	ErrInvalidOS           = errors.New("invalid OS")
-	ErrInvalidCompiler     = errors.New("invalid compiler")
+	ErrSourceCodeRequired       = errors.New("source code is required")
+	ErrInvalidLanguage          = errors.New("invalid language")
+	ErrInvalidStandard          = errors.New("invalid standard")
+	ErrInvalidArchitecture      = errors.New("invalid architecture")
+	ErrInvalidOS                = errors.New("invalid OS")
+	ErrInvalidCompiler          = errors.New("invalid compiler")
+	ErrInvalidDiagnosticsFormat = errors.New("invalid diagnostics format")
+	ErrInvalidRunOutputLimit    = errors.New("invalid run output limit")
+	ErrInvalidLinker            = errors.New("invalid linker")
+	ErrLinkerNotSupported       = errors.New("linker option is only supported for C and C++")
+	ErrInvalidTarget            = errors.New("invalid target")
+	ErrTargetNotSupported       = errors.New("target option is only supported with the zig compiler")
+	ErrInvalidDiagnosticsWidth  = errors.New("invalid diagnostics width")
+	ErrAnalyzeNotSupported      = errors.New("static analysis is only supported for C and C++")
+	ErrDependenciesNotSupported = errors.New("dependency listing is only supported for C and C++")
+	ErrCodeAndArchive           = errors.New("code and archive are mutually exclusive")
+	ErrInvalidWarningName       = errors.New("invalid warning name")
+	ErrWerrorForNotSupported    = errors.New("werror_for is only supported for C and C++")
+	ErrCompileOnlyNotSupported  = errors.New("compile_only is only supported for C and C++")
+	ErrCompileOnlyWithRun       = errors.New("compile_only produces no executable to run")
+	ErrInvalidDialectOption     = errors.New("invalid dialect option")
+	ErrDialectNotSupported      = errors.New("dialect options are only supported for C and C++")
+	ErrInvalidGist              = errors.New("invalid gist reference")
+	ErrGistWithSource           = errors.New("gist cannot be combined with code or archive")
+	ErrFilesWithSource          = errors.New("files cannot be combined with code, archive or gist")
+	ErrTestNotSupported         = errors.New("test mode is only supported for Go and Rust")
+	ErrTestWithRun              = errors.New("test mode cannot be combined with run")
+	ErrQuickNotSupported        = errors.New("quick mode is only supported for C and C++")
+	ErrPreprocessNotSupported   = errors.New("preprocess is only supported for C and C++")
+	ErrQuickIncompatible        = errors.New("quick mode cannot be combined with run, test or compile_only")
+	ErrInvalidLibc              = errors.New("invalid libc")
+	ErrSymbolsWithoutBinary     = errors.New("symbols cannot be combined with test, compile_only, quick or header_only")
+	ErrInvalidTimeout           = errors.New("invalid timeout")
+	ErrInvalidToolchain         = errors.New("invalid toolchain")
+	ErrToolchainNotSupported    = errors.New("toolchain option is only supported for C and C++")
+	ErrToolchainWithLibc        = errors.New("toolchain cannot be combined with libc")
+	ErrInvalidFlag              = errors.New("invalid compiler flag")
+	ErrFlagsNotSupported        = errors.New("flags are only supported for C, C++, Go and Rust")
+	ErrInvalidLabel             = errors.New("invalid label")
+	ErrHeaderOnlyNotSupported   = errors.New("header_only is only supported for C and C++")
+	ErrHeaderOnlySource         = errors.New("header_only takes its headers from code or files, not archive or gist")
+	ErrHeaderOnlyIncompatible   = errors.New("header_only cannot be combined with run, test, compile_only or quick")
+	ErrDeterministicUnsupported = errors.New("deterministic builds are only supported for C, C++, Go and Rust")
 )
 
+// MaxFlags caps the number of CompilationRequest.Flags.
+const MaxFlags = 32
+
+// Bounds for CompilationRequest.DiagnosticsWidth (0 means the compiler default).
+const (
+	MinDiagnosticsWidth = 20
+	MaxDiagnosticsWidth = 500
+)
+
+// targetPattern matches a target triple such as "aarch64-linux-musl" or "x86_64-windows-gnu".
+var targetPattern = regexp.MustCompile(`^[a-z0-9_]+(-[a-z0-9_.]+){1,3}$`)
+
+// AllowedDialectOptions is the allowlist for CompilationRequest.DialectOptions: language dialect
+// flags for embedded and kernel code that change what is accepted, not where output goes.
+var AllowedDialectOptions = []string{
+	"-fno-exceptions",
+	"-fno-rtti",
+	"-fno-threadsafe-statics",
+	"-ffreestanding",
+	"-fno-builtin",
+	"-fno-strict-aliasing",
+	"-fno-common",
+	"-fwrapv",
+	"-fsigned-char",
+	"-funsigned-char",
+	"-fshort-enums",
+}
+
+// gistUserPattern matches a GitHub username; gistIDPattern matches a gist ID (hex).
+var (
+	gistUserPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+	gistIDPattern   = regexp.MustCompile(`^[0-9a-f]{1,64}$`)
+)
+
+// warningNamePattern matches a gcc/clang warning name without its -W prefix, such as "return-type" or "c++20-compat".
+var warningNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+-]*$`)
+
+// Allowlists for CompilationRequest.Flags, by compiler driver. A flag must match one of its language's
+// patterns in full; spaces, quotes, shell metacharacters and paths never match, since the flags are part
+// of a shell command. Flags that pass options to the assembler or linker (-Wl, -Wa, -Wp), change the
+// compiler's search paths (-B, -specs=) or load code (-fplugin=, -toolexec=) are deliberately absent.
+var (
+	// cFamilyFlagPatterns: warnings, macros, optimization levels, the standard, diagnostics formatting and
+	// relative include directories for gcc, clang and zig cc.
+	cFamilyFlagPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^-W[a-z0-9][a-z0-9+-]*(=[a-z0-9+-]+)?$`),
+		regexp.MustCompile(`^-pedantic(-errors)?$`),
+		regexp.MustCompile(`^-D[A-Za-z_][A-Za-z0-9_]*(=[A-Za-z0-9_.+-]*)?$`),
+		regexp.MustCompile(`^-U[A-Za-z_][A-Za-z0-9_]*$`),
+		regexp.MustCompile(`^-O([0-3sgz]|fast)?$`),
+		regexp.MustCompile(`^-std=[a-z0-9+]+$`),
+		regexp.MustCompile(`^-f(no-)?(diagnostics-[a-z0-9-]+(=[a-z0-9-]+)?|color-diagnostics)$`),
+		regexp.MustCompile(`^-f(max-errors|message-length)=[0-9]+$`),
+		regexp.MustCompile(`^-I[A-Za-z0-9_][A-Za-z0-9_-]*$`),
+	}
+
+	// rustFlagPatterns: rustc lint levels such as -Dwarnings, -Wunused_results or -Aclippy::all.
+	rustFlagPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^-[DWA][a-z][a-z0-9_]*(::[a-z][a-z0-9_]*)?$`),
+	}
+
+	// goFlagPatterns: go build flags that only change checking or reporting.
+	goFlagPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^-(race|v)$`),
+	}
+
+	// cFamilyPassthroughFlags are -W prefixes that hand the rest of the flag to another tool rather than
+	// naming a warning.
+	cFamilyPassthroughFlags = []string{"-Wl", "-Wa", "-Wp"}
+)
+
+// ToolchainNamePattern matches the name of a configured toolchain, such as "rpi-aarch64" or "sdk_2.1".
+var ToolchainNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// LabelPattern matches a job label, such as "PR-1234" or "nightly.2024-06-01".
+var LabelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
 // CompilationRequest represents an incoming request to compile code.
 type CompilationRequest struct {
-	Code         string       `json:"code"`                   // Base64 encoded source code
-	Language     Language     `json:"language"`               // e.g., "cpp", "go", "rust"
-	Standard     Standard     `json:"standard,omitempty"`     // e.g., "c++20", "c++17"
-	Architecture Architecture `json:"architecture,omitempty"` // e.g., "x86_64", "arm64"
-	OS           OS           `json:"os,omitempty"`           // e.g., "linux"
-	Compiler     Compiler     `json:"compiler,omitempty"`     // e.g., "gcc-13", "clang-15"
+	Code              string            `json:"code"`                         // Base64 encoded source code
+	Archive           string            `json:"archive,omitempty"`            // Base64 encoded tar/tar.gz extracted into the workspace (alternative to code)
+	Gist              string            `json:"gist,omitempty"`               // GitHub gist as "user/id" or https://gist.github.com/user/id (alternative to code)
+	Files             map[string]string `json:"files,omitempty"`              // Base64 encoded sources by relative filename, compiled together (alternative to code)
+	UploadID          string            `json:"upload_id,omitempty"`          // Chunked upload from POST /api/v1/sources, copied into code when the job is queued (alternative to code)
+	Language          Language          `json:"language"`                     // e.g., "cpp", "go", "rust"
+	Standard          Standard          `json:"standard,omitempty"`           // e.g., "c++20", "c++17"
+	Architecture      Architecture      `json:"architecture,omitempty"`       // e.g., "x86_64", "arm64"
+	OS                OS                `json:"os,omitempty"`                 // e.g., "linux"
+	Compiler          Compiler          `json:"compiler,omitempty"`           // e.g., "gcc-13", "clang-15"
+	DiagnosticsFormat DiagnosticsFormat `json:"diagnostics_format,omitempty"` // "text" or "json"
+	Run               *bool             `json:"run,omitempty"`                // Execute the program after a successful compile (unset uses the environment's default_run)
+	RunOutputLimit    int               `json:"run_output_limit,omitempty"`   // Max bytes kept per run stream (stdout/stderr)
+	Linker            Linker            `json:"linker,omitempty"`             // e.g., "lld", "gold" (C/C++ only)
+	Target            string            `json:"target,omitempty"`             // Cross-compilation target triple, e.g., "aarch64-linux-musl" (zig only)
+	DiagnosticsWidth  int               `json:"diagnostics_width,omitempty"`  // Wrap diagnostics at this column (C/C++ only)
+	Analyze           bool              `json:"analyze,omitempty"`            // Run the environment's static analyzer (C/C++ only)
+	Dependencies      bool              `json:"dependencies,omitempty"`       // List included headers via -MM (C/C++ only)
+	WerrorFor         []string          `json:"werror_for,omitempty"`         // Warnings promoted to errors via -Werror=<name>, e.g., "return-type" (C/C++ only)
+	CompileOnly       bool              `json:"compile_only,omitempty"`       // Compile to an object file with -c, skipping the link step (C/C++ only)
+	DialectOptions    []string          `json:"dialect_options,omitempty"`    // Dialect flags from AllowedDialectOptions, e.g., "-fno-exceptions" (C/C++ only)
+	Test              bool              `json:"test,omitempty"`               // Run the test runner (go test / rustc --test / cargo test) instead of a plain build (Go/Rust only)
+	CheckFormat       bool              `json:"check_format,omitempty"`       // Report formatting differences from the environment's formatter (gofmt / clang-format)
+	RequireFormat     bool              `json:"require_format,omitempty"`     // Like check_format, but unformatted code fails the compile
+	Release           bool              `json:"release,omitempty"`            // Optimized release build (-O2 -DNDEBUG, -C opt-level=3, stripped Go binary)
+	Quick             bool              `json:"quick,omitempty"`              // Syntax check only (-fsyntax-only) with a short timeout, returning diagnostics (C/C++ only)
+	Preprocess        bool              `json:"preprocess,omitempty"`         // Return the preprocessed source (-E) to inspect macro expansion (C/C++ only)
+	ResourceUsage     bool              `json:"resource_usage,omitempty"`     // Report the compiler's peak memory and CPU time via /usr/bin/time -v
+	DiagnosticsHTML   bool              `json:"diagnostics_html,omitempty"`   // Render diagnostics as an HTML fragment (implies text diagnostics if no format is set)
+	Libc              Libc              `json:"libc,omitempty"`               // C library of the image: "glibc" or "musl" (unset uses the environment's default image)
+	RunStdoutHTML     bool              `json:"run_stdout_html,omitempty"`    // Render the program's colored stdout as an HTML fragment (run mode only)
+	Symbols           bool              `json:"symbols,omitempty"`            // List the symbols of the produced binary via nm (not with test, compile_only, quick or header_only)
+	TimeoutSeconds    int               `json:"timeout_seconds,omitempty"`    // Compile timeout (0 = the 30s default); clamped to the server's MAX_COMPILE_TIMEOUT
+	Toolchain         string            `json:"toolchain,omitempty"`          // Named sysroot/toolchain of the environment, e.g. "rpi-aarch64" (C/C++ only)
+	Flags             []string          `json:"flags,omitempty"`              // Extra compiler flags, e.g., "-Wall", "-Wextra", "-Werror" (C/C++/Go/Rust; no paths or -o)
+	Label             string            `json:"label,omitempty"`              // Groups jobs for bulk operations, e.g., "PR-1234" to cancel them all once superseded
+	HeaderOnly        bool              `json:"header_only,omitempty"`        // Check that each header compiles on its own when included, from code or files (C/C++ only)
+	Deterministic     bool              `json:"deterministic,omitempty"`      // Reproducible build: fixed timestamps, random seed and source paths (C/C++/Go/Rust)
+}
+
+// RunRequested reports whether the request explicitly asks to run the program.
+func (r *CompilationRequest) RunRequested() bool {
+	return r.Run != nil && *r.Run
+}
+
+// RunExcluded reports whether the request selects a mode that produces no program
+// to run, so an environment's default_run must not apply.
+func (r *CompilationRequest) RunExcluded() bool {
+	return r.Test || r.CompileOnly || r.Quick || r.HeaderOnly
+}
+
+// WithoutSource returns a copy of the request without its sources (code, archive, files and gist).
+func (r CompilationRequest) WithoutSource() CompilationRequest {
+	r.Code = ""
+	r.Archive = ""
+	r.Files = nil
+	r.Gist = ""
+	return r
 }
 
 // Validate validates the compilation request.
 func (r *CompilationRequest) Validate() error {
-	if r.Code == "" {
+	if r.Code == "" && r.Archive == "" && r.Gist == "" && len(r.Files) == 0 {
 		return ErrSourceCodeRequired
 	}
 
+	if len(r.Files) > 0 && (r.Code != "" || r.Archive != "" || r.Gist != "") {
+		return ErrFilesWithSource
+	}
+
+	if r.Code != "" && r.Archive != "" {
+		return ErrCodeAndArchive
+	}
+
+	if r.Gist != "" {
+		if r.Code != "" || r.Archive != "" {
+			return ErrGistWithSource
+		}
+		if _, err := ParseGistID(r.Gist); err != nil {
+			return err
+		}
+	}
+
 	if !r.Language.Valid() {
 		return fmt.Errorf("%w: %s", ErrInvalidLanguage, r.Language)
 	}
@@ -51,5 +238,211 @@ func (r *CompilationRequest) Validate() error {
 		return fmt.Errorf("%w: %s", ErrInvalidCompiler, r.Compiler)
 	}
 
+	if !r.DiagnosticsFormat.Valid() {
+		return fmt.Errorf("%w: %s", ErrInvalidDiagnosticsFormat, r.DiagnosticsFormat)
+	}
+
+	if r.RunOutputLimit < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidRunOutputLimit, r.RunOutputLimit)
+	}
+
+	if r.TimeoutSeconds < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidTimeout, r.TimeoutSeconds)
+	}
+
+	if !r.Libc.Valid() {
+		return fmt.Errorf("%w: %s", ErrInvalidLibc, r.Libc)
+	}
+
+	if r.Label != "" && !LabelPattern.MatchString(r.Label) {
+		return fmt.Errorf("%w: %q", ErrInvalidLabel, r.Label)
+	}
+
+	if r.Toolchain != "" {
+		if !ToolchainNamePattern.MatchString(r.Toolchain) {
+			return fmt.Errorf("%w: %q", ErrInvalidToolchain, r.Toolchain)
+		}
+		if !r.Language.IsCFamily() {
+			return fmt.Errorf("%w: %s", ErrToolchainNotSupported, r.Language)
+		}
+		// Both select the image; a toolchain image comes with its own C library
+		if r.Libc != "" {
+			return ErrToolchainWithLibc
+		}
+	}
+
+	if !r.Linker.Valid() {
+		return fmt.Errorf("%w: %s", ErrInvalidLinker, r.Linker)
+	}
+
+	if r.Linker != "" && !r.Language.SupportsLinker() {
+		return fmt.Errorf("%w: %s", ErrLinkerNotSupported, r.Language)
+	}
+
+	if r.DiagnosticsWidth != 0 && (r.DiagnosticsWidth < MinDiagnosticsWidth || r.DiagnosticsWidth > MaxDiagnosticsWidth) {
+		return fmt.Errorf("%w: %d (must be between %d and %d)", ErrInvalidDiagnosticsWidth,
+			r.DiagnosticsWidth, MinDiagnosticsWidth, MaxDiagnosticsWidth)
+	}
+
+	if r.Analyze && !r.Language.IsCFamily() {
+		return fmt.Errorf("%w: %s", ErrAnalyzeNotSupported, r.Language)
+	}
+
+	if r.Dependencies && !r.Language.IsCFamily() {
+		return fmt.Errorf("%w: %s", ErrDependenciesNotSupported, r.Language)
+	}
+
+	if r.Preprocess && !r.Language.IsCFamily() {
+		return fmt.Errorf("%w: %s", ErrPreprocessNotSupported, r.Language)
+	}
+
+	// Symbols are read from the linked program, which these modes do not produce
+	if r.Symbols && r.RunExcluded() {
+		return ErrSymbolsWithoutBinary
+	}
+
+	if len(r.WerrorFor) > 0 && !r.Language.IsCFamily() {
+		return fmt.Errorf("%w: %s", ErrWerrorForNotSupported, r.Language)
+	}
+
+	for _, name := range r.WerrorFor {
+		if !warningNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: %q", ErrInvalidWarningName, name)
+		}
+	}
+
+	if r.CompileOnly {
+		if !r.Language.IsCFamily() {
+			return fmt.Errorf("%w: %s", ErrCompileOnlyNotSupported, r.Language)
+		}
+		if r.RunRequested() {
+			return ErrCompileOnlyWithRun
+		}
+	}
+
+	if err := r.validateFlags(); err != nil {
+		return err
+	}
+
+	if len(r.DialectOptions) > 0 && !r.Language.IsCFamily() {
+		return fmt.Errorf("%w: %s", ErrDialectNotSupported, r.Language)
+	}
+
+	for _, option := range r.DialectOptions {
+		if !slices.Contains(AllowedDialectOptions, option) {
+			return fmt.Errorf("%w: %q", ErrInvalidDialectOption, option)
+		}
+	}
+
+	if r.Test {
+		if !r.Language.SupportsTests() {
+			return fmt.Errorf("%w: %s", ErrTestNotSupported, r.Language)
+		}
+		if r.RunRequested() {
+			return ErrTestWithRun
+		}
+	}
+
+	if r.Quick {
+		if !r.Language.IsCFamily() {
+			return fmt.Errorf("%w: %s", ErrQuickNotSupported, r.Language)
+		}
+		if r.RunRequested() || r.Test || r.CompileOnly {
+			return ErrQuickIncompatible
+		}
+	}
+
+	if r.HeaderOnly {
+		if !r.Language.IsCFamily() {
+			return fmt.Errorf("%w: %s", ErrHeaderOnlyNotSupported, r.Language)
+		}
+		if r.Archive != "" || r.Gist != "" {
+			return ErrHeaderOnlySource
+		}
+		if r.RunRequested() || r.Test || r.CompileOnly || r.Quick {
+			return ErrHeaderOnlyIncompatible
+		}
+	}
+
+	if r.Deterministic && !r.Language.SupportsDeterministic() {
+		return fmt.Errorf("%w: %s", ErrDeterministicUnsupported, r.Language)
+	}
+
+	if r.Target != "" {
+		if !targetPattern.MatchString(r.Target) {
+			return fmt.Errorf("%w: %s", ErrInvalidTarget, r.Target)
+		}
+		if !r.Compiler.IsZig() {
+			return fmt.Errorf("%w: %s", ErrTargetNotSupported, r.Compiler)
+		}
+	}
+
+	return nil
+}
+
+// ParseGistID returns the ID of a gist referenced as "user/id" or as a https://gist.github.com/user/id URL.
+// Only the ID is used to resolve the gist, so the reference can never point the server at another host.
+func ParseGistID(ref string) (string, error) {
+	path := ref
+	if strings.Contains(ref, "://") {
+		u, err := url.Parse(ref)
+		if err != nil || u.Scheme != "https" || u.Host != "gist.github.com" || u.User != nil {
+			return "", fmt.Errorf("%w: %s", ErrInvalidGist, ref)
+		}
+		path = strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), "/")
+	}
+
+	user, id, found := strings.Cut(path, "/")
+	if !found || !gistUserPattern.MatchString(user) || !gistIDPattern.MatchString(id) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidGist, ref)
+	}
+	return id, nil
+}
+
+// validateFlags checks Flags: each must be a single flag without a path, may not choose the output
+// file, which the compile command fixes, and must be on its language's allowlist.
+func (r *CompilationRequest) validateFlags() error {
+	if len(r.Flags) == 0 {
+		return nil
+	}
+	if !r.Language.SupportsFlags() {
+		return fmt.Errorf("%w: %s", ErrFlagsNotSupported, r.Language)
+	}
+	if len(r.Flags) > MaxFlags {
+		return fmt.Errorf("%w: at most %d flags are allowed, got %d", ErrInvalidFlag, MaxFlags, len(r.Flags))
+	}
+
+	for _, flag := range r.Flags {
+		switch {
+		case strings.Contains(flag, "/"):
+			return fmt.Errorf("%w %q: paths are not allowed", ErrInvalidFlag, flag)
+		case strings.HasPrefix(flag, "-o"):
+			return fmt.Errorf("%w %q: the output file cannot be changed", ErrInvalidFlag, flag)
+		case !flagAllowed(r.Language, flag):
+			return fmt.Errorf("%w %q: not an allowed flag for %s", ErrInvalidFlag, flag, r.Language)
+		}
+	}
 	return nil
 }
+
+// flagAllowed reports whether flag is on the allowlist of the language's compiler driver.
+func flagAllowed(language Language, flag string) bool {
+	var patterns []*regexp.Regexp
+	switch {
+	case language.IsCFamily():
+		// -Wall and -Waddress are warnings; -Wa and -Wa,... are not
+		passthrough, _, _ := strings.Cut(flag, ",")
+		if slices.Contains(cFamilyPassthroughFlags, passthrough) {
+			return false
+		}
+		patterns = cFamilyFlagPatterns
+	case language == LanguageRust:
+		patterns = rustFlagPatterns
+	case language == LanguageGo:
+		patterns = goFlagPatterns
+	}
+
+	return slices.ContainsFunc(patterns, func(pattern *regexp.Regexp) bool {
+		return pattern.MatchString(flag)
+	})
+}
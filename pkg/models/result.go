@@ -4,12 +4,119 @@ import "time"
 
 // CompilationResult represents the result of a compilation.
 type CompilationResult struct {
-	JobID    string        `json:"job_id"`
-	Success  bool          `json:"success"`
-	Compiled bool          `json:"compiled"` // Whether it compiled successfully
-	Stdout   string        `json:"stdout"`
-	Stderr   string        `json:"stderr"`
-	ExitCode int           `json:"exit_code"`
-	Duration time.Duration `json:"duration"`
-	Error    string        `json:"error,omitempty"`
+	JobID       string        `json:"job_id"`
+	Success     bool          `json:"success"`
+	Compiled    bool          `json:"compiled"` // Whether it compiled successfully
+	Stdout      string        `json:"stdout"`
+	Stderr      string        `json:"stderr"`
+	ExitCode    int           `json:"exit_code"`
+	Duration    time.Duration `json:"duration"`
+	Error       string        `json:"error,omitempty"`
+	TimedOut    bool          `json:"timed_out,omitempty"`   // The compile hit its timeout (or idle timeout); Error explains it
+	Cancelled   bool          `json:"cancelled,omitempty"`   // The job was cancelled before it finished; Error explains it
+	Diagnostics []Diagnostic  `json:"diagnostics,omitempty"` // Structured compiler messages (when requested)
+	Errors      []string      `json:"errors,omitempty"`      // Error diagnostics as "file:line:col: message" (when diagnostics are requested)
+	Warnings    []string      `json:"warnings,omitempty"`    // Warning diagnostics, formatted like Errors
+	Notes       []string      `json:"notes,omitempty"`       // Note diagnostics, formatted like Errors
+	Ran         bool          `json:"ran,omitempty"`         // Whether the program was executed (run mode)
+	RunStdout   string        `json:"run_stdout,omitempty"`
+	RunStderr   string        `json:"run_stderr,omitempty"`
+	RunExitCode int           `json:"run_exit_code,omitempty"`
+	RunTimedOut bool          `json:"run_timed_out,omitempty"` // Program exceeded the run timeout (distinct from compile timeout)
+	BinaryBytes int64         `json:"binary_bytes,omitempty"`  // Size of the produced binary (omitted when none was produced)
+	Analysis    []Diagnostic  `json:"analysis,omitempty"`      // Static analyzer findings (when requested), separate from Diagnostics
+	Cached      bool          `json:"cached,omitempty"`        // Served from the result cache of an identical earlier request
+	CacheAge    time.Duration `json:"cache_age,omitempty"`     // Time since the cached result was produced
+	Includes    []string      `json:"includes,omitempty"`      // Headers included by the source, from -MM (when requested)
+	SourceHash  string        `json:"source_hash,omitempty"`   // SHA-256 of the decoded source (or archive), hex-encoded
+	OutputHash  string        `json:"output_hash,omitempty"`   // SHA-256 of the produced binary, when the runtime can read it back
+	Tested      bool          `json:"tested,omitempty"`        // Whether the test runner ran (test mode)
+	TestsPassed int           `json:"tests_passed,omitempty"`  // Tests that passed (test mode)
+	TestsFailed int           `json:"tests_failed,omitempty"`  // Tests that failed (test mode)
+	FormatDiff  string        `json:"format_diff,omitempty"`   // Formatter output for unformatted code (check_format); empty when formatted
+	// Preprocessed is the -E output of the sources (preprocess), for inspecting macro expansion.
+	// PreprocessedTruncated reports that it was cut off at the runtime's artifact size limit.
+	Preprocessed          string `json:"preprocessed,omitempty"`
+	PreprocessedTruncated bool   `json:"preprocessed_truncated,omitempty"`
+	// TerminatedBySignal names the signal that killed the compiler (e.g., "SIGKILL" on timeout or OOM).
+	// Empty on a normal exit, so an exit code of 137 without it is a genuine return value.
+	TerminatedBySignal string `json:"terminated_by_signal,omitempty"`
+	// ResourceUsage is the compiler's resource usage as reported by /usr/bin/time (resource_usage).
+	ResourceUsage *ResourceUsage `json:"resource_usage,omitempty"`
+	// DiagnosticsHTML is Diagnostics rendered as an escaped HTML fragment with severity classes (diagnostics_html).
+	DiagnosticsHTML string `json:"diagnostics_html,omitempty"`
+	// RunStdoutHTML is RunStdout with its ANSI colors rendered as styled spans (run_stdout_html); RunStdout itself
+	// is plain text.
+	RunStdoutHTML string `json:"run_stdout_html,omitempty"`
+	// RunSuccess reports that the program ran, exited 0 and did not time out. Compiled, ExitCode and Status only
+	// describe the compile step, so a program that fails at run time leaves the job completed.
+	RunSuccess bool `json:"run_success,omitempty"`
+	// RunError explains a program failure that its exit code alone does not (e.g., killed by the sandbox).
+	RunError string `json:"run_error,omitempty"`
+	// Symbols lists the names of the produced binary's symbols, defined and undefined, demangled (symbols).
+	// SymbolsTruncated reports that the list was cut off at the symbol limit.
+	Symbols          []string `json:"symbols,omitempty"`
+	SymbolsTruncated bool     `json:"symbols_truncated,omitempty"`
+	// NetworkEnabled reports that the compile container could reach the network, as applied by the runtime,
+	// e.g. to tell a failed module download apart from a sandbox without network.
+	NetworkEnabled bool `json:"network_enabled"`
+}
+
+// ResourceUsage is the resource usage of the compile command, covering the compiler and its child processes.
+type ResourceUsage struct {
+	MaxRSSKB      int64   `json:"max_rss_kb"`     // Peak resident set size in kilobytes
+	UserSeconds   float64 `json:"user_seconds"`   // CPU time spent in user mode
+	SystemSeconds float64 `json:"system_seconds"` // CPU time spent in the kernel
+}
+
+// storedOutputTruncatedNotice is appended to output streams cut by TruncateOutput.
+const storedOutputTruncatedNotice = "\n... (output truncated for storage)"
+
+// TruncateOutput caps each output stream (compile, run and preprocessed source) at maxBytes, appending a notice
+// to the streams that were cut. Cutting HTML would leave unbalanced tags, so an oversized RunStdoutHTML is dropped
+// instead. A non-positive maxBytes leaves the result unchanged.
+func (r *CompilationResult) TruncateOutput(maxBytes int) {
+	if maxBytes <= 0 {
+		return
+	}
+	for _, stream := range []*string{&r.Stdout, &r.Stderr, &r.RunStdout, &r.RunStderr, &r.Preprocessed} {
+		if len(*stream) > maxBytes {
+			*stream = (*stream)[:maxBytes] + storedOutputTruncatedNotice
+		}
+	}
+	if len(r.RunStdoutHTML) > maxBytes {
+		r.RunStdoutHTML = ""
+	}
+}
+
+// Status determines the final job status of the result.
+// Status meanings:
+//   - StatusCompleted: code compiled successfully (exit code 0), however the program then ran (see RunSuccess)
+//   - StatusFailed: code failed to compile (syntax/linker errors) - user's fault
+//   - StatusTimeout: compilation timed out - could be user's code (infinite template) or system
+//   - StatusError: infrastructure/system error - our fault
+//   - StatusCancelled: cancelled on request before it finished
+func (r *CompilationResult) Status() JobStatus {
+	if r.Cancelled {
+		return StatusCancelled
+	}
+
+	// Check for timeout first; its Error is a message, not an infrastructure failure
+	if r.TimedOut {
+		return StatusTimeout
+	}
+
+	// Any other error is an infrastructure error (runtime failures, validation errors),
+	// e.g., "compilation failed: ...", "invalid base64 encoding"
+	if r.Error != "" {
+		return StatusError
+	}
+
+	// No error - check if code actually compiled
+	if r.Compiled {
+		return StatusCompleted
+	}
+
+	// Code didn't compile (syntax errors, linker errors, etc.) - user's code issue
+	return StatusFailed
 }
@@ -18,6 +18,14 @@ type CompilationRuntime interface {
 	Close() error
 }
 
+// LatencyReporter is implemented by runtimes that periodically probe their backend
+// (e.g., the Docker daemon), so a degrading backend shows up before compiles slow down.
+type LatencyReporter interface {
+	// PingLatency returns the duration of the most recent successful probe,
+	// or false if no probe has succeeded yet or the last one failed
+	PingLatency() (time.Duration, bool)
+}
+
 // CompilationConfig holds configuration for a compilation job.
 type CompilationConfig struct {
 	// JobID is a unique identifier for this compilation
@@ -29,6 +37,10 @@ type CompilationConfig struct {
 	// SourceCode is the actual source code to compile
 	SourceCode string
 
+	// Files holds the workspace files by relative path (e.g., "src/util.h") for multi-file
+	// submissions such as archives. When set, it replaces SourceCode and SourceFilename
+	Files map[string]string
+
 	// SourceFilename is the name of the source file (e.g., "source.cpp", "source.go", "source.rs")
 	// Defaults to "source.cpp" if not specified
 	SourceFilename string
@@ -37,6 +49,11 @@ type CompilationConfig struct {
 	// If empty, a default will be used based on the image
 	CompileCommand string
 
+	// CompileScript is a script baked into the image (e.g., "/usr/bin/compile.sh") that runs the
+	// compile in place of sh -c, receiving the command in CompileCommandEnv. Empty runs the command
+	// with sh -c, so stock images such as gcc:13 need no script
+	CompileScript string
+
 	// Env is a list of environment variables in "KEY=VALUE" format
 	Env []string
 
@@ -45,6 +62,73 @@ type CompilationConfig struct {
 
 	// Timeout is the maximum time allowed for compilation
 	Timeout time.Duration
+
+	// RunCommand executes the compiled program after a successful compile (run mode)
+	// If empty, only the compile step is performed
+	RunCommand string
+
+	// RunTimeout is the maximum time the program may run, separate from Timeout
+	RunTimeout time.Duration
+
+	// MaxRunOutputSize caps each of the program's stdout and stderr, separately from compile output
+	MaxRunOutputSize int
+
+	// OutputPath is the binary produced by the compile command (e.g., "/workspace/output")
+	// If set, its size is reported after compilation; leave empty for languages that produce no binary
+	OutputPath string
+
+	// ArtifactPath is a text file the compile command writes (e.g., "/workspace/output.i") that
+	// is read back after the container exits, up to MaxArtifactSize. Runtimes that cannot read
+	// files from the workspace leave CompilationOutput.Artifact empty
+	ArtifactPath string
+
+	// TmpSize is the size of the in-memory /tmp in bytes, where compilers write intermediates
+	// Defaults to DefaultTmpSize if zero
+	TmpSize int64
+
+	// CPUShares is the relative CPU weight of the container under contention; zero leaves the
+	// runtime's default. Runtimes that schedule by resource requests (e.g., Kubernetes) ignore it
+	CPUShares int64
+
+	// IdleTimeout kills the compilation early if it writes no output for this long, so a stalled
+	// compile doesn't hold a container for the whole Timeout. Disabled if zero; runtimes that
+	// cannot follow output while the container runs ignore it
+	IdleTimeout time.Duration
+
+	// PreserveANSI keeps escape sequences (e.g. colors) in the output instead of stripping them,
+	// leaving it to the caller to strip or render them
+	PreserveANSI bool
+
+	// OnOutput, if set, receives the output in chunks as the container writes it, so clients can
+	// follow a long compile; the full output is still returned in CompilationOutput. Runtimes that
+	// cannot follow output while the container runs never call it
+	OnOutput OutputFunc
+}
+
+// OutputFunc receives a chunk of a compilation's output as it is written, from StreamStdout or StreamStderr.
+type OutputFunc func(stream, chunk string)
+
+// Streams passed to an OutputFunc.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// CompileCommandEnv is the variable that passes the compile command to a CompilationConfig.CompileScript.
+const CompileCommandEnv = "COMPILE_COMMAND"
+
+// DefaultTmpSize is the size of the in-memory /tmp when CompilationConfig.TmpSize is not set.
+const DefaultTmpSize = 64 * 1024 * 1024
+
+// MaxArtifactSize caps the artifact read back from CompilationConfig.ArtifactPath (4MB).
+const MaxArtifactSize = 4 * 1024 * 1024
+
+// TmpSizeOrDefault returns the configured /tmp size, or DefaultTmpSize if none is set.
+func (c CompilationConfig) TmpSizeOrDefault() int64 {
+	if c.TmpSize > 0 {
+		return c.TmpSize
+	}
+	return DefaultTmpSize
 }
 
 // CompilationOutput holds the result of a compilation.
@@ -63,4 +147,44 @@ type CompilationOutput struct {
 
 	// TimedOut indicates if the compilation exceeded the timeout
 	TimedOut bool
+
+	// IdleTimedOut indicates the compilation was killed after IdleTimeout without output
+	IdleTimedOut bool
+
+	// Ran indicates the program was executed after compiling (run mode)
+	Ran bool
+
+	// RunStdout is the standard output of the program
+	RunStdout string
+
+	// RunStderr is the standard error of the program
+	RunStderr string
+
+	// RunExitCode is the exit code of the program
+	RunExitCode int
+
+	// RunTimedOut indicates if the program exceeded the run timeout
+	RunTimedOut bool
+
+	// BinaryBytes is the size of the file at OutputPath, or 0 if it was not produced
+	BinaryBytes int64
+
+	// BinarySHA256 is the hex-encoded SHA-256 of the file at OutputPath, or empty if it
+	// was not produced or the runtime cannot read it back
+	BinarySHA256 string
+
+	// Artifact is the content of the file at ArtifactPath, or empty if it was not produced
+	Artifact string
+
+	// ArtifactTruncated indicates the artifact was cut off at MaxArtifactSize
+	ArtifactTruncated bool
+
+	// TerminatedBySignal names the signal that killed the container (e.g., "SIGKILL"
+	// on timeout or OOM), or is empty if it exited on its own. An ExitCode of 137
+	// without it means the process itself returned 137.
+	TerminatedBySignal string
+
+	// NetworkEnabled indicates the container could reach the network. Runtimes report the
+	// isolation they actually applied, not what was asked for
+	NetworkEnabled bool
 }
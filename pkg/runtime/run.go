@@ -0,0 +1,84 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// RunMarker is written to stdout and stderr between the compile step and the run step,
+	// so the combined container output can be split back into compiler and program output.
+	RunMarker = "=== will-it-compile: run ==="
+
+	// RunTimeoutExitCode is the exit code of timeout(1) when the program exceeded its time limit.
+	RunTimeoutExitCode = 124
+
+	// DefaultRunTimeout is used when the run step has no explicit timeout.
+	DefaultRunTimeout = 10 * time.Second
+
+	// DefaultMaxRunOutputSize caps each run stream when no explicit limit is set (64KB).
+	DefaultMaxRunOutputSize = 64 * 1024
+
+	truncatedSuffix = "\n... (output truncated)"
+)
+
+// WithRunStep returns the shell command for the container.
+// Without a RunCommand this is just the compile command; otherwise the program is run
+// under its own timeout after a successful compile, preceded by RunMarker on both streams.
+func WithRunStep(config CompilationConfig) string {
+	if config.RunCommand == "" {
+		return config.CompileCommand
+	}
+
+	timeout := config.RunTimeout
+	if timeout <= 0 {
+		timeout = DefaultRunTimeout
+	}
+	seconds := int(math.Ceil(timeout.Seconds()))
+
+	return fmt.Sprintf("%s && { echo '%s'; echo '%s' >&2; timeout %d %s; }",
+		config.CompileCommand, RunMarker, RunMarker, seconds, config.RunCommand)
+}
+
+// SplitRunOutput separates run output from compile output in place.
+// If the marker is absent the program never ran (compile failed or run mode was off)
+// and the output is left untouched. Run streams are truncated to maxRunOutputSize each.
+func SplitRunOutput(output *CompilationOutput, maxRunOutputSize int) {
+	compileStdout, runStdout, foundStdout := strings.Cut(output.Stdout, RunMarker+"\n")
+	compileStderr, runStderr, foundStderr := strings.Cut(output.Stderr, RunMarker+"\n")
+	if !foundStdout && !foundStderr {
+		return
+	}
+
+	// Runtimes that merge both streams into one log see the marker twice in a row
+	runStdout = strings.TrimPrefix(runStdout, RunMarker+"\n")
+
+	if maxRunOutputSize <= 0 {
+		maxRunOutputSize = DefaultMaxRunOutputSize
+	}
+
+	output.Ran = true
+	output.Stdout = compileStdout
+	output.Stderr = compileStderr
+	output.RunStdout = truncateOutput(runStdout, maxRunOutputSize)
+	output.RunStderr = truncateOutput(runStderr, maxRunOutputSize)
+
+	// The container exit code belongs to the program; reaching the run step means the compile succeeded
+	output.RunExitCode = output.ExitCode
+	output.ExitCode = 0
+
+	// A container-level or idle timeout during the run step is the program's fault, not the compiler's
+	output.RunTimedOut = output.RunExitCode == RunTimeoutExitCode || output.TimedOut || output.IdleTimedOut
+	output.TimedOut = false
+	output.IdleTimedOut = false
+}
+
+// truncateOutput cuts s to at most limit bytes, marking that it was truncated.
+func truncateOutput(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return s[:limit] + truncatedSuffix
+}
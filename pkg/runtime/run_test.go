@@ -0,0 +1,117 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWithRunStep tests the container command with and without run mode.
+func TestWithRunStep(t *testing.T) {
+	compileOnly := CompilationConfig{CompileCommand: "g++ /workspace/source.cpp -o /workspace/output"}
+	assert.Equal(t, compileOnly.CompileCommand, WithRunStep(compileOnly))
+
+	withRun := CompilationConfig{
+		CompileCommand: "g++ /workspace/source.cpp -o /workspace/output",
+		RunCommand:     "/workspace/output",
+		RunTimeout:     5 * time.Second,
+	}
+	command := WithRunStep(withRun)
+	assert.True(t, strings.HasPrefix(command, withRun.CompileCommand+" && "), "Run step should only follow a successful compile")
+	assert.Contains(t, command, "timeout 5 /workspace/output")
+	assert.Equal(t, 2, strings.Count(command, RunMarker), "Marker should be written to both streams")
+}
+
+// TestSplitRunOutput_NotRun tests that output without the marker is left untouched.
+func TestSplitRunOutput_NotRun(t *testing.T) {
+	output := &CompilationOutput{
+		Stderr:   "source.cpp:1:1: error: expected ';'",
+		ExitCode: 1,
+	}
+
+	SplitRunOutput(output, 10)
+
+	assert.False(t, output.Ran)
+	assert.Equal(t, 1, output.ExitCode)
+	assert.Equal(t, "source.cpp:1:1: error: expected ';'", output.Stderr)
+	assert.Empty(t, output.RunStdout)
+}
+
+// TestSplitRunOutput_IndependentTruncation tests that a chatty program can't crowd out compile output.
+func TestSplitRunOutput_IndependentTruncation(t *testing.T) {
+	output := &CompilationOutput{
+		Stdout:   RunMarker + "\n" + strings.Repeat("x", 100),
+		Stderr:   "source.cpp:2:9: warning: unused variable 'x'\n" + RunMarker + "\n" + strings.Repeat("e", 100),
+		ExitCode: 0,
+	}
+
+	SplitRunOutput(output, 10)
+
+	assert.True(t, output.Ran)
+	assert.Equal(t, "source.cpp:2:9: warning: unused variable 'x'\n", output.Stderr, "Compile diagnostics should be kept in full")
+	assert.Equal(t, strings.Repeat("x", 10)+truncatedSuffix, output.RunStdout)
+	assert.Equal(t, strings.Repeat("e", 10)+truncatedSuffix, output.RunStderr)
+}
+
+// TestSplitRunOutput_ExitCodes tests that exit codes and timeouts are attributed to the program.
+func TestSplitRunOutput_ExitCodes(t *testing.T) {
+	testCases := []struct {
+		name             string
+		exitCode         int
+		containerTimeout bool
+		expectExitCode   int
+		expectTimedOut   bool
+	}{
+		{"program_succeeded", 0, false, 0, false},
+		{"program_failed", 3, false, 3, false},
+		{"run_timeout", RunTimeoutExitCode, false, RunTimeoutExitCode, true},
+		{"container_timeout_during_run", 137, true, 137, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := &CompilationOutput{
+				Stdout:   RunMarker + "\nhello\n",
+				ExitCode: tc.exitCode,
+				TimedOut: tc.containerTimeout,
+			}
+
+			SplitRunOutput(output, 0)
+
+			assert.True(t, output.Ran)
+			assert.Equal(t, 0, output.ExitCode, "Compile step succeeded")
+			assert.False(t, output.TimedOut, "Compile timeout flag should not be set")
+			assert.Equal(t, tc.expectExitCode, output.RunExitCode)
+			assert.Equal(t, tc.expectTimedOut, output.RunTimedOut)
+		})
+	}
+}
+
+// TestSplitRunOutput_IdleTimeoutDuringRun tests that a program going silent is a run timeout, not a compile one.
+func TestSplitRunOutput_IdleTimeoutDuringRun(t *testing.T) {
+	output := &CompilationOutput{
+		Stdout:       RunMarker + "\n",
+		ExitCode:     137,
+		IdleTimedOut: true,
+	}
+
+	SplitRunOutput(output, 0)
+
+	assert.Equal(t, 0, output.ExitCode)
+	assert.False(t, output.IdleTimedOut)
+	assert.True(t, output.RunTimedOut)
+}
+
+// TestSplitRunOutput_MergedStreams tests runtimes that merge stdout and stderr into one log.
+func TestSplitRunOutput_MergedStreams(t *testing.T) {
+	output := &CompilationOutput{
+		Stdout: "compiler says hi\n" + RunMarker + "\n" + RunMarker + "\nhello\n",
+	}
+
+	SplitRunOutput(output, 0)
+
+	assert.Equal(t, "compiler says hi\n", output.Stdout)
+	assert.Equal(t, "hello\n", output.RunStdout)
+}
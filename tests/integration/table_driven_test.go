@@ -32,6 +32,7 @@ func TestCompilationScenarios(t *testing.T) {
 		expectSuccess  bool
 		expectedExit   int
 		checkStderr    bool // true if we expect stderr to have content
+		compileOnly    bool
 	}{
 		{
 			name: "valid_hello_world",
@@ -148,6 +149,29 @@ int main() {
 			expectedExit:   0,
 			checkStderr:    false,
 		},
+		{
+			name:           "library_without_main",
+			sourceCode:     "int add(int a, int b) { return a + b; }",
+			language:       models.LanguageCpp,
+			compiler:       models.CompilerGCC9,
+			standard:       models.StandardCpp11,
+			expectCompiled: false, // The linker finds no main
+			expectSuccess:  true,
+			expectedExit:   1,
+			checkStderr:    true,
+		},
+		{
+			name:           "library_without_main_compile_only",
+			sourceCode:     "int add(int a, int b) { return a + b; }",
+			language:       models.LanguageCpp,
+			compiler:       models.CompilerGCC9,
+			standard:       models.StandardCpp11,
+			expectCompiled: true,
+			expectSuccess:  true,
+			expectedExit:   0,
+			checkStderr:    false,
+			compileOnly:    true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -167,10 +191,11 @@ int main() {
 
 			// Create compilation request
 			request := models.CompilationRequest{
-				Code:     encodedCode,
-				Language: tc.language,
-				Compiler: tc.compiler,
-				Standard: tc.standard,
+				Code:        encodedCode,
+				Language:    tc.language,
+				Compiler:    tc.compiler,
+				Standard:    tc.standard,
+				CompileOnly: tc.compileOnly,
 			}
 
 			body, err := json.Marshal(request)
@@ -250,7 +275,8 @@ func TestRequestValidation(t *testing.T) {
 		{
 			name: "code_too_large",
 			request: models.CompilationRequest{
-				Code:     base64.StdEncoding.EncodeToString(make([]byte, 2*1024*1024)), // 2MB
+				// Over the compiler's limit, but under the HTTP body limit (which would reject with 413)
+				Code:     base64.StdEncoding.EncodeToString(make([]byte, 1600*1024)),
 				Language: models.LanguageCpp,
 				Compiler: models.CompilerGCC9,
 				Standard: models.StandardCpp11,
@@ -1,8 +1,8 @@
 // TypeScript types matching Go backend models in pkg/models/
 
 // Enums
-export type Language = 'c' | 'cpp' | 'c++' | 'go' | 'rust'
-export type Compiler = 'gcc' | 'go' | 'rustc'
+export type Language = 'c' | 'cpp' | 'c++' | 'go' | 'rust' | 'objc' | 'objcpp' | 'swift' | 'kotlin'
+export type Compiler = 'gcc' | 'go' | 'rustc' | 'clang' | 'swiftc' | 'kotlinc'
 export type CompilerVersion = string // e.g., "13", "1.23", "1.80"
 export type Standard =
   // C++ standards
@@ -21,6 +21,11 @@ export type Standard =
   | ''
 export type Architecture = 'x86_64' | 'arm64' | 'arm' | ''
 export type OS = 'linux' | 'windows' | 'macos' | ''
+export type DiagnosticsFormat = 'text' | 'json' | ''
+export type Linker = 'bfd' | 'gold' | 'lld' | 'mold' | ''
+export type Libc = 'glibc' | 'musl' | ''
+export type Analyzer = 'clang-tidy' | 'cppcheck'
+export type Formatter = 'gofmt' | 'clang-format'
 export type JobStatus =
   | 'queued'
   | 'processing'
@@ -32,11 +37,48 @@ export type JobStatus =
 // CompilationRequest represents an incoming request to compile code
 export interface CompilationRequest {
   code: string // Base64 encoded source code
+  archive?: string // Base64 encoded tar/tar.gz extracted into the workspace (alternative to code)
+  gist?: string // GitHub gist as "user/id" or https://gist.github.com/user/id (alternative to code)
+  upload_id?: string // Chunked upload from POST /api/v1/sources (alternative to code)
   language: Language // e.g., "cpp", "go", "rust"
   standard?: Standard // e.g., "c++20", "c++17"
   architecture?: Architecture // e.g., "x86_64", "arm64"
   os?: OS // e.g., "linux"
-  compiler?: string // e.g., "gcc-13", "go-1.23", "rustc-1.80"
+  compiler?: string // e.g., "gcc-13", "go-1.23", "rustc-1.80", "zig-0.13"
+  diagnostics_format?: DiagnosticsFormat // "text" or "json"
+  run?: boolean // Execute the program after a successful compile (omitted: the environment's default_run)
+  run_output_limit?: number // Max bytes kept per run stream (stdout/stderr)
+  linker?: Linker // Alternative linker (C/C++ only)
+  target?: string // Cross-compilation target triple, e.g. "aarch64-linux-musl" (zig only)
+  diagnostics_width?: number // Wrap diagnostics at this column (C/C++ only, 20-500)
+  analyze?: boolean // Run the environment's static analyzer (C/C++ only)
+  dependencies?: boolean // List included headers via -MM (C/C++ only)
+  werror_for?: string[] // Warnings promoted to errors via -Werror=<name> (C/C++ only)
+  compile_only?: boolean // Compile to an object file without linking (C/C++ only)
+  dialect_options?: string[] // Allowlisted dialect flags, e.g. -fno-exceptions (C/C++ only)
+  test?: boolean // Run the test runner instead of a plain build (Go/Rust only)
+  release?: boolean // Optimized release build (-O2 -DNDEBUG, -C opt-level=3, stripped Go binary)
+  check_format?: boolean // Report formatting differences in format_diff
+  quick?: boolean // Syntax check only, returning diagnostics (C/C++ only)
+  preprocess?: boolean // Return the preprocessed source (C/C++ only)
+  resource_usage?: boolean // Report the compiler's peak memory and CPU time (images with GNU time)
+  diagnostics_html?: boolean // Render diagnostics as an HTML fragment in diagnostics_html
+  require_format?: boolean // Like check_format, but unformatted code fails the compile
+  libc?: Libc // C library of the image (omitted: the environment's default image)
+  run_stdout_html?: boolean // Render the program's colored stdout as HTML in run_stdout_html (run mode)
+  symbols?: boolean // List the binary's symbols in symbols (not with test, compile_only or quick)
+  timeout_seconds?: number // Compile timeout (default 30s), clamped to the server's ceiling
+  toolchain?: string // Named sysroot/toolchain of the environment (C/C++ only; not with libc)
+  flags?: string[] // Extra compiler flags, e.g. "-Wall" (C/C++/Go/Rust; no paths or -o)
+}
+
+// Diagnostic is a single structured compiler message
+export interface Diagnostic {
+  file?: string
+  line?: number
+  column?: number
+  severity: string // e.g., "error", "warning", "note"
+  message: string
 }
 
 // CompilationResult represents the result of a compilation
@@ -49,6 +91,37 @@ export interface CompilationResult {
   exit_code: number
   duration: number // Duration in nanoseconds (converted from time.Duration)
   error?: string
+  diagnostics?: Diagnostic[] // Structured compiler messages (when requested)
+  errors?: string[] // Error diagnostics as "file:line:col: message"
+  warnings?: string[] // Warning diagnostics, formatted like errors
+  notes?: string[] // Note diagnostics, formatted like errors
+  ran?: boolean // Whether the program was executed (run mode)
+  run_stdout?: string
+  run_stderr?: string
+  run_exit_code?: number
+  run_timed_out?: boolean // Program exceeded the run timeout (distinct from compile timeout)
+  binary_bytes?: number // Size of the produced binary (omitted when none was produced)
+  analysis?: Diagnostic[] // Static analyzer findings (when requested), separate from diagnostics
+  cached?: boolean // Served from the result cache of an identical earlier request
+  cache_age?: number // Nanoseconds since the cached result was produced
+  includes?: string[] // Headers included by the source, from -MM (when requested)
+  terminated_by_signal?: string // Signal that killed the compiler, e.g. SIGKILL (omitted on a normal exit)
+  source_hash?: string // SHA-256 of the submitted source
+  output_hash?: string // SHA-256 of the produced binary (omitted when none was produced)
+  tested?: boolean // Whether the test runner ran (test mode)
+  tests_passed?: number // Tests that passed (test mode)
+  tests_failed?: number // Tests that failed (test mode)
+  format_diff?: string // Formatter output for unformatted code (check_format)
+  preprocessed?: string // Preprocessor output (preprocess)
+  preprocessed_truncated?: boolean // Whether preprocessed was cut at the artifact size limit
+  resource_usage?: ResourceUsage // Compiler resource usage (resource_usage)
+  diagnostics_html?: string // Escaped HTML rendering of the diagnostics (diagnostics_html)
+  run_stdout_html?: string // Escaped HTML rendering of run_stdout with its colors as spans (run_stdout_html)
+  run_success?: boolean // Program ran, exited 0 and did not time out; compiled/exit_code only cover the compile
+  run_error?: string // Why the program failed beyond its exit code (e.g., killed by the sandbox)
+  symbols?: string[] // Symbol names of the produced binary (symbols)
+  symbols_truncated?: boolean // Whether symbols was cut at the symbol limit
+  network_enabled: boolean // Whether the compile container could reach the network
 }
 
 // CompilationJob represents a job to be processed
@@ -59,12 +132,37 @@ export interface CompilationJob {
   created_at: string // ISO 8601 timestamp
   started_at?: string // ISO 8601 timestamp
   completed_at?: string // ISO 8601 timestamp
+  last_heartbeat?: string // ISO 8601 timestamp, refreshed while processing
+}
+
+// ResourceUsage is the compiler's resource usage as reported by /usr/bin/time
+export interface ResourceUsage {
+  max_rss_kb: number // Peak resident set size in kilobytes
+  user_seconds: number
+  system_seconds: number
 }
 
 // JobResponse is returned when a job is created
 export interface JobResponse {
   job_id: string
   status: JobStatus
+  estimated_wait_ms?: number // Only on submission; absent when a worker is free
+}
+
+// SourceUploadResponse is returned when a chunked upload is created or a chunk is appended
+export interface SourceUploadResponse {
+  upload_id: string
+  size: number // Bytes received so far
+}
+
+// BatchCompilationRequest submits several compilation requests at once
+export interface BatchCompilationRequest {
+  requests: CompilationRequest[]
+}
+
+// BatchJobResponse is returned when a batch is queued, one job per request in order
+export interface BatchJobResponse {
+  jobs: JobResponse[]
 }
 
 // ErrorResponse represents an API error
@@ -83,6 +181,32 @@ export interface EnvironmentSpec {
   os: OS
   image_tag: string // Docker image tag
   flags?: string[]
+  analyzer?: Analyzer
+  formatter?: Formatter
+  capabilities: Capabilities
+}
+
+// Capabilities lists the optional request fields an environment supports
+export interface Capabilities {
+  run: boolean
+  json_diagnostics: boolean
+  diagnostics_width: boolean
+  linker: boolean
+  cross_compile: boolean
+  analyze: boolean
+  dependencies: boolean
+  werror_for: boolean
+  compile_only: boolean
+  dialect_options: boolean
+  test: boolean
+  check_format: boolean
+  quick: boolean
+  preprocess: boolean
+  resource_usage: boolean
+  symbols: boolean
+  flags: boolean
+  libc?: Libc[] // Accepted "libc" values, the default image's first
+  toolchains?: string[] // Accepted "toolchain" values
 }
 
 // Environment represents a supported compilation environment
@@ -92,6 +216,7 @@ export interface Environment {
   standards?: string[]
   oses: string[]
   architectures: string[]
+  capabilities?: Record<string, Capabilities> // Keyed by compiler
 }
 
 // Helper type for UI state
@@ -112,11 +237,52 @@ export interface WorkerStats {
   total_failed: number      // Code failed to compile (user errors)
   total_timeout: number     // Compilation timed out
   total_errors: number      // Infrastructure/system errors
+  total_rejected: number    // Submissions turned away (queue full or no workers)
   uptime: string
   uptime_seconds: number
   start_time: string // ISO 8601 timestamp
 }
 
+// JobEvent is one status change in a job's timeline
+export interface JobEvent {
+  status: JobStatus
+  timestamp: string // RFC 3339 timestamp with nanoseconds
+  duration?: number // Nanoseconds spent in this status (omitted for the current one)
+}
+
+// JobEventsResponse is the response of /api/v1/compile/{job_id}/events
+export interface JobEventsResponse {
+  job_id: string
+  events: JobEvent[]
+}
+
+// ResultDiff is the response of /api/v1/compile/{job_id}/diff?against={other_job_id}
+export interface ResultDiff {
+  job_id: string
+  against: string // Baseline job
+  changed: boolean // Compiled status, exit code or diagnostics differ
+  regressed: boolean // The baseline compiled and this job does not
+  compiled: { before: boolean; after: boolean }
+  exit_code: { before: number; after: number }
+  added_diagnostics?: Diagnostic[] // In this job but not the baseline (matched ignoring line and column)
+  removed_diagnostics?: Diagnostic[] // In the baseline but not this job
+}
+
+// QueueSnapshot is the response of /api/v1/queue (requires the admin token)
+export interface QueueSnapshot {
+  queued: { job_id: string; position: number }[] // In processing order, position 1 is next
+  processing: { job_id: string; started_at: string }[] // Oldest first
+  time: string // ISO 8601 timestamp
+}
+
+// DetailedHealth is the response of /health/detailed
+export interface DetailedHealth {
+  status: string
+  time: string // ISO 8601 timestamp
+  docker_ping_ms?: number // Latest Docker daemon ping (omitted when unknown)
+  workers: WorkerStats
+}
+
 // Default values for optional fields
 export const DEFAULT_STANDARD: Standard = 'c++20'
 export const DEFAULT_ARCHITECTURE: Architecture = 'x86_64'